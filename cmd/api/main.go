@@ -40,6 +40,8 @@ func main() {
 	}
 	defer container.Close()
 
+	container.StartWorkers(ctx)
+
 	// Create HTTP server
 	httpServer := server.NewServer(container)
 
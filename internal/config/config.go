@@ -15,6 +15,9 @@ type Config struct {
 	Redis    RedisConfig
 	Logger   LoggerConfig
 	Tracer   TracerConfig
+	Sandbox  SandboxConfig
+	Delivery DeliveryConfig
+	Replay   ReplayConfig
 }
 
 type ServerConfig struct {
@@ -67,6 +70,43 @@ type TracerConfig struct {
 	ServiceName string `env:"OTEL_SERVICE_NAME" envDefault:"catchook-api"`
 }
 
+// SandboxConfig limits the execution of user code in filters and
+// transformations.
+type SandboxConfig struct {
+	ScriptTimeout     time.Duration `env:"SANDBOX_SCRIPT_TIMEOUT" envDefault:"250ms"`
+	ScriptMemoryLimit uint64        `env:"SANDBOX_SCRIPT_MEMORY_LIMIT" envDefault:"33554432"`               // 32MB of heap growth
+	ScriptMaxInput    int           `env:"SANDBOX_SCRIPT_MAX_INPUT" envDefault:"1048576" validate:"min=0"`  // 1MB
+	ScriptMaxOutput   int           `env:"SANDBOX_SCRIPT_MAX_OUTPUT" envDefault:"1048576" validate:"min=0"` // 1MB
+	ScriptConcurrency int           `env:"SANDBOX_SCRIPT_CONCURRENCY" envDefault:"4" validate:"min=1"`
+	MaxLogLines       int           `env:"SANDBOX_MAX_LOG_LINES" envDefault:"100" validate:"min=0"`
+	WasmTimeout       time.Duration `env:"SANDBOX_WASM_TIMEOUT" envDefault:"500ms"`
+	WasmMemoryLimit   uint64        `env:"SANDBOX_WASM_MEMORY_LIMIT" envDefault:"67108864"`   // 64MB
+	WasmMaxModuleSize int           `env:"SANDBOX_WASM_MAX_MODULE_SIZE" envDefault:"4194304"` // 4MB, bounded by SERVER_BODY_LIMIT
+}
+
+// DeliveryConfig tunes the clients that deliver events to destinations.
+type DeliveryConfig struct {
+	MaxIdleConnsPerHost  int           `env:"DELIVERY_MAX_IDLE_CONNS_PER_HOST" envDefault:"16" validate:"min=1"`
+	IdleConnTimeout      time.Duration `env:"DELIVERY_IDLE_CONN_TIMEOUT" envDefault:"90s"`
+	MaxResponseBodySize  int64         `env:"DELIVERY_MAX_RESPONSE_BODY_SIZE" envDefault:"65536" validate:"min=0"` // 64KB kept per attempt
+	PollInterval         time.Duration `env:"DELIVERY_POLL_INTERVAL" envDefault:"1s"`
+	BatchSize            int           `env:"DELIVERY_BATCH_SIZE" envDefault:"50" validate:"min=1"`
+	Concurrency          int           `env:"DELIVERY_CONCURRENCY" envDefault:"10" validate:"min=1"`
+	RetryLease           time.Duration `env:"DELIVERY_RETRY_LEASE" envDefault:"6m"`        // above the 300s maximum destination timeout; also bounds in-flight slots
+	FileAllowedDirs      []string      `env:"DELIVERY_FILE_ALLOWED_DIRS" envSeparator:","` // empty disables file destinations
+	FileIdleTimeout      time.Duration `env:"DELIVERY_FILE_IDLE_TIMEOUT" envDefault:"5m"`
+	DatabaseTimeout      time.Duration `env:"DELIVERY_DATABASE_TIMEOUT" envDefault:"10s"` // per batch insert and table check
+	DatabaseIdleTimeout  time.Duration `env:"DELIVERY_DATABASE_IDLE_TIMEOUT" envDefault:"5m"`
+	DatabaseAllowedHosts []string      `env:"DELIVERY_DATABASE_ALLOWED_HOSTS" envSeparator:","` // empty disables database destinations
+}
+
+// ReplayConfig tunes the runner of the replay jobs.
+type ReplayConfig struct {
+	PollInterval time.Duration `env:"REPLAY_POLL_INTERVAL" envDefault:"1s"`
+	Lease        time.Duration `env:"REPLAY_LEASE" envDefault:"6m"` // renewed after each event, so above the longest delivery
+	BatchSize    int           `env:"REPLAY_BATCH_SIZE" envDefault:"100" validate:"min=1"`
+}
+
 func Load() (*Config, error) {
 	cfg := &Config{}
 	if err := godotenv.Load(); err != nil {
@@ -0,0 +1,424 @@
+package deliverer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+)
+
+// DatabaseOptions tunes the database deliverer.
+type DatabaseOptions struct {
+	// Timeout bounds the insert of a batch.
+	Timeout time.Duration
+	// IdleConnTimeout closes the connections unused for that long.
+	IdleConnTimeout time.Duration
+	// IdleTimeout closes the pool and the batcher of the destinations that
+	// received nothing for that long.
+	IdleTimeout time.Duration
+	// AllowedHosts are the database servers the destinations may connect
+	// to.
+	AllowedHosts destination.HostAllowlist
+}
+
+// maxQueryParams is the number of parameters Postgres accepts in a query,
+// which bounds the rows of a batch.
+const maxQueryParams = 65535
+
+var errTargetClosed = errors.New("destination is being reconfigured")
+
+// DatabaseDeliverer inserts events into the Postgres tables of database
+// destinations. Each destination gets its own connection pool, rebuilt when
+// the destination is updated, and its own batcher: the events delivered at
+// the same time are inserted by a single statement.
+type DatabaseDeliverer struct {
+	opts      DatabaseOptions
+	appLogger logger.Logger
+
+	mu        sync.Mutex
+	targets   map[string]*databaseTarget
+	lastSweep time.Time
+}
+
+type databaseTarget struct {
+	updatedAt time.Time
+	config    *destination.DatabaseConfig
+	pool      *pgxpool.Pool
+	batchSize int
+
+	// inFlight and usedAt are guarded by the mutex of the deliverer, so
+	// that a target is never swept while a delivery uses it.
+	inFlight int
+	usedAt   time.Time
+
+	rows chan *databaseRow
+	stop chan struct{}
+	// slots bounds the batches inserted at once to the size of the pool.
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+type databaseRow struct {
+	values []*string
+	result chan error
+}
+
+func NewDatabaseDeliverer(opts DatabaseOptions, appLogger logger.Logger) *DatabaseDeliverer {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = 90 * time.Second
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = 5 * time.Minute
+	}
+	return &DatabaseDeliverer{
+		opts:      opts,
+		appLogger: appLogger,
+		targets:   map[string]*databaseTarget{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Deliver inserts the row mapped from the event and waits for the batch
+// holding it. An event whose values the table rejects, such as a failed
+// cast or a constraint violation, fails for good; connection errors are
+// retried. A delivery given up on while its batch is inserted may still
+// land, so a retry can insert the event twice: upsert mode avoids that.
+func (d *DatabaseDeliverer) Deliver(ctx context.Context, dest *destination.Destination, event *pipeline.Event) (*delivery.Result, error) {
+	result := &delivery.Result{}
+	start := time.Now()
+
+	t, err := d.acquire(dest)
+	if err != nil {
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+	defer d.release(t)
+	result.Table = t.config.Table
+
+	databaseEvent := &destination.DatabaseEvent{
+		ID:         event.ID,
+		SourceID:   event.SourceID,
+		PipelineID: event.PipelineID,
+		ReceivedAt: event.CreatedAt,
+		Payload:    event.Payload,
+	}
+	if event.IsJSON() {
+		if body, err := event.Body(); err == nil {
+			databaseEvent.Body = body
+		}
+	}
+	values, err := t.config.Values(databaseEvent)
+	if err != nil {
+		err = fmt.Errorf("map event: %w", err)
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+
+	row := &databaseRow{values: values, result: make(chan error, 1)}
+	select {
+	case t.rows <- row:
+		select {
+		case err = <-row.result:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	case <-t.stop:
+		err = errTargetClosed
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		if rowError(err) {
+			return result, delivery.Permanent(err)
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+// acquire returns the target of the destination, building it on first use
+// or when the destination has been updated since, and marks it in use
+// until release is called.
+func (d *DatabaseDeliverer) acquire(dest *destination.Destination) (*databaseTarget, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sweep()
+
+	t, err := d.target(dest)
+	if err != nil {
+		return nil, err
+	}
+	t.inFlight++
+	t.usedAt = time.Now()
+	return t, nil
+}
+
+func (d *DatabaseDeliverer) release(t *databaseTarget) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t.inFlight--
+	t.usedAt = time.Now()
+}
+
+// target returns the target of the destination. The caller holds the
+// mutex of the deliverer.
+func (d *DatabaseDeliverer) target(dest *destination.Destination) (*databaseTarget, error) {
+	t, ok := d.targets[dest.ID]
+	if ok && t.updatedAt.Equal(dest.UpdatedAt) {
+		return t, nil
+	}
+
+	var config destination.DatabaseConfig
+	if err := json.Unmarshal([]byte(dest.Config), &config); err != nil {
+		return nil, fmt.Errorf("invalid database config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid database config: %w", err)
+	}
+	allowed, err := d.opts.AllowedHosts.AllowsConnection(config.ConnectionString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database config: connection_string is not valid")
+	}
+	if !allowed {
+		return nil, fmt.Errorf("connection_string connects to a host not allowed for database destinations")
+	}
+	poolConfig, err := pgxpool.ParseConfig(config.ConnectionString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database config: connection_string is not valid")
+	}
+	poolConfig.MaxConns = config.MaxConns
+	poolConfig.MinConns = 0
+	poolConfig.MaxConnIdleTime = d.opts.IdleConnTimeout
+	poolConfig.ConnConfig.ConnectTimeout = 10 * time.Second
+	// The pool connects lazily: an unreachable database fails the inserts,
+	// which are retried, not the creation of the pool.
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create pool: %w", err)
+	}
+
+	columns := len(config.ColumnNames())
+	t = &databaseTarget{
+		updatedAt: dest.UpdatedAt,
+		config:    &config,
+		pool:      pool,
+		batchSize: min(config.BatchSize, maxQueryParams/columns),
+		rows:      make(chan *databaseRow),
+		stop:      make(chan struct{}),
+		slots:     make(chan struct{}, config.MaxConns),
+	}
+	t.wg.Add(1)
+	go d.collect(t)
+
+	if previous := d.targets[dest.ID]; previous != nil {
+		go previous.close()
+	}
+	d.targets[dest.ID] = t
+	return t, nil
+}
+
+// collect gathers the rows delivered to the target into batches: a batch
+// starts with the first row received and is inserted once full or once
+// the batch wait has passed.
+func (d *DatabaseDeliverer) collect(t *databaseTarget) {
+	defer t.wg.Done()
+	wait := time.Duration(t.config.BatchWait) * time.Millisecond
+	for {
+		var batch []*databaseRow
+		select {
+		case <-t.stop:
+			return
+		case row := <-t.rows:
+			batch = append(batch, row)
+		}
+
+		timer := time.NewTimer(wait)
+	fill:
+		for len(batch) < t.batchSize {
+			select {
+			case row := <-t.rows:
+				batch = append(batch, row)
+			case <-timer.C:
+				break fill
+			case <-t.stop:
+				break fill
+			}
+		}
+		timer.Stop()
+
+		t.slots <- struct{}{}
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			defer func() { <-t.slots }()
+			d.flush(t, batch)
+		}()
+	}
+}
+
+// flush inserts a batch and hands its outcome to each of its rows. When
+// the table rejects the batch, its rows are inserted one by one so that
+// only the rows at fault fail.
+func (d *DatabaseDeliverer) flush(t *databaseTarget, batch []*databaseRow) {
+	err := d.insert(t, batch)
+	if err == nil || len(batch) == 1 || !rowError(err) {
+		for _, row := range batch {
+			row.result <- err
+		}
+		return
+	}
+
+	d.appLogger.Warn(context.Background(), "Database batch rejected, inserting its rows one by one",
+		logger.String("table", t.config.Table),
+		logger.Int("rows", len(batch)),
+		logger.Error(err),
+	)
+	for _, row := range batch {
+		row.result <- d.insert(t, []*databaseRow{row})
+	}
+}
+
+func (d *DatabaseDeliverer) insert(t *databaseTarget, batch []*databaseRow) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.opts.Timeout)
+	defer cancel()
+
+	query, args := insertQuery(t.config, batch)
+	if _, err := t.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("insert into %s: %w", t.config.Table, err)
+	}
+	return nil
+}
+
+// insertQuery builds the statement inserting the rows. Every value is sent
+// as text and cast to the type of its column.
+func insertQuery(config *destination.DatabaseConfig, batch []*databaseRow) (string, []any) {
+	names := config.ColumnNames()
+	schema, name, _ := config.TableName()
+	table := pgx.Identifier{name}
+	if schema != "" {
+		table = pgx.Identifier{schema, name}
+	}
+
+	quoted := make([]string, len(names))
+	for i, column := range names {
+		quoted[i] = pgx.Identifier{column}.Sanitize()
+	}
+
+	var b strings.Builder
+	args := make([]any, 0, len(batch)*len(names))
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", table.Sanitize(), strings.Join(quoted, ", "))
+	for r, row := range batch {
+		if r > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for i, value := range row.values {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			args = append(args, value)
+			fmt.Fprintf(&b, "$%d::text::%s", len(args), config.ColumnSQLType(i))
+		}
+		b.WriteByte(')')
+	}
+
+	if config.Mode == destination.DatabaseModeUpsert {
+		conflict := make([]string, len(config.ConflictColumns))
+		for i, column := range config.ConflictColumns {
+			conflict[i] = pgx.Identifier{column}.Sanitize()
+		}
+		var updates []string
+		for i, column := range names {
+			if !slices.Contains(config.ConflictColumns, column) {
+				updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", quoted[i], quoted[i]))
+			}
+		}
+		fmt.Fprintf(&b, " ON CONFLICT (%s) ", strings.Join(conflict, ", "))
+		if len(updates) == 0 {
+			b.WriteString("DO NOTHING")
+		} else {
+			b.WriteString("DO UPDATE SET " + strings.Join(updates, ", "))
+		}
+	}
+	return b.String(), args
+}
+
+// rowError reports whether the database rejected the values of a row
+// rather than failed: data exceptions, constraint violations, an upsert
+// touching a row twice, and statements the table no longer fits.
+func rowError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || len(pgErr.Code) < 2 {
+		return false
+	}
+	switch pgErr.Code[:2] {
+	case "21", "22", "23", "42":
+		return true
+	}
+	return false
+}
+
+// sweep closes the targets left idle, at most once per idle timeout. The
+// caller holds the mutex of the deliverer.
+func (d *DatabaseDeliverer) sweep() {
+	now := time.Now()
+	if now.Sub(d.lastSweep) < d.opts.IdleTimeout {
+		return
+	}
+	d.lastSweep = now
+	for id, t := range d.targets {
+		if t.inFlight == 0 && now.Sub(t.usedAt) >= d.opts.IdleTimeout {
+			delete(d.targets, id)
+			go t.close()
+		}
+	}
+}
+
+// Invalidate closes the target of a destination. The deliveries using it
+// fail and are retried with a new target.
+func (d *DatabaseDeliverer) Invalidate(id string) {
+	d.mu.Lock()
+	t := d.targets[id]
+	delete(d.targets, id)
+	d.mu.Unlock()
+	if t != nil {
+		go t.close()
+	}
+}
+
+// close stops the batcher of the target, waits for the batches being
+// inserted and closes its pool.
+func (t *databaseTarget) close() {
+	close(t.stop)
+	t.wg.Wait()
+	t.pool.Close()
+}
+
+// Close closes the pool of every destination.
+func (d *DatabaseDeliverer) Close() {
+	d.mu.Lock()
+	targets := d.targets
+	d.targets = map[string]*databaseTarget{}
+	d.mu.Unlock()
+
+	for _, t := range targets {
+		t.close()
+	}
+}
@@ -0,0 +1,445 @@
+package deliverer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+)
+
+// FileOptions tunes the file deliverer.
+type FileOptions struct {
+	// AllowedDirs are the directories the files may be written into.
+	AllowedDirs destination.DirAllowlist
+	// IdleTimeout closes the files that received nothing for that long.
+	IdleTimeout time.Duration
+}
+
+const (
+	fileDirMode  = 0o750
+	fileMode     = 0o640
+	rotatedStamp = "20060102T150405.000000000Z"
+)
+
+// FileDeliverer appends events to files on the host. The files stay open
+// between events and are rotated on size or age, the rotated files being
+// compressed in the background when the destination asks for it. A file is
+// meant to be written by a single server: appends are atomic, but two
+// servers would rotate it independently.
+type FileDeliverer struct {
+	opts      FileOptions
+	appLogger logger.Logger
+
+	mu        sync.Mutex
+	files     map[string]*appender
+	lastSweep time.Time
+	// compressing tracks the rotated files being compressed.
+	compressing sync.WaitGroup
+}
+
+// appender is an open file. Its mutex serializes the writes and the
+// rotation of the file.
+type appender struct {
+	mu   sync.Mutex
+	path string
+	// closed is set once the appender is removed from the deliverer, by a
+	// sweep or on close: a writer that got it before must look it up again.
+	closed   bool
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	syncedAt time.Time
+	usedAt   time.Time
+}
+
+type fileRecord struct {
+	ID          string            `json:"id"`
+	SourceID    string            `json:"source_id"`
+	PipelineID  string            `json:"pipeline_id,omitempty"`
+	Headers     map[string]string `json:"headers"`
+	ContentType string            `json:"content_type"`
+	Payload     json.RawMessage   `json:"payload"`
+	ReceivedAt  time.Time         `json:"received_at"`
+	DeliveredAt time.Time         `json:"delivered_at"`
+}
+
+func NewFileDeliverer(opts FileOptions, appLogger logger.Logger) *FileDeliverer {
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = 5 * time.Minute
+	}
+	return &FileDeliverer{
+		opts:      opts,
+		appLogger: appLogger,
+		files:     map[string]*appender{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Deliver appends the event to the file its path template resolves to.
+// Configuration errors and paths outside the allowed directories are
+// permanent; I/O errors are retried.
+func (d *FileDeliverer) Deliver(ctx context.Context, dest *destination.Destination, event *pipeline.Event) (*delivery.Result, error) {
+	result := &delivery.Result{}
+	start := time.Now()
+
+	var config destination.FileConfig
+	if err := json.Unmarshal([]byte(dest.Config), &config); err != nil {
+		err = fmt.Errorf("invalid file config: %w", err)
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+	if err := config.Validate(); err != nil {
+		err = fmt.Errorf("invalid file config: %w", err)
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+
+	receivedAt := event.CreatedAt
+	if receivedAt.IsZero() {
+		receivedAt = start
+	}
+	path := config.Expand(dest.ID, event.SourceID, event.PipelineID, event.ID, receivedAt)
+	result.Path = path
+	if !d.opts.AllowedDirs.Allows(path) {
+		err := fmt.Errorf("path %s is outside the directories allowed for file destinations", path)
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+
+	line, err := encodeFileRecord(&config, event, receivedAt, start)
+	if err != nil {
+		err = fmt.Errorf("encode event: %w", err)
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+
+	if err := d.write(ctx, &config, path, line); err != nil {
+		result.DurationMs = time.Since(start).Milliseconds()
+		result.Error = err.Error()
+		return result, err
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	d.sweep(ctx)
+	return result, nil
+}
+
+func encodeFileRecord(config *destination.FileConfig, event *pipeline.Event, receivedAt, deliveredAt time.Time) ([]byte, error) {
+	if config.Format == destination.FileFormatRaw {
+		line := event.Payload
+		if !bytes.HasSuffix(line, []byte("\n")) {
+			line = append(line[:len(line):len(line)], '\n')
+		}
+		return line, nil
+	}
+
+	payload := json.RawMessage(event.Payload)
+	if !event.IsJSON() {
+		encoded, err := json.Marshal(string(event.Payload))
+		if err != nil {
+			return nil, err
+		}
+		payload = encoded
+	} else if len(bytes.TrimSpace(payload)) == 0 {
+		payload = json.RawMessage("null")
+	}
+
+	var buf bytes.Buffer
+	// Encode compacts the payload and ends the record with a newline.
+	if err := json.NewEncoder(&buf).Encode(&fileRecord{
+		ID:          event.ID,
+		SourceID:    event.SourceID,
+		PipelineID:  event.PipelineID,
+		Headers:     event.DeliveryHeaders(),
+		ContentType: event.PayloadContentType(),
+		Payload:     payload,
+		ReceivedAt:  receivedAt.UTC(),
+		DeliveredAt: deliveredAt.UTC(),
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write appends the line to the file, rotating it first when the line
+// would exceed its size or the file is too old.
+func (d *FileDeliverer) write(ctx context.Context, config *destination.FileConfig, path string, line []byte) error {
+	a := d.lockAppender(path)
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if a.file != nil && a.size > 0 && rotationDue(config.Rotation, a, int64(len(line)), now) {
+		if err := d.rotate(ctx, config, a, now); err != nil {
+			return err
+		}
+	}
+	if a.file == nil {
+		if err := d.open(a, now); err != nil {
+			return err
+		}
+	}
+
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	a.usedAt = now
+	if err != nil {
+		// The file may have been removed or its disk may be full: it is
+		// opened again on the next attempt.
+		d.closeFile(ctx, a)
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	if config.Sync == destination.FileSyncAlways ||
+		(config.Sync == destination.FileSyncInterval && now.Sub(a.syncedAt) >= config.SyncEvery()) {
+		if err := a.file.Sync(); err != nil {
+			d.closeFile(ctx, a)
+			return fmt.Errorf("sync %s: %w", path, err)
+		}
+		a.syncedAt = now
+	}
+	return nil
+}
+
+func rotationDue(rotation destination.FileRotation, a *appender, size int64, now time.Time) bool {
+	if rotation.MaxSize > 0 && a.size+size > rotation.MaxSize {
+		return true
+	}
+	return rotation.MaxAge > 0 && now.Sub(a.openedAt) >= time.Duration(rotation.MaxAge)*time.Second
+}
+
+// open opens the file of the appender for appending, creating its
+// directories. The directory is checked again once symbolic links are
+// resolved, so that a link cannot lead the file out of the allowed
+// directories.
+func (d *FileDeliverer) open(a *appender, now time.Time) error {
+	dir := filepath.Dir(a.path)
+	if err := os.MkdirAll(dir, fileDirMode); err != nil {
+		return fmt.Errorf("create directory %s: %w", dir, err)
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("resolve directory %s: %w", dir, err)
+	}
+	if !d.resolvedAllows(filepath.Join(resolved, filepath.Base(a.path))) {
+		return delivery.Permanent(fmt.Errorf("path %s resolves outside the directories allowed for file destinations", a.path))
+	}
+
+	file, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, fileMode)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", a.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat %s: %w", a.path, err)
+	}
+	if !info.Mode().IsRegular() {
+		_ = file.Close()
+		return delivery.Permanent(fmt.Errorf("%s is not a regular file", a.path))
+	}
+
+	a.file = file
+	a.size = info.Size()
+	a.openedAt = now
+	a.syncedAt = now
+	return nil
+}
+
+// resolvedAllows checks a path whose symbolic links are resolved against
+// the allowed directories, resolved the same way.
+func (d *FileDeliverer) resolvedAllows(path string) bool {
+	for _, dir := range d.opts.AllowedDirs {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = resolved
+		}
+		if (destination.DirAllowlist{dir}).Allows(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// rotate closes the file and renames it after the time of the rotation,
+// before the extension, then compresses it in the background if needed.
+func (d *FileDeliverer) rotate(ctx context.Context, config *destination.FileConfig, a *appender, now time.Time) error {
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", a.path, err)
+	}
+	if err := a.file.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", a.path, err)
+	}
+	a.file = nil
+
+	ext := filepath.Ext(a.path)
+	rotated := strings.TrimSuffix(a.path, ext) + "." + now.UTC().Format(rotatedStamp) + ext
+	if err := os.Rename(a.path, rotated); err != nil {
+		return fmt.Errorf("rotate %s: %w", a.path, err)
+	}
+
+	if config.Compression != destination.FileCompressionNone {
+		d.compressing.Add(1)
+		go func() {
+			defer d.compressing.Done()
+			if err := compressFile(rotated, config.Compression); err != nil {
+				d.appLogger.Error(ctx, "Failed to compress rotated file",
+					logger.String("path", rotated),
+					logger.Error(err),
+				)
+			}
+		}()
+	}
+	return nil
+}
+
+// compressFile writes the compressed copy of the file next to it, then
+// removes the file. The copy is written under a temporary name so that a
+// partial copy is never mistaken for a complete one.
+func compressFile(path string, compression destination.FileCompression) (err error) {
+	ext := ".gz"
+	if compression == destination.FileCompressionZstd {
+		ext = ".zst"
+	}
+	target := path + ext
+	tmp := target + ".tmp"
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	var w io.WriteCloser
+	if compression == destination.FileCompressionZstd {
+		if w, err = zstd.NewWriter(dst); err != nil {
+			return err
+		}
+	} else {
+		w = gzip.NewWriter(dst)
+	}
+	if _, err = io.Copy(w, src); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = dst.Sync(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, target); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (d *FileDeliverer) appender(path string) *appender {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	a, ok := d.files[path]
+	if !ok {
+		a = &appender{path: path}
+		d.files[path] = a
+	}
+	return a
+}
+
+// lockAppender returns the locked appender of the path. An appender swept
+// between its lookup and its lock is closed and no longer tracked, so it is
+// looked up again rather than used to reopen an untracked file.
+func (d *FileDeliverer) lockAppender(path string) *appender {
+	for {
+		a := d.appender(path)
+		a.mu.Lock()
+		if !a.closed {
+			return a
+		}
+		a.mu.Unlock()
+	}
+}
+
+// sweep closes the files left idle, at most once per idle timeout. The
+// date placeholders of the paths leave the files of the previous days
+// behind, which are closed this way.
+func (d *FileDeliverer) sweep(ctx context.Context) {
+	now := time.Now()
+	d.mu.Lock()
+	if now.Sub(d.lastSweep) < d.opts.IdleTimeout {
+		d.mu.Unlock()
+		return
+	}
+	d.lastSweep = now
+	idle := make([]*appender, 0)
+	for path, a := range d.files {
+		if a.mu.TryLock() {
+			if now.Sub(a.usedAt) >= d.opts.IdleTimeout {
+				delete(d.files, path)
+				a.closed = true
+				idle = append(idle, a)
+			}
+			a.mu.Unlock()
+		}
+	}
+	d.mu.Unlock()
+
+	for _, a := range idle {
+		a.mu.Lock()
+		d.closeFile(ctx, a)
+		a.mu.Unlock()
+	}
+}
+
+// closeFile flushes and closes the file of the appender, if open. The
+// caller holds the lock of the appender.
+func (d *FileDeliverer) closeFile(ctx context.Context, a *appender) {
+	if a.file == nil {
+		return
+	}
+	err := errors.Join(a.file.Sync(), a.file.Close())
+	a.file = nil
+	if err != nil {
+		d.appLogger.Warn(ctx, "Failed to close file", logger.String("path", a.path), logger.Error(err))
+	}
+}
+
+// Close closes every file and waits for the compressions in progress.
+func (d *FileDeliverer) Close() {
+	d.mu.Lock()
+	files := d.files
+	d.files = map[string]*appender{}
+	d.mu.Unlock()
+
+	for _, a := range files {
+		a.mu.Lock()
+		a.closed = true
+		d.closeFile(context.Background(), a)
+		a.mu.Unlock()
+	}
+	d.compressing.Wait()
+}
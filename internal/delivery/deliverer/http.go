@@ -0,0 +1,220 @@
+package deliverer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+)
+
+// HTTPOptions tunes the connection pools of the HTTP deliverer.
+type HTTPOptions struct {
+	// MaxIdleConnsPerHost is the number of keep-alive connections kept
+	// open to each host of a destination.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes keep-alive connections unused for that long.
+	IdleConnTimeout time.Duration
+	// MaxResponseBodySize bounds the part of the response body stored with
+	// the delivery step.
+	MaxResponseBodySize int64
+}
+
+// maxDrainSize bounds how much of a response body is read, beyond the part
+// kept, so that the connection can be reused.
+const maxDrainSize = 256 << 10
+
+// HTTPDeliverer sends events to HTTP destinations. Each destination gets
+// its own client and connection pool, rebuilt when the destination is
+// updated, so that a slow destination cannot exhaust the connections of
+// the others.
+type HTTPDeliverer struct {
+	opts    HTTPOptions
+	mu      sync.RWMutex
+	clients map[string]*httpClient
+}
+
+type httpClient struct {
+	updatedAt time.Time
+	config    *destination.HTTPConfig
+	client    *http.Client
+	transport *http.Transport
+}
+
+func NewHTTPDeliverer(opts HTTPOptions) *HTTPDeliverer {
+	if opts.MaxIdleConnsPerHost <= 0 {
+		opts.MaxIdleConnsPerHost = 16
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = 90 * time.Second
+	}
+	if opts.MaxResponseBodySize <= 0 {
+		opts.MaxResponseBodySize = 64 << 10
+	}
+	return &HTTPDeliverer{opts: opts, clients: map[string]*httpClient{}}
+}
+
+// Deliver sends the payload of the event, converted to the content type of
+// the destination, with the delivery headers of the event and the
+// destination auth.
+func (d *HTTPDeliverer) Deliver(ctx context.Context, dest *destination.Destination, event *pipeline.Event) (*delivery.Result, error) {
+	result := &delivery.Result{}
+
+	c, err := d.client(dest)
+	if err != nil {
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+	result.Method = string(c.config.Method)
+	result.URL = c.config.URL
+
+	body, err := c.config.Negotiate(event.Payload, event.PayloadContentType())
+	if err != nil {
+		err = fmt.Errorf("convert payload: %w", err)
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+
+	var reader io.Reader
+	if c.config.Method != destination.HTTPMethodGET {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, string(c.config.Method), c.config.URL, reader)
+	if err != nil {
+		err = fmt.Errorf("build request: %w", err)
+		result.Error = err.Error()
+		return result, delivery.Permanent(err)
+	}
+	for name, value := range c.config.RequestHeaders(event.DeliveryHeaders(), event.PayloadContentType()) {
+		req.Header.Set(name, value)
+	}
+	if c.config.Signing != nil {
+		// Sign the bytes actually sent: GET requests carry no body.
+		signed := body
+		if reader == nil {
+			signed = nil
+		}
+		headers, err := c.config.Signing.Sign(event.ID, signed, time.Now())
+		if err != nil {
+			err = fmt.Errorf("sign request: %w", err)
+			result.Error = err.Error()
+			return result, delivery.Permanent(err)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		result.DurationMs = time.Since(start).Milliseconds()
+		result.Error = err.Error()
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	kept, err := io.ReadAll(io.LimitReader(resp.Body, d.opts.MaxResponseBodySize+1))
+	if err == nil && int64(len(kept)) > d.opts.MaxResponseBodySize {
+		kept = kept[:d.opts.MaxResponseBodySize]
+		result.Truncated = true
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainSize))
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	result.StatusCode = resp.StatusCode
+	result.Body = string(kept)
+	result.Headers = make(map[string]string, len(resp.Header))
+	for name := range resp.Header {
+		result.Headers[name] = resp.Header.Get(name)
+	}
+
+	if !result.Succeeded() {
+		err = fmt.Errorf("destination responded with status %d", resp.StatusCode)
+		result.Error = err.Error()
+		return result, err
+	}
+	return result, nil
+}
+
+// client returns the client of the destination, building it on first use
+// or when the destination has been updated since. The destination service
+// invalidates the client on update and delete; comparing the update time
+// covers the changes made through another instance.
+func (d *HTTPDeliverer) client(dest *destination.Destination) (*httpClient, error) {
+	d.mu.RLock()
+	c, ok := d.clients[dest.ID]
+	d.mu.RUnlock()
+	if ok && c.updatedAt.Equal(dest.UpdatedAt) {
+		return c, nil
+	}
+
+	var config destination.HTTPConfig
+	if err := json.Unmarshal([]byte(dest.Config), &config); err != nil {
+		return nil, fmt.Errorf("invalid http config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid http config: %w", err)
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          d.opts.MaxIdleConnsPerHost * 4,
+		MaxIdleConnsPerHost:   d.opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:       d.opts.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	c = &httpClient{
+		updatedAt: dest.UpdatedAt,
+		config:    &config,
+		transport: transport,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(config.Timeout) * time.Second,
+		},
+	}
+
+	d.mu.Lock()
+	previous := d.clients[dest.ID]
+	d.clients[dest.ID] = c
+	d.mu.Unlock()
+	if previous != nil {
+		previous.transport.CloseIdleConnections()
+	}
+	return c, nil
+}
+
+// Invalidate drops the client of a destination and closes its idle
+// connections.
+func (d *HTTPDeliverer) Invalidate(id string) {
+	d.mu.Lock()
+	c := d.clients[id]
+	delete(d.clients, id)
+	d.mu.Unlock()
+	if c != nil {
+		c.transport.CloseIdleConnections()
+	}
+}
+
+// Close closes the idle connections of every destination.
+func (d *HTTPDeliverer) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id, c := range d.clients {
+		c.transport.CloseIdleConnections()
+		delete(d.clients, id)
+	}
+}
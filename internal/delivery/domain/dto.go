@@ -0,0 +1,89 @@
+package delivery
+
+import (
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/response"
+)
+
+// MaxBulkDeadLetters bounds the number of dead letters a bulk action
+// handles at once. A selection of all the dead letters of a scope beyond
+// it is handled over several calls.
+const MaxBulkDeadLetters = 1000
+
+type ListDeadLettersRequest struct {
+	DestinationID string
+	PipelineID    string
+	Status        string `query:"status" validate:"omitempty,oneof=dead redriven discarded"`
+	Page          int
+	Limit         int
+}
+
+type ListDeadLettersResponse struct {
+	DeadLetters []*DeadLetter        `json:"data"`
+	Pagination  *response.Pagination `json:"pagination"`
+}
+
+// DeadLetterSelection picks the dead letters a bulk action applies to:
+// the listed ones, or with All every dead letter of the destination or
+// pipeline still dead. A scope given along with IDs restricts them to it.
+type DeadLetterSelection struct {
+	IDs           []string `json:"ids" validate:"omitempty,max=1000,dive,uuid"`
+	All           bool     `json:"all"`
+	DestinationID string   `json:"destination_id" validate:"omitempty,uuid"`
+	PipelineID    string   `json:"pipeline_id" validate:"omitempty,uuid"`
+}
+
+type RedriveDeadLettersRequest struct {
+	IDs           []string `json:"ids" validate:"omitempty,max=1000,dive,uuid"`
+	All           bool     `json:"all"`
+	DestinationID string   `json:"destination_id" validate:"omitempty,uuid"`
+	PipelineID    string   `json:"pipeline_id" validate:"omitempty,uuid"`
+	// TargetDestinationID redrives to another destination than the one
+	// that failed.
+	TargetDestinationID string `json:"target_destination_id" validate:"omitempty,uuid"`
+}
+
+func (r RedriveDeadLettersRequest) Selection() DeadLetterSelection {
+	return DeadLetterSelection{
+		IDs:           r.IDs,
+		All:           r.All,
+		DestinationID: r.DestinationID,
+		PipelineID:    r.PipelineID,
+	}
+}
+
+// DeadLetterBulkResult reports the outcome of a bulk action. HasMore is set
+// when a selection of all the dead letters of a scope was capped at
+// MaxBulkDeadLetters.
+type DeadLetterBulkResult struct {
+	Matched   int                   `json:"matched"`
+	Succeeded int                   `json:"succeeded"`
+	Redriven  []*RedrivenDeadLetter `json:"redriven,omitempty"`
+	Skipped   []*SkippedDeadLetter  `json:"skipped"`
+	HasMore   bool                  `json:"has_more"`
+}
+
+type RedrivenDeadLetter struct {
+	ID         string `json:"id"`
+	DeliveryID string `json:"delivery_id"`
+}
+
+type SkippedDeadLetter struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// DeadLetterDetail is a dead letter with its event, every delivery of the
+// event and the attempts of those deliveries, oldest first.
+type DeadLetterDetail struct {
+	*DeadLetter
+	Event      *pipeline.EventResponse `json:"event,omitempty"`
+	Deliveries []*Delivery             `json:"deliveries"`
+	Attempts   []*pipeline.Step        `json:"attempts"`
+}
+
+// DeadLetterExport is one line of an export.
+type DeadLetterExport struct {
+	*DeadLetter
+	Event *pipeline.EventResponse `json:"event,omitempty"`
+}
@@ -0,0 +1,137 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+)
+
+// MaxRetryAfter bounds the delay a destination can ask for with a
+// Retry-After header.
+const MaxRetryAfter = 24 * time.Hour
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusSuccess  Status = "success"
+	StatusFailed   Status = "failed"
+	StatusRetrying Status = "retrying"
+)
+
+// Delivery is an attempt to hand a webhook event over to its destination.
+// ResponseCode is zero when no response was received. ReplayJobID is set
+// on the deliveries created by a replay.
+type Delivery struct {
+	ID            string     `json:"id"`
+	EventID       string     `json:"webhook_event_id"`
+	DestinationID string     `json:"destination_id"`
+	Status        Status     `json:"status"`
+	ResponseCode  int32      `json:"response_code,omitempty"`
+	Attempt       int32      `json:"attempt"`
+	LastError     string     `json:"last_error,omitempty"`
+	ScheduledAt   *time.Time `json:"scheduled_at,omitempty"`
+	ReplayJobID   string     `json:"replay_job_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// EventStatus returns the status of the webhook event after the delivery:
+// an event waiting for a retry stays transformed until it is delivered or
+// its retries are exhausted.
+func (d *Delivery) EventStatus() pipeline.Status {
+	switch d.Status {
+	case StatusSuccess:
+		return pipeline.StatusDelivered
+	case StatusRetrying:
+		return pipeline.StatusTransformed
+	default:
+		return pipeline.StatusFailed
+	}
+}
+
+// Result describes what a deliverer sent and what it got back. It is stored
+// as the output of the delivery step.
+type Result struct {
+	DeliveryID string `json:"delivery_id,omitempty"`
+	Attempt    int32  `json:"attempt"`
+	Method     string `json:"method,omitempty"`
+	URL        string `json:"url,omitempty"`
+	// Path is the file the event was appended to, for file destinations.
+	Path string `json:"path,omitempty"`
+	// Table is the table the event was inserted into, for database
+	// destinations.
+	Table      string            `json:"table,omitempty"`
+	StatusCode int               `json:"status_code,omitempty"`
+	Headers    map[string]string `json:"response_headers,omitempty"`
+	Body       string            `json:"response_body,omitempty"`
+	// Truncated is set when the response body exceeded the size kept.
+	Truncated  bool   `json:"truncated,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+	// NextAttemptAt is set when the delivery has been scheduled for retry.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// Succeeded reports whether the destination accepted the event.
+func (r *Result) Succeeded() bool {
+	return r.StatusCode >= http.StatusOK && r.StatusCode < http.StatusMultipleChoices
+}
+
+// RetryAfter returns the delay requested by the Retry-After header of the
+// response, given in seconds or as an HTTP date, and zero when there is
+// none. It is capped at MaxRetryAfter.
+func (r *Result) RetryAfter(now time.Time) time.Duration {
+	value := r.Headers["Retry-After"]
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		delay = at.Sub(now)
+	}
+
+	return min(max(delay, 0), MaxRetryAfter)
+}
+
+type DeadLetterStatus string
+
+const (
+	DeadLetterDead      DeadLetterStatus = "dead"
+	DeadLetterRedriven  DeadLetterStatus = "redriven"
+	DeadLetterDiscarded DeadLetterStatus = "discarded"
+)
+
+// DeadLetter is a delivery that failed for good, either once its retries
+// were exhausted or because retrying could not fix it. Reason is the last
+// error and LastResponse the result of the last attempt, nil when the
+// delivery failed without one. A redriven dead letter points to the
+// delivery created by the redrive.
+type DeadLetter struct {
+	ID                string           `json:"id"`
+	DeliveryID        string           `json:"delivery_id"`
+	EventID           string           `json:"webhook_event_id"`
+	DestinationID     string           `json:"destination_id"`
+	PipelineID        string           `json:"pipeline_id,omitempty"`
+	Reason            string           `json:"reason"`
+	Attempts          int32            `json:"attempts"`
+	LastResponse      *Result          `json:"last_response,omitempty"`
+	Status            DeadLetterStatus `json:"status"`
+	RedriveDeliveryID string           `json:"redrive_delivery_id,omitempty"`
+	ResolvedAt        *time.Time       `json:"resolved_at,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+// DeadLetterFilter scopes a listing of dead letters. Empty fields match
+// every dead letter.
+type DeadLetterFilter struct {
+	DestinationID string
+	PipelineID    string
+	Status        DeadLetterStatus
+}
@@ -0,0 +1,42 @@
+package delivery
+
+import "errors"
+
+var (
+	ErrDeliveryNotFound       = errors.New("delivery not found")
+	ErrDestinationNotFound    = errors.New("destination not found")
+	ErrDestinationInactive    = errors.New("destination is inactive")
+	ErrUnsupportedDestination = errors.New("destination type is not supported")
+	ErrEventNotWaiting        = errors.New("webhook event is no longer waiting for delivery")
+	ErrCircuitOpen            = errors.New("destination circuit breaker is open")
+	ErrDeliveryLimited        = errors.New("destination delivery limits reached")
+	ErrOrderingBlocked        = errors.New("waiting for an earlier event with the same ordering key")
+	ErrDeadLetterNotFound     = errors.New("dead letter not found")
+	ErrDeadLetterResolved     = errors.New("dead letter has already been redriven or discarded")
+	ErrEventNotFailed         = errors.New("webhook event is no longer failed")
+)
+
+// PermanentError marks a failure that retrying cannot fix, such as an
+// invalid configuration or a payload that cannot be converted.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent marks err as a failure that must not be retried.
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err must not be retried.
+func IsPermanent(err error) bool {
+	var perr *PermanentError
+	return errors.As(err, &perr)
+}
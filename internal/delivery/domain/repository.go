@@ -0,0 +1,36 @@
+package delivery
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, delivery *Delivery) error
+	Update(ctx context.Context, delivery *Delivery) error
+	// RecordAttempt stores the outcome of an attempt as a whole: the
+	// response code, error and retry time it leaves unset are cleared.
+	RecordAttempt(ctx context.Context, delivery *Delivery) error
+	ListByEvent(ctx context.Context, eventID string) ([]*Delivery, error)
+	// ClaimDue returns up to limit deliveries whose retry is due and hides
+	// them from other callers for the duration of the lease.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error)
+}
+
+type DeadLetterRepository interface {
+	// Create stores the dead letter of a delivery, or refreshes the one it
+	// already has.
+	Create(ctx context.Context, deadLetter *DeadLetter) error
+	GetByID(ctx context.Context, id string) (*DeadLetter, error)
+	List(ctx context.Context, filter DeadLetterFilter, limit, offset int) ([]*DeadLetter, int64, error)
+	ListByIDs(ctx context.Context, ids []string) ([]*DeadLetter, error)
+	// Resolve moves a dead letter out of the dead status, recording the
+	// delivery created by a redrive. It reports false when the dead letter
+	// is no longer dead.
+	Resolve(ctx context.Context, id string, status DeadLetterStatus, redriveDeliveryID string) (bool, error)
+	// Redrive puts the failed event of a dead letter back in the transformed
+	// state, creates its new delivery and resolves the dead letter, in one
+	// transaction. Nothing is written unless the event is still failed and
+	// the dead letter still dead, which the flags report.
+	Redrive(ctx context.Context, deadLetter *DeadLetter, redrive *Delivery) (failed, dead bool, err error)
+}
@@ -0,0 +1,53 @@
+package delivery
+
+import (
+	"context"
+	"time"
+
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/response"
+)
+
+// Deliverer sends an event to a destination of one type. The returned
+// result is never nil; the error is set when the destination did not
+// accept the event, including when it answered with a non-2xx status.
+// Failures that a retry cannot fix are wrapped with Permanent.
+type Deliverer interface {
+	Deliver(ctx context.Context, dest *destination.Destination, event *pipeline.Event) (*Result, error)
+}
+
+// Dispatcher delivers processed events to the destination of their
+// pipeline, recording each attempt.
+type Dispatcher interface {
+	// Dispatch makes the first attempt to deliver the event.
+	Dispatch(ctx context.Context, event *pipeline.Event, order int32) (*Delivery, error)
+	// DispatchTo makes the first attempt to deliver the event to the given
+	// destination instead of the destination of its pipeline.
+	DispatchTo(ctx context.Context, event *pipeline.Event, destinationID string, order int32) (*Delivery, error)
+	// Retry makes the next attempt of a delivery scheduled for retry.
+	Retry(ctx context.Context, delivery *Delivery) (*Delivery, error)
+	// DeliverAt returns when the event should be delivered according to the
+	// delay of its destination, the zero time meaning right away.
+	DeliverAt(ctx context.Context, event *pipeline.Event) (time.Time, error)
+	// OrderingKey resolves the ordering key of the event from the ordering
+	// key of its pipeline or destination, empty when it is not ordered.
+	OrderingKey(ctx context.Context, event *pipeline.Event) (string, error)
+}
+
+// DeadLetterService exposes the dead letters of the destinations and
+// pipelines through the API.
+type DeadLetterService interface {
+	List(ctx context.Context, req ListDeadLettersRequest) ([]*DeadLetter, *response.Pagination, error)
+	// Get returns a dead letter along with its event and the history of
+	// its deliveries and attempts.
+	Get(ctx context.Context, id string) (*DeadLetterDetail, error)
+	// Redrive creates a new delivery for each selected dead letter, to its
+	// destination or to the target destination of the request. The failed
+	// deliveries and their attempts are kept.
+	Redrive(ctx context.Context, req RedriveDeadLettersRequest) (*DeadLetterBulkResult, error)
+	// Discard settles the selected dead letters without delivering them.
+	Discard(ctx context.Context, req DeadLetterSelection) (*DeadLetterBulkResult, error)
+	// Export returns the selected dead letters along with their events.
+	Export(ctx context.Context, req DeadLetterSelection) ([]*DeadLetterExport, error)
+}
@@ -0,0 +1,313 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type deadLetterRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewDeadLetterRepository(db *pgxpool.Pool, appLogger logger.Logger) delivery.DeadLetterRepository {
+	return &deadLetterRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r deadLetterRepository) Create(ctx context.Context, dl *delivery.DeadLetter) error {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.dead_letter.create")
+	defer span.End()
+
+	deliveryID, err := uuid.Parse(dl.DeliveryID)
+	if err != nil {
+		return fmt.Errorf("invalid delivery ID format: %w", err)
+	}
+	eventID, err := uuid.Parse(dl.EventID)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+	destinationID, err := uuid.Parse(dl.DestinationID)
+	if err != nil {
+		return fmt.Errorf("invalid destination ID format: %w", err)
+	}
+	pipelineID, err := toNullUUID(dl.PipelineID)
+	if err != nil {
+		return fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+
+	var lastResponse []byte
+	if dl.LastResponse != nil {
+		if lastResponse, err = json.Marshal(dl.LastResponse); err != nil {
+			return fmt.Errorf("marshal last response: %w", err)
+		}
+	}
+
+	result, err := r.queries.CreateDeadLetter(ctx,
+		deliveryID,
+		eventID,
+		destinationID,
+		pipelineID,
+		dl.Reason,
+		dl.Attempts,
+		lastResponse,
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to create dead letter",
+			logger.String("delivery_id", dl.DeliveryID),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to create dead letter: %w", err)
+	}
+
+	created, err := toDeadLetter(result)
+	if err != nil {
+		return err
+	}
+	*dl = *created
+	return nil
+}
+
+func (r deadLetterRepository) GetByID(ctx context.Context, id string) (*delivery.DeadLetter, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.dead_letter.get_by_id")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dead letter ID format: %w", err)
+	}
+
+	result, err := r.queries.GetDeadLetterByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get dead letter by ID: %w", err)
+	}
+	return toDeadLetter(result)
+}
+
+func (r deadLetterRepository) List(ctx context.Context, filter delivery.DeadLetterFilter, limit, offset int) ([]*delivery.DeadLetter, int64, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.dead_letter.list")
+	defer span.End()
+
+	destinationID, err := parseOptionalUUID(filter.DestinationID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid destination ID format: %w", err)
+	}
+	pipelineID, err := parseOptionalUUID(filter.PipelineID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+
+	results, err := r.queries.ListDeadLetters(ctx, destinationID, pipelineID, string(filter.Status), int32(limit), int32(offset))
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	total, err := r.queries.CountDeadLetters(ctx, destinationID, pipelineID, string(filter.Status))
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+
+	deadLetters, err := toDeadLetters(results)
+	if err != nil {
+		return nil, 0, err
+	}
+	return deadLetters, total, nil
+}
+
+func (r deadLetterRepository) ListByIDs(ctx context.Context, ids []string) ([]*delivery.DeadLetter, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.dead_letter.list_by_ids")
+	defer span.End()
+
+	uids := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dead letter ID format: %w", err)
+		}
+		uids = append(uids, uid)
+	}
+
+	results, err := r.queries.ListDeadLettersByIDs(ctx, uids)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return toDeadLetters(results)
+}
+
+func (r deadLetterRepository) Resolve(ctx context.Context, id string, status delivery.DeadLetterStatus, redriveDeliveryID string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.dead_letter.resolve")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid dead letter ID format: %w", err)
+	}
+	redriveID, err := toNullUUID(redriveDeliveryID)
+	if err != nil {
+		return false, fmt.Errorf("invalid delivery ID format: %w", err)
+	}
+
+	rows, err := r.queries.ResolveDeadLetter(ctx, uid, generated.DeadLetterStatus(status), redriveID)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to resolve dead letter: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r deadLetterRepository) Redrive(ctx context.Context, dl *delivery.DeadLetter, d *delivery.Delivery) (bool, bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.dead_letter.redrive")
+	defer span.End()
+
+	uid, err := uuid.Parse(dl.ID)
+	if err != nil {
+		return false, false, fmt.Errorf("invalid dead letter ID format: %w", err)
+	}
+	eventID, err := uuid.Parse(dl.EventID)
+	if err != nil {
+		return false, false, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+	destinationID, err := uuid.Parse(d.DestinationID)
+	if err != nil {
+		return false, false, fmt.Errorf("invalid destination ID format: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return false, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	// Requeueing only succeeds from the failed state, which keeps a dead
+	// letter from being redriven twice concurrently.
+	requeued, err := qtx.RequeueWebhookEvent(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		return false, false, fmt.Errorf("failed to requeue webhook event: %w", err)
+	}
+	if requeued == 0 {
+		return false, false, nil
+	}
+
+	result, err := qtx.CreateDelivery(ctx,
+		eventID,
+		destinationID,
+		generated.DeliveryStatus(d.Status),
+		toInt4(d.ResponseCode),
+		d.Attempt,
+		toText(d.LastError),
+		toTimestamptz(d.ScheduledAt),
+		pgtype.UUID{},
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to create redrive delivery",
+			logger.String("dead_letter_id", dl.ID),
+			logger.Error(err),
+		)
+		return true, false, fmt.Errorf("failed to create delivery: %w", err)
+	}
+
+	resolved, err := qtx.ResolveDeadLetter(ctx, uid, generated.DeadLetterStatusRedriven, pgtype.UUID{Bytes: result.ID, Valid: true})
+	if err != nil {
+		span.RecordError(err)
+		return true, false, fmt.Errorf("failed to resolve dead letter: %w", err)
+	}
+	if resolved == 0 {
+		return true, false, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return true, true, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	*d = *toDelivery(result)
+	return true, true, nil
+}
+
+func toDeadLetters(results []generated.DeadLetter) ([]*delivery.DeadLetter, error) {
+	deadLetters := make([]*delivery.DeadLetter, 0, len(results))
+	for _, result := range results {
+		dl, err := toDeadLetter(result)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, dl)
+	}
+	return deadLetters, nil
+}
+
+func toDeadLetter(result generated.DeadLetter) (*delivery.DeadLetter, error) {
+	dl := &delivery.DeadLetter{
+		ID:            result.ID.String(),
+		DeliveryID:    result.DeliveryID.String(),
+		EventID:       result.WebhookEventID.String(),
+		DestinationID: result.DestinationID.String(),
+		Reason:        result.Reason,
+		Attempts:      result.Attempts,
+		Status:        delivery.DeadLetterStatus(result.Status),
+		CreatedAt:     result.CreatedAt.Time,
+		UpdatedAt:     result.UpdatedAt.Time,
+	}
+	if result.PipelineID.Valid {
+		dl.PipelineID = uuid.UUID(result.PipelineID.Bytes).String()
+	}
+	if result.RedriveDeliveryID.Valid {
+		dl.RedriveDeliveryID = uuid.UUID(result.RedriveDeliveryID.Bytes).String()
+	}
+	if result.ResolvedAt.Valid {
+		dl.ResolvedAt = &result.ResolvedAt.Time
+	}
+	if len(result.LastResponse) > 0 {
+		if err := json.Unmarshal(result.LastResponse, &dl.LastResponse); err != nil {
+			return nil, fmt.Errorf("decode last response of dead letter %s: %w", dl.ID, err)
+		}
+	}
+	return dl, nil
+}
+
+// parseOptionalUUID maps an empty ID to the nil UUID, which the listing
+// queries read as no filter.
+func parseOptionalUUID(id string) (uuid.UUID, error) {
+	if id == "" {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(id)
+}
+
+func toNullUUID(id string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return pgtype.UUID{Bytes: uid, Valid: true}, nil
+}
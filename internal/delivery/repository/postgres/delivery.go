@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type deliveryRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewDeliveryRepository(db *pgxpool.Pool, appLogger logger.Logger) delivery.Repository {
+	return &deliveryRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r deliveryRepository) Create(ctx context.Context, d *delivery.Delivery) error {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.create")
+	defer span.End()
+
+	eventID, err := uuid.Parse(d.EventID)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+	destinationID, err := uuid.Parse(d.DestinationID)
+	if err != nil {
+		return fmt.Errorf("invalid destination ID format: %w", err)
+	}
+	replayJobID, err := toNullUUID(d.ReplayJobID)
+	if err != nil {
+		return fmt.Errorf("invalid replay job ID format: %w", err)
+	}
+
+	result, err := r.queries.CreateDelivery(ctx,
+		eventID,
+		destinationID,
+		generated.DeliveryStatus(d.Status),
+		toInt4(d.ResponseCode),
+		d.Attempt,
+		toText(d.LastError),
+		toTimestamptz(d.ScheduledAt),
+		replayJobID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to create delivery",
+			logger.String("webhook_event_id", d.EventID),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to create delivery: %w", err)
+	}
+
+	*d = *toDelivery(result)
+	return nil
+}
+
+// Update stores the status, response code, attempt and error of the
+// delivery. Zero values leave the stored columns unchanged.
+func (r deliveryRepository) Update(ctx context.Context, d *delivery.Delivery) error {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.update")
+	defer span.End()
+
+	id, err := uuid.Parse(d.ID)
+	if err != nil {
+		return fmt.Errorf("invalid delivery ID format: %w", err)
+	}
+
+	result, err := r.queries.UpdateDelivery(ctx,
+		id,
+		generated.DeliveryStatus(d.Status),
+		toInt4(d.ResponseCode),
+		toInt4(d.Attempt),
+		toText(d.LastError),
+		toTimestamptz(d.ScheduledAt),
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to update delivery",
+			logger.String("delivery_id", d.ID),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+
+	*d = *toDelivery(result)
+	return nil
+}
+
+// RecordAttempt stores the outcome of an attempt, clearing the response
+// code, error and retry time the attempt did not set.
+func (r deliveryRepository) RecordAttempt(ctx context.Context, d *delivery.Delivery) error {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.record_attempt")
+	defer span.End()
+
+	id, err := uuid.Parse(d.ID)
+	if err != nil {
+		return fmt.Errorf("invalid delivery ID format: %w", err)
+	}
+
+	result, err := r.queries.RecordDeliveryAttempt(ctx,
+		id,
+		generated.DeliveryStatus(d.Status),
+		toInt4(d.ResponseCode),
+		toInt4(d.Attempt),
+		toText(d.LastError),
+		toTimestamptz(d.ScheduledAt),
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to record delivery attempt",
+			logger.String("delivery_id", d.ID),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to record delivery attempt: %w", err)
+	}
+
+	*d = *toDelivery(result)
+	return nil
+}
+
+func (r deliveryRepository) ListByEvent(ctx context.Context, eventID string) ([]*delivery.Delivery, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.list_by_event")
+	defer span.End()
+
+	uid, err := uuid.Parse(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	results, err := r.queries.ListDeliveriesByWebhookEvent(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+
+	deliveries := make([]*delivery.Delivery, 0, len(results))
+	for _, result := range results {
+		deliveries = append(deliveries, toDelivery(result))
+	}
+	return deliveries, nil
+}
+
+func (r deliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*delivery.Delivery, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.repository.claim_due")
+	defer span.End()
+
+	results, err := r.queries.ClaimDueDeliveries(ctx, int32(limit), lease.Seconds())
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to claim due deliveries: %w", err)
+	}
+
+	deliveries := make([]*delivery.Delivery, 0, len(results))
+	for _, result := range results {
+		deliveries = append(deliveries, toDelivery(result))
+	}
+	return deliveries, nil
+}
+
+func toDelivery(result generated.Delivery) *delivery.Delivery {
+	d := &delivery.Delivery{
+		ID:            result.ID.String(),
+		EventID:       result.WebhookEventID.String(),
+		DestinationID: result.DestinationID.String(),
+		Status:        delivery.Status(result.Status),
+		ResponseCode:  result.ResponseCode.Int32,
+		Attempt:       result.Attempt.Int32,
+		LastError:     result.LastError.String,
+		CreatedAt:     result.CreatedAt.Time,
+		UpdatedAt:     result.UpdatedAt.Time,
+	}
+	if result.ScheduledAt.Valid {
+		d.ScheduledAt = &result.ScheduledAt.Time
+	}
+	if result.ReplayJobID.Valid {
+		d.ReplayJobID = uuid.UUID(result.ReplayJobID.Bytes).String()
+	}
+	return d
+}
+
+func toText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func toInt4(v int32) pgtype.Int4 {
+	return pgtype.Int4{Int32: v, Valid: v != 0}
+}
+
+func toTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
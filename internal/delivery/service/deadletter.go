@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+const defaultDeadLettersLimit = 50
+
+type deadLetterService struct {
+	deadLetterRepo  delivery.DeadLetterRepository
+	deliveryRepo    delivery.Repository
+	destinationRepo destination.Repository
+	pipelineRepo    pipeline.Repository
+	eventRepo       pipeline.EventRepository
+	appLogger       logger.Logger
+}
+
+func NewDeadLetterService(
+	deadLetterRepo delivery.DeadLetterRepository,
+	deliveryRepo delivery.Repository,
+	destinationRepo destination.Repository,
+	pipelineRepo pipeline.Repository,
+	eventRepo pipeline.EventRepository,
+	appLogger logger.Logger,
+) delivery.DeadLetterService {
+	return &deadLetterService{
+		deadLetterRepo:  deadLetterRepo,
+		deliveryRepo:    deliveryRepo,
+		destinationRepo: destinationRepo,
+		pipelineRepo:    pipelineRepo,
+		eventRepo:       eventRepo,
+		appLogger:       appLogger,
+	}
+}
+
+func (s deadLetterService) List(ctx context.Context, req delivery.ListDeadLettersRequest) ([]*delivery.DeadLetter, *response.Pagination, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.service.dead_letter.list")
+	defer span.End()
+
+	if err := s.checkScope(ctx, req.DestinationID, req.PipelineID); err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = defaultDeadLettersLimit
+	}
+
+	filter := delivery.DeadLetterFilter{
+		DestinationID: req.DestinationID,
+		PipelineID:    req.PipelineID,
+		Status:        delivery.DeadLetterStatus(req.Status),
+	}
+	deadLetters, total, err := s.deadLetterRepo.List(ctx, filter, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("listing dead letters: %w", err)
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	pagination := &response.Pagination{
+		CurrentPage: req.Page,
+		TotalPages:  totalPages,
+		Total:       int(total),
+		Limit:       req.Limit,
+		HasNext:     req.Page < totalPages,
+		HasPrev:     req.Page > 1,
+	}
+	return deadLetters, pagination, nil
+}
+
+func (s deadLetterService) Get(ctx context.Context, id string) (*delivery.DeadLetterDetail, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.service.dead_letter.get")
+	defer span.End()
+
+	dl, err := s.deadLetterRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting dead letter: %w", err)
+	}
+	if dl == nil {
+		return nil, delivery.ErrDeadLetterNotFound
+	}
+
+	detail := &delivery.DeadLetterDetail{DeadLetter: dl}
+	event, err := s.eventRepo.GetByID(ctx, dl.EventID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting webhook event: %w", err)
+	}
+	if event != nil {
+		detail.Event = event.ToResponse()
+	}
+
+	if detail.Deliveries, err = s.deliveryRepo.ListByEvent(ctx, dl.EventID); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("listing deliveries: %w", err)
+	}
+	if detail.Attempts, err = s.eventRepo.ListSteps(ctx, dl.EventID, pipeline.StepTypeDelivery); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("listing delivery attempts: %w", err)
+	}
+	return detail, nil
+}
+
+// Redrive puts the event of each dead letter back in the transformed state
+// and creates a delivery due right away, which the scheduler then attempts
+// with the retries of its destination. The event of a dead letter must
+// still be failed: an event delivered or redriven since is skipped.
+func (s deadLetterService) Redrive(ctx context.Context, req delivery.RedriveDeadLettersRequest) (*delivery.DeadLetterBulkResult, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.service.dead_letter.redrive")
+	defer span.End()
+
+	if req.TargetDestinationID != "" {
+		if err := s.checkTarget(ctx, req.TargetDestinationID); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	deadLetters, result, err := s.selection(ctx, req.Selection())
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.appLogger.Info(ctx, "Redriving dead letters",
+		logger.Int("count", len(deadLetters)),
+		logger.String("target_destination_id", req.TargetDestinationID),
+	)
+
+	for _, dl := range deadLetters {
+		target := req.TargetDestinationID
+		if target == "" {
+			target = dl.DestinationID
+		}
+		deliveryID, err := s.redrive(ctx, dl, target)
+		if err != nil {
+			if !isSkipped(err) {
+				span.RecordError(err)
+				s.appLogger.Error(ctx, "Failed to redrive dead letter",
+					logger.String("dead_letter_id", dl.ID),
+					logger.Error(err),
+				)
+			}
+			result.Skipped = append(result.Skipped, &delivery.SkippedDeadLetter{ID: dl.ID, Reason: err.Error()})
+			continue
+		}
+		result.Succeeded++
+		result.Redriven = append(result.Redriven, &delivery.RedrivenDeadLetter{ID: dl.ID, DeliveryID: deliveryID})
+	}
+	return result, nil
+}
+
+func (s deadLetterService) redrive(ctx context.Context, dl *delivery.DeadLetter, target string) (string, error) {
+	if dl.Status != delivery.DeadLetterDead {
+		return "", delivery.ErrDeadLetterResolved
+	}
+
+	now := time.Now()
+	record := &delivery.Delivery{
+		EventID:       dl.EventID,
+		DestinationID: target,
+		Status:        delivery.StatusRetrying,
+		ScheduledAt:   &now,
+	}
+	failed, dead, err := s.deadLetterRepo.Redrive(ctx, dl, record)
+	if err != nil {
+		return "", fmt.Errorf("redriving dead letter: %w", err)
+	}
+	if !failed {
+		return "", delivery.ErrEventNotFailed
+	}
+	if !dead {
+		return "", delivery.ErrDeadLetterResolved
+	}
+	return record.ID, nil
+}
+
+func (s deadLetterService) Discard(ctx context.Context, req delivery.DeadLetterSelection) (*delivery.DeadLetterBulkResult, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.service.dead_letter.discard")
+	defer span.End()
+
+	deadLetters, result, err := s.selection(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.appLogger.Info(ctx, "Discarding dead letters", logger.Int("count", len(deadLetters)))
+
+	for _, dl := range deadLetters {
+		resolved := false
+		if dl.Status == delivery.DeadLetterDead {
+			if resolved, err = s.deadLetterRepo.Resolve(ctx, dl.ID, delivery.DeadLetterDiscarded, ""); err != nil {
+				span.RecordError(err)
+				return nil, fmt.Errorf("discarding dead letter: %w", err)
+			}
+		}
+		if !resolved {
+			result.Skipped = append(result.Skipped, &delivery.SkippedDeadLetter{ID: dl.ID, Reason: delivery.ErrDeadLetterResolved.Error()})
+			continue
+		}
+		result.Succeeded++
+	}
+	return result, nil
+}
+
+func (s deadLetterService) Export(ctx context.Context, req delivery.DeadLetterSelection) ([]*delivery.DeadLetterExport, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.service.dead_letter.export")
+	defer span.End()
+
+	deadLetters, _, err := s.selection(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	exports := make([]*delivery.DeadLetterExport, 0, len(deadLetters))
+	for _, dl := range deadLetters {
+		event, err := s.eventRepo.GetByID(ctx, dl.EventID)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("getting webhook event: %w", err)
+		}
+		export := &delivery.DeadLetterExport{DeadLetter: dl}
+		if event != nil {
+			export.Event = event.ToResponse()
+		}
+		exports = append(exports, export)
+	}
+	return exports, nil
+}
+
+// selection resolves the dead letters picked by a bulk action, along with
+// a result holding how many matched.
+func (s deadLetterService) selection(ctx context.Context, req delivery.DeadLetterSelection) ([]*delivery.DeadLetter, *delivery.DeadLetterBulkResult, error) {
+	switch {
+	case req.All && len(req.IDs) > 0:
+		return nil, nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"ids": "ids cannot be combined with all",
+		}}
+	case req.All && req.DestinationID == "" && req.PipelineID == "":
+		return nil, nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"all": "destination_id or pipeline_id is required to select all dead letters",
+		}}
+	case !req.All && len(req.IDs) == 0:
+		return nil, nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"ids": "ids is required unless all is set",
+		}}
+	}
+
+	result := &delivery.DeadLetterBulkResult{Skipped: []*delivery.SkippedDeadLetter{}}
+
+	if req.All {
+		if err := s.checkScope(ctx, req.DestinationID, req.PipelineID); err != nil {
+			return nil, nil, err
+		}
+		filter := delivery.DeadLetterFilter{
+			DestinationID: req.DestinationID,
+			PipelineID:    req.PipelineID,
+			Status:        delivery.DeadLetterDead,
+		}
+		deadLetters, total, err := s.deadLetterRepo.List(ctx, filter, delivery.MaxBulkDeadLetters, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("listing dead letters: %w", err)
+		}
+		result.Matched = len(deadLetters)
+		result.HasMore = total > int64(len(deadLetters))
+		return deadLetters, result, nil
+	}
+
+	found, err := s.deadLetterRepo.ListByIDs(ctx, req.IDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing dead letters: %w", err)
+	}
+	byID := make(map[string]*delivery.DeadLetter, len(found))
+	for _, dl := range found {
+		byID[dl.ID] = dl
+	}
+
+	deadLetters := make([]*delivery.DeadLetter, 0, len(found))
+	seen := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		dl, ok := byID[id]
+		if !ok ||
+			(req.DestinationID != "" && dl.DestinationID != req.DestinationID) ||
+			(req.PipelineID != "" && dl.PipelineID != req.PipelineID) {
+			result.Skipped = append(result.Skipped, &delivery.SkippedDeadLetter{ID: id, Reason: delivery.ErrDeadLetterNotFound.Error()})
+			continue
+		}
+		deadLetters = append(deadLetters, dl)
+	}
+	result.Matched = len(deadLetters)
+	return deadLetters, result, nil
+}
+
+// checkScope checks that the destination and pipeline scoping a listing
+// exist.
+func (s deadLetterService) checkScope(ctx context.Context, destinationID, pipelineID string) error {
+	if destinationID != "" {
+		dest, err := s.destinationRepo.GetByID(ctx, destinationID)
+		if err != nil {
+			return fmt.Errorf("getting destination: %w", err)
+		}
+		if dest == nil {
+			return delivery.ErrDestinationNotFound
+		}
+	}
+	if pipelineID != "" {
+		p, err := s.pipelineRepo.GetByID(ctx, pipelineID)
+		if err != nil {
+			return fmt.Errorf("getting pipeline: %w", err)
+		}
+		if p == nil {
+			return pipeline.ErrPipelineNotFound
+		}
+	}
+	return nil
+}
+
+// checkTarget checks that dead letters can be redriven to the destination.
+func (s deadLetterService) checkTarget(ctx context.Context, destinationID string) error {
+	dest, err := s.destinationRepo.GetByID(ctx, destinationID)
+	if err != nil {
+		return fmt.Errorf("getting destination: %w", err)
+	}
+	if dest == nil {
+		return delivery.ErrDestinationNotFound
+	}
+	if !dest.IsActive {
+		return delivery.ErrDestinationInactive
+	}
+	return nil
+}
+
+// isSkipped reports whether a redrive failed because of the state of the
+// dead letter rather than an error.
+func isSkipped(err error) bool {
+	return errors.Is(err, delivery.ErrDeadLetterResolved) || errors.Is(err, delivery.ErrEventNotFailed)
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+// orderingRetryInterval is how long a delivery waiting for an earlier event
+// of its ordering key waits at least before checking again.
+const orderingRetryInterval = time.Second
+
+type dispatcher struct {
+	deliveryRepo    delivery.Repository
+	deadLetterRepo  delivery.DeadLetterRepository
+	pipelineRepo    pipeline.Repository
+	destinationRepo destination.Repository
+	eventRepo       pipeline.EventRepository
+	deliverers      map[destination.DestinationType]delivery.Deliverer
+	breaker         destination.CircuitBreaker
+	limiter         destination.DeliveryLimiter
+	appLogger       logger.Logger
+}
+
+func NewDispatcher(
+	deliveryRepo delivery.Repository,
+	deadLetterRepo delivery.DeadLetterRepository,
+	pipelineRepo pipeline.Repository,
+	destinationRepo destination.Repository,
+	eventRepo pipeline.EventRepository,
+	deliverers map[destination.DestinationType]delivery.Deliverer,
+	breaker destination.CircuitBreaker,
+	limiter destination.DeliveryLimiter,
+	appLogger logger.Logger,
+) delivery.Dispatcher {
+	return &dispatcher{
+		deliveryRepo:    deliveryRepo,
+		deadLetterRepo:  deadLetterRepo,
+		pipelineRepo:    pipelineRepo,
+		destinationRepo: destinationRepo,
+		eventRepo:       eventRepo,
+		deliverers:      deliverers,
+		breaker:         breaker,
+		limiter:         limiter,
+		appLogger:       appLogger,
+	}
+}
+
+// Dispatch sends the event to the destination of its pipeline. The attempt
+// is stored in deliveries and recorded as a delivery step. The returned
+// error is set whenever the event was not delivered, whether the
+// destination refused it or it could not be sent at all; the delivery is
+// then either scheduled for retry or failed. While the circuit of the
+// destination is open, its limits are reached or an earlier event with the
+// same ordering key is not settled, the delivery is parked without an
+// attempt.
+func (d dispatcher) Dispatch(ctx context.Context, event *pipeline.Event, order int32) (*delivery.Delivery, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.dispatcher.dispatch")
+	defer span.End()
+
+	dest, deliverer, err := d.pipelineDestination(ctx, event)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return d.dispatch(ctx, event, dest, deliverer, order)
+}
+
+// DispatchTo sends the event to the given destination rather than to the
+// destination of its pipeline, the same way as Dispatch.
+func (d dispatcher) DispatchTo(ctx context.Context, event *pipeline.Event, destinationID string, order int32) (*delivery.Delivery, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.dispatcher.dispatch_to")
+	defer span.End()
+
+	dest, deliverer, err := d.destination(ctx, destinationID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return d.dispatch(ctx, event, dest, deliverer, order)
+}
+
+func (d dispatcher) dispatch(ctx context.Context, event *pipeline.Event, dest *destination.Destination, deliverer delivery.Deliverer, order int32) (*delivery.Delivery, error) {
+	record := &delivery.Delivery{
+		EventID:       event.ID,
+		DestinationID: dest.ID,
+		Status:        delivery.StatusPending,
+		Attempt:       1,
+		ReplayJobID:   event.ReplayJobID,
+	}
+	release, until, cause := d.admit(ctx, dest, event)
+	if !until.IsZero() {
+		record.Attempt = 0
+		return d.park(ctx, dest, record, until, cause)
+	}
+	defer release()
+	if err := d.deliveryRepo.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("creating delivery: %w", err)
+	}
+
+	return d.attempt(ctx, event, dest, deliverer, record, order)
+}
+
+// Retry makes the next attempt of a delivery. The delivery fails without
+// an attempt when its event is no longer waiting for delivery or its
+// destination has been deactivated; only the latter is dead-lettered, the
+// event having been settled elsewhere in the former case.
+func (d dispatcher) Retry(ctx context.Context, record *delivery.Delivery) (*delivery.Delivery, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.dispatcher.retry")
+	defer span.End()
+
+	event, err := d.eventRepo.GetByID(ctx, record.EventID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting webhook event: %w", err)
+	}
+	if event == nil {
+		return d.fail(ctx, record, pipeline.ErrEventNotFound)
+	}
+	if event.Status != pipeline.StatusTransformed {
+		return d.fail(ctx, record, fmt.Errorf("%w: it is %s", delivery.ErrEventNotWaiting, event.Status))
+	}
+
+	dest, deliverer, err := d.destination(ctx, record.DestinationID)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, delivery.ErrDestinationNotFound) ||
+			errors.Is(err, delivery.ErrDestinationInactive) ||
+			errors.Is(err, delivery.ErrUnsupportedDestination) {
+			failed, failErr := d.fail(ctx, record, err)
+			if failed != nil {
+				d.deadLetter(ctx, event, failed, nil)
+			}
+			return failed, failErr
+		}
+		return nil, err
+	}
+
+	release, until, cause := d.admit(ctx, dest, event)
+	if !until.IsZero() {
+		return d.park(ctx, dest, record, until, cause)
+	}
+	defer release()
+
+	order, err := d.eventRepo.NextStepOrder(ctx, event.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting next step order: %w", err)
+	}
+
+	record.Attempt++
+	return d.attempt(ctx, event, dest, deliverer, record, order)
+}
+
+// attempt sends the event once and stores the outcome. A failed attempt is
+// scheduled for retry when the destination has retries left, its policy
+// retries the failure and the failure is not permanent; otherwise the
+// delivery is dead-lettered. Every attempt is recorded in the circuit
+// breaker of the destination, which releases its half-open probe, but only
+// the failures worth retrying count against it.
+func (d dispatcher) attempt(ctx context.Context, event *pipeline.Event, dest *destination.Destination, deliverer delivery.Deliverer, record *delivery.Delivery, order int32) (*delivery.Delivery, error) {
+	step := &pipeline.Step{
+		EventID:        event.ID,
+		PipelineID:     event.PipelineID,
+		Type:           pipeline.StepTypeDelivery,
+		Name:           dest.Name,
+		RefID:          dest.ID,
+		ExecutionOrder: order,
+		StartedAt:      time.Now(),
+	}
+
+	// The outcome of the previous attempt must not outlive this one.
+	record.LastError = ""
+	record.ScheduledAt = nil
+
+	result, deliverErr := deliverer.Deliver(ctx, dest, event)
+	result.DeliveryID = record.ID
+	result.Attempt = record.Attempt
+
+	// A destination that rejects the payload is up, and a permanent failure
+	// says nothing of its availability: only the failures worth retrying
+	// make the circuit open.
+	healthy := deliverErr == nil || delivery.IsPermanent(deliverErr) || !dest.RetryPolicy.Retryable(result.StatusCode)
+	if err := d.breaker.Record(ctx, dest, healthy); err != nil {
+		d.appLogger.Warn(ctx, "Failed to record delivery outcome in circuit breaker",
+			logger.String("destination_id", dest.ID),
+			logger.Error(err),
+		)
+	}
+
+	now := time.Now()
+	step.CompletedAt = now
+	step.OutputData = result
+	step.Status = pipeline.StepStatusSuccess
+	record.Status = delivery.StatusSuccess
+	record.ResponseCode = int32(result.StatusCode)
+	if deliverErr != nil {
+		step.Status = pipeline.StepStatusFailed
+		step.ErrorMessage = deliverErr.Error()
+		record.Status = delivery.StatusFailed
+		record.LastError = deliverErr.Error()
+
+		if record.Attempt <= dest.RetryAttempts && !delivery.IsPermanent(deliverErr) && dest.RetryPolicy.Retryable(result.StatusCode) {
+			delay := max(dest.RetryPolicy.Backoff(record.Attempt, rand.Float64()), result.RetryAfter(now))
+			next := now.Add(delay)
+			record.Status = delivery.StatusRetrying
+			record.ScheduledAt = &next
+			result.NextAttemptAt = &next
+		}
+	}
+
+	if err := d.deliveryRepo.RecordAttempt(ctx, record); err != nil {
+		return nil, fmt.Errorf("recording delivery attempt: %w", err)
+	}
+	d.recordStep(ctx, step)
+	if record.Status == delivery.StatusFailed {
+		d.deadLetter(ctx, event, record, result)
+	}
+
+	d.appLogger.Info(ctx, "Webhook event delivery attempted",
+		logger.String("webhook_event_id", event.ID),
+		logger.String("destination_id", dest.ID),
+		logger.String("status", string(record.Status)),
+		logger.Int("attempt", int(record.Attempt)),
+		logger.Int("response_code", result.StatusCode),
+	)
+
+	if deliverErr != nil {
+		return record, fmt.Errorf("delivery to %q: %w", dest.Name, deliverErr)
+	}
+	return record, nil
+}
+
+// admit checks the ordering of the event, then the limits and the circuit
+// breaker of the destination. When a delivery may be attempted now, it
+// returns the function releasing its slot once the attempt is over and the
+// zero time; otherwise when to try again and why. Deliveries go ahead when
+// Redis cannot be reached, but never out of order.
+func (d dispatcher) admit(ctx context.Context, dest *destination.Destination, event *pipeline.Event) (func(), time.Time, error) {
+	if event.OrderingKey != "" {
+		blocked, dueAt, err := d.eventRepo.OrderingPredecessor(ctx, event.ID)
+		if err != nil {
+			return nil, d.orderingRetryAt(nil), fmt.Errorf("checking ordering: %w", err)
+		}
+		if blocked {
+			return nil, d.orderingRetryAt(dueAt), delivery.ErrOrderingBlocked
+		}
+	}
+
+	token, until, err := d.limiter.Acquire(ctx, dest)
+	if err != nil {
+		d.appLogger.Warn(ctx, "Failed to check delivery limits, delivering anyway",
+			logger.String("destination_id", dest.ID),
+			logger.Error(err),
+		)
+	}
+	if !until.IsZero() {
+		return nil, until, delivery.ErrDeliveryLimited
+	}
+	release := func() {
+		if err := d.limiter.Release(context.WithoutCancel(ctx), dest, token); err != nil {
+			d.appLogger.Warn(ctx, "Failed to release delivery slot",
+				logger.String("destination_id", dest.ID),
+				logger.Error(err),
+			)
+		}
+	}
+
+	until, err = d.breaker.Allow(ctx, dest)
+	if err != nil {
+		d.appLogger.Warn(ctx, "Failed to check circuit breaker, delivering anyway",
+			logger.String("destination_id", dest.ID),
+			logger.Error(err),
+		)
+	}
+	if until.IsZero() {
+		return release, until, nil
+	}
+	release()
+	// Parked deliveries are spread over the open duration so that they are
+	// not all released at once.
+	spread := float64(dest.CircuitBreaker.WithDefaults().OpenDuration) * rand.Float64()
+	return nil, until.Add(time.Duration(spread * float64(time.Second))), delivery.ErrCircuitOpen
+}
+
+// orderingRetryAt returns when to check again a delivery waiting for an
+// earlier event of its ordering key: shortly after that event is next due,
+// or shortly when that is not known.
+func (d dispatcher) orderingRetryAt(dueAt *time.Time) time.Time {
+	at := time.Now().Add(orderingRetryInterval)
+	if dueAt != nil && dueAt.After(at) {
+		at = *dueAt
+	}
+	return at.Add(time.Duration(rand.Int64N(int64(orderingRetryInterval))))
+}
+
+// park schedules a delivery for later without attempting it or using its
+// retries, while the circuit of the destination is open or its limits are
+// reached.
+func (d dispatcher) park(ctx context.Context, dest *destination.Destination, record *delivery.Delivery, until time.Time, cause error) (*delivery.Delivery, error) {
+	record.Status = delivery.StatusRetrying
+	record.ScheduledAt = &until
+	record.LastError = cause.Error()
+	if record.ID == "" {
+		if err := d.deliveryRepo.Create(ctx, record); err != nil {
+			return nil, fmt.Errorf("creating delivery: %w", err)
+		}
+	} else if err := d.deliveryRepo.Update(ctx, record); err != nil {
+		return nil, fmt.Errorf("updating delivery: %w", err)
+	}
+
+	d.appLogger.Info(ctx, "Webhook event delivery parked",
+		logger.String("webhook_event_id", record.EventID),
+		logger.String("destination_id", dest.ID),
+		logger.String("scheduled_at", until.Format(time.RFC3339)),
+		logger.String("reason", cause.Error()),
+	)
+	return record, fmt.Errorf("delivery to %q: %w", dest.Name, cause)
+}
+
+// fail gives up on a delivery without attempting it.
+func (d dispatcher) fail(ctx context.Context, record *delivery.Delivery, cause error) (*delivery.Delivery, error) {
+	record.Status = delivery.StatusFailed
+	record.LastError = cause.Error()
+	if err := d.deliveryRepo.Update(ctx, record); err != nil {
+		return nil, fmt.Errorf("updating delivery: %w", err)
+	}
+	return record, cause
+}
+
+// DeliverAt reads the delay of the destination of the event's pipeline.
+// When the delay path is set, the event payload must be JSON for the path
+// to apply; other payloads wait for delay_seconds.
+func (d dispatcher) DeliverAt(ctx context.Context, event *pipeline.Event) (time.Time, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.dispatcher.deliver_at")
+	defer span.End()
+
+	dest, _, err := d.pipelineDestination(ctx, event)
+	if err != nil {
+		span.RecordError(err)
+		return time.Time{}, err
+	}
+
+	var body any
+	if dest.DelayPath != "" && event.IsJSON() {
+		if body, err = event.Body(); err != nil {
+			return time.Time{}, fmt.Errorf("decode payload: %w", err)
+		}
+	}
+	return dest.DeliverAt(time.Now(), body)
+}
+
+// OrderingKey resolves the ordering key of the event from its ingested
+// payload. The key is scoped to the pipeline or destination defining it, so
+// that equal values of different scopes do not block each other. An
+// ordering key that cannot be evaluated fails the event permanently.
+func (d dispatcher) OrderingKey(ctx context.Context, event *pipeline.Event) (string, error) {
+	ctx, span := tracer.StartSpan(ctx, "delivery.dispatcher.ordering_key")
+	defer span.End()
+
+	p, err := d.pipelineRepo.GetByID(ctx, event.PipelineID)
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("getting pipeline: %w", err)
+	}
+	if p == nil {
+		return "", pipeline.ErrPipelineNotFound
+	}
+
+	key, scope := p.OrderingKey, "pipeline:"+p.ID
+	if key == "" {
+		dest, err := d.destinationRepo.GetByID(ctx, p.DestinationID)
+		if err != nil {
+			span.RecordError(err)
+			return "", fmt.Errorf("getting destination: %w", err)
+		}
+		if dest == nil {
+			return "", nil
+		}
+		key, scope = dest.OrderingKey, "destination:"+dest.ID
+	}
+	if key == "" {
+		return "", nil
+	}
+
+	var body any
+	if key != destination.OrderingKeySource && event.IsJSON() {
+		if body, err = event.Body(); err != nil {
+			return "", nil
+		}
+	}
+	value, ok, err := destination.OrderingValue(key, event.SourceID, body)
+	if err != nil {
+		return "", delivery.Permanent(err)
+	}
+	if !ok {
+		return "", nil
+	}
+	return scope + ":" + value, nil
+}
+
+// pipelineDestination loads the destination of the event's pipeline.
+func (d dispatcher) pipelineDestination(ctx context.Context, event *pipeline.Event) (*destination.Destination, delivery.Deliverer, error) {
+	p, err := d.pipelineRepo.GetByID(ctx, event.PipelineID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting pipeline: %w", err)
+	}
+	if p == nil {
+		return nil, nil, pipeline.ErrPipelineNotFound
+	}
+	return d.destination(ctx, p.DestinationID)
+}
+
+// destination loads an active destination and the deliverer of its type.
+func (d dispatcher) destination(ctx context.Context, id string) (*destination.Destination, delivery.Deliverer, error) {
+	dest, err := d.destinationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting destination: %w", err)
+	}
+	if dest == nil {
+		return nil, nil, delivery.ErrDestinationNotFound
+	}
+	if !dest.IsActive {
+		return nil, nil, delivery.ErrDestinationInactive
+	}
+
+	deliverer, ok := d.deliverers[dest.DestinationType]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", delivery.ErrUnsupportedDestination, dest.DestinationType)
+	}
+	return dest, deliverer, nil
+}
+
+// deadLetter records a delivery that failed for good along with the result
+// of its last attempt, if any. A failure to record is logged but does not
+// change the outcome of the delivery.
+func (d dispatcher) deadLetter(ctx context.Context, event *pipeline.Event, record *delivery.Delivery, result *delivery.Result) {
+	dl := &delivery.DeadLetter{
+		DeliveryID:    record.ID,
+		EventID:       record.EventID,
+		DestinationID: record.DestinationID,
+		PipelineID:    event.PipelineID,
+		Reason:        record.LastError,
+		Attempts:      record.Attempt,
+		LastResponse:  result,
+	}
+	if err := d.deadLetterRepo.Create(ctx, dl); err != nil {
+		d.appLogger.Error(ctx, "Failed to record dead letter",
+			logger.String("delivery_id", record.ID),
+			logger.Error(err),
+		)
+		return
+	}
+
+	d.appLogger.Warn(ctx, "Webhook event delivery dead-lettered",
+		logger.String("webhook_event_id", record.EventID),
+		logger.String("destination_id", record.DestinationID),
+		logger.String("dead_letter_id", dl.ID),
+		logger.String("reason", dl.Reason),
+	)
+}
+
+// recordStep stores the delivery step. A failure to record is logged but
+// does not change the outcome of the delivery.
+func (d dispatcher) recordStep(ctx context.Context, step *pipeline.Step) {
+	if err := d.eventRepo.CreateStep(ctx, step); err != nil {
+		d.appLogger.Error(ctx, "Failed to record delivery step",
+			logger.String("webhook_event_id", step.EventID),
+			logger.String("destination_id", step.RefID),
+			logger.Error(err),
+		)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+// SchedulerOptions tunes the polling of the scheduler.
+type SchedulerOptions struct {
+	// PollInterval is the time between two polls when nothing is due.
+	PollInterval time.Duration
+	// BatchSize is the number of deliveries, and of delayed events, claimed
+	// per poll.
+	BatchSize int
+	// Concurrency is the number of attempts made in parallel.
+	Concurrency int
+	// Lease hides a claimed delivery or event from other schedulers. It must exceed
+	// the longest destination timeout, or a slow attempt may be repeated.
+	Lease time.Duration
+}
+
+// Scheduler delivers the delayed events and makes the attempts of the
+// deliveries scheduled for retry once they are due. Several instances can
+// run side by side: each event and delivery is claimed by a single one.
+type Scheduler struct {
+	deliveryRepo delivery.Repository
+	eventRepo    pipeline.EventRepository
+	dispatcher   delivery.Dispatcher
+	opts         SchedulerOptions
+	appLogger    logger.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewScheduler(
+	deliveryRepo delivery.Repository,
+	eventRepo pipeline.EventRepository,
+	dispatcher delivery.Dispatcher,
+	opts SchedulerOptions,
+	appLogger logger.Logger,
+) *Scheduler {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 10
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 6 * time.Minute
+	}
+	return &Scheduler{
+		deliveryRepo: deliveryRepo,
+		eventRepo:    eventRepo,
+		dispatcher:   dispatcher,
+		opts:         opts,
+		appLogger:    appLogger,
+	}
+}
+
+// Start polls for due deliveries in the background until Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.opts.PollInterval)
+		defer ticker.Stop()
+		for {
+			// A full batch suggests that more work is due, so polling goes
+			// on without waiting for the next tick.
+			if s.poll(ctx) && ctx.Err() == nil {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling and waits for the attempts in flight.
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// poll claims a batch of due deliveries and a batch of due delayed events
+// and processes them. It reports whether either batch was full.
+func (s *Scheduler) poll(ctx context.Context) bool {
+	ctx, span := tracer.StartSpan(ctx, "delivery.scheduler.poll")
+	defer span.End()
+
+	deliveries, err := s.deliveryRepo.ClaimDue(ctx, s.opts.BatchSize, s.opts.Lease)
+	if err != nil && ctx.Err() == nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to claim due deliveries", logger.Error(err))
+	}
+	events, err := s.eventRepo.ClaimDelayed(ctx, s.opts.BatchSize, s.opts.Lease)
+	if err != nil && ctx.Err() == nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to claim delayed webhook events", logger.Error(err))
+	}
+
+	// Claimed work is carried out to the end even when the scheduler stops,
+	// rather than waiting for its lease to expire.
+	workCtx := context.WithoutCancel(ctx)
+	sem := make(chan struct{}, s.opts.Concurrency)
+	var wg sync.WaitGroup
+	run := func(work func()) {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			work()
+		}()
+	}
+	for _, d := range deliveries {
+		run(func() { s.retry(workCtx, d) })
+	}
+	for _, e := range events {
+		run(func() { s.deliverDelayed(workCtx, e) })
+	}
+	wg.Wait()
+
+	return len(deliveries) == s.opts.BatchSize || len(events) == s.opts.BatchSize
+}
+
+// deliverDelayed makes the first attempt of a delayed event, unless it has
+// been cancelled since it was claimed.
+func (s *Scheduler) deliverDelayed(ctx context.Context, event *pipeline.Event) {
+	// The order is read while the event is still delayed, so that on error
+	// it is claimed again once its lease expires.
+	order, err := s.eventRepo.NextStepOrder(ctx, event.ID)
+	if err != nil {
+		s.appLogger.Error(ctx, "Failed to get next step order",
+			logger.String("webhook_event_id", event.ID),
+			logger.Error(err),
+		)
+		return
+	}
+
+	started, err := s.eventRepo.StartDelayed(ctx, event.ID)
+	if err != nil {
+		s.appLogger.Error(ctx, "Failed to start delayed webhook event",
+			logger.String("webhook_event_id", event.ID),
+			logger.Error(err),
+		)
+		return
+	}
+	if !started {
+		return
+	}
+	event.Status = pipeline.StatusTransformed
+
+	record, err := s.dispatcher.Dispatch(ctx, event, order)
+	status := pipeline.StatusFailed
+	if record != nil {
+		status = record.EventStatus()
+	}
+	s.updateStatus(ctx, event.ID, status, err)
+}
+
+func (s *Scheduler) retry(ctx context.Context, d *delivery.Delivery) {
+	record, err := s.dispatcher.Retry(ctx, d)
+	if record == nil {
+		s.appLogger.Error(ctx, "Failed to retry delivery",
+			logger.String("delivery_id", d.ID),
+			logger.Error(err),
+		)
+		return
+	}
+
+	// The event has been settled elsewhere, its status is left as it is.
+	if errors.Is(err, pipeline.ErrEventNotFound) || errors.Is(err, delivery.ErrEventNotWaiting) {
+		return
+	}
+
+	s.updateStatus(ctx, record.EventID, record.EventStatus(), err)
+}
+
+func (s *Scheduler) updateStatus(ctx context.Context, eventID string, status pipeline.Status, cause error) {
+	errorMessage := ""
+	if cause != nil {
+		errorMessage = cause.Error()
+	}
+	if err := s.eventRepo.UpdateStatus(ctx, eventID, status, errorMessage); err != nil {
+		s.appLogger.Error(ctx, "Failed to update webhook event status",
+			logger.String("webhook_event_id", eventID),
+			logger.Error(err),
+		)
+	}
+}
@@ -0,0 +1,200 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/internal/platform/http/middleware"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+// Handler holds the dead letter dependencies
+type Handler struct {
+	deadLetterService delivery.DeadLetterService
+	validator         *validatorpkg.Validator
+}
+
+// NewHandler creates a new dead letter handler
+func NewHandler(deadLetterService delivery.DeadLetterService, validator *validatorpkg.Validator) *Handler {
+	return &Handler{
+		deadLetterService: deadLetterService,
+		validator:         validator,
+	}
+}
+
+func (h *Handler) ListDestinationDeadLetters(c *fiber.Ctx) error {
+	destinationID := c.Params("id")
+	if destinationID == "" {
+		return response.BadRequest(c, "destination_id is required", nil)
+	}
+	return h.list(c, delivery.ListDeadLettersRequest{DestinationID: destinationID})
+}
+
+func (h *Handler) ListPipelineDeadLetters(c *fiber.Ctx) error {
+	pipelineID := c.Params("id")
+	if pipelineID == "" {
+		return response.BadRequest(c, "pipeline_id is required", nil)
+	}
+	return h.list(c, delivery.ListDeadLettersRequest{PipelineID: pipelineID})
+}
+
+func (h *Handler) list(c *fiber.Ctx, req delivery.ListDeadLettersRequest) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "delivery.handler.dead_letter.list")
+	defer span.End()
+
+	req.Page = c.QueryInt("page", 1)
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	req.Limit = c.QueryInt("limit", 50)
+	if req.Limit < 1 {
+		req.Limit = 50
+	}
+	if req.Limit > 1000 {
+		req.Limit = 1000
+	}
+
+	req.Status = c.Query("status", string(delivery.DeadLetterDead))
+	if errs := h.validator.Validate(req); len(errs) > 0 {
+		return response.ValidationFailed(c, errs)
+	}
+
+	deadLetters, pagination, err := h.deadLetterService.List(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, delivery.ErrDestinationNotFound):
+			return response.NotFound(c, "destination not found")
+		case errors.Is(err, pipeline.ErrPipelineNotFound):
+			return response.NotFound(c, "pipeline not found")
+		default:
+			return response.InternalError(c, "failed to list dead letters")
+		}
+	}
+
+	return response.Success(c, &delivery.ListDeadLettersResponse{
+		DeadLetters: deadLetters,
+		Pagination:  pagination,
+	}, "dead letters listed")
+}
+
+func (h *Handler) GetDeadLetter(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "delivery.handler.dead_letter.get")
+	defer span.End()
+
+	id := c.Params("id")
+	if id == "" {
+		return response.BadRequest(c, "dead_letter_id is required", nil)
+	}
+
+	detail, err := h.deadLetterService.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, delivery.ErrDeadLetterNotFound) {
+			return response.NotFound(c, "dead letter not found")
+		}
+		return response.InternalError(c, "failed to get dead letter")
+	}
+
+	return response.Success(c, detail, "dead letter retrieved")
+}
+
+func (h *Handler) RedriveDeadLetters(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "delivery.handler.dead_letter.redrive")
+	defer span.End()
+
+	var req delivery.RedriveDeadLettersRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	result, err := h.deadLetterService.Redrive(ctx, req)
+	if err != nil {
+		return h.bulkError(c, err, "failed to redrive dead letters")
+	}
+
+	return response.Success(c, result, "dead letters redriven")
+}
+
+func (h *Handler) DiscardDeadLetters(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "delivery.handler.dead_letter.discard")
+	defer span.End()
+
+	var req delivery.DeadLetterSelection
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	result, err := h.deadLetterService.Discard(ctx, req)
+	if err != nil {
+		return h.bulkError(c, err, "failed to discard dead letters")
+	}
+
+	return response.Success(c, result, "dead letters discarded")
+}
+
+// ExportDeadLetters returns the selected dead letters and their events as
+// newline-delimited JSON.
+func (h *Handler) ExportDeadLetters(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "delivery.handler.dead_letter.export")
+	defer span.End()
+
+	var req delivery.DeadLetterSelection
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	exports, err := h.deadLetterService.Export(ctx, req)
+	if err != nil {
+		return h.bulkError(c, err, "failed to export dead letters")
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, export := range exports {
+		if err := enc.Encode(export); err != nil {
+			span.RecordError(err)
+			return response.InternalError(c, "failed to serialize dead letters")
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Set(fiber.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="dead-letters-%s.ndjson"`, time.Now().UTC().Format("20060102T150405Z")))
+	return c.Send(buf.Bytes())
+}
+
+func (h *Handler) bulkError(c *fiber.Ctx, err error, message string) error {
+	var verr *validatorpkg.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		return response.ValidationFailed(c, verr.Errors)
+	case errors.Is(err, delivery.ErrDestinationNotFound):
+		return response.NotFound(c, "destination not found")
+	case errors.Is(err, pipeline.ErrPipelineNotFound):
+		return response.NotFound(c, "pipeline not found")
+	case errors.Is(err, delivery.ErrDestinationInactive):
+		return response.BadRequest(c, "target destination is inactive", nil)
+	default:
+		return response.InternalError(c, message)
+	}
+}
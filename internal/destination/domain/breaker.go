@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Defaults of the circuit breaker policy.
+const (
+	DefaultBreakerConsecutiveFailures = 5
+	DefaultBreakerWindow              = 60
+	DefaultBreakerMinRequests         = 20
+	DefaultBreakerOpenDuration        = 30
+	DefaultBreakerHalfOpenProbes      = 3
+	DefaultBreakerRampUp              = 60
+)
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreakerPolicy stops deliveries to a destination that keeps
+// failing. The circuit opens after consecutive_failures failed deliveries
+// in a row, or when failure_rate of the deliveries of the last window
+// seconds failed, provided there were at least min_requests of them.
+//
+// While the circuit is open, deliveries are parked without using their
+// retries. After open_duration seconds it is half-open: up to
+// half_open_probes deliveries are let through, and the circuit closes once
+// that many succeeded, or opens again on the first failure. Once closed,
+// parked deliveries are released gradually over ramp_up seconds.
+//
+// Only failures that the retry policy retries count: a destination that
+// rejects a payload with a 400 is up.
+type CircuitBreakerPolicy struct {
+	Disabled            bool    `json:"disabled,omitempty"`
+	ConsecutiveFailures int     `json:"consecutive_failures,omitempty" validate:"omitempty,min=1,max=1000"`
+	FailureRate         float64 `json:"failure_rate,omitempty" validate:"omitempty,gt=0,max=1"`
+	Window              int     `json:"window,omitempty" validate:"omitempty,min=1,max=3600"`
+	MinRequests         int     `json:"min_requests,omitempty" validate:"omitempty,min=1,max=10000"`
+	OpenDuration        int     `json:"open_duration,omitempty" validate:"omitempty,min=1,max=86400"`
+	HalfOpenProbes      int     `json:"half_open_probes,omitempty" validate:"omitempty,min=1,max=100"`
+	RampUp              int     `json:"ramp_up,omitempty" validate:"omitempty,min=1,max=3600"`
+}
+
+func (p *CircuitBreakerPolicy) Validate() error {
+	if p.FailureRate < 0 || p.FailureRate > 1 {
+		return fmt.Errorf("failure_rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// WithDefaults returns the policy with its empty fields set to the
+// defaults. The failure rate has no default: it is only used when set.
+func (p CircuitBreakerPolicy) WithDefaults() CircuitBreakerPolicy {
+	if p.ConsecutiveFailures == 0 {
+		p.ConsecutiveFailures = DefaultBreakerConsecutiveFailures
+	}
+	if p.Window == 0 {
+		p.Window = DefaultBreakerWindow
+	}
+	if p.MinRequests == 0 {
+		p.MinRequests = DefaultBreakerMinRequests
+	}
+	if p.OpenDuration == 0 {
+		p.OpenDuration = DefaultBreakerOpenDuration
+	}
+	if p.HalfOpenProbes == 0 {
+		p.HalfOpenProbes = DefaultBreakerHalfOpenProbes
+	}
+	if p.RampUp == 0 {
+		p.RampUp = DefaultBreakerRampUp
+	}
+	return p
+}
+
+// CircuitBreakerStatus is the shared state of the circuit of a destination.
+// Requests and Failures count the deliveries of the current window.
+type CircuitBreakerStatus struct {
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	Requests            int          `json:"requests"`
+	Failures            int          `json:"failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	HalfOpenAt          *time.Time   `json:"half_open_at,omitempty"`
+	ClosedAt            *time.Time   `json:"closed_at,omitempty"`
+}
+
+// CircuitBreaker keeps the circuit of each destination, shared by every
+// worker.
+type CircuitBreaker interface {
+	// Allow reports whether a delivery to the destination may be attempted
+	// now. It returns the zero time when it may, otherwise when to try
+	// again.
+	Allow(ctx context.Context, dest *Destination) (time.Time, error)
+	// Record counts the outcome of an attempted delivery.
+	Record(ctx context.Context, dest *Destination, success bool) error
+	Status(ctx context.Context, dest *Destination) (*CircuitBreakerStatus, error)
+	// Reset closes the circuit and forgets the past deliveries.
+	Reset(ctx context.Context, destinationID string) error
+}
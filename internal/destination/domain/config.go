@@ -1,10 +1,15 @@
 package domain
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/textproto"
 	"net/url"
 	"strings"
+
+	"github.com/theotruvelot/catchook/pkg/xmljson"
 )
 
 type HTTPAuthType string
@@ -50,6 +55,10 @@ type HTTPConfig struct {
 	ContentType HTTPContentType `json:"content_type" validate:"omitempty,oneof=application/json application/xml application/x-www-form-urlencoded text/plain"`
 	Timeout     int             `json:"timeout" validate:"omitempty,min=1,max=300"`
 	Auth        *HTTPAuth       `json:"auth,omitempty"`
+	// Headers are sent with every request to the destination.
+	Headers map[string]string `json:"headers,omitempty" validate:"omitempty,max=64"`
+	// Signing signs every request when set.
+	Signing *HTTPSigning `json:"signing,omitempty"`
 }
 
 func (h *HTTPConfig) Validate() error {
@@ -72,12 +81,20 @@ func (h *HTTPConfig) Validate() error {
 		}
 	}
 
-	if h.Timeout == 0 {
-		h.Timeout = 30
+	for name := range h.Headers {
+		if !validHeaderName(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
 	}
 
-	if h.ContentType == "" {
-		h.ContentType = HTTPContentTypeJSON
+	if h.Signing != nil {
+		if err := h.Signing.Validate(); err != nil {
+			return fmt.Errorf("signing validation failed: %w", err)
+		}
+	}
+
+	if h.Timeout == 0 {
+		h.Timeout = 30
 	}
 
 	return nil
@@ -141,16 +158,98 @@ func HTTPConfigFromMap(data map[string]interface{}) (*HTTPConfig, error) {
 	return &config, nil
 }
 
+// MergeHeaders merges userHeaders over defaultHeaders. Header names are
+// case-insensitive, so keys are canonicalized and a user header replaces a
+// default header whatever its case.
 func MergeHeaders(defaultHeaders, userHeaders map[string]string) map[string]string {
-	merged := make(map[string]string)
+	merged := make(map[string]string, len(defaultHeaders)+len(userHeaders))
 
 	for k, v := range defaultHeaders {
-		merged[k] = v
+		merged[textproto.CanonicalMIMEHeaderKey(k)] = v
 	}
 
 	for k, v := range userHeaders {
-		merged[k] = v
+		merged[textproto.CanonicalMIMEHeaderKey(k)] = v
 	}
 
 	return merged
 }
+
+// RequestHeaders builds the headers of a request to the destination from the
+// headers of the event, once transformed. From lowest to highest precedence:
+//
+//  1. the headers configured on the destination
+//  2. the event headers, so that header transformations can override them
+//  3. Content-Type and the auth header, which the destination owns
+//
+// A destination without a content type sends the payload with its own
+// media type, unless the headers already give one.
+func (h *HTTPConfig) RequestHeaders(eventHeaders map[string]string, payloadContentType string) map[string]string {
+	headers := MergeHeaders(h.Headers, eventHeaders)
+
+	if h.ContentType != "" {
+		headers["Content-Type"] = string(h.ContentType)
+	} else if _, ok := headers["Content-Type"]; !ok && payloadContentType != "" {
+		headers["Content-Type"] = payloadContentType
+	}
+
+	if h.Auth != nil {
+		switch h.Auth.Type {
+		case HTTPAuthTypeBasic:
+			credentials := base64.StdEncoding.EncodeToString([]byte(h.Auth.Username + ":" + h.Auth.Password))
+			headers["Authorization"] = "Basic " + credentials
+		case HTTPAuthTypeBearer:
+			headers["Authorization"] = "Bearer " + h.Auth.Token
+		case HTTPAuthTypeAPIKey:
+			headers[textproto.CanonicalMIMEHeaderKey(h.Auth.Header)] = h.Auth.APIKey
+		}
+	}
+
+	return headers
+}
+
+// Negotiate converts a payload of the given media type to the content type
+// of the destination when one is JSON and the other XML, using the default
+// conversion settings. A destination without a content type takes the
+// payload as it is, so that the output of a format_xml or format_json
+// transformation, which gives control over the conversion, is kept.
+func (h *HTTPConfig) Negotiate(payload []byte, contentType string) ([]byte, error) {
+	target := h.ContentType
+	if target == "" {
+		return payload, nil
+	}
+	if contentType == "" {
+		contentType = string(HTTPContentTypeJSON)
+	}
+
+	switch {
+	case target == HTTPContentTypeXML && contentType == string(HTTPContentTypeJSON):
+		dec := json.NewDecoder(bytes.NewReader(payload))
+		dec.UseNumber()
+		var doc any
+		if err := dec.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode JSON payload: %w", err)
+		}
+		return xmljson.Encode(doc, xmljson.Options{})
+	case target == HTTPContentTypeJSON && contentType == string(HTTPContentTypeXML):
+		doc, err := xmljson.Decode(payload, xmljson.Options{})
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(doc)
+	default:
+		return payload, nil
+	}
+}
+
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r >= 0x7f || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,479 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+type DatabaseMode string
+
+const (
+	// DatabaseModeInsert inserts a row per event.
+	DatabaseModeInsert DatabaseMode = "insert"
+	// DatabaseModeUpsert inserts a row per event, updating the row holding
+	// the same conflict columns instead when there is one.
+	DatabaseModeUpsert DatabaseMode = "upsert"
+	// DatabaseModeJSONB inserts the whole payload into a JSONB column, next
+	// to the mapped columns.
+	DatabaseModeJSONB DatabaseMode = "jsonb"
+)
+
+// DatabaseColumnType is the type a mapped value is cast to before it is
+// written. The cast is done by Postgres, from the text of the value.
+type DatabaseColumnType string
+
+const (
+	DatabaseColumnText        DatabaseColumnType = "text"
+	DatabaseColumnInteger     DatabaseColumnType = "integer"
+	DatabaseColumnBigint      DatabaseColumnType = "bigint"
+	DatabaseColumnNumeric     DatabaseColumnType = "numeric"
+	DatabaseColumnDouble      DatabaseColumnType = "double"
+	DatabaseColumnBoolean     DatabaseColumnType = "boolean"
+	DatabaseColumnTimestamptz DatabaseColumnType = "timestamptz"
+	DatabaseColumnDate        DatabaseColumnType = "date"
+	DatabaseColumnUUID        DatabaseColumnType = "uuid"
+	DatabaseColumnJSONB       DatabaseColumnType = "jsonb"
+)
+
+// databaseColumnTypes maps the column types to their Postgres names.
+var databaseColumnTypes = map[DatabaseColumnType]string{
+	DatabaseColumnText:        "text",
+	DatabaseColumnInteger:     "integer",
+	DatabaseColumnBigint:      "bigint",
+	DatabaseColumnNumeric:     "numeric",
+	DatabaseColumnDouble:      "double precision",
+	DatabaseColumnBoolean:     "boolean",
+	DatabaseColumnTimestamptz: "timestamptz",
+	DatabaseColumnDate:        "date",
+	DatabaseColumnUUID:        "uuid",
+	DatabaseColumnJSONB:       "jsonb",
+}
+
+// The event fields a column can be mapped from instead of a JSON path.
+const (
+	DatabaseFieldID         = "@id"
+	DatabaseFieldSourceID   = "@source_id"
+	DatabaseFieldPipelineID = "@pipeline_id"
+	DatabaseFieldReceivedAt = "@received_at"
+)
+
+const (
+	DefaultDatabasePayloadColumn = "payload"
+	DefaultDatabaseBatchSize     = 100
+	MaxDatabaseBatchSize         = 1000
+	DefaultDatabaseBatchWait     = 20 // milliseconds
+	DefaultDatabaseMaxConns      = 4
+	MaxDatabaseMaxConns          = 32
+	MaxDatabaseColumns           = 100
+)
+
+// DatabaseColumn maps a value of the event to a column. Path is a JSON path
+// into the payload, such as $.customer.id, or one of the event fields. A
+// value the payload lacks is written as NULL.
+type DatabaseColumn struct {
+	Name string             `json:"name" validate:"required"`
+	Path string             `json:"path" validate:"required"`
+	Type DatabaseColumnType `json:"type" validate:"omitempty,oneof=text integer bigint numeric double boolean timestamptz date uuid jsonb"`
+}
+
+// DatabaseConfig inserts the events into a Postgres table. The events of a
+// destination delivered at the same time are inserted together, in batches
+// of up to BatchSize rows gathered for at most BatchWait milliseconds.
+type DatabaseConfig struct {
+	ConnectionString string           `json:"connection_string" validate:"required"`
+	Table            string           `json:"table" validate:"required"`
+	Mode             DatabaseMode     `json:"mode" validate:"omitempty,oneof=insert upsert jsonb"`
+	Columns          []DatabaseColumn `json:"columns,omitempty"`
+	// ConflictColumns identify the row an upsert updates. They must be
+	// mapped and covered by a unique index of the table.
+	ConflictColumns []string `json:"conflict_columns,omitempty"`
+	// PayloadColumn receives the payload in jsonb mode.
+	PayloadColumn string `json:"payload_column,omitempty"`
+	BatchSize     int    `json:"batch_size,omitempty" validate:"min=0"`
+	BatchWait     int    `json:"batch_wait,omitempty" validate:"min=0,max=1000"`
+	MaxConns      int32  `json:"max_conns,omitempty" validate:"min=0"`
+
+	// paths are the compiled paths of the columns, set by Validate.
+	paths []*jsonpath.Path
+}
+
+var databaseIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}$`)
+
+// Validate checks the config and fills in the defaults.
+func (c *DatabaseConfig) Validate() error {
+	u, err := url.Parse(c.ConnectionString)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || u.Host == "" {
+		return fmt.Errorf("connection_string must be a postgres:// URL")
+	}
+	if _, _, err := c.TableName(); err != nil {
+		return err
+	}
+
+	if c.Mode == "" {
+		c.Mode = DatabaseModeInsert
+	}
+	switch c.Mode {
+	case DatabaseModeInsert, DatabaseModeUpsert:
+		if len(c.Columns) == 0 {
+			return fmt.Errorf("%s mode requires at least one column", c.Mode)
+		}
+		if c.PayloadColumn != "" {
+			return fmt.Errorf("payload_column only applies to jsonb mode")
+		}
+	case DatabaseModeJSONB:
+		if c.PayloadColumn == "" {
+			c.PayloadColumn = DefaultDatabasePayloadColumn
+		}
+		if !databaseIdentifier.MatchString(c.PayloadColumn) {
+			return fmt.Errorf("invalid payload_column %q", c.PayloadColumn)
+		}
+	default:
+		return fmt.Errorf("unsupported mode: %s", c.Mode)
+	}
+	if len(c.Columns) > MaxDatabaseColumns {
+		return fmt.Errorf("at most %d columns can be mapped", MaxDatabaseColumns)
+	}
+
+	c.paths = make([]*jsonpath.Path, len(c.Columns))
+	mapped := map[string]bool{}
+	if c.Mode == DatabaseModeJSONB {
+		mapped[c.PayloadColumn] = true
+	}
+	for i := range c.Columns {
+		column := &c.Columns[i]
+		if !databaseIdentifier.MatchString(column.Name) {
+			return fmt.Errorf("invalid column name %q", column.Name)
+		}
+		if mapped[column.Name] {
+			return fmt.Errorf("column %s is mapped twice", column.Name)
+		}
+		mapped[column.Name] = true
+
+		path, err := compileDatabasePath(column.Path)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", column.Name, err)
+		}
+		c.paths[i] = path
+		if column.Type == "" {
+			column.Type = DatabaseColumnText
+		}
+		if _, ok := databaseColumnTypes[column.Type]; !ok {
+			return fmt.Errorf("column %s: unsupported type %s", column.Name, column.Type)
+		}
+	}
+
+	if c.Mode == DatabaseModeUpsert {
+		if len(c.ConflictColumns) == 0 {
+			return fmt.Errorf("upsert mode requires conflict_columns")
+		}
+		for _, name := range c.ConflictColumns {
+			if !mapped[name] {
+				return fmt.Errorf("conflict column %s is not mapped", name)
+			}
+		}
+	} else if len(c.ConflictColumns) > 0 {
+		return fmt.Errorf("conflict_columns only apply to upsert mode")
+	}
+
+	if c.BatchSize < 0 || c.BatchSize > MaxDatabaseBatchSize {
+		return fmt.Errorf("batch_size must be between 1 and %d", MaxDatabaseBatchSize)
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = DefaultDatabaseBatchSize
+	}
+	if c.BatchWait < 0 || c.BatchWait > 1000 {
+		return fmt.Errorf("batch_wait must be between 0 and 1000 milliseconds")
+	}
+	if c.BatchWait == 0 {
+		c.BatchWait = DefaultDatabaseBatchWait
+	}
+	if c.MaxConns < 0 || c.MaxConns > MaxDatabaseMaxConns {
+		return fmt.Errorf("max_conns must be between 1 and %d", MaxDatabaseMaxConns)
+	}
+	if c.MaxConns == 0 {
+		c.MaxConns = DefaultDatabaseMaxConns
+	}
+	return nil
+}
+
+// compileDatabasePath compiles the path of a column, nil for an event
+// field.
+func compileDatabasePath(path string) (*jsonpath.Path, error) {
+	switch path {
+	case DatabaseFieldID, DatabaseFieldSourceID, DatabaseFieldPipelineID, DatabaseFieldReceivedAt:
+		return nil, nil
+	}
+	if strings.HasPrefix(path, "@") {
+		return nil, fmt.Errorf("unknown event field %s", path)
+	}
+	compiled, err := jsonpath.Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !compiled.IsDefinite() {
+		return nil, fmt.Errorf("%s must select a single field", path)
+	}
+	return compiled, nil
+}
+
+// TableName splits the table into its schema, empty when the table is not
+// qualified, and its name.
+func (c *DatabaseConfig) TableName() (string, string, error) {
+	parts := strings.Split(c.Table, ".")
+	if len(parts) > 2 {
+		return "", "", fmt.Errorf("invalid table %q", c.Table)
+	}
+	for _, part := range parts {
+		if !databaseIdentifier.MatchString(part) {
+			return "", "", fmt.Errorf("invalid table %q", c.Table)
+		}
+	}
+	if len(parts) == 1 {
+		return "", parts[0], nil
+	}
+	return parts[0], parts[1], nil
+}
+
+// ColumnNames returns the columns written, in the order of Values.
+func (c *DatabaseConfig) ColumnNames() []string {
+	names := make([]string, 0, len(c.Columns)+1)
+	for _, column := range c.Columns {
+		names = append(names, column.Name)
+	}
+	if c.Mode == DatabaseModeJSONB {
+		names = append(names, c.PayloadColumn)
+	}
+	return names
+}
+
+// ColumnSQLType returns the Postgres type the value of the column is cast
+// to.
+func (c *DatabaseConfig) ColumnSQLType(i int) string {
+	if i == len(c.Columns) {
+		return databaseColumnTypes[DatabaseColumnJSONB]
+	}
+	return databaseColumnTypes[c.Columns[i].Type]
+}
+
+// DatabaseEvent is the part of an event the columns are mapped from.
+type DatabaseEvent struct {
+	ID         string
+	SourceID   string
+	PipelineID string
+	ReceivedAt time.Time
+	// Payload is the raw payload and Body its decoded JSON, nil when the
+	// payload is not JSON.
+	Payload []byte
+	Body    any
+}
+
+// Values returns the text of the values of the columns, nil standing for
+// NULL, for the casts of ColumnSQLType. The config must be validated.
+func (c *DatabaseConfig) Values(event *DatabaseEvent) ([]*string, error) {
+	values := make([]*string, 0, len(c.Columns)+1)
+	for i, column := range c.Columns {
+		value, err := columnValue(column, c.paths[i], event)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column.Name, err)
+		}
+		values = append(values, value)
+	}
+	if c.Mode == DatabaseModeJSONB {
+		payload := string(event.Payload)
+		if event.Body == nil {
+			// A payload that is not JSON is stored as a JSON string.
+			encoded, err := json.Marshal(payload)
+			if err != nil {
+				return nil, fmt.Errorf("encode payload: %w", err)
+			}
+			payload = string(encoded)
+		}
+		values = append(values, &payload)
+	}
+	return values, nil
+}
+
+func columnValue(column DatabaseColumn, path *jsonpath.Path, event *DatabaseEvent) (*string, error) {
+	var value any
+	switch column.Path {
+	case DatabaseFieldID:
+		value = event.ID
+	case DatabaseFieldSourceID:
+		value = event.SourceID
+	case DatabaseFieldPipelineID:
+		if event.PipelineID == "" {
+			return nil, nil
+		}
+		value = event.PipelineID
+	case DatabaseFieldReceivedAt:
+		value = event.ReceivedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		if event.Body == nil {
+			return nil, nil
+		}
+		found, ok := path.Get(event.Body)
+		if !ok || found == nil {
+			return nil, nil
+		}
+		value = found
+	}
+
+	if column.Type == DatabaseColumnJSONB {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		text := string(encoded)
+		return &text, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return &v, nil
+	case json.Number:
+		text := v.String()
+		// Numbers written to timestamp columns are Unix times in seconds.
+		if column.Type == DatabaseColumnTimestamptz || column.Type == DatabaseColumnDate {
+			seconds, err := v.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("%s is not a Unix time in seconds", v)
+			}
+			text = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+		}
+		return &text, nil
+	case bool:
+		text := fmt.Sprint(v)
+		return &text, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		text := string(encoded)
+		return &text, nil
+	}
+}
+
+// SchemaError reports a database destination that does not fit its table.
+type SchemaError struct {
+	// Field is the config field at fault, such as config.table.
+	Field   string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// TableInspector checks the table of a database destination before the
+// destination is saved.
+type TableInspector interface {
+	// Inspect returns a *SchemaError when the database is not allowed or
+	// cannot be reached, or when the table does not fit the config, and
+	// another error when the inspection itself fails.
+	Inspect(ctx context.Context, config *DatabaseConfig) error
+}
+
+func (c *DatabaseConfig) ToMap() (map[string]interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func DatabaseConfigFromMap(data map[string]interface{}) (*DatabaseConfig, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var config DatabaseConfig
+	if err := json.Unmarshal(jsonData, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// HostAllowlist lists the database servers database destinations may
+// connect to. An entry is a host name or IP address, optionally with a
+// port, a *.domain wildcard or a CIDR range matching IP addresses. An empty
+// list allows none.
+type HostAllowlist []string
+
+// Allows reports whether the host, as written in the connection string,
+// and port are allowed. Host names are not resolved.
+func (a HostAllowlist) Allows(host string, port uint16) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+	for _, entry := range a {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		pattern, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			pattern, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != strconv.Itoa(int(port)) {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if ip == nil && strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if patternIP := net.ParseIP(pattern); patternIP != nil {
+			if ip != nil && patternIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsConnection reports whether every server the connection string may
+// connect to, fallbacks included, is allowed. The connection string is
+// parsed the way the connections are made, so that no host set through a
+// query parameter escapes the check.
+func (a HostAllowlist) AllowsConnection(connString string) (bool, error) {
+	config, err := pgconn.ParseConfig(connString)
+	if err != nil {
+		return false, err
+	}
+	if !a.Allows(config.Host, config.Port) {
+		return false, nil
+	}
+	for _, fallback := range config.Fallbacks {
+		if !a.Allows(fallback.Host, fallback.Port) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
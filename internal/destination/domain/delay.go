@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// MaxDelay bounds how far in the future the delay path can schedule a
+// delivery.
+const MaxDelay = 30 * 24 * time.Hour
+
+// ValidateDelayPath checks that the delay path selects a single field.
+func ValidateDelayPath(path string) error {
+	if path == "" {
+		return nil
+	}
+	compiled, err := jsonpath.Compile(path)
+	if err != nil {
+		return err
+	}
+	if !compiled.IsDefinite() {
+		return fmt.Errorf("%s must select a single field", path)
+	}
+	return nil
+}
+
+// DeliverAt returns when an event should be delivered, the zero time
+// meaning right away. The field at the delay path of the body, when
+// present, is either an RFC 3339 timestamp or a Unix time in seconds.
+// Without it, the event waits for delay_seconds.
+func (d *Destination) DeliverAt(now time.Time, body any) (time.Time, error) {
+	if d.DelayPath != "" && body != nil {
+		path, err := jsonpath.Compile(d.DelayPath)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid delay path: %w", err)
+		}
+		if value, ok := path.Get(body); ok && value != nil {
+			at, err := parseDeliveryTime(value)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%s: %w", d.DelayPath, err)
+			}
+			if at.Sub(now) > MaxDelay {
+				return time.Time{}, fmt.Errorf("%s: %s is more than %s ahead", d.DelayPath, at.Format(time.RFC3339), MaxDelay)
+			}
+			if !at.After(now) {
+				return time.Time{}, nil
+			}
+			return at, nil
+		}
+	}
+
+	if d.DelaySeconds > 0 {
+		return now.Add(time.Duration(d.DelaySeconds) * time.Second), nil
+	}
+	return time.Time{}, nil
+}
+
+func parseDeliveryTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		at, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q is not an RFC 3339 timestamp", v)
+		}
+		return at, nil
+	case json.Number:
+		seconds, err := v.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s is not a Unix time in seconds", v)
+		}
+		return time.Unix(seconds, 0), nil
+	case float64:
+		return time.Unix(int64(v), 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("expected a timestamp or a Unix time, got %T", value)
+	}
+}
@@ -25,7 +25,12 @@ type CreateRequest struct {
 	DestinationType DestinationType        `json:"destination_type" validate:"required,oneof=http rabbitmq database file queue cli"`
 	Config          map[string]interface{} `json:"config" validate:"omitempty"`
 	DelaySeconds    int32                  `json:"delay_seconds" validate:"omitempty,min=0"`
+	DelayPath       string                 `json:"delay_path" validate:"omitempty,max=255"`
 	RetryAttempts   int32                  `json:"retry_attempts" validate:"omitempty,min=0"`
+	RetryPolicy     *RetryPolicy           `json:"retry_policy" validate:"omitempty"`
+	CircuitBreaker  *CircuitBreakerPolicy  `json:"circuit_breaker" validate:"omitempty"`
+	Limits          *DeliveryLimits        `json:"limits" validate:"omitempty"`
+	OrderingKey     string                 `json:"ordering_key" validate:"omitempty,max=255"`
 }
 
 type UpdateRequest struct {
@@ -35,7 +40,12 @@ type UpdateRequest struct {
 	Config          map[string]interface{} `json:"config" validate:"omitempty"`
 	IsActive        bool                   `json:"is_active" validate:"omitempty"`
 	DelaySeconds    int32                  `json:"delay_seconds" validate:"omitempty,min=0"`
+	DelayPath       *string                `json:"delay_path" validate:"omitempty,max=255"`
 	RetryAttempts   int32                  `json:"retry_attempts" validate:"omitempty,min=0"`
+	RetryPolicy     *RetryPolicy           `json:"retry_policy" validate:"omitempty"`
+	CircuitBreaker  *CircuitBreakerPolicy  `json:"circuit_breaker" validate:"omitempty"`
+	Limits          *DeliveryLimits        `json:"limits" validate:"omitempty"`
+	OrderingKey     *string                `json:"ordering_key" validate:"omitempty,max=255"`
 }
 
 type DestinationResponse struct {
@@ -46,11 +56,25 @@ type DestinationResponse struct {
 	Config          map[string]interface{} `json:"config,omitempty"`
 	IsActive        bool                   `json:"is_active"`
 	DelaySeconds    int32                  `json:"delay_seconds"`
+	DelayPath       string                 `json:"delay_path,omitempty"`
 	RetryAttempts   int32                  `json:"retry_attempts"`
+	RetryPolicy     RetryPolicy            `json:"retry_policy"`
+	CircuitBreaker  CircuitBreakerPolicy   `json:"circuit_breaker"`
+	Limits          DeliveryLimits         `json:"limits"`
+	OrderingKey     string                 `json:"ordering_key,omitempty"`
 	CreatedAt       time.Time              `json:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at"`
 }
 
+// RotateSigningSecretRequest replaces the signing secret of an HTTP
+// destination. The current secret keeps signing requests for
+// overlap_seconds, 24 hours by default. An empty secret is generated. A
+// rotation is refused until the previous secret of the last one expires.
+type RotateSigningSecretRequest struct {
+	Secret         string `json:"secret" validate:"omitempty,max=255"`
+	OverlapSeconds int32  `json:"overlap_seconds" validate:"omitempty,min=0,max=2592000"`
+}
+
 type ListDestinationsRequest struct {
 	Page            int    `query:"page" validate:"omitempty,min=1"`
 	Limit           int    `query:"limit" validate:"omitempty,min=1"`
@@ -83,7 +107,12 @@ func (d *Destination) ToResponse() (*DestinationResponse, error) {
 		DestinationType: string(d.DestinationType),
 		IsActive:        d.IsActive,
 		DelaySeconds:    d.DelaySeconds,
+		DelayPath:       d.DelayPath,
 		RetryAttempts:   d.RetryAttempts,
+		RetryPolicy:     d.RetryPolicy.WithDefaults(),
+		CircuitBreaker:  d.CircuitBreaker.WithDefaults(),
+		Limits:          d.Limits.WithDefaults(),
+		OrderingKey:     d.OrderingKey,
 		CreatedAt:       d.CreatedAt,
 		UpdatedAt:       d.UpdatedAt,
 	}
@@ -13,7 +13,14 @@ type Destination struct {
 	Config          string          `json:"config"`
 	IsActive        bool            `json:"is_active"`
 	DelaySeconds    int32           `json:"delay_seconds"`
-	RetryAttempts   int32           `json:"retry_attempts"`
-	CreatedAt       time.Time       `json:"created_at"`
-	UpdatedAt       time.Time       `json:"updated_at"`
+	// DelayPath selects a payload field holding the delivery time.
+	DelayPath      string               `json:"delay_path"`
+	RetryAttempts  int32                `json:"retry_attempts"`
+	RetryPolicy    RetryPolicy          `json:"retry_policy"`
+	CircuitBreaker CircuitBreakerPolicy `json:"circuit_breaker"`
+	Limits         DeliveryLimits       `json:"limits"`
+	// OrderingKey delivers the events sharing its value in ingestion order.
+	OrderingKey string    `json:"ordering_key"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
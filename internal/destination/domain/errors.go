@@ -5,4 +5,6 @@ import "errors"
 var (
 	ErrDestinationNotFound      = errors.New("destination not found")
 	ErrDestinationAlreadyExists = errors.New("destination already exists")
+	ErrSigningNotConfigured     = errors.New("destination signing not configured")
+	ErrSigningRotationActive    = errors.New("the previous signing secret is still in use")
 )
@@ -0,0 +1,216 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type FileFormat string
+
+const (
+	// FileFormatNDJSON appends each event as a JSON object on its own line.
+	FileFormatNDJSON FileFormat = "ndjson"
+	// FileFormatRaw appends the payload of each event as it is, followed by
+	// a newline unless it already ends with one.
+	FileFormatRaw FileFormat = "raw"
+)
+
+type FileCompression string
+
+const (
+	FileCompressionNone FileCompression = "none"
+	FileCompressionGzip FileCompression = "gzip"
+	FileCompressionZstd FileCompression = "zstd"
+)
+
+// FileSync tells when the data appended to a file is flushed to disk.
+// Whatever the policy, a file is flushed before it is rotated or closed.
+type FileSync string
+
+const (
+	// FileSyncAlways flushes after every event.
+	FileSyncAlways FileSync = "always"
+	// FileSyncInterval flushes at most once every sync interval, on the
+	// write that follows it.
+	FileSyncInterval FileSync = "interval"
+	// FileSyncNever leaves flushing to the operating system.
+	FileSyncNever FileSync = "never"
+)
+
+// DefaultFileSyncInterval applies to the interval policy when no interval
+// is set, in seconds.
+const DefaultFileSyncInterval = 1
+
+// FileRotation rotates the current file once it reaches MaxSize bytes or
+// was opened MaxAge seconds ago. Zero disables either limit; the date
+// placeholders of the path rotate the files on their own.
+type FileRotation struct {
+	MaxSize int64 `json:"max_size,omitempty" validate:"min=0"`
+	MaxAge  int   `json:"max_age,omitempty" validate:"min=0"`
+}
+
+// IsZero reports whether the files are never rotated on size or age.
+func (r FileRotation) IsZero() bool {
+	return r.MaxSize == 0 && r.MaxAge == 0
+}
+
+// FileConfig appends the events to files on the host. Path is an absolute
+// template, such as /data/{source}/{yyyy}/{mm}/{dd}.ndjson, which must lie
+// in a directory allowed by the server. The date placeholders are those of
+// the ingestion of the event, in UTC.
+type FileConfig struct {
+	Path         string          `json:"path" validate:"required"`
+	Format       FileFormat      `json:"format" validate:"omitempty,oneof=ndjson raw"`
+	Rotation     FileRotation    `json:"rotation"`
+	Compression  FileCompression `json:"compression" validate:"omitempty,oneof=none gzip zstd"`
+	Sync         FileSync        `json:"sync" validate:"omitempty,oneof=always interval never"`
+	SyncInterval int             `json:"sync_interval,omitempty" validate:"min=0,max=3600"`
+}
+
+var filePlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// filePlaceholders are the placeholders a file path may use.
+var filePlaceholders = map[string]bool{
+	"source":      true,
+	"pipeline":    true,
+	"destination": true,
+	"event":       true,
+	"yyyy":        true,
+	"mm":          true,
+	"dd":          true,
+	"hh":          true,
+}
+
+// Validate checks the config and fills in the defaults.
+func (c *FileConfig) Validate() error {
+	if !filepath.IsAbs(c.Path) {
+		return fmt.Errorf("path must be absolute")
+	}
+	if strings.HasSuffix(c.Path, "/") {
+		return fmt.Errorf("path must name a file")
+	}
+	for _, part := range strings.Split(c.Path, "/") {
+		if part == "." || part == ".." {
+			return fmt.Errorf("path cannot contain %q", part)
+		}
+	}
+	for _, m := range filePlaceholder.FindAllStringSubmatch(c.Path, -1) {
+		if !filePlaceholders[m[1]] {
+			return fmt.Errorf("unknown placeholder {%s} in path", m[1])
+		}
+	}
+	if rest := filePlaceholder.ReplaceAllString(c.Path, ""); strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("path has an unclosed placeholder")
+	}
+
+	if c.Rotation.MaxSize < 0 || c.Rotation.MaxAge < 0 {
+		return fmt.Errorf("rotation limits cannot be negative")
+	}
+	if c.Compression != "" && c.Compression != FileCompressionNone && c.Rotation.IsZero() && !c.hasDatePlaceholder() {
+		return fmt.Errorf("compression requires rotation or a date placeholder in the path")
+	}
+
+	if c.Format == "" {
+		c.Format = FileFormatNDJSON
+	}
+	if c.Compression == "" {
+		c.Compression = FileCompressionNone
+	}
+	if c.Sync == "" {
+		c.Sync = FileSyncInterval
+	}
+	if c.Sync == FileSyncInterval && c.SyncInterval == 0 {
+		c.SyncInterval = DefaultFileSyncInterval
+	}
+	return nil
+}
+
+func (c *FileConfig) hasDatePlaceholder() bool {
+	return strings.Contains(c.Path, "{yyyy}") || strings.Contains(c.Path, "{mm}") ||
+		strings.Contains(c.Path, "{dd}") || strings.Contains(c.Path, "{hh}")
+}
+
+// Dir returns the directory holding every file of the template: the part
+// of the path before its first placeholder, up to the last separator.
+func (c *FileConfig) Dir() string {
+	static := c.Path
+	if i := strings.IndexByte(static, '{'); i >= 0 {
+		static = static[:i]
+	}
+	return filepath.Dir(static + "x")
+}
+
+// Expand returns the path of the file receiving an event of the given
+// source and pipeline, ingested at the given time.
+func (c *FileConfig) Expand(destinationID, sourceID, pipelineID, eventID string, at time.Time) string {
+	at = at.UTC()
+	if pipelineID == "" {
+		pipelineID = "none"
+	}
+	values := map[string]string{
+		"source":      sourceID,
+		"pipeline":    pipelineID,
+		"destination": destinationID,
+		"event":       eventID,
+		"yyyy":        at.Format("2006"),
+		"mm":          at.Format("01"),
+		"dd":          at.Format("02"),
+		"hh":          at.Format("15"),
+	}
+	return filepath.Clean(filePlaceholder.ReplaceAllStringFunc(c.Path, func(m string) string {
+		return values[m[1:len(m)-1]]
+	}))
+}
+
+// SyncEvery returns the interval of the interval policy.
+func (c *FileConfig) SyncEvery() time.Duration {
+	return time.Duration(c.SyncInterval) * time.Second
+}
+
+func (c *FileConfig) ToMap() (map[string]interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func FileConfigFromMap(data map[string]interface{}) (*FileConfig, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var config FileConfig
+	if err := json.Unmarshal(jsonData, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// DirAllowlist lists the directories of the host the file destinations may
+// write into, their subdirectories included. An empty list allows none.
+type DirAllowlist []string
+
+// Allows reports whether the path lies in one of the directories. The path
+// must be clean and absolute; symbolic links are not resolved.
+func (a DirAllowlist) Allows(path string) bool {
+	for _, dir := range a {
+		dir = filepath.Clean(dir)
+		if path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DeliveryLimits caps the deliveries to a destination across every worker:
+// at most max_in_flight attempts at a time, and rate attempts per second
+// with bursts of up to burst attempts. Zero fields set no limit; burst
+// defaults to the rate rounded up.
+//
+// Deliveries beyond the limits wait for their turn without using their
+// retries.
+type DeliveryLimits struct {
+	MaxInFlight int     `json:"max_in_flight,omitempty" validate:"omitempty,min=1,max=10000"`
+	Rate        float64 `json:"rate,omitempty" validate:"omitempty,gt=0,max=10000"`
+	Burst       int     `json:"burst,omitempty" validate:"omitempty,min=1,max=10000"`
+}
+
+func (l *DeliveryLimits) Validate() error {
+	if l.Rate < 0 {
+		return fmt.Errorf("rate must be positive")
+	}
+	if l.Burst > 0 && l.Rate == 0 {
+		return fmt.Errorf("burst requires a rate")
+	}
+	return nil
+}
+
+// WithDefaults returns the limits with the burst set when there is a rate.
+func (l DeliveryLimits) WithDefaults() DeliveryLimits {
+	if l.Rate > 0 && l.Burst == 0 {
+		l.Burst = max(1, int(math.Ceil(l.Rate)))
+	}
+	return l
+}
+
+// IsZero reports whether the limits limit nothing.
+func (l DeliveryLimits) IsZero() bool {
+	return l.MaxInFlight == 0 && l.Rate == 0
+}
+
+// DeliveryLimiter enforces the delivery limits of the destinations, shared
+// by every worker.
+type DeliveryLimiter interface {
+	// Acquire reserves an attempt of a delivery to the destination. When
+	// the limits allow it, it returns the token to release once the
+	// attempt is over and the zero time; otherwise when to try again.
+	Acquire(ctx context.Context, dest *Destination) (string, time.Time, error)
+	Release(ctx context.Context, dest *Destination, token string) error
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// OrderingKeySource orders all the events of a source as a single key.
+const OrderingKeySource = "source"
+
+// An ordering key, set on a pipeline or a destination, makes deliveries
+// sharing a key go out strictly in ingestion order. The key is either a
+// JSON path into the payload, such as $.customer.id, or "source". The key
+// of the pipeline takes precedence over the key of its destination, and
+// keys are scoped to the pipeline or the destination defining them.
+//
+// An event is keyed from its ingested payload when the engine picks it up,
+// before its filters and transformations; an event whose payload is not
+// JSON or lacks the field is not ordered. Until it is settled, that is
+// delivered, failed, filtered or cancelled, an event blocks the later
+// events of its key; other keys are not affected:
+//
+//   - Retries: a failing event stays at the head of its key while its
+//     retries are pending, and the events behind it are parked until it is
+//     delivered or fails for good and is dead-lettered. Parked events do
+//     not use their retries.
+//   - Delay: a delayed event holds its key until it is delivered or
+//     cancelled, even when the events behind it have no delay of their
+//     own. An event is never delivered before an earlier event of its key,
+//     whatever their delays.
+//   - Circuit breaker and limits apply to the head of each key only; the
+//     events behind it wait for their turn.
+
+// ValidateOrderingKey checks that the ordering key is "source" or selects
+// a single field.
+func ValidateOrderingKey(key string) error {
+	if key == "" || key == OrderingKeySource {
+		return nil
+	}
+	compiled, err := jsonpath.Compile(key)
+	if err != nil {
+		return err
+	}
+	if !compiled.IsDefinite() {
+		return fmt.Errorf("%s must select a single field", key)
+	}
+	return nil
+}
+
+// OrderingValue returns the value of the ordering key for an event of the
+// source with the given JSON body, nil when it is not JSON. It reports
+// false when the event is not ordered.
+func OrderingValue(key, sourceID string, body any) (string, bool, error) {
+	switch key {
+	case "":
+		return "", false, nil
+	case OrderingKeySource:
+		return "source:" + sourceID, true, nil
+	}
+	if body == nil {
+		return "", false, nil
+	}
+
+	path, err := jsonpath.Compile(key)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid ordering key: %w", err)
+	}
+	value, ok := path.Get(body)
+	if !ok || value == nil {
+		return "", false, nil
+	}
+	if s, ok := value.(string); ok {
+		return "value:" + s, true, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false, fmt.Errorf("encode ordering key: %w", err)
+	}
+	return "value:" + string(encoded), true, nil
+}
@@ -11,6 +11,6 @@ type Repository interface {
 	GetByID(ctx context.Context, id string) (*Destination, error)
 	GetByName(ctx context.Context, name string) (*Destination, error)
 	List(ctx context.Context, req ListDestinationsRequest) ([]*DestinationListItem, *response.Pagination, error)
-	Update(ctx context.Context, id, name, description string, destType DestinationType, config string, isActive bool, delaySeconds, retryAttempts int32) (*Destination, error)
+	Update(ctx context.Context, id, name, description string, destType DestinationType, config string, isActive bool, delaySeconds int32, delayPath string, retryAttempts int32, retryPolicy *RetryPolicy, circuitBreaker *CircuitBreakerPolicy, limits *DeliveryLimits, orderingKey string) (*Destination, error)
 	Delete(ctx context.Context, id string) error
 }
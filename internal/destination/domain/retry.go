@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Defaults of the retry policy.
+const (
+	DefaultRetryInitialInterval = 10
+	DefaultRetryMaxInterval     = 3600
+	DefaultRetryMultiplier      = 2.0
+	DefaultRetryJitter          = 0.2
+)
+
+// DefaultRetryOn are the status classes retried when the policy names none:
+// request timeouts, rate limiting and server errors.
+var DefaultRetryOn = []string{"408", "429", "5xx"}
+
+// RetryPolicy shapes the delays between the retries of a failed delivery.
+// The number of retries is the retry_attempts of the destination. The n-th
+// retry waits initial_interval * multiplier^(n-1) seconds, capped at
+// max_interval and shortened by up to the jitter fraction, or longer when
+// the destination answered with a Retry-After header.
+//
+// Deliveries that got no response, such as connection errors and
+// timeouts, are always retried; those that got one are retried when its
+// status matches retry_on, a list of codes ("429") or classes ("5xx").
+type RetryPolicy struct {
+	InitialInterval int      `json:"initial_interval,omitempty" validate:"omitempty,min=1,max=86400"`
+	MaxInterval     int      `json:"max_interval,omitempty" validate:"omitempty,min=1,max=604800"`
+	Multiplier      float64  `json:"multiplier,omitempty" validate:"omitempty,min=1,max=10"`
+	Jitter          *float64 `json:"jitter,omitempty" validate:"omitempty,min=0,max=1"`
+	RetryOn         []string `json:"retry_on,omitempty" validate:"omitempty,max=20"`
+}
+
+func (p *RetryPolicy) Validate() error {
+	for _, class := range p.RetryOn {
+		if !validStatusClass(class) {
+			return fmt.Errorf("invalid status class %q, expected a code such as 429 or a class such as 5xx", class)
+		}
+	}
+	effective := p.WithDefaults()
+	if effective.MaxInterval < effective.InitialInterval {
+		return fmt.Errorf("max_interval must not be lower than initial_interval")
+	}
+	return nil
+}
+
+// WithDefaults returns the policy with its empty fields set to the defaults.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	if p.InitialInterval == 0 {
+		p.InitialInterval = DefaultRetryInitialInterval
+	}
+	if p.MaxInterval == 0 {
+		p.MaxInterval = max(DefaultRetryMaxInterval, p.InitialInterval)
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = DefaultRetryMultiplier
+	}
+	if p.Jitter == nil {
+		jitter := DefaultRetryJitter
+		p.Jitter = &jitter
+	}
+	if len(p.RetryOn) == 0 {
+		p.RetryOn = DefaultRetryOn
+	}
+	return p
+}
+
+// Retryable reports whether a delivery that ended with the status code
+// should be retried. Zero means that no response was received.
+func (p RetryPolicy) Retryable(statusCode int) bool {
+	if statusCode == 0 {
+		return true
+	}
+	code := strconv.Itoa(statusCode)
+	for _, class := range p.WithDefaults().RetryOn {
+		if class == code || (len(class) == 3 && class[1:] == "xx" && class[0] == code[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay before the given retry, counted from 1. random
+// is a number in [0, 1) drawing the jitter.
+func (p RetryPolicy) Backoff(retry int32, random float64) time.Duration {
+	p = p.WithDefaults()
+	if retry < 1 {
+		retry = 1
+	}
+
+	seconds := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(retry-1))
+	seconds = math.Min(seconds, float64(p.MaxInterval))
+	seconds -= seconds * *p.Jitter * random
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func validStatusClass(class string) bool {
+	if len(class) != 3 || class[0] < '1' || class[0] > '5' {
+		return false
+	}
+	if class[1:] == "xx" {
+		return true
+	}
+	code, err := strconv.Atoi(class)
+	return err == nil && code >= 100 && code <= 599
+}
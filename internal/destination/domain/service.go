@@ -12,4 +12,24 @@ type Service interface {
 	List(ctx context.Context, req ListDestinationsRequest) ([]*DestinationListItem, *response.Pagination, error)
 	Update(ctx context.Context, id string, req UpdateRequest) (*Destination, error)
 	Delete(ctx context.Context, id string) error
+	RotateSigningSecret(ctx context.Context, id string, req RotateSigningSecretRequest) (*Destination, error)
+	CircuitBreakerStatus(ctx context.Context, id string) (*CircuitBreakerStatus, error)
+	ResetCircuitBreaker(ctx context.Context, id string) (*CircuitBreakerStatus, error)
+}
+
+// Clients is a cache of per-destination clients, such as the connection
+// pools of the deliverers. The service drops the clients of a destination
+// when it is updated or deleted, so that they are rebuilt from the new
+// config or closed.
+type Clients interface {
+	Invalidate(id string)
+}
+
+// ClientSet invalidates several caches at once.
+type ClientSet []Clients
+
+func (s ClientSet) Invalidate(id string) {
+	for _, clients := range s {
+		clients.Invalidate(id)
+	}
 }
@@ -0,0 +1,250 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SigningPreset string
+
+const (
+	// SigningPresetHMAC signs the body, optionally prefixed with a
+	// timestamp, and sends the signature in a single header.
+	SigningPresetHMAC SigningPreset = "hmac"
+	// SigningPresetStandardWebhooks follows the Standard Webhooks
+	// specification: the webhook-id, webhook-timestamp and
+	// webhook-signature headers, signed with HMAC-SHA256.
+	SigningPresetStandardWebhooks SigningPreset = "standard_webhooks"
+)
+
+// Defaults of the hmac preset.
+const (
+	DefaultSignatureHeader = "X-Catchook-Signature"
+	DefaultTimestampHeader = "X-Catchook-Timestamp"
+)
+
+// standardWebhooksSecretPrefix prefixes the base64 secrets of the Standard
+// Webhooks preset.
+const standardWebhooksSecretPrefix = "whsec_"
+
+// HTTPSigning signs every request sent to an HTTP destination so that the
+// receiver can check that it comes from Catchook. The signature covers the
+// exact bytes of the body.
+//
+// With the hmac preset, the signature is computed over the body, or over
+// "{timestamp}.{body}" when timestamp is set, in which case the Unix time is
+// also sent in timestamp_header.
+//
+// During a secret rotation the request is signed with both the secret and
+// previous_secret, until previous_secret_expires_at. The signatures are
+// separated by a comma with the hmac preset, and by a space with the
+// Standard Webhooks preset.
+type HTTPSigning struct {
+	Preset                  SigningPreset `json:"preset,omitempty" validate:"omitempty,oneof=hmac standard_webhooks"`
+	Secret                  string        `json:"secret" validate:"required"`
+	PreviousSecret          string        `json:"previous_secret,omitempty"`
+	PreviousSecretExpiresAt *time.Time    `json:"previous_secret_expires_at,omitempty"`
+	Algorithm               string        `json:"algorithm,omitempty" validate:"omitempty,oneof=sha1 sha256 sha512"`
+	Encoding                string        `json:"encoding,omitempty" validate:"omitempty,oneof=hex base64"`
+	Header                  string        `json:"header,omitempty"`
+	Timestamp               bool          `json:"timestamp,omitempty"`
+	TimestampHeader         string        `json:"timestamp_header,omitempty"`
+}
+
+func (s *HTTPSigning) Validate() error {
+	if s.Preset == "" {
+		s.Preset = SigningPresetHMAC
+	}
+
+	switch s.Preset {
+	case SigningPresetHMAC:
+		if s.Algorithm == "" {
+			s.Algorithm = "sha256"
+		}
+		if s.Encoding == "" {
+			s.Encoding = "hex"
+		}
+		if s.Header == "" {
+			s.Header = DefaultSignatureHeader
+		}
+		if s.Timestamp && s.TimestampHeader == "" {
+			s.TimestampHeader = DefaultTimestampHeader
+		}
+		if _, err := newHash(s.Algorithm); err != nil {
+			return err
+		}
+		if s.Encoding != "hex" && s.Encoding != "base64" {
+			return fmt.Errorf("unsupported encoding: %s", s.Encoding)
+		}
+		for _, name := range []string{s.Header, s.TimestampHeader} {
+			if name != "" && !validHeaderName(name) {
+				return fmt.Errorf("invalid header name %q", name)
+			}
+		}
+	case SigningPresetStandardWebhooks:
+		// The specification fixes the algorithm, encoding and headers.
+		s.Algorithm, s.Encoding, s.Header, s.Timestamp, s.TimestampHeader = "sha256", "base64", "", true, ""
+	default:
+		return fmt.Errorf("unsupported signing preset: %s", s.Preset)
+	}
+
+	if strings.TrimSpace(s.Secret) == "" {
+		return fmt.Errorf("secret is required")
+	}
+	if _, err := s.key(s.Secret); err != nil {
+		return fmt.Errorf("invalid secret: %w", err)
+	}
+	if s.PreviousSecret != "" {
+		if _, err := s.key(s.PreviousSecret); err != nil {
+			return fmt.Errorf("invalid previous_secret: %w", err)
+		}
+	}
+	return nil
+}
+
+// Sign returns the headers carrying the signature of body. messageID
+// identifies the message for the Standard Webhooks preset and is kept
+// across retries so that receivers can deduplicate.
+func (s *HTTPSigning) Sign(messageID string, body []byte, now time.Time) (map[string]string, error) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	secrets := []string{s.Secret}
+	if s.PreviousSecretActive(now) {
+		secrets = append(secrets, s.PreviousSecret)
+	}
+
+	switch s.Preset {
+	case SigningPresetStandardWebhooks:
+		content := []byte(messageID + "." + timestamp + "." + string(body))
+		signatures := make([]string, 0, len(secrets))
+		for _, secret := range secrets {
+			mac, err := s.mac(secret, content)
+			if err != nil {
+				return nil, err
+			}
+			signatures = append(signatures, "v1,"+base64.StdEncoding.EncodeToString(mac))
+		}
+		return map[string]string{
+			"Webhook-Id":        messageID,
+			"Webhook-Timestamp": timestamp,
+			"Webhook-Signature": strings.Join(signatures, " "),
+		}, nil
+	default:
+		content := body
+		if s.Timestamp {
+			content = []byte(timestamp + "." + string(body))
+		}
+		signatures := make([]string, 0, len(secrets))
+		for _, secret := range secrets {
+			mac, err := s.mac(secret, content)
+			if err != nil {
+				return nil, err
+			}
+			if s.Encoding == "base64" {
+				signatures = append(signatures, base64.StdEncoding.EncodeToString(mac))
+			} else {
+				signatures = append(signatures, hex.EncodeToString(mac))
+			}
+		}
+		headers := map[string]string{s.Header: strings.Join(signatures, ",")}
+		if s.Timestamp {
+			headers[s.TimestampHeader] = timestamp
+		}
+		return headers, nil
+	}
+}
+
+// PreviousSecretActive reports whether requests are still signed with the
+// previous secret.
+func (s *HTTPSigning) PreviousSecretActive(now time.Time) bool {
+	return s.PreviousSecret != "" && (s.PreviousSecretExpiresAt == nil || now.Before(*s.PreviousSecretExpiresAt))
+}
+
+// Rotate replaces the secret, keeping the current one as the previous
+// secret until the end of the overlap. An empty secret is generated. It
+// fails with ErrSigningRotationActive while the previous secret of an
+// earlier rotation is still active: it would be dropped before the end of
+// its overlap.
+func (s *HTTPSigning) Rotate(secret string, overlap time.Duration, now time.Time) error {
+	if s.PreviousSecretActive(now) {
+		return ErrSigningRotationActive
+	}
+	if secret == "" {
+		generated, err := s.GenerateSecret()
+		if err != nil {
+			return err
+		}
+		secret = generated
+	}
+	if _, err := s.key(secret); err != nil {
+		return fmt.Errorf("invalid secret: %w", err)
+	}
+
+	expiresAt := now.Add(overlap)
+	s.PreviousSecret = s.Secret
+	s.PreviousSecretExpiresAt = &expiresAt
+	s.Secret = secret
+	return nil
+}
+
+// GenerateSecret returns a random secret in the format of the preset.
+func (s *HTTPSigning) GenerateSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate secret: %w", err)
+	}
+	if s.Preset == SigningPresetStandardWebhooks {
+		return standardWebhooksSecretPrefix + base64.StdEncoding.EncodeToString(key), nil
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func (s *HTTPSigning) mac(secret string, content []byte) ([]byte, error) {
+	key, err := s.key(secret)
+	if err != nil {
+		return nil, err
+	}
+	h, err := newHash(s.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(h, key)
+	mac.Write(content)
+	return mac.Sum(nil), nil
+}
+
+// key returns the HMAC key of a secret. Standard Webhooks secrets are
+// base64, optionally prefixed with whsec_; other secrets are used as is.
+func (s *HTTPSigning) key(secret string) ([]byte, error) {
+	if s.Preset != SigningPresetStandardWebhooks {
+		return []byte(secret), nil
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, standardWebhooksSecretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("standard webhooks secrets must be base64, optionally prefixed with %s", standardWebhooksSecretPrefix)
+	}
+	return key, nil
+}
+
+func newHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "sha1":
+		return sha1.New, nil
+	case "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -45,6 +46,19 @@ func (r destinationRepository) Create(ctx context.Context, dest *destination.Des
 		config = "{}"
 	}
 
+	retryPolicy, err := json.Marshal(dest.RetryPolicy)
+	if err != nil {
+		return fmt.Errorf("marshal retry policy: %w", err)
+	}
+	circuitBreaker, err := json.Marshal(dest.CircuitBreaker)
+	if err != nil {
+		return fmt.Errorf("marshal circuit breaker: %w", err)
+	}
+	limits, err := json.Marshal(dest.Limits)
+	if err != nil {
+		return fmt.Errorf("marshal limits: %w", err)
+	}
+
 	result, err := r.queries.CreateDestination(ctx,
 		userId,
 		dest.Name,
@@ -54,6 +68,11 @@ func (r destinationRepository) Create(ctx context.Context, dest *destination.Des
 		dest.IsActive,
 		dest.DelaySeconds,
 		dest.RetryAttempts,
+		retryPolicy,
+		dest.DelayPath,
+		circuitBreaker,
+		limits,
+		dest.OrderingKey,
 	)
 
 	if err != nil {
@@ -104,7 +123,12 @@ func (r destinationRepository) GetByID(ctx context.Context, id string) (*destina
 		Config:          string(result.Config),
 		IsActive:        result.IsActive,
 		DelaySeconds:    result.DelaySeconds,
+		DelayPath:       result.DelayPath,
 		RetryAttempts:   result.RetryAttempts,
+		RetryPolicy:     toRetryPolicy(result.RetryPolicy),
+		CircuitBreaker:  toCircuitBreaker(result.CircuitBreaker),
+		Limits:          toLimits(result.Limits),
+		OrderingKey:     result.OrderingKey,
 		CreatedAt:       result.CreatedAt.Time,
 		UpdatedAt:       result.UpdatedAt.Time,
 	}, nil
@@ -133,7 +157,12 @@ func (r destinationRepository) GetByName(ctx context.Context, name string) (*des
 		Config:          string(result.Config),
 		IsActive:        result.IsActive,
 		DelaySeconds:    result.DelaySeconds,
+		DelayPath:       result.DelayPath,
 		RetryAttempts:   result.RetryAttempts,
+		RetryPolicy:     toRetryPolicy(result.RetryPolicy),
+		CircuitBreaker:  toCircuitBreaker(result.CircuitBreaker),
+		Limits:          toLimits(result.Limits),
+		OrderingKey:     result.OrderingKey,
 		CreatedAt:       result.CreatedAt.Time,
 		UpdatedAt:       result.UpdatedAt.Time,
 	}, nil
@@ -200,7 +229,7 @@ func (r destinationRepository) List(ctx context.Context, req destination.ListDes
 	return destinations, pagination, nil
 }
 
-func (r destinationRepository) Update(ctx context.Context, id, name, description string, destType destination.DestinationType, config string, isActive bool, delaySeconds, retryAttempts int32) (*destination.Destination, error) {
+func (r destinationRepository) Update(ctx context.Context, id, name, description string, destType destination.DestinationType, config string, isActive bool, delaySeconds int32, delayPath string, retryAttempts int32, retryPolicy *destination.RetryPolicy, circuitBreaker *destination.CircuitBreakerPolicy, limits *destination.DeliveryLimits, orderingKey string) (*destination.Destination, error) {
 	ctx, span := tracer.StartSpan(ctx, "destination.repository.update")
 	defer span.End()
 
@@ -216,6 +245,25 @@ func (r destinationRepository) Update(ctx context.Context, id, name, description
 		configParam = nil
 	}
 
+	var retryPolicyParam []byte
+	if retryPolicy != nil {
+		if retryPolicyParam, err = json.Marshal(retryPolicy); err != nil {
+			return nil, fmt.Errorf("marshal retry policy: %w", err)
+		}
+	}
+	var circuitBreakerParam []byte
+	if circuitBreaker != nil {
+		if circuitBreakerParam, err = json.Marshal(circuitBreaker); err != nil {
+			return nil, fmt.Errorf("marshal circuit breaker: %w", err)
+		}
+	}
+	var limitsParam []byte
+	if limits != nil {
+		if limitsParam, err = json.Marshal(limits); err != nil {
+			return nil, fmt.Errorf("marshal limits: %w", err)
+		}
+	}
+
 	result, err := r.queries.UpdateDestination(ctx,
 		uid,
 		name,
@@ -225,6 +273,11 @@ func (r destinationRepository) Update(ctx context.Context, id, name, description
 		isActive,
 		delaySeconds,
 		retryAttempts,
+		retryPolicyParam,
+		delayPath,
+		circuitBreakerParam,
+		limitsParam,
+		orderingKey,
 	)
 	if err != nil {
 		span.RecordError(err)
@@ -241,7 +294,12 @@ func (r destinationRepository) Update(ctx context.Context, id, name, description
 		Config:          string(result.Config),
 		IsActive:        result.IsActive,
 		DelaySeconds:    result.DelaySeconds,
+		DelayPath:       result.DelayPath,
 		RetryAttempts:   result.RetryAttempts,
+		RetryPolicy:     toRetryPolicy(result.RetryPolicy),
+		CircuitBreaker:  toCircuitBreaker(result.CircuitBreaker),
+		Limits:          toLimits(result.Limits),
+		OrderingKey:     result.OrderingKey,
 		CreatedAt:       result.CreatedAt.Time,
 		UpdatedAt:       result.UpdatedAt.Time,
 	}, nil
@@ -262,3 +320,33 @@ func (r destinationRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// toRetryPolicy decodes the retry_policy column. A policy that cannot be
+// decoded falls back to the defaults rather than blocking deliveries.
+func toRetryPolicy(raw []byte) destination.RetryPolicy {
+	var policy destination.RetryPolicy
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &policy)
+	}
+	return policy
+}
+
+// toCircuitBreaker decodes the circuit_breaker column. A policy that cannot
+// be decoded falls back to the defaults.
+func toCircuitBreaker(raw []byte) destination.CircuitBreakerPolicy {
+	var policy destination.CircuitBreakerPolicy
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &policy)
+	}
+	return policy
+}
+
+// toLimits decodes the limits column. Limits that cannot be decoded limit
+// nothing.
+func toLimits(raw []byte) destination.DeliveryLimits {
+	var limits destination.DeliveryLimits
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &limits)
+	}
+	return limits
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+// The state of a circuit is a hash holding its state, consecutive failures
+// and transition times in milliseconds, along with two sorted sets of the
+// deliveries and failures of the current window scored by time. The
+// scripts below keep every transition atomic across workers.
+
+// allowScript returns 0 when a delivery may be attempted, otherwise the
+// time in milliseconds at which to try again. An open circuit turns
+// half-open once its open duration has elapsed; a half-open circuit lets
+// a limited number of probes through, and forgets probes that never
+// reported after an open duration. A circuit that closed recently lets a
+// growing share of the deliveries through until the end of its ramp up.
+//
+// KEYS: state. ARGV: now, open duration, probes, ramp up, random in [0, 1).
+var allowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local state = redis.call('HGET', KEYS[1], 'state') or 'closed'
+
+if state == 'open' then
+	local reopens = tonumber(redis.call('HGET', KEYS[1], 'half_open_at'))
+	if now < reopens then
+		return reopens
+	end
+	redis.call('HSET', KEYS[1], 'state', 'half_open', 'probes', 0, 'successes', 0, 'probe_expires_at', 0)
+	state = 'half_open'
+end
+
+if state == 'half_open' then
+	local probes = tonumber(redis.call('HGET', KEYS[1], 'probes') or '0')
+	local expires = tonumber(redis.call('HGET', KEYS[1], 'probe_expires_at') or '0')
+	if probes >= tonumber(ARGV[3]) then
+		if now < expires then
+			return expires
+		end
+		probes = 0
+	end
+	redis.call('HSET', KEYS[1], 'probes', probes + 1, 'probe_expires_at', now + tonumber(ARGV[2]))
+	return 0
+end
+
+local closed = tonumber(redis.call('HGET', KEYS[1], 'closed_at') or '0')
+local ramp = tonumber(ARGV[4])
+if closed > 0 and now < closed + ramp then
+	local random = tonumber(ARGV[5])
+	if random >= (now - closed) / ramp then
+		return now + math.floor((closed + ramp - now) * random) + 1
+	end
+end
+return 0
+`)
+
+// recordScript counts the outcome of a delivery and moves the circuit
+// accordingly. It returns 1 when the outcome opened the circuit. Outcomes
+// reported while the circuit is open come from deliveries started before
+// it opened and are ignored.
+//
+// KEYS: state, window deliveries, window failures. ARGV: now, success,
+// consecutive failures, failure rate, window, min requests, open duration,
+// probes, ttl, member.
+var recordScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local success = ARGV[2] == '1'
+local state = redis.call('HGET', KEYS[1], 'state') or 'closed'
+
+local function open()
+	redis.call('HSET', KEYS[1], 'state', 'open', 'opened_at', now, 'half_open_at', now + tonumber(ARGV[7]), 'probes', 0, 'successes', 0)
+	redis.call('HDEL', KEYS[1], 'closed_at')
+	redis.call('DEL', KEYS[2], KEYS[3])
+end
+
+if state == 'open' then
+	return 0
+end
+
+if state == 'half_open' then
+	local probes = redis.call('HINCRBY', KEYS[1], 'probes', -1)
+	if probes < 0 then
+		redis.call('HSET', KEYS[1], 'probes', 0)
+	end
+	local opened = 0
+	if not success then
+		open()
+		opened = 1
+	elseif redis.call('HINCRBY', KEYS[1], 'successes', 1) >= tonumber(ARGV[8]) then
+		redis.call('HSET', KEYS[1], 'state', 'closed', 'failures', 0, 'closed_at', now)
+		redis.call('HDEL', KEYS[1], 'opened_at', 'half_open_at', 'probes', 'successes', 'probe_expires_at')
+	end
+	redis.call('PEXPIRE', KEYS[1], ARGV[9])
+	return opened
+end
+
+local since = now - tonumber(ARGV[5])
+redis.call('ZADD', KEYS[2], now, ARGV[10])
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', since)
+redis.call('PEXPIRE', KEYS[2], ARGV[5])
+local failures = 0
+if success then
+	redis.call('HSET', KEYS[1], 'failures', 0)
+else
+	failures = redis.call('HINCRBY', KEYS[1], 'failures', 1)
+	redis.call('ZADD', KEYS[3], now, ARGV[10])
+	redis.call('PEXPIRE', KEYS[3], ARGV[5])
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', since)
+
+local total = redis.call('ZCARD', KEYS[2])
+local failed = redis.call('ZCARD', KEYS[3])
+local rate = tonumber(ARGV[4])
+local opened = 0
+if failures >= tonumber(ARGV[3]) or (rate > 0 and total >= tonumber(ARGV[6]) and failed / total >= rate) then
+	open()
+	opened = 1
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[9])
+return opened
+`)
+
+// CircuitBreaker keeps the circuits of the destinations in Redis. It
+// implements destination.CircuitBreaker.
+type CircuitBreaker struct {
+	redis     *redis.Client
+	appLogger logger.Logger
+}
+
+func NewCircuitBreaker(rdb *redis.Client, appLogger logger.Logger) *CircuitBreaker {
+	return &CircuitBreaker{redis: rdb, appLogger: appLogger}
+}
+
+func (b *CircuitBreaker) Allow(ctx context.Context, dest *destination.Destination) (time.Time, error) {
+	if dest.CircuitBreaker.Disabled {
+		return time.Time{}, nil
+	}
+	policy := dest.CircuitBreaker.WithDefaults()
+
+	next, err := allowScript.Run(ctx, b.redis, []string{breakerKey(dest.ID)},
+		time.Now().UnixMilli(),
+		seconds(policy.OpenDuration).Milliseconds(),
+		policy.HalfOpenProbes,
+		seconds(policy.RampUp).Milliseconds(),
+		rand.Float64(),
+	).Int64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("check circuit breaker: %w", err)
+	}
+	if next == 0 {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(next), nil
+}
+
+func (b *CircuitBreaker) Record(ctx context.Context, dest *destination.Destination, success bool) error {
+	if dest.CircuitBreaker.Disabled {
+		return nil
+	}
+	policy := dest.CircuitBreaker.WithDefaults()
+
+	now := time.Now()
+	ok := "0"
+	if success {
+		ok = "1"
+	}
+	// The state outlives the window and the whole open, half-open and
+	// ramp up cycle; an idle closed circuit may expire.
+	ttl := 2 * seconds(policy.Window+policy.OpenDuration+policy.RampUp)
+	key := breakerKey(dest.ID)
+
+	opened, err := recordScript.Run(ctx, b.redis, []string{key, key + ":requests", key + ":failures"},
+		now.UnixMilli(),
+		ok,
+		policy.ConsecutiveFailures,
+		policy.FailureRate,
+		seconds(policy.Window).Milliseconds(),
+		policy.MinRequests,
+		seconds(policy.OpenDuration).Milliseconds(),
+		policy.HalfOpenProbes,
+		ttl.Milliseconds(),
+		strconv.FormatInt(now.UnixNano(), 36)+strconv.FormatUint(rand.Uint64(), 36),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("record circuit breaker outcome: %w", err)
+	}
+	if opened == 1 {
+		b.appLogger.Warn(ctx, "Destination circuit breaker opened",
+			logger.String("destination_id", dest.ID),
+			logger.Duration("open_duration", seconds(policy.OpenDuration)),
+		)
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) Status(ctx context.Context, dest *destination.Destination) (*destination.CircuitBreakerStatus, error) {
+	ctx, span := tracer.StartSpan(ctx, "destination.breaker.status")
+	defer span.End()
+
+	policy := dest.CircuitBreaker.WithDefaults()
+	key := breakerKey(dest.ID)
+	now := time.Now()
+	since := strconv.FormatInt(now.Add(-seconds(policy.Window)).UnixMilli(), 10)
+
+	pipe := b.redis.Pipeline()
+	fields := pipe.HGetAll(ctx, key)
+	requests := pipe.ZCount(ctx, key+":requests", since, "+inf")
+	failures := pipe.ZCount(ctx, key+":failures", since, "+inf")
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		return nil, fmt.Errorf("get circuit breaker: %w", err)
+	}
+
+	state := fields.Val()
+	status := &destination.CircuitBreakerStatus{
+		State:    destination.CircuitClosed,
+		Requests: int(requests.Val()),
+		Failures: int(failures.Val()),
+	}
+	if s := state["state"]; s != "" {
+		status.State = destination.CircuitState(s)
+	}
+	status.ConsecutiveFailures, _ = strconv.Atoi(state["failures"])
+	status.OpenedAt = millis(state["opened_at"])
+	status.HalfOpenAt = millis(state["half_open_at"])
+	status.ClosedAt = millis(state["closed_at"])
+	// An open circuit turns half-open on the next delivery after its open
+	// duration.
+	if status.State == destination.CircuitOpen && status.HalfOpenAt != nil && !now.Before(*status.HalfOpenAt) {
+		status.State = destination.CircuitHalfOpen
+	}
+	return status, nil
+}
+
+func (b *CircuitBreaker) Reset(ctx context.Context, destinationID string) error {
+	key := breakerKey(destinationID)
+	if err := b.redis.Del(ctx, key, key+":requests", key+":failures").Err(); err != nil {
+		return fmt.Errorf("reset circuit breaker: %w", err)
+	}
+	return nil
+}
+
+func breakerKey(destinationID string) string {
+	return "destination:breaker:" + destinationID
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+func millis(value string) *time.Time {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms == 0 {
+		return nil
+	}
+	t := time.UnixMilli(ms)
+	return &t
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+)
+
+type tableColumn struct {
+	dataType  string
+	notNull   bool
+	hasValue  bool
+	generated bool
+}
+
+type tableInspector struct {
+	hosts     destination.HostAllowlist
+	timeout   time.Duration
+	appLogger logger.Logger
+}
+
+// NewTableInspector returns the inspector checking the tables of database
+// destinations, connecting to the allowed hosts only, for at most timeout.
+func NewTableInspector(hosts destination.HostAllowlist, timeout time.Duration, appLogger logger.Logger) destination.TableInspector {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &tableInspector{hosts: hosts, timeout: timeout, appLogger: appLogger}
+}
+
+// Inspect checks that the database is allowed, that the table exists and
+// can be written to, that every mapped column exists and that no column
+// requiring a value is left out. In upsert mode, the conflict columns must
+// match a unique index, which ON CONFLICT requires.
+func (i tableInspector) Inspect(ctx context.Context, config *destination.DatabaseConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, i.timeout)
+	defer cancel()
+
+	allowed, err := i.hosts.AllowsConnection(config.ConnectionString)
+	if err != nil {
+		return &destination.SchemaError{Field: "config.connection_string", Message: "is not a valid connection string"}
+	}
+	if !allowed {
+		return &destination.SchemaError{Field: "config.connection_string", Message: "connects to a host not allowed for database destinations"}
+	}
+
+	connConfig, err := pgx.ParseConfig(config.ConnectionString)
+	if err != nil {
+		return &destination.SchemaError{Field: "config.connection_string", Message: "is not a valid connection string"}
+	}
+	connConfig.ConnectTimeout = i.timeout
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		// The cause stays in the logs: returned to the caller, it would
+		// tell apart closed ports, unknown users and wrong passwords.
+		i.appLogger.Warn(ctx, "Failed to connect to the database of a destination",
+			logger.String("table", config.Table),
+			logger.Error(err),
+		)
+		return &destination.SchemaError{Field: "config.connection_string", Message: "cannot connect to the database"}
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	schema, name, err := config.TableName()
+	if err != nil {
+		return &destination.SchemaError{Field: "config.table", Message: err.Error()}
+	}
+	identifier := pgx.Identifier{name}
+	if schema != "" {
+		identifier = pgx.Identifier{schema, name}
+	}
+
+	var oid *uint32
+	if err := conn.QueryRow(ctx, `SELECT to_regclass($1)::oid`, identifier.Sanitize()).Scan(&oid); err != nil {
+		return fmt.Errorf("look up table: %w", err)
+	}
+	if oid == nil {
+		return &destination.SchemaError{Field: "config.table", Message: fmt.Sprintf("table %s does not exist", config.Table)}
+	}
+
+	var canInsert, canUpdate bool
+	if err := conn.QueryRow(ctx,
+		`SELECT has_table_privilege($1::oid, 'INSERT'), has_table_privilege($1::oid, 'UPDATE')`, *oid,
+	).Scan(&canInsert, &canUpdate); err != nil {
+		return fmt.Errorf("check privileges: %w", err)
+	}
+	if !canInsert || (config.Mode == destination.DatabaseModeUpsert && !canUpdate) {
+		return &destination.SchemaError{Field: "config.table", Message: fmt.Sprintf("the user of the connection cannot write to %s", config.Table)}
+	}
+
+	columns, err := tableColumns(ctx, conn, *oid)
+	if err != nil {
+		return err
+	}
+	names := config.ColumnNames()
+	for index, name := range names {
+		field := fmt.Sprintf("config.columns[%d].name", index)
+		if index == len(config.Columns) {
+			field = "config.payload_column"
+		}
+		column, ok := columns[name]
+		if !ok {
+			return &destination.SchemaError{Field: field, Message: fmt.Sprintf("column %s does not exist", name)}
+		}
+		if column.generated {
+			return &destination.SchemaError{Field: field, Message: fmt.Sprintf("column %s is generated", name)}
+		}
+		if field == "config.payload_column" && column.dataType != "jsonb" && column.dataType != "json" && column.dataType != "text" {
+			return &destination.SchemaError{Field: field, Message: fmt.Sprintf("column %s is %s, not jsonb", name, column.dataType)}
+		}
+	}
+	for name, column := range columns {
+		if column.notNull && !column.hasValue && !column.generated && !slices.Contains(names, name) {
+			return &destination.SchemaError{Field: "config.columns", Message: fmt.Sprintf("column %s requires a value but is not mapped", name)}
+		}
+	}
+
+	if config.Mode == destination.DatabaseModeUpsert {
+		matched, err := hasUniqueIndex(ctx, conn, *oid, config.ConflictColumns)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return &destination.SchemaError{
+				Field:   "config.conflict_columns",
+				Message: fmt.Sprintf("no unique index of %s covers exactly %s", config.Table, strings.Join(config.ConflictColumns, ", ")),
+			}
+		}
+	}
+	return nil
+}
+
+func tableColumns(ctx context.Context, conn *pgx.Conn, oid uint32) (map[string]tableColumn, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT a.attname, format_type(a.atttypid, NULL), a.attnotnull,
+			a.atthasdef OR a.attidentity <> '', a.attgenerated <> ''
+		FROM pg_attribute a
+		WHERE a.attrelid = $1::oid AND a.attnum > 0 AND NOT a.attisdropped`, oid)
+	if err != nil {
+		return nil, fmt.Errorf("list columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := map[string]tableColumn{}
+	for rows.Next() {
+		var name string
+		var column tableColumn
+		if err := rows.Scan(&name, &column.dataType, &column.notNull, &column.hasValue, &column.generated); err != nil {
+			return nil, fmt.Errorf("list columns: %w", err)
+		}
+		columns[name] = column
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list columns: %w", err)
+	}
+	return columns, nil
+}
+
+// hasUniqueIndex reports whether a unique index, neither partial nor on
+// expressions, is made of exactly the given columns.
+func hasUniqueIndex(ctx context.Context, conn *pgx.Conn, oid uint32, columns []string) (bool, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT array_agg(a.attname::text)
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = $1::oid AND i.indisunique AND i.indpred IS NULL AND i.indexprs IS NULL
+		GROUP BY i.indexrelid`, oid)
+	if err != nil {
+		return false, fmt.Errorf("list unique indexes: %w", err)
+	}
+	defer rows.Close()
+
+	want := slices.Sorted(slices.Values(columns))
+	for rows.Next() {
+		var indexed []string
+		if err := rows.Scan(&indexed); err != nil {
+			return false, fmt.Errorf("list unique indexes: %w", err)
+		}
+		slices.Sort(indexed)
+		if slices.Equal(indexed, want) {
+			return true, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("list unique indexes: %w", err)
+	}
+	return false, nil
+}
+
+// schemaErrors turns the outcome of an inspection into validation errors.
+// The other errors are logged, not returned.
+func (s destinationService) schemaErrors(ctx context.Context, errs map[string]string, err error) {
+	var schemaErr *destination.SchemaError
+	if errors.As(err, &schemaErr) {
+		errs[schemaErr.Field] = schemaErr.Message
+		return
+	}
+	s.appLogger.Warn(ctx, "Failed to inspect the table of a destination", logger.Error(err))
+	errs["config.table"] = "cannot inspect the table"
+}
@@ -3,8 +3,10 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	destination "github.com/theotruvelot/catchook/internal/destination/domain"
 	"github.com/theotruvelot/catchook/internal/platform/auth"
@@ -16,12 +18,30 @@ import (
 
 type destinationService struct {
 	destinationRepo destination.Repository
-	appLogger       logger.Logger
+	breaker         destination.CircuitBreaker
+	// fileDirs are the directories file destinations may write into.
+	fileDirs destination.DirAllowlist
+	// tables checks the tables of database destinations.
+	tables destination.TableInspector
+	// clients are dropped when a destination changes.
+	clients   destination.Clients
+	appLogger logger.Logger
 }
 
-func NewDestinationService(destinationRepo destination.Repository, appLogger logger.Logger) destination.Service {
+func NewDestinationService(
+	destinationRepo destination.Repository,
+	breaker destination.CircuitBreaker,
+	fileDirs destination.DirAllowlist,
+	tables destination.TableInspector,
+	clients destination.Clients,
+	appLogger logger.Logger,
+) destination.Service {
 	return &destinationService{
 		destinationRepo: destinationRepo,
+		breaker:         breaker,
+		fileDirs:        fileDirs,
+		tables:          tables,
+		clients:         clients,
 		appLogger:       appLogger,
 	}
 }
@@ -42,13 +62,29 @@ func (s destinationService) Create(ctx context.Context, req destination.CreateRe
 		return nil, destination.ErrDestinationAlreadyExists
 	}
 
-	config, err := validateAndMarshalConfig(req.DestinationType, req.Config)
+	config, err := s.validateAndMarshalConfig(ctx, req.DestinationType, req.Config)
 	if err != nil {
 		span.RecordError(err)
 		s.appLogger.Error(ctx, "Failed to build config", logger.Error(err))
 		return nil, fmt.Errorf("building config: %w", err)
 	}
 
+	if err := validateRetryPolicy(req.RetryPolicy); err != nil {
+		return nil, err
+	}
+	if err := validateCircuitBreaker(req.CircuitBreaker); err != nil {
+		return nil, err
+	}
+	if err := validateLimits(req.Limits); err != nil {
+		return nil, err
+	}
+	if err := validateDelayPath(req.DelayPath); err != nil {
+		return nil, err
+	}
+	if err := validateOrderingKey(req.OrderingKey); err != nil {
+		return nil, err
+	}
+
 	currentUserID, err := auth.GetUserID(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -64,7 +100,18 @@ func (s destinationService) Create(ctx context.Context, req destination.CreateRe
 		Config:          config,
 		IsActive:        true,
 		DelaySeconds:    req.DelaySeconds,
+		DelayPath:       req.DelayPath,
 		RetryAttempts:   req.RetryAttempts,
+		OrderingKey:     req.OrderingKey,
+	}
+	if req.RetryPolicy != nil {
+		newDestination.RetryPolicy = *req.RetryPolicy
+	}
+	if req.CircuitBreaker != nil {
+		newDestination.CircuitBreaker = *req.CircuitBreaker
+	}
+	if req.Limits != nil {
+		newDestination.Limits = *req.Limits
 	}
 
 	if err := s.destinationRepo.Create(ctx, newDestination); err != nil {
@@ -76,7 +123,7 @@ func (s destinationService) Create(ctx context.Context, req destination.CreateRe
 	return newDestination, nil
 }
 
-func validateAndMarshalConfig(destType destination.DestinationType, cfg map[string]interface{}) (string, error) {
+func (s destinationService) validateAndMarshalConfig(ctx context.Context, destType destination.DestinationType, cfg map[string]interface{}) (string, error) {
 	errors := map[string]string{}
 
 	switch destType {
@@ -97,9 +144,27 @@ func validateAndMarshalConfig(destType destination.DestinationType, cfg map[stri
 	case destination.DestinationTypeRabbitMQ:
 		requireFields(errors, cfg, "host", "queue")
 	case destination.DestinationTypeDatabase:
-		requireFields(errors, cfg, "connection_string", "table")
+		databaseConfig, err := destination.DatabaseConfigFromMap(cfg)
+		if err != nil {
+			errors["config"] = fmt.Sprintf("invalid database config format: %v", err)
+		} else if err := databaseConfig.Validate(); err != nil {
+			errors["config"] = fmt.Sprintf("database config validation failed: %v", err)
+		} else if err := s.tables.Inspect(ctx, databaseConfig); err != nil {
+			s.schemaErrors(ctx, errors, err)
+		} else if cfg, err = databaseConfig.ToMap(); err != nil {
+			errors["config"] = fmt.Sprintf("failed to convert database config: %v", err)
+		}
 	case destination.DestinationTypeFile:
-		requireFields(errors, cfg, "path")
+		fileConfig, err := destination.FileConfigFromMap(cfg)
+		if err != nil {
+			errors["config"] = fmt.Sprintf("invalid file config format: %v", err)
+		} else if err := fileConfig.Validate(); err != nil {
+			errors["config"] = fmt.Sprintf("file config validation failed: %v", err)
+		} else if !s.fileDirs.Allows(fileConfig.Dir()) {
+			errors["config.path"] = "is outside the directories allowed for file destinations"
+		} else if cfg, err = fileConfig.ToMap(); err != nil {
+			errors["config"] = fmt.Sprintf("failed to convert file config: %v", err)
+		}
 	case destination.DestinationTypeQueue:
 		requireFields(errors, cfg, "host", "queue")
 	case destination.DestinationTypeCLI:
@@ -124,6 +189,60 @@ func validateAndMarshalConfig(destType destination.DestinationType, cfg map[stri
 	return string(b), nil
 }
 
+func validateRetryPolicy(policy *destination.RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if err := policy.Validate(); err != nil {
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"retry_policy": err.Error(),
+		}}
+	}
+	return nil
+}
+
+func validateCircuitBreaker(policy *destination.CircuitBreakerPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if err := policy.Validate(); err != nil {
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"circuit_breaker": err.Error(),
+		}}
+	}
+	return nil
+}
+
+func validateLimits(limits *destination.DeliveryLimits) error {
+	if limits == nil {
+		return nil
+	}
+	if err := limits.Validate(); err != nil {
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"limits": err.Error(),
+		}}
+	}
+	return nil
+}
+
+func validateOrderingKey(key string) error {
+	if err := destination.ValidateOrderingKey(key); err != nil {
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"ordering_key": err.Error(),
+		}}
+	}
+	return nil
+}
+
+func validateDelayPath(path string) error {
+	if err := destination.ValidateDelayPath(path); err != nil {
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"delay_path": err.Error(),
+		}}
+	}
+	return nil
+}
+
 func requireFields(errs map[string]string, cfg map[string]interface{}, fields ...string) {
 	for _, f := range fields {
 		v, ok := cfg[f]
@@ -192,17 +311,41 @@ func (s destinationService) Update(ctx context.Context, id string, req destinati
 		if destType == "" {
 			destType = existing.DestinationType
 		}
-		configStr, err = validateAndMarshalConfig(destType, req.Config)
+		configStr, err = s.validateAndMarshalConfig(ctx, destType, req.Config)
 		if err != nil {
 			span.RecordError(err)
 			return nil, fmt.Errorf("building config: %w", err)
 		}
 	}
-	updated, err := s.destinationRepo.Update(ctx, id, req.Name, req.Description, req.DestinationType, configStr, req.IsActive, req.DelaySeconds, req.RetryAttempts)
+	if err := validateRetryPolicy(req.RetryPolicy); err != nil {
+		return nil, err
+	}
+	if err := validateCircuitBreaker(req.CircuitBreaker); err != nil {
+		return nil, err
+	}
+	if err := validateLimits(req.Limits); err != nil {
+		return nil, err
+	}
+	delayPath := existing.DelayPath
+	if req.DelayPath != nil {
+		if err := validateDelayPath(*req.DelayPath); err != nil {
+			return nil, err
+		}
+		delayPath = *req.DelayPath
+	}
+	orderingKey := existing.OrderingKey
+	if req.OrderingKey != nil {
+		if err := validateOrderingKey(*req.OrderingKey); err != nil {
+			return nil, err
+		}
+		orderingKey = *req.OrderingKey
+	}
+	updated, err := s.destinationRepo.Update(ctx, id, req.Name, req.Description, req.DestinationType, configStr, req.IsActive, req.DelaySeconds, delayPath, req.RetryAttempts, req.RetryPolicy, req.CircuitBreaker, req.Limits, orderingKey)
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("updating destination: %w", err)
 	}
+	s.clients.Invalidate(id)
 
 	return updated, nil
 }
@@ -228,6 +371,114 @@ func (s destinationService) Delete(ctx context.Context, id string) error {
 		s.appLogger.Error(ctx, "Failed to delete destination", logger.Error(err))
 		return fmt.Errorf("deleting destination: %w", err)
 	}
+	s.clients.Invalidate(id)
 
 	return nil
 }
+
+// defaultSigningOverlap is how long the previous signing secret keeps
+// signing requests after a rotation, unless the request sets it.
+const defaultSigningOverlap = 24 * time.Hour
+
+func (s destinationService) RotateSigningSecret(ctx context.Context, id string, req destination.RotateSigningSecretRequest) (*destination.Destination, error) {
+	ctx, span := tracer.StartSpan(ctx, "destination.service.rotate_signing_secret")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Rotating destination signing secret", logger.String("destination_id", id))
+	existing, err := s.destinationRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting destination by ID: %w", err)
+	}
+	if existing == nil {
+		return nil, destination.ErrDestinationNotFound
+	}
+	if existing.DestinationType != destination.DestinationTypeHTTP {
+		return nil, destination.ErrSigningNotConfigured
+	}
+
+	var httpConfig destination.HTTPConfig
+	if err := json.Unmarshal([]byte(existing.Config), &httpConfig); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("decoding HTTP config: %w", err)
+	}
+	if httpConfig.Signing == nil {
+		return nil, destination.ErrSigningNotConfigured
+	}
+
+	overlap := defaultSigningOverlap
+	if req.OverlapSeconds > 0 {
+		overlap = time.Duration(req.OverlapSeconds) * time.Second
+	}
+	if err := httpConfig.Signing.Rotate(req.Secret, overlap, time.Now()); err != nil {
+		if errors.Is(err, destination.ErrSigningRotationActive) {
+			return nil, err
+		}
+		return nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"secret": err.Error(),
+		}}
+	}
+
+	cfg, err := httpConfig.ToMap()
+	if err != nil {
+		return nil, fmt.Errorf("converting HTTP config: %w", err)
+	}
+	configStr, err := s.validateAndMarshalConfig(ctx, destination.DestinationTypeHTTP, cfg)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("building config: %w", err)
+	}
+
+	updated, err := s.destinationRepo.Update(ctx, id, existing.Name, existing.Description, existing.DestinationType, configStr, existing.IsActive, existing.DelaySeconds, existing.DelayPath, existing.RetryAttempts, nil, nil, nil, existing.OrderingKey)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("updating destination: %w", err)
+	}
+	s.clients.Invalidate(id)
+
+	return updated, nil
+}
+
+func (s destinationService) CircuitBreakerStatus(ctx context.Context, id string) (*destination.CircuitBreakerStatus, error) {
+	ctx, span := tracer.StartSpan(ctx, "destination.service.circuit_breaker_status")
+	defer span.End()
+
+	existing, err := s.destinationRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting destination by ID: %w", err)
+	}
+	if existing == nil {
+		return nil, destination.ErrDestinationNotFound
+	}
+
+	status, err := s.breaker.Status(ctx, existing)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting circuit breaker: %w", err)
+	}
+	return status, nil
+}
+
+// ResetCircuitBreaker closes the circuit of the destination. Parked
+// deliveries are released at their scheduled time.
+func (s destinationService) ResetCircuitBreaker(ctx context.Context, id string) (*destination.CircuitBreakerStatus, error) {
+	ctx, span := tracer.StartSpan(ctx, "destination.service.reset_circuit_breaker")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Resetting destination circuit breaker", logger.String("destination_id", id))
+	existing, err := s.destinationRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting destination by ID: %w", err)
+	}
+	if existing == nil {
+		return nil, destination.ErrDestinationNotFound
+	}
+
+	if err := s.breaker.Reset(ctx, id); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("resetting circuit breaker: %w", err)
+	}
+	return s.breaker.Status(ctx, existing)
+}
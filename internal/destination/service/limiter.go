@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+)
+
+// limitRetryInterval is how long a delivery waits for an in-flight slot of
+// its destination before trying again.
+const limitRetryInterval = time.Second
+
+// acquireScript reserves an attempt against the limits of a destination.
+// The attempts in flight are a sorted set of tokens scored by the end of
+// their lease, so that the slot of a worker that died is eventually freed.
+// The rate is a token bucket refilled at rate tokens per second up to
+// burst. It returns 0 when the attempt may go ahead, -1 when every slot is
+// taken, or the milliseconds until a rate token is available.
+//
+// KEYS: in flight, bucket. ARGV: now, max in flight, rate, burst, lease,
+// token.
+var acquireScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local maxInFlight = tonumber(ARGV[2])
+local rate = tonumber(ARGV[3])
+local burst = tonumber(ARGV[4])
+
+if maxInFlight > 0 then
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+	if redis.call('ZCARD', KEYS[1]) >= maxInFlight then
+		return -1
+	end
+end
+
+if rate > 0 then
+	local bucket = redis.call('HMGET', KEYS[2], 'tokens', 'at')
+	local tokens = tonumber(bucket[1]) or burst
+	local at = tonumber(bucket[2]) or now
+	tokens = math.min(burst, tokens + (now - at) * rate / 1000)
+	if tokens < 1 then
+		return math.ceil((1 - tokens) * 1000 / rate)
+	end
+	redis.call('HSET', KEYS[2], 'tokens', tokens - 1, 'at', now)
+	redis.call('PEXPIRE', KEYS[2], math.ceil(burst * 1000 / rate) + 1000)
+end
+
+if maxInFlight > 0 then
+	redis.call('ZADD', KEYS[1], now + tonumber(ARGV[5]), ARGV[6])
+	redis.call('PEXPIRE', KEYS[1], ARGV[5])
+end
+return 0
+`)
+
+// DeliveryLimiter enforces the delivery limits of the destinations in
+// Redis. It implements destination.DeliveryLimiter.
+type DeliveryLimiter struct {
+	redis *redis.Client
+	// lease bounds how long an attempt holds its slot, should its worker
+	// never release it.
+	lease time.Duration
+}
+
+func NewDeliveryLimiter(rdb *redis.Client, lease time.Duration) *DeliveryLimiter {
+	return &DeliveryLimiter{redis: rdb, lease: lease}
+}
+
+// Acquire reserves an attempt. Deliveries waiting for a slot or a rate
+// token try again at slightly different times, so that they do not all
+// come back at once.
+func (l *DeliveryLimiter) Acquire(ctx context.Context, dest *destination.Destination) (string, time.Time, error) {
+	if dest.Limits.IsZero() {
+		return "", time.Time{}, nil
+	}
+	limits := dest.Limits.WithDefaults()
+
+	now := time.Now()
+	token := strconv.FormatInt(now.UnixNano(), 36) + strconv.FormatUint(rand.Uint64(), 36)
+	key := limiterKey(dest.ID)
+	wait, err := acquireScript.Run(ctx, l.redis, []string{key + ":in_flight", key + ":bucket"},
+		now.UnixMilli(),
+		limits.MaxInFlight,
+		limits.Rate,
+		limits.Burst,
+		l.lease.Milliseconds(),
+		token,
+	).Int64()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("acquire delivery slot: %w", err)
+	}
+
+	switch {
+	case wait == 0:
+		if limits.MaxInFlight == 0 {
+			return "", time.Time{}, nil
+		}
+		return token, time.Time{}, nil
+	case wait < 0:
+		return "", now.Add(limitRetryInterval + time.Duration(rand.Int64N(int64(limitRetryInterval)))), nil
+	default:
+		delay := time.Duration(wait) * time.Millisecond
+		return "", now.Add(delay + time.Duration(rand.Int64N(int64(max(delay, limitRetryInterval))))), nil
+	}
+}
+
+func (l *DeliveryLimiter) Release(ctx context.Context, dest *destination.Destination, token string) error {
+	if token == "" {
+		return nil
+	}
+	if err := l.redis.ZRem(ctx, limiterKey(dest.ID)+":in_flight", token).Err(); err != nil {
+		return fmt.Errorf("release delivery slot: %w", err)
+	}
+	return nil
+}
+
+func limiterKey(destinationID string) string {
+	return "destination:limits:" + destinationID
+}
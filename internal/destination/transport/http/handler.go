@@ -225,3 +225,89 @@ func (h *Handler) DeleteDestination(c *fiber.Ctx) error {
 
 	return response.Success(c, nil, "destination deleted")
 }
+
+func (h *Handler) RotateSigningSecret(c *fiber.Ctx) error {
+	ctx := middleware.GetContextWithRequestID(c)
+	ctx, span := tracer.StartSpan(ctx, "destination.handler.rotate_signing_secret")
+	defer span.End()
+
+	destinationID := c.Params("id")
+	if destinationID == "" {
+		return response.BadRequest(c, "destination_id is required", nil)
+	}
+
+	var req destination.RotateSigningSecretRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	updated, err := h.destinationService.RotateSigningSecret(ctx, destinationID, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, destination.ErrDestinationNotFound):
+			return response.NotFound(c, "destination not found")
+		case errors.Is(err, destination.ErrSigningNotConfigured):
+			return response.Conflict(c, "destination has no signing config")
+		case errors.Is(err, destination.ErrSigningRotationActive):
+			return response.Conflict(c, "the previous signing secret is still in use until previous_secret_expires_at")
+		default:
+			return response.InternalError(c, "failed to rotate signing secret")
+		}
+	}
+
+	resp, err := updated.ToResponse()
+	if err != nil {
+		return response.InternalError(c, "failed to serialize destination")
+	}
+
+	return response.Success(c, resp, "signing secret rotated")
+}
+
+func (h *Handler) GetCircuitBreaker(c *fiber.Ctx) error {
+	ctx := middleware.GetContextWithRequestID(c)
+	ctx, span := tracer.StartSpan(ctx, "destination.handler.get_circuit_breaker")
+	defer span.End()
+
+	destinationID := c.Params("id")
+	if destinationID == "" {
+		return response.BadRequest(c, "destination_id is required", nil)
+	}
+
+	status, err := h.destinationService.CircuitBreakerStatus(ctx, destinationID)
+	if err != nil {
+		if errors.Is(err, destination.ErrDestinationNotFound) {
+			return response.NotFound(c, "destination not found")
+		}
+		return response.InternalError(c, "failed to get circuit breaker")
+	}
+
+	return response.Success(c, status, "circuit breaker")
+}
+
+func (h *Handler) ResetCircuitBreaker(c *fiber.Ctx) error {
+	ctx := middleware.GetContextWithRequestID(c)
+	ctx, span := tracer.StartSpan(ctx, "destination.handler.reset_circuit_breaker")
+	defer span.End()
+
+	destinationID := c.Params("id")
+	if destinationID == "" {
+		return response.BadRequest(c, "destination_id is required", nil)
+	}
+
+	status, err := h.destinationService.ResetCircuitBreaker(ctx, destinationID)
+	if err != nil {
+		if errors.Is(err, destination.ErrDestinationNotFound) {
+			return response.NotFound(c, "destination not found")
+		}
+		return response.InternalError(c, "failed to reset circuit breaker")
+	}
+
+	return response.Success(c, status, "circuit breaker reset")
+}
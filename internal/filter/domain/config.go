@@ -0,0 +1,386 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+const (
+	MaxRuleDepth     = 8
+	MaxRuleCount     = 64
+	MaxPatternLength = 512
+	// MaxPatternSize bounds the number of instructions of a compiled
+	// pattern, which catches short patterns that expand into huge programs
+	// such as nested counted repetitions.
+	MaxPatternSize = 10000
+	MaxInValues    = 1024
+)
+
+type Operator string
+
+const (
+	OperatorEq         Operator = "eq"
+	OperatorNeq        Operator = "neq"
+	OperatorGt         Operator = "gt"
+	OperatorLt         Operator = "lt"
+	OperatorIn         Operator = "in"
+	OperatorContains   Operator = "contains"
+	OperatorStartsWith Operator = "starts_with"
+	OperatorExists     Operator = "exists"
+	OperatorMatches    Operator = "matches"
+)
+
+type FieldScope string
+
+const (
+	FieldScopeBody     FieldScope = "body"
+	FieldScopeHeaders  FieldScope = "headers"
+	FieldScopeQuery    FieldScope = "query"
+	FieldScopeMetadata FieldScope = "metadata"
+)
+
+// Rule is a node of a no-code condition. A node is either a group
+// (exactly one of And, Or or Not) or a comparison of Field against Value.
+//
+//	{"and": [
+//	  {"field": "body.type", "operator": "eq", "value": "invoice.paid"},
+//	  {"not": {"field": "headers.X-Test", "operator": "exists"}}
+//	]}
+type Rule struct {
+	And      []*Rule  `json:"and,omitempty"`
+	Or       []*Rule  `json:"or,omitempty"`
+	Not      *Rule    `json:"not,omitempty"`
+	Field    string   `json:"field,omitempty"`
+	Operator Operator `json:"operator,omitempty"`
+	Value    any      `json:"value,omitempty"`
+}
+
+type ConditionConfig struct {
+	Rule *Rule `json:"rule"`
+}
+
+type RegexTarget string
+
+const (
+	RegexTargetBody     RegexTarget = "body"
+	RegexTargetJSONPath RegexTarget = "json_path"
+	RegexTargetHeader   RegexTarget = "header"
+	RegexTargetQuery    RegexTarget = "query"
+	RegexTargetPath     RegexTarget = "path"
+)
+
+type RegexExpect string
+
+const (
+	RegexExpectMatch   RegexExpect = "match"
+	RegexExpectNoMatch RegexExpect = "no_match"
+)
+
+// RegexConfig configures a regex filter. Path is the JSONPath expression of
+// the json_path target, Header the header name of the header target, and
+// Param an optional query parameter of the query target; without it the
+// whole encoded query string is matched.
+//
+//	{"target": "header", "header": "X-Event", "pattern": "^invoice\\.(?P<action>\\w+)$"}
+type RegexConfig struct {
+	Target  RegexTarget `json:"target"`
+	Path    string      `json:"path,omitempty"`
+	Header  string      `json:"header,omitempty"`
+	Param   string      `json:"param,omitempty"`
+	Pattern string      `json:"pattern"`
+	Flags   string      `json:"flags,omitempty"`
+	Expect  RegexExpect `json:"expect,omitempty"`
+}
+
+// JSONPathConfig configures a jsonpath filter. The filter passes when the
+// expression yields a truthy value or a non-empty list of matches.
+type JSONPathConfig struct {
+	Expression string `json:"expression"`
+}
+
+// WasmConfig configures a wasm filter. Module is the content hash of an
+// uploaded module exporting filter(ptr, len).
+type WasmConfig struct {
+	Module string `json:"module"`
+}
+
+// Field is a parsed rule field such as "body.customer.id" or "headers.X-Event".
+type Field struct {
+	Scope FieldScope
+	Path  []string
+}
+
+func ParseField(s string) (*Field, error) {
+	scope, rest, _ := strings.Cut(strings.TrimSpace(s), ".")
+
+	switch FieldScope(scope) {
+	case FieldScopeBody, FieldScopeMetadata:
+		field := &Field{Scope: FieldScope(scope)}
+		if rest != "" {
+			field.Path = strings.Split(rest, ".")
+		}
+		for _, p := range field.Path {
+			if p == "" {
+				return nil, fmt.Errorf("field %q has an empty path segment", s)
+			}
+		}
+		return field, nil
+	case FieldScopeHeaders, FieldScopeQuery:
+		if rest == "" {
+			return nil, fmt.Errorf("field %q must name a %s entry", s, scope)
+		}
+		return &Field{Scope: FieldScope(scope), Path: []string{rest}}, nil
+	default:
+		return nil, fmt.Errorf("field %q must start with one of: body headers query metadata", s)
+	}
+}
+
+func ConditionConfigFromMap(data map[string]interface{}) (*ConditionConfig, error) {
+	var config ConditionConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func decodeConfig(data map[string]interface{}, v any) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonData, v)
+}
+
+func (c *ConditionConfig) Validate() error {
+	if c.Rule == nil {
+		return fmt.Errorf("rule is required")
+	}
+
+	count := 0
+	return c.Rule.validate("rule", 1, &count)
+}
+
+func (r *Rule) validate(path string, depth int, count *int) error {
+	if r == nil {
+		return fmt.Errorf("%s: rule cannot be empty", path)
+	}
+
+	*count++
+	if *count > MaxRuleCount {
+		return fmt.Errorf("condition exceeds the maximum of %d rules", MaxRuleCount)
+	}
+	if depth > MaxRuleDepth {
+		return fmt.Errorf("%s: condition exceeds the maximum depth of %d", path, MaxRuleDepth)
+	}
+
+	kinds := 0
+	if r.And != nil {
+		kinds++
+	}
+	if r.Or != nil {
+		kinds++
+	}
+	if r.Not != nil {
+		kinds++
+	}
+	if r.Field != "" || r.Operator != "" {
+		kinds++
+	}
+	if kinds != 1 {
+		return fmt.Errorf("%s: rule must define exactly one of and, or, not or a field comparison", path)
+	}
+
+	switch {
+	case r.And != nil:
+		return validateGroup(path+".and", r.And, depth, count)
+	case r.Or != nil:
+		return validateGroup(path+".or", r.Or, depth, count)
+	case r.Not != nil:
+		return r.Not.validate(path+".not", depth+1, count)
+	default:
+		return r.validateComparison(path)
+	}
+}
+
+func validateGroup(path string, rules []*Rule, depth int, count *int) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("%s: group cannot be empty", path)
+	}
+	for i, rule := range rules {
+		if err := rule.validate(fmt.Sprintf("%s[%d]", path, i), depth+1, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Rule) validateComparison(path string) error {
+	if _, err := ParseField(r.Field); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch r.Operator {
+	case OperatorEq, OperatorNeq:
+	case OperatorGt, OperatorLt:
+		switch r.Value.(type) {
+		case float64, string:
+		default:
+			return fmt.Errorf("%s: %s requires a number or string value", path, r.Operator)
+		}
+	case OperatorIn:
+		values, ok := r.Value.([]any)
+		if !ok || len(values) == 0 {
+			return fmt.Errorf("%s: in requires a non-empty array value", path)
+		}
+		if len(values) > MaxInValues {
+			return fmt.Errorf("%s: in accepts at most %d values", path, MaxInValues)
+		}
+	case OperatorContains:
+		if r.Value == nil {
+			return fmt.Errorf("%s: contains requires a value", path)
+		}
+	case OperatorStartsWith:
+		if _, ok := r.Value.(string); !ok {
+			return fmt.Errorf("%s: starts_with requires a string value", path)
+		}
+	case OperatorExists:
+		switch r.Value.(type) {
+		case nil, bool:
+		default:
+			return fmt.Errorf("%s: exists accepts only a boolean value", path)
+		}
+	case OperatorMatches:
+		pattern, ok := r.Value.(string)
+		if !ok || pattern == "" {
+			return fmt.Errorf("%s: matches requires a pattern", path)
+		}
+		if _, err := CompilePattern(pattern, ""); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case "":
+		return fmt.Errorf("%s: operator is required", path)
+	default:
+		return fmt.Errorf("%s: unsupported operator %q", path, r.Operator)
+	}
+
+	return nil
+}
+
+func JSONPathConfigFromMap(data map[string]interface{}) (*JSONPathConfig, error) {
+	var config JSONPathConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *JSONPathConfig) Validate() error {
+	if strings.TrimSpace(c.Expression) == "" {
+		return fmt.Errorf("expression is required")
+	}
+	if _, err := jsonpath.Compile(c.Expression); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CompilePattern compiles a user supplied regular expression after checking
+// its length and size. Flags is a combination of i, m, s and U.
+func CompilePattern(pattern, flags string) (*regexp.Regexp, error) {
+	if len(pattern) > MaxPatternLength {
+		return nil, fmt.Errorf("pattern exceeds %d characters", MaxPatternLength)
+	}
+	for _, flag := range flags {
+		if !strings.ContainsRune("imsU", flag) {
+			return nil, fmt.Errorf("unsupported flag %q, allowed flags are i m s U", flag)
+		}
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if len(prog.Inst) > MaxPatternSize {
+		return nil, fmt.Errorf("pattern is too complex")
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+func RegexConfigFromMap(data map[string]interface{}) (*RegexConfig, error) {
+	var config RegexConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *RegexConfig) Validate() error {
+	switch c.Target {
+	case RegexTargetBody, RegexTargetQuery, RegexTargetPath:
+	case RegexTargetJSONPath:
+		if strings.TrimSpace(c.Path) == "" {
+			return fmt.Errorf("path is required for the json_path target")
+		}
+		if _, err := jsonpath.Compile(c.Path); err != nil {
+			return fmt.Errorf("path: %w", err)
+		}
+	case RegexTargetHeader:
+		if strings.TrimSpace(c.Header) == "" {
+			return fmt.Errorf("header is required for the header target")
+		}
+	case "":
+		return fmt.Errorf("target is required")
+	default:
+		return fmt.Errorf("target must be one of: body json_path header query path")
+	}
+
+	if c.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if _, err := CompilePattern(c.Pattern, c.Flags); err != nil {
+		return err
+	}
+
+	switch c.Expect {
+	case "", RegexExpectMatch, RegexExpectNoMatch:
+	default:
+		return fmt.Errorf("expect must be one of: match no_match")
+	}
+	return nil
+}
+
+func WasmConfigFromMap(data map[string]interface{}) (*WasmConfig, error) {
+	var config WasmConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *WasmConfig) Validate() error {
+	if c.Module == "" {
+		return fmt.Errorf("module is required")
+	}
+	if !wasmvm.IsHash(c.Module) {
+		return fmt.Errorf("module must be the sha256 hash of an uploaded module")
+	}
+	return nil
+}
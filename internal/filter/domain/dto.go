@@ -0,0 +1,81 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type CreateRequest struct {
+	Name           string         `json:"name" validate:"required,min=2,max=100"`
+	Description    string         `json:"description" validate:"omitempty,max=255"`
+	FilterType     FilterType     `json:"filter_type" validate:"required,oneof=condition javascript jsonpath regex wasm"`
+	Mode           Mode           `json:"mode" validate:"omitempty,oneof=nocode code"`
+	Config         map[string]any `json:"config" validate:"omitempty"`
+	Code           string         `json:"code" validate:"omitempty"`
+	ExecutionOrder int32          `json:"execution_order" validate:"omitempty,min=1"`
+}
+
+type UpdateRequest struct {
+	Name           string         `json:"name" validate:"omitempty,min=2,max=100"`
+	Description    string         `json:"description" validate:"omitempty,max=255"`
+	FilterType     FilterType     `json:"filter_type" validate:"omitempty,oneof=condition javascript jsonpath regex wasm"`
+	Mode           Mode           `json:"mode" validate:"omitempty,oneof=nocode code"`
+	Config         map[string]any `json:"config" validate:"omitempty"`
+	Code           string         `json:"code" validate:"omitempty"`
+	ExecutionOrder int32          `json:"execution_order" validate:"omitempty,min=1"`
+	IsActive       *bool          `json:"is_active" validate:"omitempty"`
+}
+
+type FilterResponse struct {
+	ID             string         `json:"id"`
+	PipelineID     string         `json:"pipeline_id"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	FilterType     string         `json:"filter_type"`
+	Mode           string         `json:"mode"`
+	Config         map[string]any `json:"config,omitempty"`
+	Code           string         `json:"code,omitempty"`
+	IsActive       bool           `json:"is_active"`
+	ExecutionOrder int32          `json:"execution_order"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func (f *Filter) ToResponse() (*FilterResponse, error) {
+	resp := &FilterResponse{
+		ID:             f.ID,
+		PipelineID:     f.PipelineID,
+		Name:           f.Name,
+		Description:    f.Description,
+		FilterType:     string(f.FilterType),
+		Mode:           string(f.Mode),
+		Code:           f.Code,
+		IsActive:       f.IsActive,
+		ExecutionOrder: f.ExecutionOrder,
+		CreatedAt:      f.CreatedAt,
+		UpdatedAt:      f.UpdatedAt,
+	}
+
+	if f.Config != "" {
+		var cfg map[string]any
+		if err := json.Unmarshal([]byte(f.Config), &cfg); err != nil {
+			return nil, fmt.Errorf("unmarshal config: %w", err)
+		}
+		resp.Config = cfg
+	}
+
+	return resp, nil
+}
+
+func ToResponses(list []*Filter) ([]*FilterResponse, error) {
+	resp := make([]*FilterResponse, 0, len(list))
+	for _, item := range list {
+		r, err := item.ToResponse()
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, r)
+	}
+	return resp, nil
+}
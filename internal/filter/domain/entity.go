@@ -0,0 +1,37 @@
+package filter
+
+import (
+	"time"
+)
+
+type FilterType string
+
+const (
+	FilterTypeCondition  FilterType = "condition"
+	FilterTypeJavascript FilterType = "javascript"
+	FilterTypeJSONPath   FilterType = "jsonpath"
+	FilterTypeRegex      FilterType = "regex"
+	FilterTypeWasm       FilterType = "wasm"
+)
+
+type Mode string
+
+const (
+	ModeNocode Mode = "nocode"
+	ModeCode   Mode = "code"
+)
+
+type Filter struct {
+	ID             string     `json:"id"`
+	PipelineID     string     `json:"pipeline_id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	FilterType     FilterType `json:"filter_type"`
+	Mode           Mode       `json:"mode"`
+	Config         string     `json:"config"`
+	Code           string     `json:"code"`
+	IsActive       bool       `json:"is_active"`
+	ExecutionOrder int32      `json:"execution_order"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,8 @@
+package filter
+
+import "errors"
+
+var (
+	ErrFilterNotFound        = errors.New("filter not found")
+	ErrUnsupportedFilterType = errors.New("unsupported filter type")
+)
@@ -0,0 +1,14 @@
+package filter
+
+import (
+	"context"
+)
+
+type Repository interface {
+	Create(ctx context.Context, filter *Filter) error
+	GetByID(ctx context.Context, id string) (*Filter, error)
+	ListByPipeline(ctx context.Context, pipelineID string) ([]*Filter, error)
+	ListActiveByPipeline(ctx context.Context, pipelineID string) ([]*Filter, error)
+	Update(ctx context.Context, filter *Filter) error
+	Delete(ctx context.Context, id string) error
+}
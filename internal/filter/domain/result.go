@@ -0,0 +1,24 @@
+package filter
+
+// Result is the outcome of one filter for one event. Details carries the
+// type-specific explanation (for example the evaluated rule tree of a
+// condition filter) so the dashboard can show why an event was filtered.
+type Result struct {
+	FilterID   string     `json:"filter_id"`
+	Name       string     `json:"name"`
+	FilterType FilterType `json:"filter_type"`
+	Passed     bool       `json:"passed"`
+	Error      string     `json:"error,omitempty"`
+	Details    any        `json:"details,omitempty"`
+	// Captures holds the named groups captured by a regex filter. They are
+	// made available to the transformations of the same run.
+	Captures map[string]string `json:"captures,omitempty"`
+	// Logs holds the console output of code filters.
+	Logs []string `json:"logs,omitempty"`
+}
+
+// Results is the document stored in webhook_events.filter_results.
+type Results struct {
+	Passed  bool      `json:"passed"`
+	Filters []*Result `json:"filters"`
+}
@@ -0,0 +1,20 @@
+package filter
+
+import (
+	"context"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+)
+
+type Service interface {
+	Create(ctx context.Context, pipelineID string, req CreateRequest) (*Filter, error)
+	GetByID(ctx context.Context, pipelineID, id string) (*Filter, error)
+	List(ctx context.Context, pipelineID string) ([]*Filter, error)
+	Update(ctx context.Context, pipelineID, id string, req UpdateRequest) (*Filter, error)
+	Delete(ctx context.Context, pipelineID, id string) error
+}
+
+// Evaluator decides whether an event passes a single filter.
+type Evaluator interface {
+	Evaluate(ctx context.Context, event *pipeline.Event) (*Result, error)
+}
@@ -0,0 +1,29 @@
+package evaluator
+
+import (
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	"github.com/theotruvelot/catchook/pkg/compiled"
+)
+
+// Cache keeps compiled evaluators per filter ID. An entry is rebuilt when
+// the filter has been updated since it was compiled.
+type Cache struct {
+	opts       Options
+	evaluators *compiled.Cache[filter.Evaluator]
+}
+
+func NewCache(opts Options) *Cache {
+	return &Cache{opts: opts, evaluators: compiled.NewCache[filter.Evaluator]()}
+}
+
+// Get returns the evaluator of the filter, compiling it on first use.
+func (c *Cache) Get(f *filter.Filter) (filter.Evaluator, error) {
+	return c.evaluators.Get(f.ID, f.UpdatedAt, func() (filter.Evaluator, error) {
+		return New(f, c.opts)
+	})
+}
+
+// Invalidate drops the compiled evaluator of a filter.
+func (c *Cache) Invalidate(id string) {
+	c.evaluators.Invalidate(id)
+}
@@ -0,0 +1,384 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+)
+
+const maxReportedLength = 256
+
+// RuleResult mirrors a condition rule and records how it evaluated. It is
+// stored as the details of the filter result.
+type RuleResult struct {
+	Operator string        `json:"operator"`
+	Field    string        `json:"field,omitempty"`
+	Expected any           `json:"expected,omitempty"`
+	Actual   any           `json:"actual,omitempty"`
+	Found    *bool         `json:"found,omitempty"`
+	Matched  bool          `json:"matched"`
+	Rules    []*RuleResult `json:"rules,omitempty"`
+}
+
+type conditionEvaluator struct {
+	rule     *filter.Rule
+	fields   map[*filter.Rule]*filter.Field
+	patterns map[*filter.Rule]*regexp.Regexp
+}
+
+func newConditionEvaluator(config string) (*conditionEvaluator, error) {
+	var cfg filter.ConditionConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid condition config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid condition config: %w", err)
+	}
+
+	e := &conditionEvaluator{
+		rule:     cfg.Rule,
+		fields:   map[*filter.Rule]*filter.Field{},
+		patterns: map[*filter.Rule]*regexp.Regexp{},
+	}
+	if err := e.compile(cfg.Rule); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *conditionEvaluator) compile(rule *filter.Rule) error {
+	switch {
+	case rule.And != nil:
+		for _, child := range rule.And {
+			if err := e.compile(child); err != nil {
+				return err
+			}
+		}
+	case rule.Or != nil:
+		for _, child := range rule.Or {
+			if err := e.compile(child); err != nil {
+				return err
+			}
+		}
+	case rule.Not != nil:
+		return e.compile(rule.Not)
+	default:
+		field, err := filter.ParseField(rule.Field)
+		if err != nil {
+			return err
+		}
+		e.fields[rule] = field
+
+		if rule.Operator == filter.OperatorMatches {
+			re, err := filter.CompilePattern(rule.Value.(string), "")
+			if err != nil {
+				return err
+			}
+			e.patterns[rule] = re
+		}
+	}
+	return nil
+}
+
+func (e *conditionEvaluator) Evaluate(ctx context.Context, event *pipeline.Event) (*filter.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	scope := &conditionScope{event: event, body: body}
+	result := e.evaluate(e.rule, scope)
+
+	return &filter.Result{
+		Passed:  result.Matched,
+		Details: result,
+	}, nil
+}
+
+type conditionScope struct {
+	event *pipeline.Event
+	body  any
+}
+
+func (e *conditionEvaluator) evaluate(rule *filter.Rule, scope *conditionScope) *RuleResult {
+	switch {
+	case rule.And != nil:
+		result := &RuleResult{Operator: "and", Matched: true}
+		for _, child := range rule.And {
+			r := e.evaluate(child, scope)
+			result.Rules = append(result.Rules, r)
+			result.Matched = result.Matched && r.Matched
+		}
+		return result
+	case rule.Or != nil:
+		result := &RuleResult{Operator: "or"}
+		for _, child := range rule.Or {
+			r := e.evaluate(child, scope)
+			result.Rules = append(result.Rules, r)
+			result.Matched = result.Matched || r.Matched
+		}
+		return result
+	case rule.Not != nil:
+		r := e.evaluate(rule.Not, scope)
+		return &RuleResult{Operator: "not", Matched: !r.Matched, Rules: []*RuleResult{r}}
+	default:
+		return e.compare(rule, scope)
+	}
+}
+
+func (e *conditionEvaluator) compare(rule *filter.Rule, scope *conditionScope) *RuleResult {
+	actual, found := resolveField(e.fields[rule], scope)
+
+	result := &RuleResult{
+		Operator: string(rule.Operator),
+		Field:    rule.Field,
+		Expected: rule.Value,
+		Actual:   summarize(actual),
+		Found:    &found,
+	}
+
+	switch rule.Operator {
+	case filter.OperatorExists:
+		want := true
+		if b, ok := rule.Value.(bool); ok {
+			want = b
+		}
+		result.Matched = found == want
+	case filter.OperatorNeq:
+		result.Matched = !found || !valuesEqual(actual, rule.Value)
+	default:
+		if found {
+			result.Matched = e.matchFound(rule, actual)
+		}
+	}
+
+	return result
+}
+
+func (e *conditionEvaluator) matchFound(rule *filter.Rule, actual any) bool {
+	switch rule.Operator {
+	case filter.OperatorEq:
+		return valuesEqual(actual, rule.Value)
+	case filter.OperatorGt:
+		c, ok := compareValues(actual, rule.Value)
+		return ok && c > 0
+	case filter.OperatorLt:
+		c, ok := compareValues(actual, rule.Value)
+		return ok && c < 0
+	case filter.OperatorIn:
+		for _, candidate := range rule.Value.([]any) {
+			if valuesEqual(actual, candidate) {
+				return true
+			}
+		}
+		return false
+	case filter.OperatorContains:
+		return containsValue(actual, rule.Value)
+	case filter.OperatorStartsWith:
+		s, ok := actual.(string)
+		return ok && strings.HasPrefix(s, rule.Value.(string))
+	case filter.OperatorMatches:
+		s, ok := stringValue(actual)
+		return ok && e.patterns[rule].MatchString(s)
+	default:
+		return false
+	}
+}
+
+func resolveField(field *filter.Field, scope *conditionScope) (any, bool) {
+	switch field.Scope {
+	case filter.FieldScopeBody:
+		return walkPath(scope.body, field.Path)
+	case filter.FieldScopeMetadata:
+		return walkPath(scope.event.Metadata, field.Path)
+	case filter.FieldScopeHeaders:
+		return scope.event.Header(field.Path[0])
+	case filter.FieldScopeQuery:
+		v, ok := scope.event.Query[field.Path[0]]
+		return v, ok
+	default:
+		return nil, false
+	}
+}
+
+func walkPath(value any, path []string) (any, bool) {
+	if len(path) == 0 {
+		return value, value != nil
+	}
+
+	current := value
+	for _, segment := range path {
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, false
+			}
+			current = node[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func valuesEqual(a, b any) bool {
+	if x, y, ok := numericPair(a, b); ok {
+		return x == y
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// compareValues orders numbers numerically and strings lexically, which also
+// orders RFC 3339 timestamps correctly.
+func compareValues(a, b any) (int, bool) {
+	if x, y, ok := numericPair(a, b); ok {
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	x, okA := a.(string)
+	y, okB := b.(string)
+	if !okA || !okB {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
+
+func containsValue(actual, value any) bool {
+	switch v := actual.(type) {
+	case string:
+		s, ok := stringValue(value)
+		return ok && strings.Contains(v, s)
+	case []any:
+		for _, item := range v {
+			if valuesEqual(item, value) {
+				return true
+			}
+		}
+		return false
+	case map[string]any:
+		key, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, exists := v[key]
+		return exists
+	default:
+		return false
+	}
+}
+
+// numericPair converts both values to float64 when at least one of them is a
+// number. Header and query values are always strings, so "42" must compare
+// equal to 42, while two strings keep comparing as strings.
+func numericPair(a, b any) (float64, float64, bool) {
+	_, aIsString := a.(string)
+	_, bIsString := b.(string)
+	if aIsString && bIsString {
+		return 0, 0, false
+	}
+	x, ok := toNumber(a)
+	if !ok {
+		return 0, 0, false
+	}
+	y, ok := toNumber(b)
+	if !ok {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func stringValue(v any) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case json.Number:
+		return s.String(), true
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(s), true
+	default:
+		return "", false
+	}
+}
+
+func normalize(v any) any {
+	switch n := v.(type) {
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+		return n.String()
+	case map[string]any:
+		out := make(map[string]any, len(n))
+		for k, item := range n {
+			out[k] = normalize(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(n))
+		for i, item := range n {
+			out[i] = normalize(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// summarize keeps the recorded actual value small enough to be stored with
+// every event.
+func summarize(v any) any {
+	switch n := v.(type) {
+	case string:
+		if len(n) > maxReportedLength {
+			return n[:maxReportedLength] + "..."
+		}
+		return n
+	case map[string]any:
+		return fmt.Sprintf("<object with %d keys>", len(n))
+	case []any:
+		return fmt.Sprintf("<array of %d items>", len(n))
+	default:
+		return v
+	}
+}
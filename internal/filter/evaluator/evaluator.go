@@ -0,0 +1,49 @@
+package evaluator
+
+import (
+	"fmt"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	"github.com/theotruvelot/catchook/pkg/jsvm"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+// Options holds the runtime settings shared by the evaluators. Modules
+// resolves the modules of wasm filters.
+type Options struct {
+	Script  jsvm.Limits
+	Modules wasmvm.Loader
+}
+
+// New builds the evaluator matching the type of the given filter.
+func New(f *filter.Filter, opts Options) (filter.Evaluator, error) {
+	switch f.FilterType {
+	case filter.FilterTypeCondition:
+		if f.Mode != filter.ModeNocode {
+			return nil, fmt.Errorf("%w: condition filters only support nocode mode", filter.ErrUnsupportedFilterType)
+		}
+		return newConditionEvaluator(f.Config)
+	case filter.FilterTypeJSONPath:
+		if f.Mode != filter.ModeNocode {
+			return nil, fmt.Errorf("%w: jsonpath filters only support nocode mode", filter.ErrUnsupportedFilterType)
+		}
+		return newJSONPathEvaluator(f.Config)
+	case filter.FilterTypeRegex:
+		if f.Mode != filter.ModeNocode {
+			return nil, fmt.Errorf("%w: regex filters only support nocode mode", filter.ErrUnsupportedFilterType)
+		}
+		return newRegexEvaluator(f.Config)
+	case filter.FilterTypeJavascript:
+		if f.Mode != filter.ModeCode {
+			return nil, fmt.Errorf("%w: javascript filters only support code mode", filter.ErrUnsupportedFilterType)
+		}
+		return newJavascriptEvaluator(f, opts.Script)
+	case filter.FilterTypeWasm:
+		if f.Mode != filter.ModeCode {
+			return nil, fmt.Errorf("%w: wasm filters only support code mode", filter.ErrUnsupportedFilterType)
+		}
+		return newWasmEvaluator(f.Config, opts.Modules)
+	default:
+		return nil, fmt.Errorf("%w: %s", filter.ErrUnsupportedFilterType, f.FilterType)
+	}
+}
@@ -0,0 +1,49 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/jsvm"
+)
+
+// JavascriptEntry is the function a javascript filter must define.
+const JavascriptEntry = "filter"
+
+type javascriptEvaluator struct {
+	program *jsvm.Program
+	limits  jsvm.Limits
+}
+
+func newJavascriptEvaluator(f *filter.Filter, limits jsvm.Limits) (*javascriptEvaluator, error) {
+	program, err := jsvm.Compile(f.Name+".js", f.Code, JavascriptEntry)
+	if err != nil {
+		return nil, err
+	}
+	return &javascriptEvaluator{program: program, limits: limits}, nil
+}
+
+// Evaluate calls filter(event), which must return a boolean. Console output
+// is kept in the result even when the script fails.
+func (e *javascriptEvaluator) Evaluate(ctx context.Context, event *pipeline.Event) (*filter.Result, error) {
+	res, err := e.program.Call(ctx, e.limits, event.ScriptEvent())
+	if res == nil {
+		return nil, err
+	}
+
+	result := &filter.Result{Logs: res.Logs}
+	if err != nil {
+		return result, err
+	}
+
+	switch string(res.Output) {
+	case "true":
+		result.Passed = true
+	case "false":
+	default:
+		return result, fmt.Errorf("%s(event) must return a boolean", JavascriptEntry)
+	}
+	return result, nil
+}
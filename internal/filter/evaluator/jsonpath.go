@@ -0,0 +1,66 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// JSONPathResult is stored as the details of a jsonpath filter result.
+type JSONPathResult struct {
+	Expression string `json:"expression"`
+	Matches    int    `json:"matches"`
+	Value      any    `json:"value,omitempty"`
+}
+
+type jsonPathEvaluator struct {
+	path *jsonpath.Path
+}
+
+func newJSONPathEvaluator(config string) (*jsonPathEvaluator, error) {
+	var cfg filter.JSONPathConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath config: %w", err)
+	}
+
+	path, err := jsonpath.Compile(cfg.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jsonpath config: %w", err)
+	}
+	return &jsonPathEvaluator{path: path}, nil
+}
+
+// Evaluate passes when a definite path (such as $.data.paid) selects a
+// truthy value, or when any other path (wildcards, filters, slices) selects
+// at least one node.
+func (e *jsonPathEvaluator) Evaluate(ctx context.Context, event *pipeline.Event) (*filter.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	nodes := e.path.Query(body)
+	details := &JSONPathResult{
+		Expression: e.path.String(),
+		Matches:    len(nodes),
+	}
+
+	var passed bool
+	if e.path.IsDefinite() {
+		if len(nodes) > 0 {
+			details.Value = summarize(nodes[0])
+			passed = jsonpath.Truthy(nodes[0])
+		}
+	} else {
+		passed = len(nodes) > 0
+	}
+
+	return &filter.Result{
+		Passed:  passed,
+		Details: details,
+	}, nil
+}
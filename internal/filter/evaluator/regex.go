@@ -0,0 +1,163 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// RegexResult is stored as the details of a regex filter result.
+type RegexResult struct {
+	Target  filter.RegexTarget `json:"target"`
+	Pattern string             `json:"pattern"`
+	Expect  filter.RegexExpect `json:"expect"`
+	Found   bool               `json:"found"`
+	Matched bool               `json:"matched"`
+	Match   string             `json:"match,omitempty"`
+}
+
+type regexEvaluator struct {
+	cfg     filter.RegexConfig
+	pattern *regexp.Regexp
+	path    *jsonpath.Path
+}
+
+func newRegexEvaluator(config string) (*regexEvaluator, error) {
+	var cfg filter.RegexConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid regex config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid regex config: %w", err)
+	}
+	if cfg.Expect == "" {
+		cfg.Expect = filter.RegexExpectMatch
+	}
+
+	pattern, err := filter.CompilePattern(cfg.Pattern, cfg.Flags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex config: %w", err)
+	}
+
+	e := &regexEvaluator{cfg: cfg, pattern: pattern}
+	if cfg.Target == filter.RegexTargetJSONPath {
+		e.path = jsonpath.MustCompile(cfg.Path)
+	}
+	return e, nil
+}
+
+// Evaluate matches the pattern against the configured target. A json_path
+// target selecting several values matches when any of them does; the named
+// groups of the first match are returned as captures.
+func (e *regexEvaluator) Evaluate(ctx context.Context, event *pipeline.Event) (*filter.Result, error) {
+	inputs, err := e.inputs(event)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &RegexResult{
+		Target:  e.cfg.Target,
+		Pattern: e.cfg.Pattern,
+		Expect:  e.cfg.Expect,
+		Found:   len(inputs) > 0,
+	}
+
+	var captures map[string]string
+	for _, input := range inputs {
+		loc := e.pattern.FindStringSubmatchIndex(input)
+		if loc == nil {
+			continue
+		}
+		details.Matched = true
+		details.Match = summarize(input[loc[0]:loc[1]]).(string)
+		captures = e.captures(input, loc)
+		break
+	}
+
+	passed := details.Matched
+	if e.cfg.Expect == filter.RegexExpectNoMatch {
+		passed = !details.Matched
+	}
+
+	return &filter.Result{
+		Passed:   passed,
+		Details:  details,
+		Captures: captures,
+	}, nil
+}
+
+func (e *regexEvaluator) inputs(event *pipeline.Event) ([]string, error) {
+	switch e.cfg.Target {
+	case filter.RegexTargetBody:
+		return []string{string(event.Payload)}, nil
+	case filter.RegexTargetJSONPath:
+		body, err := event.Body()
+		if err != nil {
+			return nil, fmt.Errorf("decode payload: %w", err)
+		}
+		nodes := e.path.Query(body)
+		inputs := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			s, err := textValue(node)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, s)
+		}
+		return inputs, nil
+	case filter.RegexTargetHeader:
+		if v, ok := event.Header(e.cfg.Header); ok {
+			return []string{v}, nil
+		}
+		return nil, nil
+	case filter.RegexTargetQuery:
+		if e.cfg.Param != "" {
+			if v, ok := event.Query[e.cfg.Param]; ok {
+				return []string{v}, nil
+			}
+			return nil, nil
+		}
+		values := url.Values{}
+		for k, v := range event.Query {
+			values.Set(k, v)
+		}
+		return []string{values.Encode()}, nil
+	case filter.RegexTargetPath:
+		return []string{event.Path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported regex target %q", e.cfg.Target)
+	}
+}
+
+func (e *regexEvaluator) captures(input string, loc []int) map[string]string {
+	var captures map[string]string
+	for i, name := range e.pattern.SubexpNames() {
+		if name == "" || loc[2*i] < 0 {
+			continue
+		}
+		if captures == nil {
+			captures = map[string]string{}
+		}
+		captures[name] = input[loc[2*i]:loc[2*i+1]]
+	}
+	return captures
+}
+
+// textValue renders a JSON value as the text a pattern is matched against:
+// strings as is, other scalars in their JSON form and containers as JSON.
+func textValue(v any) (string, error) {
+	if s, ok := stringValue(v); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encode value: %w", err)
+	}
+	return string(b), nil
+}
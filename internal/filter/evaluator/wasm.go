@@ -0,0 +1,67 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+// WasmEntry is the function a wasm filter module must export.
+const WasmEntry = "filter"
+
+type wasmEvaluator struct {
+	hash    string
+	modules wasmvm.Loader
+}
+
+func newWasmEvaluator(raw string, modules wasmvm.Loader) (*wasmEvaluator, error) {
+	var config filter.WasmConfig
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		return nil, fmt.Errorf("invalid wasm config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &wasmEvaluator{hash: config.Module, modules: modules}, nil
+}
+
+// Evaluate passes the event to the module, which must answer true or false.
+// The module is resolved on first use, then served from the runtime cache.
+func (e *wasmEvaluator) Evaluate(ctx context.Context, event *pipeline.Event) (*filter.Result, error) {
+	if e.modules == nil {
+		return nil, fmt.Errorf("wasm runtime is not configured")
+	}
+	module, err := e.modules.Load(ctx, e.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := json.Marshal(event.ScriptEvent())
+	if err != nil {
+		return nil, fmt.Errorf("encode event: %w", err)
+	}
+
+	res, err := module.Call(ctx, WasmEntry, input)
+	if res == nil {
+		return nil, err
+	}
+
+	result := &filter.Result{Logs: res.Logs}
+	if err != nil {
+		return result, err
+	}
+
+	switch string(bytes.TrimSpace(res.Output)) {
+	case "true":
+		result.Passed = true
+	case "false":
+	default:
+		return result, fmt.Errorf("%s must return true or false", WasmEntry)
+	}
+	return result, nil
+}
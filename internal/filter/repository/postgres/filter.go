@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type filterRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewFilterRepository(db *pgxpool.Pool, appLogger logger.Logger) filter.Repository {
+	return &filterRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r filterRepository) Create(ctx context.Context, f *filter.Filter) error {
+	ctx, span := tracer.StartSpan(ctx, "filter.repository.create")
+	defer span.End()
+
+	pipelineID, err := uuid.Parse(f.PipelineID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("invalid pipeline id: %w", err)
+	}
+
+	result, err := r.queries.CreateFilter(ctx,
+		pipelineID,
+		f.Name,
+		f.Description,
+		generated.FilterType(f.FilterType),
+		generated.FilterMode(f.Mode),
+		[]byte(configOrEmpty(f.Config)),
+		toText(f.Code),
+		f.ExecutionOrder,
+		f.IsActive,
+	)
+	if err != nil {
+		r.appLogger.Error(ctx, "Failed to create filter",
+			logger.String("name", f.Name),
+			logger.String("pipeline_id", f.PipelineID),
+			logger.Error(err),
+		)
+		span.RecordError(err)
+		return fmt.Errorf("failed to create filter: %w", err)
+	}
+
+	*f = *toFilter(result)
+	return nil
+}
+
+func (r filterRepository) GetByID(ctx context.Context, id string) (*filter.Filter, error) {
+	ctx, span := tracer.StartSpan(ctx, "filter.repository.get_by_id")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter ID format: %w", err)
+	}
+
+	result, err := r.queries.GetFilterByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to get filter by ID", logger.Error(err))
+		return nil, fmt.Errorf("failed to get filter by ID: %w", err)
+	}
+
+	return toFilter(result), nil
+}
+
+func (r filterRepository) ListByPipeline(ctx context.Context, pipelineID string) ([]*filter.Filter, error) {
+	ctx, span := tracer.StartSpan(ctx, "filter.repository.list_by_pipeline")
+	defer span.End()
+
+	uid, err := uuid.Parse(pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+
+	results, err := r.queries.ListFiltersByPipeline(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list filters: %w", err)
+	}
+
+	return toFilters(results), nil
+}
+
+func (r filterRepository) ListActiveByPipeline(ctx context.Context, pipelineID string) ([]*filter.Filter, error) {
+	ctx, span := tracer.StartSpan(ctx, "filter.repository.list_active_by_pipeline")
+	defer span.End()
+
+	uid, err := uuid.Parse(pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+
+	results, err := r.queries.ListActiveFiltersByPipeline(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list active filters: %w", err)
+	}
+
+	return toFilters(results), nil
+}
+
+func (r filterRepository) Update(ctx context.Context, f *filter.Filter) error {
+	ctx, span := tracer.StartSpan(ctx, "filter.repository.update")
+	defer span.End()
+
+	uid, err := uuid.Parse(f.ID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("invalid filter id: %w", err)
+	}
+
+	result, err := r.queries.UpdateFilter(ctx,
+		uid,
+		f.Name,
+		pgtype.Text{String: f.Description, Valid: true},
+		generated.FilterType(f.FilterType),
+		generated.FilterMode(f.Mode),
+		[]byte(configOrEmpty(f.Config)),
+		toText(f.Code),
+		f.ExecutionOrder,
+		f.IsActive,
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to update filter", logger.Error(err))
+		return fmt.Errorf("failed to update filter: %w", err)
+	}
+
+	*f = *toFilter(result)
+	return nil
+}
+
+func (r filterRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.StartSpan(ctx, "filter.repository.delete")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid filter id: %w", err)
+	}
+
+	if err := r.queries.DeleteFilter(ctx, uid); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete filter: %w", err)
+	}
+	return nil
+}
+
+func toFilter(result generated.Filter) *filter.Filter {
+	return &filter.Filter{
+		ID:             result.ID.String(),
+		PipelineID:     result.PipelineID.String(),
+		Name:           result.Name,
+		Description:    result.Description.String,
+		FilterType:     filter.FilterType(result.FilterType),
+		Mode:           filter.Mode(result.Mode),
+		Config:         string(result.Config),
+		Code:           result.Code.String,
+		IsActive:       result.IsActive,
+		ExecutionOrder: result.ExecutionOrder,
+		CreatedAt:      result.CreatedAt.Time,
+		UpdatedAt:      result.UpdatedAt.Time,
+	}
+}
+
+func toFilters(results []generated.Filter) []*filter.Filter {
+	filters := make([]*filter.Filter, len(results))
+	for i, result := range results {
+		filters[i] = toFilter(result)
+	}
+	return filters
+}
+
+func configOrEmpty(config string) string {
+	if config == "" {
+		return "{}"
+	}
+	return config
+}
+
+func toText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	"github.com/theotruvelot/catchook/internal/filter/evaluator"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	wasm "github.com/theotruvelot/catchook/internal/wasm/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+type filterService struct {
+	filterRepo   filter.Repository
+	pipelineRepo pipeline.Repository
+	evaluators   *evaluator.Cache
+	modules      wasmvm.Loader
+	appLogger    logger.Logger
+}
+
+func NewFilterService(filterRepo filter.Repository, pipelineRepo pipeline.Repository, evaluators *evaluator.Cache, modules wasmvm.Loader, appLogger logger.Logger) filter.Service {
+	return &filterService{
+		filterRepo:   filterRepo,
+		pipelineRepo: pipelineRepo,
+		evaluators:   evaluators,
+		modules:      modules,
+		appLogger:    appLogger,
+	}
+}
+
+func (s filterService) Create(ctx context.Context, pipelineID string, req filter.CreateRequest) (*filter.Filter, error) {
+	ctx, span := tracer.StartSpan(ctx, "filter.service.create")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Creating new filter",
+		logger.String("pipeline_id", pipelineID),
+		logger.String("name", req.Name),
+	)
+
+	if err := s.ensurePipeline(ctx, pipelineID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = filter.ModeNocode
+		if req.FilterType == filter.FilterTypeJavascript || req.FilterType == filter.FilterTypeWasm {
+			mode = filter.ModeCode
+		}
+	}
+	executionOrder := req.ExecutionOrder
+	if executionOrder == 0 {
+		executionOrder = 1
+	}
+
+	newFilter := &filter.Filter{
+		PipelineID:     pipelineID,
+		Name:           req.Name,
+		Description:    req.Description,
+		FilterType:     req.FilterType,
+		Mode:           mode,
+		Code:           req.Code,
+		IsActive:       true,
+		ExecutionOrder: executionOrder,
+	}
+
+	config, err := validateAndMarshalConfig(newFilter, req.Config)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("building config: %w", err)
+	}
+	newFilter.Config = config
+
+	if err := s.ensureModule(ctx, newFilter); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.filterRepo.Create(ctx, newFilter); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to create filter", logger.Error(err))
+		return nil, fmt.Errorf("creating filter: %w", err)
+	}
+
+	return newFilter, nil
+}
+
+// validateAndMarshalConfig checks the mode and config of each filter type,
+// then builds the evaluator once so that patterns, expressions and scripts
+// failing to compile are reported on the config or code field.
+func validateAndMarshalConfig(f *filter.Filter, cfg map[string]any) (string, error) {
+	errors := map[string]string{}
+
+	if cfg == nil {
+		cfg = map[string]any{}
+	}
+
+	switch f.FilterType {
+	case filter.FilterTypeCondition:
+		if f.Mode != filter.ModeNocode {
+			errors["mode"] = "condition filters only support nocode mode"
+			break
+		}
+		conditionConfig, err := filter.ConditionConfigFromMap(cfg)
+		if err != nil {
+			errors["config"] = fmt.Sprintf("invalid condition config format: %v", err)
+		} else if err := conditionConfig.Validate(); err != nil {
+			errors["config"] = fmt.Sprintf("condition config validation failed: %v", err)
+		}
+	case filter.FilterTypeJSONPath:
+		if f.Mode != filter.ModeNocode {
+			errors["mode"] = "jsonpath filters only support nocode mode"
+			break
+		}
+		jsonPathConfig, err := filter.JSONPathConfigFromMap(cfg)
+		if err != nil {
+			errors["config"] = fmt.Sprintf("invalid jsonpath config format: %v", err)
+		} else if err := jsonPathConfig.Validate(); err != nil {
+			errors["config.expression"] = err.Error()
+		}
+	case filter.FilterTypeRegex:
+		if f.Mode != filter.ModeNocode {
+			errors["mode"] = "regex filters only support nocode mode"
+			break
+		}
+		regexConfig, err := filter.RegexConfigFromMap(cfg)
+		if err != nil {
+			errors["config"] = fmt.Sprintf("invalid regex config format: %v", err)
+		} else if err := regexConfig.Validate(); err != nil {
+			errors["config"] = fmt.Sprintf("regex config validation failed: %v", err)
+		}
+	case filter.FilterTypeJavascript:
+		if f.Mode != filter.ModeCode {
+			errors["mode"] = "javascript filters only support code mode"
+		}
+		if strings.TrimSpace(f.Code) == "" {
+			errors["code"] = "code is required for javascript filters"
+		}
+	case filter.FilterTypeWasm:
+		if f.Mode != filter.ModeCode {
+			errors["mode"] = "wasm filters only support code mode"
+			break
+		}
+		wasmConfig, err := filter.WasmConfigFromMap(cfg)
+		if err != nil {
+			errors["config"] = fmt.Sprintf("invalid wasm config format: %v", err)
+		} else if err := wasmConfig.Validate(); err != nil {
+			errors["config.module"] = err.Error()
+		}
+	default:
+		return "", &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"filter_type": "unsupported filter_type",
+		}}
+	}
+
+	if len(errors) > 0 {
+		return "", &validatorpkg.ValidationErrors{Errors: errors}
+	}
+
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("marshal config: %w", err)
+	}
+
+	f.Config = string(b)
+	if _, err := evaluator.New(f, evaluator.Options{}); err != nil {
+		field := "config"
+		if f.Mode == filter.ModeCode {
+			field = "code"
+		}
+		return "", &validatorpkg.ValidationErrors{Errors: map[string]string{
+			field: err.Error(),
+		}}
+	}
+
+	return string(b), nil
+}
+
+func (s filterService) GetByID(ctx context.Context, pipelineID, id string) (*filter.Filter, error) {
+	ctx, span := tracer.StartSpan(ctx, "filter.service.get_by_id")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Fetching filter by ID", logger.String("filter_id", id))
+
+	filterData, err := s.filterRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting filter by ID: %w", err)
+	}
+	if filterData == nil || filterData.PipelineID != pipelineID {
+		return nil, filter.ErrFilterNotFound
+	}
+
+	return filterData, nil
+}
+
+func (s filterService) List(ctx context.Context, pipelineID string) ([]*filter.Filter, error) {
+	ctx, span := tracer.StartSpan(ctx, "filter.service.list")
+	defer span.End()
+
+	if err := s.ensurePipeline(ctx, pipelineID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	filters, err := s.filterRepo.ListByPipeline(ctx, pipelineID)
+	if err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to list filters", logger.Error(err))
+		return nil, fmt.Errorf("failed to list filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+func (s filterService) Update(ctx context.Context, pipelineID, id string, req filter.UpdateRequest) (*filter.Filter, error) {
+	ctx, span := tracer.StartSpan(ctx, "filter.service.update")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Updating filter", logger.String("filter_id", id))
+
+	existing, err := s.GetByID(ctx, pipelineID, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Name) != "" {
+		existing.Name = req.Name
+	}
+	if req.Description != "" {
+		existing.Description = req.Description
+	}
+	if req.ExecutionOrder != 0 {
+		existing.ExecutionOrder = req.ExecutionOrder
+	}
+	if req.IsActive != nil {
+		existing.IsActive = *req.IsActive
+	}
+
+	definitionChanged := req.FilterType != "" || req.Mode != "" || req.Config != nil || req.Code != ""
+	if req.FilterType != "" {
+		existing.FilterType = req.FilterType
+	}
+	if req.Mode != "" {
+		existing.Mode = req.Mode
+	}
+	if req.Code != "" {
+		existing.Code = req.Code
+	}
+
+	if definitionChanged {
+		cfg := req.Config
+		if cfg == nil {
+			if err := json.Unmarshal([]byte(existing.Config), &cfg); err != nil {
+				return nil, fmt.Errorf("unmarshal existing config: %w", err)
+			}
+		}
+		config, err := validateAndMarshalConfig(existing, cfg)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("building config: %w", err)
+		}
+		existing.Config = config
+
+		if err := s.ensureModule(ctx, existing); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	if err := s.filterRepo.Update(ctx, existing); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to update filter", logger.Error(err))
+		return nil, fmt.Errorf("updating filter: %w", err)
+	}
+	s.evaluators.Invalidate(id)
+
+	return existing, nil
+}
+
+func (s filterService) Delete(ctx context.Context, pipelineID, id string) error {
+	ctx, span := tracer.StartSpan(ctx, "filter.service.delete")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Deleting filter", logger.String("filter_id", id))
+
+	if _, err := s.GetByID(ctx, pipelineID, id); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := s.filterRepo.Delete(ctx, id); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to delete filter", logger.Error(err))
+		return fmt.Errorf("deleting filter: %w", err)
+	}
+	s.evaluators.Invalidate(id)
+
+	return nil
+}
+
+// ensureModule checks that the module of a wasm filter was uploaded and
+// exports the filter entry point.
+func (s filterService) ensureModule(ctx context.Context, f *filter.Filter) error {
+	if f.FilterType != filter.FilterTypeWasm {
+		return nil
+	}
+
+	var config filter.WasmConfig
+	if err := json.Unmarshal([]byte(f.Config), &config); err != nil {
+		return fmt.Errorf("unmarshal wasm config: %w", err)
+	}
+
+	module, err := s.modules.Load(ctx, config.Module)
+	switch {
+	case errors.Is(err, wasm.ErrModuleNotFound):
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"config.module": "module not found",
+		}}
+	case err != nil:
+		return fmt.Errorf("loading wasm module: %w", err)
+	case !module.HasEntry(evaluator.WasmEntry):
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"config.module": fmt.Sprintf("module must export %s(ptr i32, len i32) i64", evaluator.WasmEntry),
+		}}
+	}
+	return nil
+}
+
+func (s filterService) ensurePipeline(ctx context.Context, pipelineID string) error {
+	existing, err := s.pipelineRepo.GetByID(ctx, pipelineID)
+	if err != nil {
+		return fmt.Errorf("getting pipeline by ID: %w", err)
+	}
+	if existing == nil {
+		return pipeline.ErrPipelineNotFound
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/internal/platform/http/middleware"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+// Handler holds the filter-specific dependencies
+type Handler struct {
+	filterService filter.Service
+	validator     *validatorpkg.Validator
+}
+
+// NewHandler creates a new filter handler
+func NewHandler(filterService filter.Service, validator *validatorpkg.Validator) *Handler {
+	return &Handler{
+		filterService: filterService,
+		validator:     validator,
+	}
+}
+
+func (h *Handler) CreateFilter(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "filter.handler.create")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	if pipelineID == "" {
+		return response.BadRequest(c, "pipeline_id is required", nil)
+	}
+
+	var req filter.CreateRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	created, err := h.filterService.Create(ctx, pipelineID, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, pipeline.ErrPipelineNotFound):
+			return response.NotFound(c, "pipeline not found")
+		default:
+			return response.InternalError(c, "failed to create filter")
+		}
+	}
+
+	resp, err := created.ToResponse()
+	if err != nil {
+		return response.InternalError(c, "failed to serialize filter")
+	}
+
+	return response.Success(c, resp, "filter created")
+}
+
+func (h *Handler) GetFilter(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "filter.handler.get")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	filterID := c.Params("filterId")
+	if pipelineID == "" || filterID == "" {
+		return response.BadRequest(c, "pipeline_id and filter_id are required", nil)
+	}
+
+	found, err := h.filterService.GetByID(ctx, pipelineID, filterID)
+	if err != nil {
+		if errors.Is(err, filter.ErrFilterNotFound) {
+			return response.NotFound(c, "filter not found")
+		}
+		return response.InternalError(c, "failed to get filter")
+	}
+
+	resp, err := found.ToResponse()
+	if err != nil {
+		return response.InternalError(c, "failed to serialize filter")
+	}
+	return response.Success(c, resp, "filter")
+}
+
+func (h *Handler) ListFilters(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "filter.handler.list")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	if pipelineID == "" {
+		return response.BadRequest(c, "pipeline_id is required", nil)
+	}
+
+	filters, err := h.filterService.List(ctx, pipelineID)
+	if err != nil {
+		if errors.Is(err, pipeline.ErrPipelineNotFound) {
+			return response.NotFound(c, "pipeline not found")
+		}
+		return response.InternalError(c, "failed to list filters")
+	}
+
+	resp, err := filter.ToResponses(filters)
+	if err != nil {
+		return response.InternalError(c, "failed to serialize filters")
+	}
+
+	return response.Success(c, resp, "filters listed")
+}
+
+func (h *Handler) UpdateFilter(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "filter.handler.update")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	filterID := c.Params("filterId")
+	if pipelineID == "" || filterID == "" {
+		return response.BadRequest(c, "pipeline_id and filter_id are required", nil)
+	}
+
+	var req filter.UpdateRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	updated, err := h.filterService.Update(ctx, pipelineID, filterID, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, filter.ErrFilterNotFound):
+			return response.NotFound(c, "filter not found")
+		default:
+			return response.InternalError(c, "failed to update filter")
+		}
+	}
+
+	resp, err := updated.ToResponse()
+	if err != nil {
+		return response.InternalError(c, "failed to serialize filter")
+	}
+
+	return response.Success(c, resp, "filter updated")
+}
+
+func (h *Handler) DeleteFilter(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "filter.handler.delete")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	filterID := c.Params("filterId")
+	if pipelineID == "" || filterID == "" {
+		return response.BadRequest(c, "pipeline_id and filter_id are required", nil)
+	}
+
+	if err := h.filterService.Delete(ctx, pipelineID, filterID); err != nil {
+		switch {
+		case errors.Is(err, filter.ErrFilterNotFound):
+			return response.NotFound(c, "filter not found")
+		default:
+			return response.InternalError(c, "failed to delete filter")
+		}
+	}
+
+	return response.Success(c, nil, "filter deleted")
+}
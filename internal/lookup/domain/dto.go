@@ -0,0 +1,112 @@
+package lookup
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/theotruvelot/catchook/pkg/response"
+)
+
+type UploadFormat string
+
+const (
+	UploadFormatCSV  UploadFormat = "csv"
+	UploadFormatJSON UploadFormat = "json"
+)
+
+type CreateRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description" validate:"omitempty,max=255"`
+	TTLSeconds  *int32 `json:"ttl_seconds" validate:"omitempty,min=1"`
+}
+
+// UpdateRequest changes the fields that are set. A ttl_seconds of 0 removes
+// the default TTL; entries already written keep their expiry.
+type UpdateRequest struct {
+	Name        string  `json:"name" validate:"omitempty,min=2,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+	TTLSeconds  *int32  `json:"ttl_seconds" validate:"omitempty,min=0"`
+}
+
+type ListEntriesRequest struct {
+	Page  int `query:"page" validate:"omitempty,min=1"`
+	Limit int `query:"limit" validate:"omitempty,min=1,max=1000"`
+}
+
+// PutEntryRequest writes the value of a key. TTLSeconds overrides the
+// default TTL of the table.
+type PutEntryRequest struct {
+	Value      json.RawMessage `json:"value" validate:"required"`
+	TTLSeconds *int32          `json:"ttl_seconds" validate:"omitempty,min=1"`
+}
+
+// UploadRequest holds the form fields sent along an uploaded file. Format
+// is guessed from the file name when empty. Replace removes the existing
+// entries first; otherwise uploaded keys are added or overwritten.
+type UploadRequest struct {
+	Format     UploadFormat `form:"format" validate:"omitempty,oneof=csv json"`
+	Replace    bool         `form:"replace"`
+	TTLSeconds *int32       `form:"ttl_seconds" validate:"omitempty,min=1"`
+}
+
+type UploadResponse struct {
+	Imported int  `json:"imported"`
+	Replaced bool `json:"replaced"`
+}
+
+type TableResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	TTLSeconds  *int32    `json:"ttl_seconds"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type EntryResponse struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ListEntriesResponse struct {
+	Entries    []*EntryResponse     `json:"data"`
+	Pagination *response.Pagination `json:"pagination"`
+}
+
+func (t *Table) ToResponse() *TableResponse {
+	return &TableResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		TTLSeconds:  t.TTLSeconds,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+func ToResponses(list []*Table) []*TableResponse {
+	resp := make([]*TableResponse, 0, len(list))
+	for _, item := range list {
+		resp = append(resp, item.ToResponse())
+	}
+	return resp
+}
+
+func (e *Entry) ToResponse() *EntryResponse {
+	return &EntryResponse{
+		Key:       e.Key,
+		Value:     e.Value,
+		ExpiresAt: e.ExpiresAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+func ToEntryResponses(list []*Entry) []*EntryResponse {
+	resp := make([]*EntryResponse, 0, len(list))
+	for _, item := range list {
+		resp = append(resp, item.ToResponse())
+	}
+	return resp
+}
@@ -0,0 +1,55 @@
+package lookup
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	MaxEntries     = 100000
+	MaxKeyLength   = 512
+	MaxValueLength = 64 * 1024
+)
+
+// Table is a named key/value table used by enrich transformations.
+// TTLSeconds, when set, is the lifetime of entries written without their
+// own TTL.
+type Table struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	TTLSeconds  *int32    `json:"ttl_seconds"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Entry maps a key to a JSON value. An entry past ExpiresAt is treated as
+// missing.
+type Entry struct {
+	TableID   string          `json:"table_id"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt *time.Time      `json:"expires_at"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Expired reports whether the entry is past its expiry at the given time.
+func (e *Entry) Expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// ExpiresAt returns the expiry of an entry written now with the given TTL,
+// falling back to the TTL of the table. It returns nil when neither is set.
+func ExpiresAt(now time.Time, ttlSeconds, tableTTLSeconds *int32) *time.Time {
+	ttl := ttlSeconds
+	if ttl == nil {
+		ttl = tableTTLSeconds
+	}
+	if ttl == nil || *ttl <= 0 {
+		return nil
+	}
+	expiresAt := now.Add(time.Duration(*ttl) * time.Second)
+	return &expiresAt
+}
@@ -0,0 +1,10 @@
+package lookup
+
+import "errors"
+
+var (
+	ErrTableNotFound      = errors.New("lookup table not found")
+	ErrTableAlreadyExists = errors.New("lookup table already exists")
+	ErrEntryNotFound      = errors.New("lookup entry not found")
+	ErrTooManyEntries     = errors.New("lookup table has too many entries")
+)
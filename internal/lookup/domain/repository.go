@@ -0,0 +1,23 @@
+package lookup
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, table *Table) error
+	GetByID(ctx context.Context, userID, id string) (*Table, error)
+	GetByName(ctx context.Context, userID, name string) (*Table, error)
+	ListByUser(ctx context.Context, userID string) ([]*Table, error)
+	Update(ctx context.Context, table *Table) error
+	Delete(ctx context.Context, id string) error
+
+	// PutEntries writes the entries in a single transaction, after removing
+	// the existing ones when replace is set. It fails with
+	// ErrTooManyEntries, writing nothing, when the table would end up with
+	// more than maxEntries live entries.
+	PutEntries(ctx context.Context, tableID string, entries []*Entry, replace bool, maxEntries int) error
+	GetEntry(ctx context.Context, tableID, key string) (*Entry, error)
+	ListEntries(ctx context.Context, tableID string, limit, offset int) ([]*Entry, int64, error)
+	// ListAllEntries returns every live entry of a table, whoever owns it.
+	ListAllEntries(ctx context.Context, tableID string) ([]*Entry, error)
+	DeleteEntry(ctx context.Context, tableID, key string) (bool, error)
+}
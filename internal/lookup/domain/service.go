@@ -0,0 +1,30 @@
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/theotruvelot/catchook/pkg/response"
+)
+
+type Service interface {
+	Create(ctx context.Context, req CreateRequest) (*Table, error)
+	GetByID(ctx context.Context, id string) (*Table, error)
+	List(ctx context.Context) ([]*Table, error)
+	Update(ctx context.Context, id string, req UpdateRequest) (*Table, error)
+	Delete(ctx context.Context, id string) error
+
+	ListEntries(ctx context.Context, id string, req ListEntriesRequest) ([]*Entry, *response.Pagination, error)
+	GetEntry(ctx context.Context, id, key string) (*Entry, error)
+	PutEntry(ctx context.Context, id, key string, req PutEntryRequest) (*Entry, error)
+	DeleteEntry(ctx context.Context, id, key string) error
+	// Upload imports entries from a CSV or JSON document. The file name is
+	// used to guess the format when the request does not give it.
+	Upload(ctx context.Context, id string, req UploadRequest, filename string, content []byte) (*UploadResponse, error)
+}
+
+// Resolver resolves keys for enrich transformations. The engine calls it
+// without a user in the context, so tables are resolved by ID only.
+type Resolver interface {
+	Lookup(ctx context.Context, tableID, key string) (json.RawMessage, bool, error)
+}
@@ -0,0 +1,347 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type lookupRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewLookupRepository(db *pgxpool.Pool, appLogger logger.Logger) lookup.Repository {
+	return &lookupRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r lookupRepository) Create(ctx context.Context, t *lookup.Table) error {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.create")
+	defer span.End()
+
+	userID, err := uuid.Parse(t.UserID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	result, err := r.queries.CreateLookupTable(ctx, userID, t.Name, toText(t.Description), toInt4(t.TTLSeconds))
+	if err != nil {
+		r.appLogger.Error(ctx, "Failed to create lookup table",
+			logger.String("name", t.Name),
+			logger.Error(err),
+		)
+		span.RecordError(err)
+		return fmt.Errorf("failed to create lookup table: %w", err)
+	}
+
+	*t = *toTable(result)
+	return nil
+}
+
+func (r lookupRepository) GetByID(ctx context.Context, userID, id string) (*lookup.Table, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.get_by_id")
+	defer span.End()
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	tid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	result, err := r.queries.GetLookupTableByID(ctx, tid, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get lookup table by ID: %w", err)
+	}
+	return toTable(result), nil
+}
+
+func (r lookupRepository) GetByName(ctx context.Context, userID, name string) (*lookup.Table, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.get_by_name")
+	defer span.End()
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	result, err := r.queries.GetLookupTableByName(ctx, uid, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get lookup table by name: %w", err)
+	}
+	return toTable(result), nil
+}
+
+func (r lookupRepository) ListByUser(ctx context.Context, userID string) ([]*lookup.Table, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.list_by_user")
+	defer span.End()
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	results, err := r.queries.ListLookupTablesByUser(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list lookup tables: %w", err)
+	}
+
+	tables := make([]*lookup.Table, len(results))
+	for i, result := range results {
+		tables[i] = toTable(result)
+	}
+	return tables, nil
+}
+
+func (r lookupRepository) Update(ctx context.Context, t *lookup.Table) error {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.update")
+	defer span.End()
+
+	id, err := uuid.Parse(t.ID)
+	if err != nil {
+		return fmt.Errorf("invalid lookup table id: %w", err)
+	}
+
+	result, err := r.queries.UpdateLookupTable(ctx, id, t.Name, toText(t.Description), toInt4(t.TTLSeconds))
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to update lookup table", logger.Error(err))
+		return fmt.Errorf("failed to update lookup table: %w", err)
+	}
+
+	*t = *toTable(result)
+	return nil
+}
+
+func (r lookupRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.delete")
+	defer span.End()
+
+	tid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid lookup table id: %w", err)
+	}
+
+	if err := r.queries.DeleteLookupTable(ctx, tid); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete lookup table: %w", err)
+	}
+	return nil
+}
+
+func (r lookupRepository) PutEntries(ctx context.Context, tableID string, entries []*lookup.Entry, replace bool, maxEntries int) error {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.put_entries")
+	defer span.End()
+
+	tid, err := uuid.Parse(tableID)
+	if err != nil {
+		return fmt.Errorf("invalid lookup table id: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	if replace {
+		if err := qtx.DeleteLookupEntriesByTable(ctx, tid); err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("failed to clear lookup entries: %w", err)
+		}
+	}
+
+	for _, e := range entries {
+		result, err := qtx.UpsertLookupEntry(ctx, tid, e.Key, e.Value, toTimestamptz(e.ExpiresAt))
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("failed to write lookup entry: %w", err)
+		}
+		*e = *toEntry(result)
+	}
+
+	count, err := qtx.CountLookupEntries(ctx, tid)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to count lookup entries: %w", err)
+	}
+	if count > int64(maxEntries) {
+		return fmt.Errorf("%w: the limit is %d", lookup.ErrTooManyEntries, maxEntries)
+	}
+
+	if err := qtx.TouchLookupTable(ctx, tid); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to touch lookup table: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to commit lookup entries", logger.Error(err))
+		return fmt.Errorf("failed to commit lookup entries: %w", err)
+	}
+	return nil
+}
+
+func (r lookupRepository) GetEntry(ctx context.Context, tableID, key string) (*lookup.Entry, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.get_entry")
+	defer span.End()
+
+	tid, err := uuid.Parse(tableID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lookup table id: %w", err)
+	}
+
+	result, err := r.queries.GetLookupEntry(ctx, tid, key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get lookup entry: %w", err)
+	}
+	return toEntry(result), nil
+}
+
+func (r lookupRepository) ListEntries(ctx context.Context, tableID string, limit, offset int) ([]*lookup.Entry, int64, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.list_entries")
+	defer span.End()
+
+	tid, err := uuid.Parse(tableID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid lookup table id: %w", err)
+	}
+
+	results, err := r.queries.ListLookupEntries(ctx, tid, int32(limit), int32(offset))
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, fmt.Errorf("failed to list lookup entries: %w", err)
+	}
+
+	total, err := r.queries.CountLookupEntries(ctx, tid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, fmt.Errorf("failed to count lookup entries: %w", err)
+	}
+
+	return toEntries(results), total, nil
+}
+
+func (r lookupRepository) ListAllEntries(ctx context.Context, tableID string) ([]*lookup.Entry, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.list_all_entries")
+	defer span.End()
+
+	tid, err := uuid.Parse(tableID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lookup table id: %w", err)
+	}
+
+	results, err := r.queries.ListAllLookupEntries(ctx, tid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list lookup entries: %w", err)
+	}
+	return toEntries(results), nil
+}
+
+func (r lookupRepository) DeleteEntry(ctx context.Context, tableID, key string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.repository.delete_entry")
+	defer span.End()
+
+	tid, err := uuid.Parse(tableID)
+	if err != nil {
+		return false, fmt.Errorf("invalid lookup table id: %w", err)
+	}
+
+	rows, err := r.queries.DeleteLookupEntry(ctx, tid, key)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to delete lookup entry: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func toTable(result generated.LookupTable) *lookup.Table {
+	t := &lookup.Table{
+		ID:          result.ID.String(),
+		UserID:      result.UserID.String(),
+		Name:        result.Name,
+		Description: result.Description.String,
+		CreatedAt:   result.CreatedAt.Time,
+		UpdatedAt:   result.UpdatedAt.Time,
+	}
+	if result.TtlSeconds.Valid {
+		ttl := result.TtlSeconds.Int32
+		t.TTLSeconds = &ttl
+	}
+	return t
+}
+
+func toEntry(result generated.LookupEntry) *lookup.Entry {
+	e := &lookup.Entry{
+		TableID:   result.TableID.String(),
+		Key:       result.Key,
+		Value:     result.Value,
+		CreatedAt: result.CreatedAt.Time,
+		UpdatedAt: result.UpdatedAt.Time,
+	}
+	if result.ExpiresAt.Valid {
+		expiresAt := result.ExpiresAt.Time
+		e.ExpiresAt = &expiresAt
+	}
+	return e
+}
+
+func toEntries(results []generated.LookupEntry) []*lookup.Entry {
+	entries := make([]*lookup.Entry, len(results))
+	for i, result := range results {
+		entries[i] = toEntry(result)
+	}
+	return entries
+}
+
+func toText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func toInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func toTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
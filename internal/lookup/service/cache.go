@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+// InvalidationChannel is the Redis channel on which the ID of a modified
+// table is published, so that every instance drops its copy.
+const InvalidationChannel = "lookup:invalidate"
+
+// DefaultCacheMaxAge bounds how long a table stays cached. Redis pub/sub
+// does not redeliver messages missed while disconnected, so tables are
+// reloaded periodically even without invalidation.
+const DefaultCacheMaxAge = 5 * time.Minute
+
+// Cache keeps whole lookup tables in memory so that enrich transformations
+// need no database round trip per event. It implements lookup.Resolver.
+type Cache struct {
+	repo      lookup.Repository
+	redis     *redis.Client
+	maxAge    time.Duration
+	appLogger logger.Logger
+
+	mu     sync.RWMutex
+	tables map[string]*cachedTable
+	// generations counts the invalidations of each table, so that a load
+	// racing with an invalidation does not store stale entries.
+	generations map[string]uint64
+
+	pubsub *redis.PubSub
+}
+
+type cachedTable struct {
+	entries  map[string]*lookup.Entry
+	loadedAt time.Time
+}
+
+func NewCache(repo lookup.Repository, rdb *redis.Client, maxAge time.Duration, appLogger logger.Logger) *Cache {
+	if maxAge <= 0 {
+		maxAge = DefaultCacheMaxAge
+	}
+	return &Cache{
+		repo:        repo,
+		redis:       rdb,
+		maxAge:      maxAge,
+		appLogger:   appLogger,
+		tables:      map[string]*cachedTable{},
+		generations: map[string]uint64{},
+	}
+}
+
+// Lookup returns the value of key, loading the table on first use.
+func (c *Cache) Lookup(ctx context.Context, tableID, key string) (json.RawMessage, bool, error) {
+	table, err := c.table(ctx, tableID)
+	if err != nil {
+		return nil, false, err
+	}
+	entry, ok := table.entries[key]
+	if !ok || entry.Expired(time.Now()) {
+		return nil, false, nil
+	}
+	return entry.Value, true, nil
+}
+
+func (c *Cache) table(ctx context.Context, tableID string) (*cachedTable, error) {
+	c.mu.RLock()
+	table, ok := c.tables[tableID]
+	generation := c.generations[tableID]
+	c.mu.RUnlock()
+	if ok && time.Since(table.loadedAt) < c.maxAge {
+		return table, nil
+	}
+
+	ctx, span := tracer.StartSpan(ctx, "lookup.cache.load")
+	defer span.End()
+
+	entries, err := c.repo.ListAllEntries(ctx, tableID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("loading lookup table %s: %w", tableID, err)
+	}
+
+	table = &cachedTable{entries: make(map[string]*lookup.Entry, len(entries)), loadedAt: time.Now()}
+	for _, e := range entries {
+		table.entries[e.Key] = e
+	}
+
+	c.mu.Lock()
+	if c.generations[tableID] == generation {
+		c.tables[tableID] = table
+	}
+	c.mu.Unlock()
+	return table, nil
+}
+
+// Invalidate drops the table locally and asks the other instances to do
+// the same. A failure to publish is logged: the other instances catch up
+// when their copy reaches its maximum age.
+func (c *Cache) Invalidate(ctx context.Context, tableID string) {
+	c.drop(tableID)
+	if c.redis == nil {
+		return
+	}
+	if err := c.redis.Publish(ctx, InvalidationChannel, tableID).Err(); err != nil {
+		c.appLogger.Error(ctx, "Failed to publish lookup table invalidation",
+			logger.String("lookup_table_id", tableID),
+			logger.Error(err),
+		)
+	}
+}
+
+func (c *Cache) drop(tableID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tables, tableID)
+	c.generations[tableID]++
+}
+
+// Listen subscribes to the invalidations published by every instance,
+// this one included, until Close is called.
+func (c *Cache) Listen(ctx context.Context) error {
+	if c.redis == nil {
+		return nil
+	}
+	pubsub := c.redis.Subscribe(ctx, InvalidationChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return fmt.Errorf("subscribing to %s: %w", InvalidationChannel, err)
+	}
+	c.pubsub = pubsub
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			c.drop(msg.Payload)
+		}
+	}()
+	return nil
+}
+
+func (c *Cache) Close() error {
+	if c.pubsub == nil {
+		return nil
+	}
+	return c.pubsub.Close()
+}
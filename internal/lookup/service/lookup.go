@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+	"github.com/theotruvelot/catchook/internal/platform/auth"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+const defaultEntriesLimit = 50
+
+type lookupService struct {
+	lookupRepo lookup.Repository
+	cache      *Cache
+	appLogger  logger.Logger
+}
+
+func NewLookupService(lookupRepo lookup.Repository, cache *Cache, appLogger logger.Logger) lookup.Service {
+	return &lookupService{
+		lookupRepo: lookupRepo,
+		cache:      cache,
+		appLogger:  appLogger,
+	}
+}
+
+func (s lookupService) Create(ctx context.Context, req lookup.CreateRequest) (*lookup.Table, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.create")
+	defer span.End()
+
+	currentUserID, err := auth.GetUserID(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting current user ID: %w", err)
+	}
+
+	s.appLogger.Info(ctx, "Creating lookup table", logger.String("name", req.Name))
+
+	existing, err := s.lookupRepo.GetByName(ctx, currentUserID, req.Name)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("checking existing lookup table by name: %w", err)
+	}
+	if existing != nil {
+		return nil, lookup.ErrTableAlreadyExists
+	}
+
+	table := &lookup.Table{
+		UserID:      currentUserID,
+		Name:        req.Name,
+		Description: req.Description,
+		TTLSeconds:  req.TTLSeconds,
+	}
+	if err := s.lookupRepo.Create(ctx, table); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to create lookup table", logger.Error(err))
+		return nil, fmt.Errorf("creating lookup table: %w", err)
+	}
+
+	return table, nil
+}
+
+func (s lookupService) GetByID(ctx context.Context, id string) (*lookup.Table, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.get_by_id")
+	defer span.End()
+
+	table, err := s.getTable(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return table, nil
+}
+
+func (s lookupService) List(ctx context.Context) ([]*lookup.Table, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.list")
+	defer span.End()
+
+	currentUserID, err := auth.GetUserID(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting current user ID: %w", err)
+	}
+
+	tables, err := s.lookupRepo.ListByUser(ctx, currentUserID)
+	if err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to list lookup tables", logger.Error(err))
+		return nil, fmt.Errorf("listing lookup tables: %w", err)
+	}
+	return tables, nil
+}
+
+func (s lookupService) Update(ctx context.Context, id string, req lookup.UpdateRequest) (*lookup.Table, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.update")
+	defer span.End()
+
+	table, err := s.getTable(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if req.Name != "" && req.Name != table.Name {
+		existing, err := s.lookupRepo.GetByName(ctx, table.UserID, req.Name)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("checking existing lookup table by name: %w", err)
+		}
+		if existing != nil {
+			return nil, lookup.ErrTableAlreadyExists
+		}
+		table.Name = req.Name
+	}
+	if req.Description != nil {
+		table.Description = *req.Description
+	}
+	if req.TTLSeconds != nil {
+		table.TTLSeconds = req.TTLSeconds
+		if *req.TTLSeconds == 0 {
+			table.TTLSeconds = nil
+		}
+	}
+
+	if err := s.lookupRepo.Update(ctx, table); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("updating lookup table: %w", err)
+	}
+	return table, nil
+}
+
+// Delete removes the table and its entries. Enrich transformations still
+// referencing it treat every key as missing.
+func (s lookupService) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.delete")
+	defer span.End()
+
+	table, err := s.getTable(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	s.appLogger.Info(ctx, "Deleting lookup table", logger.String("lookup_table_id", table.ID))
+
+	if err := s.lookupRepo.Delete(ctx, table.ID); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to delete lookup table", logger.Error(err))
+		return fmt.Errorf("deleting lookup table: %w", err)
+	}
+	s.cache.Invalidate(ctx, table.ID)
+	return nil
+}
+
+func (s lookupService) ListEntries(ctx context.Context, id string, req lookup.ListEntriesRequest) ([]*lookup.Entry, *response.Pagination, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.list_entries")
+	defer span.End()
+
+	table, err := s.getTable(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = defaultEntriesLimit
+	}
+
+	entries, total, err := s.lookupRepo.ListEntries(ctx, table.ID, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("listing lookup entries: %w", err)
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	pagination := &response.Pagination{
+		CurrentPage: req.Page,
+		TotalPages:  totalPages,
+		Total:       int(total),
+		Limit:       req.Limit,
+		HasNext:     req.Page < totalPages,
+		HasPrev:     req.Page > 1,
+	}
+	return entries, pagination, nil
+}
+
+func (s lookupService) GetEntry(ctx context.Context, id, key string) (*lookup.Entry, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.get_entry")
+	defer span.End()
+
+	table, err := s.getTable(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	entry, err := s.lookupRepo.GetEntry(ctx, table.ID, key)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting lookup entry: %w", err)
+	}
+	if entry == nil {
+		return nil, lookup.ErrEntryNotFound
+	}
+	return entry, nil
+}
+
+func (s lookupService) PutEntry(ctx context.Context, id, key string, req lookup.PutEntryRequest) (*lookup.Entry, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.put_entry")
+	defer span.End()
+
+	table, err := s.getTable(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := validateEntry(key, req.Value); err != nil {
+		return nil, &validatorpkg.ValidationErrors{Errors: map[string]string{"value": err.Error()}}
+	}
+
+	entry := &lookup.Entry{
+		Key:       key,
+		Value:     req.Value,
+		ExpiresAt: lookup.ExpiresAt(time.Now(), req.TTLSeconds, table.TTLSeconds),
+	}
+	if err := s.lookupRepo.PutEntries(ctx, table.ID, []*lookup.Entry{entry}, false, lookup.MaxEntries); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("writing lookup entry: %w", err)
+	}
+	s.cache.Invalidate(ctx, table.ID)
+	return entry, nil
+}
+
+func (s lookupService) DeleteEntry(ctx context.Context, id, key string) error {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.delete_entry")
+	defer span.End()
+
+	table, err := s.getTable(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.lookupRepo.DeleteEntry(ctx, table.ID, key)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("deleting lookup entry: %w", err)
+	}
+	if !deleted {
+		return lookup.ErrEntryNotFound
+	}
+	s.cache.Invalidate(ctx, table.ID)
+	return nil
+}
+
+// Upload imports a whole document in one transaction: either every entry
+// is written or none is.
+func (s lookupService) Upload(ctx context.Context, id string, req lookup.UploadRequest, filename string, content []byte) (*lookup.UploadResponse, error) {
+	ctx, span := tracer.StartSpan(ctx, "lookup.service.upload")
+	defer span.End()
+
+	table, err := s.getTable(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	format := req.Format
+	if format == "" {
+		format = detectFormat(filename, content)
+	}
+	entries, err := parseUpload(format, content)
+	if err != nil {
+		return nil, &validatorpkg.ValidationErrors{Errors: map[string]string{"file": err.Error()}}
+	}
+
+	expiresAt := lookup.ExpiresAt(time.Now(), req.TTLSeconds, table.TTLSeconds)
+	for _, e := range entries {
+		e.ExpiresAt = expiresAt
+	}
+
+	s.appLogger.Info(ctx, "Uploading lookup entries",
+		logger.String("lookup_table_id", table.ID),
+		logger.String("format", string(format)),
+		logger.Int("entries", len(entries)),
+		logger.Any("replace", req.Replace),
+	)
+
+	if err := s.lookupRepo.PutEntries(ctx, table.ID, entries, req.Replace, lookup.MaxEntries); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("importing lookup entries: %w", err)
+	}
+	s.cache.Invalidate(ctx, table.ID)
+
+	return &lookup.UploadResponse{Imported: len(entries), Replaced: req.Replace}, nil
+}
+
+func (s lookupService) getTable(ctx context.Context, id string) (*lookup.Table, error) {
+	currentUserID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting current user ID: %w", err)
+	}
+
+	table, err := s.lookupRepo.GetByID(ctx, currentUserID, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting lookup table by ID: %w", err)
+	}
+	if table == nil {
+		return nil, lookup.ErrTableNotFound
+	}
+	return table, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+)
+
+// detectFormat guesses the format of an uploaded file from its name, then
+// from its first non-blank character.
+func detectFormat(filename string, content []byte) lookup.UploadFormat {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return lookup.UploadFormatCSV
+	case ".json":
+		return lookup.UploadFormatJSON
+	}
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return lookup.UploadFormatJSON
+	}
+	return lookup.UploadFormatCSV
+}
+
+// parseUpload reads the entries of an uploaded document.
+//
+// A CSV document starts with a header row. The key is read from the "key"
+// column, or the first column when there is none. The value is the "value"
+// column when there is one, the single other column when there are two
+// columns, and otherwise an object of the other columns named after the
+// header:
+//
+//	customer_id,email,owner
+//	cus_123,ada@example.com,Ada
+//
+// A JSON document is either an object mapping keys to values or an array
+// of {"key": ..., "value": ...} objects.
+func parseUpload(format lookup.UploadFormat, content []byte) ([]*lookup.Entry, error) {
+	var entries []*lookup.Entry
+	var err error
+	switch format {
+	case lookup.UploadFormatCSV:
+		entries, err = parseCSV(content)
+	case lookup.UploadFormatJSON:
+		entries, err = parseJSON(content)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("the file holds no entries")
+	}
+	if len(entries) > lookup.MaxEntries {
+		return nil, fmt.Errorf("the file holds more than %d entries", lookup.MaxEntries)
+	}
+
+	seen := make(map[string]int, len(entries))
+	for i, e := range entries {
+		if err := validateEntry(e.Key, e.Value); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		if first, ok := seen[e.Key]; ok {
+			return nil, fmt.Errorf("entry %d: key %q already appears in entry %d", i+1, e.Key, first+1)
+		}
+		seen[e.Key] = i
+	}
+	return entries, nil
+}
+
+func parseCSV(content []byte) ([]*lookup.Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(header) < 2 {
+		return nil, fmt.Errorf("the CSV header must have at least a key and a value column")
+	}
+
+	keyColumn, valueColumn := 0, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "key":
+			keyColumn = i
+		case "value":
+			valueColumn = i
+		}
+	}
+	if valueColumn < 0 && len(header) == 2 {
+		valueColumn = 1 - keyColumn
+	}
+
+	var entries []*lookup.Entry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		var value any
+		if valueColumn >= 0 {
+			value = record[valueColumn]
+		} else {
+			fields := make(map[string]string, len(header)-1)
+			for i, name := range header {
+				if i != keyColumn {
+					fields[strings.TrimSpace(name)] = record[i]
+				}
+			}
+			value = fields
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding value: %w", err)
+		}
+		entries = append(entries, &lookup.Entry{Key: record[keyColumn], Value: encoded})
+	}
+	return entries, nil
+}
+
+func parseJSON(content []byte) ([]*lookup.Entry, error) {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []struct {
+			Key   *string         `json:"key"`
+			Value json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		entries := make([]*lookup.Entry, 0, len(items))
+		for i, item := range items {
+			if item.Key == nil {
+				return nil, fmt.Errorf("entry %d: key is required", i+1)
+			}
+			if item.Value == nil {
+				return nil, fmt.Errorf("entry %d: value is required", i+1)
+			}
+			entries = append(entries, &lookup.Entry{Key: *item.Key, Value: item.Value})
+		}
+		return entries, nil
+	}
+
+	// Decoding through a token stream keeps the order of the document, so
+	// errors point at the same entry as the user sees.
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("invalid JSON: the document must be an object or an array")
+	}
+	var entries []*lookup.Entry
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		entries = append(entries, &lookup.Entry{Key: tok.(string), Value: value})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return entries, nil
+}
+
+func validateEntry(key string, value json.RawMessage) error {
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if len(key) > lookup.MaxKeyLength {
+		return fmt.Errorf("key exceeds %d bytes", lookup.MaxKeyLength)
+	}
+	if len(value) > lookup.MaxValueLength {
+		return fmt.Errorf("value exceeds %d bytes", lookup.MaxValueLength)
+	}
+	if !json.Valid(value) {
+		return fmt.Errorf("value is not valid JSON")
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+	"github.com/theotruvelot/catchook/internal/platform/http/middleware"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+// Handler holds the lookup table dependencies
+type Handler struct {
+	lookupService lookup.Service
+	validator     *validatorpkg.Validator
+}
+
+// NewHandler creates a new lookup table handler
+func NewHandler(lookupService lookup.Service, validator *validatorpkg.Validator) *Handler {
+	return &Handler{
+		lookupService: lookupService,
+		validator:     validator,
+	}
+}
+
+func (h *Handler) CreateTable(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.create")
+	defer span.End()
+
+	var req lookup.CreateRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	table, err := h.lookupService.Create(ctx, req)
+	if err != nil {
+		if errors.Is(err, lookup.ErrTableAlreadyExists) {
+			return response.Conflict(c, "lookup table already exists")
+		}
+		return response.InternalError(c, "failed to create lookup table")
+	}
+
+	return response.Success(c, table.ToResponse(), "lookup table created")
+}
+
+func (h *Handler) GetTable(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.get")
+	defer span.End()
+
+	tableID := c.Params("id")
+	if tableID == "" {
+		return response.BadRequest(c, "lookup_table_id is required", nil)
+	}
+
+	table, err := h.lookupService.GetByID(ctx, tableID)
+	if err != nil {
+		if errors.Is(err, lookup.ErrTableNotFound) {
+			return response.NotFound(c, "lookup table not found")
+		}
+		return response.InternalError(c, "failed to get lookup table")
+	}
+
+	return response.Success(c, table.ToResponse(), "lookup table")
+}
+
+func (h *Handler) ListTables(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.list")
+	defer span.End()
+
+	tables, err := h.lookupService.List(ctx)
+	if err != nil {
+		return response.InternalError(c, "failed to list lookup tables")
+	}
+
+	return response.Success(c, lookup.ToResponses(tables), "lookup tables listed")
+}
+
+func (h *Handler) UpdateTable(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.update")
+	defer span.End()
+
+	tableID := c.Params("id")
+	if tableID == "" {
+		return response.BadRequest(c, "lookup_table_id is required", nil)
+	}
+
+	var req lookup.UpdateRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	table, err := h.lookupService.Update(ctx, tableID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, lookup.ErrTableNotFound):
+			return response.NotFound(c, "lookup table not found")
+		case errors.Is(err, lookup.ErrTableAlreadyExists):
+			return response.Conflict(c, "lookup table already exists")
+		default:
+			return response.InternalError(c, "failed to update lookup table")
+		}
+	}
+
+	return response.Success(c, table.ToResponse(), "lookup table updated")
+}
+
+func (h *Handler) DeleteTable(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.delete")
+	defer span.End()
+
+	tableID := c.Params("id")
+	if tableID == "" {
+		return response.BadRequest(c, "lookup_table_id is required", nil)
+	}
+
+	if err := h.lookupService.Delete(ctx, tableID); err != nil {
+		if errors.Is(err, lookup.ErrTableNotFound) {
+			return response.NotFound(c, "lookup table not found")
+		}
+		return response.InternalError(c, "failed to delete lookup table")
+	}
+
+	return response.Success(c, nil, "lookup table deleted")
+}
+
+func (h *Handler) ListEntries(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.list_entries")
+	defer span.End()
+
+	tableID := c.Params("id")
+	if tableID == "" {
+		return response.BadRequest(c, "lookup_table_id is required", nil)
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 {
+		limit = 50
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	req := lookup.ListEntriesRequest{Page: page, Limit: limit}
+
+	entries, pagination, err := h.lookupService.ListEntries(ctx, tableID, req)
+	if err != nil {
+		if errors.Is(err, lookup.ErrTableNotFound) {
+			return response.NotFound(c, "lookup table not found")
+		}
+		return response.InternalError(c, "failed to list lookup entries")
+	}
+
+	return response.Success(c, &lookup.ListEntriesResponse{
+		Entries:    lookup.ToEntryResponses(entries),
+		Pagination: pagination,
+	}, "lookup entries listed")
+}
+
+func (h *Handler) GetEntry(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.get_entry")
+	defer span.End()
+
+	tableID, key, err := entryParams(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	entry, err := h.lookupService.GetEntry(ctx, tableID, key)
+	if err != nil {
+		switch {
+		case errors.Is(err, lookup.ErrTableNotFound):
+			return response.NotFound(c, "lookup table not found")
+		case errors.Is(err, lookup.ErrEntryNotFound):
+			return response.NotFound(c, "lookup entry not found")
+		default:
+			return response.InternalError(c, "failed to get lookup entry")
+		}
+	}
+
+	return response.Success(c, entry.ToResponse(), "lookup entry")
+}
+
+func (h *Handler) PutEntry(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.put_entry")
+	defer span.End()
+
+	tableID, key, err := entryParams(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	var req lookup.PutEntryRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	entry, err := h.lookupService.PutEntry(ctx, tableID, key, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, lookup.ErrTableNotFound):
+			return response.NotFound(c, "lookup table not found")
+		case errors.Is(err, lookup.ErrTooManyEntries):
+			return response.BadRequest(c, err.Error(), nil)
+		default:
+			return response.InternalError(c, "failed to write lookup entry")
+		}
+	}
+
+	return response.Success(c, entry.ToResponse(), "lookup entry written")
+}
+
+func (h *Handler) DeleteEntry(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.delete_entry")
+	defer span.End()
+
+	tableID, key, err := entryParams(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	if err := h.lookupService.DeleteEntry(ctx, tableID, key); err != nil {
+		switch {
+		case errors.Is(err, lookup.ErrTableNotFound):
+			return response.NotFound(c, "lookup table not found")
+		case errors.Is(err, lookup.ErrEntryNotFound):
+			return response.NotFound(c, "lookup entry not found")
+		default:
+			return response.InternalError(c, "failed to delete lookup entry")
+		}
+	}
+
+	return response.Success(c, nil, "lookup entry deleted")
+}
+
+// UploadEntries expects a multipart form with the document in a file field,
+// along with the optional format, replace and ttl_seconds fields.
+func (h *Handler) UploadEntries(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "lookup.handler.upload")
+	defer span.End()
+
+	tableID := c.Params("id")
+	if tableID == "" {
+		return response.BadRequest(c, "lookup_table_id is required", nil)
+	}
+
+	var req lookup.UploadRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return response.ValidationFailed(c, map[string]string{"file": "file is required"})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return response.BadRequest(c, "failed to read file", nil)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return response.BadRequest(c, "failed to read file", nil)
+	}
+
+	result, err := h.lookupService.Upload(ctx, tableID, req, fileHeader.Filename, content)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, lookup.ErrTableNotFound):
+			return response.NotFound(c, "lookup table not found")
+		case errors.Is(err, lookup.ErrTooManyEntries):
+			return response.BadRequest(c, err.Error(), nil)
+		default:
+			return response.InternalError(c, "failed to upload lookup entries")
+		}
+	}
+
+	return response.Success(c, result, "lookup entries uploaded")
+}
+
+// entryParams reads the table ID and the key, which may hold any character
+// and is therefore sent percent-encoded.
+func entryParams(c *fiber.Ctx) (string, string, error) {
+	tableID := c.Params("id")
+	if tableID == "" {
+		return "", "", errors.New("lookup_table_id is required")
+	}
+	key, err := url.PathUnescape(c.Params("key"))
+	if err != nil || key == "" {
+		return "", "", errors.New("a valid key is required")
+	}
+	return tableID, key, nil
+}
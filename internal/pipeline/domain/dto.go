@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// ResendRequest edits an event and sends the result as a new event. Headers
+// replace the inbound headers of the event; when omitted, they are kept.
+type ResendRequest struct {
+	Payload       json.RawMessage   `json:"payload" validate:"required"`
+	Headers       map[string]string `json:"headers"`
+	Mode          string            `json:"mode" validate:"required,oneof=pipeline destination"`
+	DestinationID string            `json:"destination_id" validate:"required_if=Mode destination,omitempty,uuid"`
+	Note          string            `json:"note" validate:"max=1000"`
+}
+
+type ResendResponse struct {
+	Event *EventResponse `json:"event"`
+	Edit  *Edit          `json:"edit"`
+}
+
+type EventResponse struct {
+	ID            string            `json:"id"`
+	SourceID      string            `json:"source_id"`
+	PipelineID    string            `json:"pipeline_id,omitempty"`
+	OrderingKey   string            `json:"ordering_key,omitempty"`
+	ReplayJobID   string            `json:"replay_job_id,omitempty"`
+	DerivedFromID string            `json:"derived_from_id,omitempty"`
+	Status        string            `json:"status"`
+	ErrorMessage  string            `json:"error_message,omitempty"`
+	Method        string            `json:"method,omitempty"`
+	Path          string            `json:"path,omitempty"`
+	Headers       map[string]string `json:"headers"`
+	// Payload is the current payload, a JSON string when it is not JSON.
+	Payload     json.RawMessage `json:"payload"`
+	ContentType string          `json:"content_type"`
+	ScheduledAt *time.Time      `json:"scheduled_at,omitempty"`
+	ProcessedAt *time.Time      `json:"processed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func (e *Event) ToResponse() *EventResponse {
+	payload := json.RawMessage(e.Payload)
+	if !e.IsJSON() {
+		payload, _ = json.Marshal(string(e.Payload))
+	} else if len(bytes.TrimSpace(payload)) == 0 {
+		payload = json.RawMessage("null")
+	}
+
+	return &EventResponse{
+		ID:            e.ID,
+		SourceID:      e.SourceID,
+		PipelineID:    e.PipelineID,
+		OrderingKey:   e.OrderingKey,
+		ReplayJobID:   e.ReplayJobID,
+		DerivedFromID: e.DerivedFromID,
+		Status:        string(e.Status),
+		ErrorMessage:  e.ErrorMessage,
+		Method:        e.Method,
+		Path:          e.Path,
+		Headers:       e.Headers,
+		Payload:       payload,
+		ContentType:   e.PayloadContentType(),
+		ScheduledAt:   e.ScheduledAt,
+		ProcessedAt:   e.ProcessedAt,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+	}
+}
@@ -0,0 +1,362 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Pipeline struct {
+	ID             string `json:"id"`
+	UserID         string `json:"user_id"`
+	SourceID       string `json:"source_id"`
+	DestinationID  string `json:"destination_id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	IsActive       bool   `json:"is_active"`
+	ExecutionOrder int32  `json:"execution_order"`
+	// OrderingKey delivers the events sharing its value in ingestion order,
+	// over the ordering key of the destination.
+	OrderingKey string    `json:"ordering_key"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Status string
+
+const (
+	StatusPending     Status = "pending"
+	StatusFiltered    Status = "filtered"
+	StatusTransformed Status = "transformed"
+	StatusDelayed     Status = "delayed"
+	StatusDelivered   Status = "delivered"
+	StatusFailed      Status = "failed"
+	StatusCancelled   Status = "cancelled"
+)
+
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeXML  = "application/xml"
+)
+
+// Event is a webhook event as seen by the pipeline engine. The request
+// details (method, path, headers and query) are persisted inside the
+// metadata column and surfaced here as dedicated fields.
+type Event struct {
+	ID              string            `json:"id"`
+	SourceID        string            `json:"source_id"`
+	PipelineID      string            `json:"pipeline_id"`
+	Method          string            `json:"method"`
+	Path            string            `json:"path"`
+	Headers         map[string]string `json:"headers"`
+	Query           map[string]string `json:"query"`
+	Payload         []byte            `json:"payload"`
+	OriginalPayload []byte            `json:"original_payload"`
+	// ContentType is the media type of Payload once a transformation
+	// converted it to another format, empty while it is JSON.
+	ContentType string         `json:"content_type,omitempty"`
+	Metadata    map[string]any `json:"metadata"`
+	// OutboundHeaders are the headers left by the transformation stage. They
+	// are nil until the event has been transformed.
+	OutboundHeaders map[string]string `json:"outbound_headers,omitempty"`
+	// Captures collects the named groups captured by filters while the
+	// event runs through its pipeline.
+	Captures map[string]string `json:"captures,omitempty"`
+	// OrderingKey is the resolved ordering key of the event, empty when its
+	// deliveries are not ordered.
+	OrderingKey string `json:"ordering_key,omitempty"`
+	// ReplayJobID is the replay that last re-ran the event, if any. The
+	// deliveries dispatched meanwhile are linked to it.
+	ReplayJobID string `json:"replay_job_id,omitempty"`
+	// DerivedFromID is the event this one was edited from, if any.
+	DerivedFromID string     `json:"derived_from_id,omitempty"`
+	Status        Status     `json:"status"`
+	ErrorMessage  string     `json:"error_message"`
+	ScheduledAt   *time.Time `json:"scheduled_at"`
+	ProcessedAt   *time.Time `json:"processed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Header returns the value of the named header, ignoring case.
+func (e *Event) Header(name string) (string, bool) {
+	if v, ok := e.Headers[name]; ok {
+		return v, true
+	}
+	for k, v := range e.Headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// hopHeaders are tied to the inbound connection or recomputed by the HTTP
+// client, so they are never forwarded.
+var hopHeaders = map[string]bool{
+	"connection":          true,
+	"content-length":      true,
+	"host":                true,
+	"keep-alive":          true,
+	"proxy-authenticate":  true,
+	"proxy-authorization": true,
+	"proxy-connection":    true,
+	"te":                  true,
+	"trailer":             true,
+	"transfer-encoding":   true,
+	"upgrade":             true,
+	"accept-encoding":     true,
+}
+
+// DeliveryHeaders returns the headers to forward to the destination: the
+// outbound headers once the event has been transformed, the inbound headers
+// otherwise, without the hop-by-hop headers.
+func (e *Event) DeliveryHeaders() map[string]string {
+	source := e.Headers
+	if e.OutboundHeaders != nil {
+		source = e.OutboundHeaders
+	}
+	headers := make(map[string]string, len(source))
+	for k, v := range source {
+		if !hopHeaders[strings.ToLower(k)] {
+			headers[k] = v
+		}
+	}
+	return headers
+}
+
+// IsJSON reports whether the payload is JSON.
+func (e *Event) IsJSON() bool {
+	return e.ContentType == "" || e.ContentType == ContentTypeJSON
+}
+
+// PayloadContentType returns the media type of the payload.
+func (e *Event) PayloadContentType() string {
+	if e.IsJSON() {
+		return ContentTypeJSON
+	}
+	return e.ContentType
+}
+
+// Body decodes the current payload. Numbers are kept as json.Number so that
+// re-encoding the body does not lose precision.
+func (e *Event) Body() (any, error) {
+	if !e.IsJSON() {
+		return nil, fmt.Errorf("the payload is %s, not JSON", e.ContentType)
+	}
+	return DecodeJSON(e.Payload)
+}
+
+// ScriptEvent is the JSON view of an event handed to user code (JavaScript
+// and WebAssembly). User code returns the same shape; only body and headers
+// are read back.
+type ScriptEvent struct {
+	ID         string            `json:"id"`
+	SourceID   string            `json:"source_id"`
+	PipelineID string            `json:"pipeline_id"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Headers    map[string]string `json:"headers"`
+	Query      map[string]string `json:"query"`
+	Metadata   map[string]any    `json:"metadata"`
+	Captures   map[string]string `json:"captures"`
+	Body       json.RawMessage   `json:"body"`
+}
+
+// ScriptEvent returns the view of the event handed to user code. A payload
+// that is not JSON is passed as a string.
+func (e *Event) ScriptEvent() *ScriptEvent {
+	body := json.RawMessage(e.Payload)
+	if !e.IsJSON() {
+		body, _ = json.Marshal(string(e.Payload))
+	} else if len(bytes.TrimSpace(body)) == 0 {
+		body = json.RawMessage("null")
+	}
+	return &ScriptEvent{
+		ID:         e.ID,
+		SourceID:   e.SourceID,
+		PipelineID: e.PipelineID,
+		Method:     e.Method,
+		Path:       e.Path,
+		Headers:    e.Headers,
+		Query:      e.Query,
+		Metadata:   e.Metadata,
+		Captures:   e.Captures,
+		Body:       body,
+	}
+}
+
+// ApplyScriptOutput updates the event from the JSON returned by user code.
+// The output must be an object with a body, which makes the payload JSON
+// again; headers are replaced when present.
+func (e *Event) ApplyScriptOutput(output []byte) error {
+	var out struct {
+		Headers map[string]string `json:"headers"`
+		Body    json.RawMessage   `json:"body"`
+	}
+	if len(output) == 0 || output[0] != '{' {
+		return fmt.Errorf("the returned value must be an event object")
+	}
+	if err := json.Unmarshal(output, &out); err != nil {
+		return fmt.Errorf("invalid returned event: %w", err)
+	}
+	if len(out.Body) == 0 {
+		return fmt.Errorf("the returned event has no body")
+	}
+
+	e.Payload = out.Body
+	e.ContentType = ""
+	if out.Headers != nil {
+		e.Headers = out.Headers
+	}
+	return nil
+}
+
+func DecodeJSON(data []byte) (any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// EditMode tells where an event derived by hand from another one is sent.
+type EditMode string
+
+const (
+	// EditModePipeline runs the derived event through the pipeline of the
+	// original event.
+	EditModePipeline EditMode = "pipeline"
+	// EditModeDestination delivers the derived event as is to a chosen
+	// destination.
+	EditModeDestination EditMode = "destination"
+)
+
+// EditChanges describes how the derived event differs from the original
+// one: whether the payload changed and which inbound headers were added,
+// changed or removed.
+type EditChanges struct {
+	Payload        bool              `json:"payload"`
+	HeadersAdded   map[string]string `json:"headers_added,omitempty"`
+	HeadersChanged map[string]string `json:"headers_changed,omitempty"`
+	HeadersRemoved []string          `json:"headers_removed,omitempty"`
+}
+
+// Edit is the audit record of an event derived by hand from another one.
+type Edit struct {
+	ID              string      `json:"id"`
+	OriginalEventID string      `json:"original_event_id"`
+	DerivedEventID  string      `json:"derived_event_id"`
+	UserID          string      `json:"user_id,omitempty"`
+	Mode            EditMode    `json:"mode"`
+	DestinationID   string      `json:"destination_id,omitempty"`
+	Changes         EditChanges `json:"changes"`
+	Note            string      `json:"note,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// Derive returns a pending copy of the event carrying the given payload and
+// inbound headers, along with the changes it makes. A nil headers map keeps
+// the headers of the event. The copy keeps the request details, the source
+// and the pipeline of the event.
+func (e *Event) Derive(payload []byte, headers map[string]string) (*Event, EditChanges) {
+	if headers == nil {
+		headers = e.Headers
+	}
+
+	derived := &Event{
+		SourceID:      e.SourceID,
+		PipelineID:    e.PipelineID,
+		Method:        e.Method,
+		Path:          e.Path,
+		Headers:       headers,
+		Query:         e.Query,
+		Payload:       payload,
+		Metadata:      e.Metadata,
+		DerivedFromID: e.ID,
+		Status:        StatusPending,
+	}
+
+	// The payload is compared with the payload as received.
+	received := e.OriginalPayload
+	if received == nil {
+		received = e.Payload
+	}
+	changes := EditChanges{Payload: !jsonEqual(received, payload)}
+	for k, v := range headers {
+		old, ok := e.Headers[k]
+		switch {
+		case !ok:
+			if changes.HeadersAdded == nil {
+				changes.HeadersAdded = map[string]string{}
+			}
+			changes.HeadersAdded[k] = v
+		case old != v:
+			if changes.HeadersChanged == nil {
+				changes.HeadersChanged = map[string]string{}
+			}
+			changes.HeadersChanged[k] = v
+		}
+	}
+	for k := range e.Headers {
+		if _, ok := headers[k]; !ok {
+			changes.HeadersRemoved = append(changes.HeadersRemoved, k)
+		}
+	}
+	sort.Strings(changes.HeadersRemoved)
+
+	return derived, changes
+}
+
+func jsonEqual(a, b []byte) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+type StepType string
+
+const (
+	StepTypeAuth           StepType = "auth"
+	StepTypeFilter         StepType = "filter"
+	StepTypeTransformation StepType = "transformation"
+	StepTypeDelivery       StepType = "delivery"
+	StepTypeReplay         StepType = "replay"
+)
+
+type StepStatus string
+
+const (
+	StepStatusPending StepStatus = "pending"
+	StepStatusSuccess StepStatus = "success"
+	StepStatusFailed  StepStatus = "failed"
+	StepStatusSkipped StepStatus = "skipped"
+)
+
+// Step is one entry of the execution trace stored in webhook_steps.
+type Step struct {
+	ID             string     `json:"id"`
+	EventID        string     `json:"webhook_event_id"`
+	PipelineID     string     `json:"pipeline_id"`
+	Type           StepType   `json:"step_type"`
+	Name           string     `json:"step_name"`
+	RefID          string     `json:"step_id"`
+	ExecutionOrder int32      `json:"execution_order"`
+	Status         StepStatus `json:"status"`
+	InputData      any        `json:"input_data"`
+	OutputData     any        `json:"output_data"`
+	ErrorMessage   string     `json:"error_message"`
+	StartedAt      time.Time  `json:"started_at"`
+	CompletedAt    time.Time  `json:"completed_at"`
+}
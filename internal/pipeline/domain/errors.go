@@ -0,0 +1,11 @@
+package pipeline
+
+import "errors"
+
+var (
+	ErrPipelineNotFound      = errors.New("pipeline not found")
+	ErrEventNotFound         = errors.New("webhook event not found")
+	ErrEventWithoutPipeline  = errors.New("webhook event is not attached to a pipeline")
+	ErrEventAlreadyProcessed = errors.New("webhook event already processed")
+	ErrEventNotDelayed       = errors.New("webhook event is not delayed")
+)
@@ -0,0 +1,58 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	GetByID(ctx context.Context, id string) (*Pipeline, error)
+}
+
+type EventRepository interface {
+	GetByID(ctx context.Context, id string) (*Event, error)
+	UpdateStatus(ctx context.Context, id string, status Status, errorMessage string) error
+	UpdateFilterResults(ctx context.Context, id string, results []byte) error
+	UpdateTransformationResults(ctx context.Context, event *Event, results []byte) error
+	CreateStep(ctx context.Context, step *Step) error
+	// NextStepOrder returns the execution order following the last step
+	// recorded for the event.
+	NextStepOrder(ctx context.Context, eventID string) (int32, error)
+	// Schedule marks the event as delayed until the given time.
+	Schedule(ctx context.Context, id string, at time.Time) error
+	// ClaimDelayed returns up to limit delayed events that are due and hides
+	// them from other callers for the duration of the lease.
+	ClaimDelayed(ctx context.Context, limit int, lease time.Duration) ([]*Event, error)
+	// StartDelayed moves a delayed event to transformed before its delivery.
+	// It reports false when the event is no longer delayed.
+	StartDelayed(ctx context.Context, id string) (bool, error)
+	// SetOrderingKey stores the resolved ordering key of the event.
+	SetOrderingKey(ctx context.Context, id, key string) error
+	// OrderingPredecessor reports whether an event ingested before the
+	// given one shares its ordering key and is not settled yet, and when
+	// the earliest of them is next due, if known.
+	OrderingPredecessor(ctx context.Context, id string) (bool, *time.Time, error)
+	// CancelDelayed cancels a delayed event. It reports false when the
+	// event is not delayed.
+	CancelDelayed(ctx context.Context, id string) (bool, error)
+	// Requeue moves a failed event back to transformed ahead of a new
+	// delivery. It reports false when the event is not failed.
+	Requeue(ctx context.Context, id string) (bool, error)
+	// ResetForReplay puts a settled event back in the pending state with
+	// its original payload and tags it with the replay job. It reports
+	// false when the event is not settled.
+	ResetForReplay(ctx context.Context, id, jobID string) (bool, error)
+	// ResetForRedelivery puts a delivered or failed event back in the
+	// transformed state and tags it with the replay job. It reports false
+	// when the event is neither delivered nor failed.
+	ResetForRedelivery(ctx context.Context, id, jobID string) (bool, error)
+	// CreateDerived stores an event derived from another one along with the
+	// record of the edit, which it links to the new event.
+	CreateDerived(ctx context.Context, event *Event, edit *Edit) error
+	// ListEdits returns the edits the event was derived from or gave rise
+	// to, oldest first.
+	ListEdits(ctx context.Context, eventID string) ([]*Edit, error)
+	// ListSteps returns the steps of one type recorded for the event, in
+	// execution order.
+	ListSteps(ctx context.Context, eventID string, stepType StepType) ([]*Step, error)
+}
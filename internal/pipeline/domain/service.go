@@ -0,0 +1,25 @@
+package pipeline
+
+import (
+	"context"
+)
+
+// Engine runs a webhook event through the filters and transformations of
+// its pipeline, then delivers it to the pipeline destination.
+type Engine interface {
+	Process(ctx context.Context, eventID string) (*Event, error)
+}
+
+// EventService exposes webhook events through the API.
+type EventService interface {
+	GetByID(ctx context.Context, id string) (*Event, error)
+	// Cancel cancels an event waiting for a delayed delivery.
+	Cancel(ctx context.Context, id string) (*Event, error)
+	// Resend derives a new event from an edited copy of the event and sends
+	// it through the pipeline or to a destination. The event itself is left
+	// untouched and the edit is recorded.
+	Resend(ctx context.Context, userID, id string, req ResendRequest) (*Event, *Edit, error)
+	// ListEdits returns the edits the event was derived from or gave rise
+	// to.
+	ListEdits(ctx context.Context, id string) ([]*Edit, error)
+}
@@ -0,0 +1,741 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+// Keys of the metadata column that hold the inbound request details.
+const (
+	metadataMethod  = "method"
+	metadataPath    = "path"
+	metadataHeaders = "headers"
+	metadataQuery   = "query"
+	// metadataOutboundHeaders holds the headers left by the transformation
+	// stage, which are the ones forwarded to the destination.
+	metadataOutboundHeaders = "outbound_headers"
+	// metadataContentType holds the media type of a payload converted from
+	// JSON. Such payloads are stored as a JSON string in the payload column.
+	metadataContentType = "content_type"
+)
+
+type eventRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewEventRepository(db *pgxpool.Pool, appLogger logger.Logger) pipeline.EventRepository {
+	return &eventRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r eventRepository) GetByID(ctx context.Context, id string) (*pipeline.Event, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.get_by_id")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	result, err := r.queries.GetWebhookEventByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to get webhook event by ID", logger.Error(err))
+		return nil, fmt.Errorf("failed to get webhook event by ID: %w", err)
+	}
+
+	return toEvent(result)
+}
+
+func (r eventRepository) UpdateStatus(ctx context.Context, id string, status pipeline.Status, errorMessage string) error {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.update_status")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	if _, err := r.queries.UpdateWebhookEventStatus(ctx, uid, generated.WebhookStatus(status), toText(errorMessage)); err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to update webhook event status",
+			logger.String("webhook_event_id", id),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to update webhook event status: %w", err)
+	}
+	return nil
+}
+
+func (r eventRepository) UpdateFilterResults(ctx context.Context, id string, results []byte) error {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.update_filter_results")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	if err := r.queries.UpdateWebhookEventFilterResults(ctx, uid, results); err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to update webhook event filter results",
+			logger.String("webhook_event_id", id),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to update webhook event filter results: %w", err)
+	}
+	return nil
+}
+
+// UpdateTransformationResults stores the transformed payload and results.
+// The outbound headers and the content type of the payload are saved in the
+// metadata unless the outbound headers are nil.
+func (r eventRepository) UpdateTransformationResults(ctx context.Context, event *pipeline.Event, results []byte) error {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.update_transformation_results")
+	defer span.End()
+
+	uid, err := uuid.Parse(event.ID)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	payload, err := encodePayload(event.Payload, event.IsJSON())
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	var headers []byte
+	if event.OutboundHeaders != nil {
+		if headers, err = json.Marshal(event.OutboundHeaders); err != nil {
+			return fmt.Errorf("encode outbound headers: %w", err)
+		}
+	}
+
+	contentType := ""
+	if !event.IsJSON() {
+		contentType = event.ContentType
+	}
+
+	if err := r.queries.UpdateWebhookEventTransformationResults(ctx, uid, payload, results, headers, contentType); err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to update webhook event transformation results",
+			logger.String("webhook_event_id", event.ID),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to update webhook event transformation results: %w", err)
+	}
+	return nil
+}
+
+func (r eventRepository) CreateStep(ctx context.Context, step *pipeline.Step) error {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.create_step")
+	defer span.End()
+
+	eventID, err := uuid.Parse(step.EventID)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	pipelineID, err := toNullUUID(step.PipelineID)
+	if err != nil {
+		return fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+
+	refID, err := toNullUUID(step.RefID)
+	if err != nil {
+		return fmt.Errorf("invalid step ID format: %w", err)
+	}
+
+	input, err := marshalStepData(step.InputData)
+	if err != nil {
+		return fmt.Errorf("marshal step input: %w", err)
+	}
+
+	output, err := marshalStepData(step.OutputData)
+	if err != nil {
+		return fmt.Errorf("marshal step output: %w", err)
+	}
+
+	var completedAt pgtype.Timestamptz
+	var duration pgtype.Int4
+	if !step.CompletedAt.IsZero() {
+		completedAt = pgtype.Timestamptz{Time: step.CompletedAt, Valid: true}
+		duration = pgtype.Int4{Int32: int32(step.CompletedAt.Sub(step.StartedAt) / time.Millisecond), Valid: true}
+	}
+
+	result, err := r.queries.CreateWebhookStep(ctx,
+		eventID,
+		pipelineID,
+		generated.StepType(step.Type),
+		step.Name,
+		refID,
+		step.ExecutionOrder,
+		generated.StepStatus(step.Status),
+		input,
+		output,
+		toText(step.ErrorMessage),
+		duration,
+		pgtype.Timestamptz{Time: step.StartedAt, Valid: !step.StartedAt.IsZero()},
+		completedAt,
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to create webhook step",
+			logger.String("webhook_event_id", step.EventID),
+			logger.String("step_name", step.Name),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to create webhook step: %w", err)
+	}
+
+	step.ID = result.ID.String()
+	return nil
+}
+
+func (r eventRepository) NextStepOrder(ctx context.Context, eventID string) (int32, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.next_step_order")
+	defer span.End()
+
+	uid, err := uuid.Parse(eventID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	order, err := r.queries.GetNextWebhookStepOrder(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to get next webhook step order: %w", err)
+	}
+	return order, nil
+}
+
+func (r eventRepository) SetOrderingKey(ctx context.Context, id, key string) error {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.set_ordering_key")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	if err := r.queries.SetWebhookEventOrderingKey(ctx, uid, toText(key)); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to set webhook event ordering key: %w", err)
+	}
+	return nil
+}
+
+func (r eventRepository) OrderingPredecessor(ctx context.Context, id string) (bool, *time.Time, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.ordering_predecessor")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, nil, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	result, err := r.queries.GetWebhookEventOrderingPredecessor(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil, nil
+		}
+		span.RecordError(err)
+		return false, nil, fmt.Errorf("failed to get webhook event ordering predecessor: %w", err)
+	}
+	if !result.DueAt.Valid {
+		return true, nil, nil
+	}
+	return true, &result.DueAt.Time, nil
+}
+
+func (r eventRepository) Schedule(ctx context.Context, id string, at time.Time) error {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.schedule")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	if err := r.queries.ScheduleWebhookEvent(ctx, uid, pgtype.Timestamptz{Time: at, Valid: true}); err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to schedule webhook event",
+			logger.String("webhook_event_id", id),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to schedule webhook event: %w", err)
+	}
+	return nil
+}
+
+func (r eventRepository) ClaimDelayed(ctx context.Context, limit int, lease time.Duration) ([]*pipeline.Event, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.claim_delayed")
+	defer span.End()
+
+	results, err := r.queries.ClaimDueWebhookEvents(ctx, int32(limit), lease.Seconds())
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to claim delayed webhook events: %w", err)
+	}
+
+	events := make([]*pipeline.Event, 0, len(results))
+	for _, result := range results {
+		event, err := toEvent(result)
+		if err != nil {
+			r.appLogger.Error(ctx, "Failed to decode delayed webhook event",
+				logger.String("webhook_event_id", result.ID.String()),
+				logger.Error(err),
+			)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (r eventRepository) StartDelayed(ctx context.Context, id string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.start_delayed")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	rows, err := r.queries.StartDelayedWebhookEvent(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to start delayed webhook event: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r eventRepository) CancelDelayed(ctx context.Context, id string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.cancel_delayed")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	rows, err := r.queries.CancelDelayedWebhookEvent(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to cancel delayed webhook event: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r eventRepository) Requeue(ctx context.Context, id string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.requeue")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	rows, err := r.queries.RequeueWebhookEvent(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to requeue webhook event: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r eventRepository) ResetForReplay(ctx context.Context, id, jobID string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.reset_for_replay")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+	replayJobID, err := toNullUUID(jobID)
+	if err != nil {
+		return false, fmt.Errorf("invalid replay job ID format: %w", err)
+	}
+
+	rows, err := r.queries.ResetWebhookEventForReplay(ctx, uid, replayJobID)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to reset webhook event for replay: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r eventRepository) ResetForRedelivery(ctx context.Context, id, jobID string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.reset_for_redelivery")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+	replayJobID, err := toNullUUID(jobID)
+	if err != nil {
+		return false, fmt.Errorf("invalid replay job ID format: %w", err)
+	}
+
+	rows, err := r.queries.ResetWebhookEventForRedelivery(ctx, uid, replayJobID)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to reset webhook event for redelivery: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r eventRepository) CreateDerived(ctx context.Context, event *pipeline.Event, edit *pipeline.Edit) error {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.create_derived")
+	defer span.End()
+
+	sourceID, err := uuid.Parse(event.SourceID)
+	if err != nil {
+		return fmt.Errorf("invalid source ID format: %w", err)
+	}
+	pipelineID, err := toNullUUID(event.PipelineID)
+	if err != nil {
+		return fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+	originalID, err := uuid.Parse(event.DerivedFromID)
+	if err != nil {
+		return fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+	userID, err := toNullUUID(edit.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+	destinationID, err := toNullUUID(edit.DestinationID)
+	if err != nil {
+		return fmt.Errorf("invalid destination ID format: %w", err)
+	}
+
+	metadata, err := encodeMetadata(event)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+	changes, err := json.Marshal(edit.Changes)
+	if err != nil {
+		return fmt.Errorf("encode changes: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	result, err := qtx.CreateDerivedWebhookEvent(ctx,
+		sourceID,
+		pipelineID,
+		event.Payload,
+		metadata,
+		generated.WebhookStatus(event.Status),
+		pgtype.UUID{Bytes: originalID, Valid: true},
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to create derived webhook event",
+			logger.String("webhook_event_id", event.DerivedFromID),
+			logger.Error(err),
+		)
+		return fmt.Errorf("failed to create derived webhook event: %w", err)
+	}
+
+	editResult, err := qtx.CreateEventEdit(ctx,
+		originalID,
+		result.ID,
+		userID,
+		generated.EventEditMode(edit.Mode),
+		destinationID,
+		changes,
+		edit.Note,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create event edit: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	created, err := toEvent(result)
+	if err != nil {
+		return err
+	}
+	*event = *created
+	createdEdit, err := toEdit(editResult)
+	if err != nil {
+		return err
+	}
+	*edit = *createdEdit
+	return nil
+}
+
+func (r eventRepository) ListEdits(ctx context.Context, eventID string) ([]*pipeline.Edit, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.list_edits")
+	defer span.End()
+
+	uid, err := uuid.Parse(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	results, err := r.queries.ListEventEditsByEvent(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list event edits: %w", err)
+	}
+
+	edits := make([]*pipeline.Edit, 0, len(results))
+	for _, result := range results {
+		edit, err := toEdit(result)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+	return edits, nil
+}
+
+func (r eventRepository) ListSteps(ctx context.Context, eventID string, stepType pipeline.StepType) ([]*pipeline.Step, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.event.list_steps")
+	defer span.End()
+
+	uid, err := uuid.Parse(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook event ID format: %w", err)
+	}
+
+	results, err := r.queries.ListWebhookStepsByEventAndType(ctx, uid, generated.StepType(stepType))
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list webhook steps: %w", err)
+	}
+
+	steps := make([]*pipeline.Step, 0, len(results))
+	for _, result := range results {
+		steps = append(steps, toStep(result))
+	}
+	return steps, nil
+}
+
+func toEvent(result generated.WebhookEvent) (*pipeline.Event, error) {
+	event := &pipeline.Event{
+		ID:              result.ID.String(),
+		SourceID:        result.SourceID.String(),
+		Payload:         result.Payload,
+		OriginalPayload: result.OriginalPayload,
+		OrderingKey:     result.OrderingKey.String,
+		Status:          pipeline.Status(result.Status),
+		ErrorMessage:    result.ErrorMessage.String,
+		CreatedAt:       result.CreatedAt.Time,
+		UpdatedAt:       result.UpdatedAt.Time,
+	}
+
+	if result.PipelineID.Valid {
+		event.PipelineID = uuid.UUID(result.PipelineID.Bytes).String()
+	}
+	if result.ReplayJobID.Valid {
+		event.ReplayJobID = uuid.UUID(result.ReplayJobID.Bytes).String()
+	}
+	if result.DerivedFromID.Valid {
+		event.DerivedFromID = uuid.UUID(result.DerivedFromID.Bytes).String()
+	}
+	if result.ScheduledAt.Valid {
+		event.ScheduledAt = &result.ScheduledAt.Time
+	}
+	if result.ProcessedAt.Valid {
+		event.ProcessedAt = &result.ProcessedAt.Time
+	}
+
+	if err := decodeMetadata(event, result.Metadata); err != nil {
+		return nil, fmt.Errorf("decode metadata: %w", err)
+	}
+	if !event.IsJSON() {
+		var payload string
+		if err := json.Unmarshal(result.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("decode %s payload: %w", event.ContentType, err)
+		}
+		event.Payload = []byte(payload)
+	}
+
+	return event, nil
+}
+
+func toEdit(result generated.EventEdit) (*pipeline.Edit, error) {
+	edit := &pipeline.Edit{
+		ID:              result.ID.String(),
+		OriginalEventID: result.OriginalEventID.String(),
+		DerivedEventID:  result.DerivedEventID.String(),
+		Mode:            pipeline.EditMode(result.Mode),
+		Note:            result.Note,
+		CreatedAt:       result.CreatedAt.Time,
+	}
+	if result.UserID.Valid {
+		edit.UserID = uuid.UUID(result.UserID.Bytes).String()
+	}
+	if result.DestinationID.Valid {
+		edit.DestinationID = uuid.UUID(result.DestinationID.Bytes).String()
+	}
+	if err := json.Unmarshal(result.Changes, &edit.Changes); err != nil {
+		return nil, fmt.Errorf("decode changes of event edit %s: %w", edit.ID, err)
+	}
+	return edit, nil
+}
+
+// toStep keeps the input and output of the step as raw JSON.
+func toStep(result generated.WebhookStep) *pipeline.Step {
+	step := &pipeline.Step{
+		ID:             result.ID.String(),
+		EventID:        result.WebhookEventID.String(),
+		Type:           pipeline.StepType(result.StepType),
+		Name:           result.StepName,
+		ExecutionOrder: result.ExecutionOrder,
+		Status:         pipeline.StepStatus(result.Status),
+		ErrorMessage:   result.ErrorMessage.String,
+		StartedAt:      result.StartedAt.Time,
+		CompletedAt:    result.CompletedAt.Time,
+	}
+	if result.PipelineID.Valid {
+		step.PipelineID = uuid.UUID(result.PipelineID.Bytes).String()
+	}
+	if result.StepID.Valid {
+		step.RefID = uuid.UUID(result.StepID.Bytes).String()
+	}
+	if len(result.InputData) > 0 {
+		step.InputData = json.RawMessage(result.InputData)
+	}
+	if len(result.OutputData) > 0 {
+		step.OutputData = json.RawMessage(result.OutputData)
+	}
+	return step
+}
+
+// encodePayload returns the value of the JSONB payload column: the payload
+// itself when it is JSON, a JSON string holding it otherwise.
+func encodePayload(payload []byte, isJSON bool) ([]byte, error) {
+	if isJSON {
+		return payload, nil
+	}
+	return json.Marshal(string(payload))
+}
+
+// encodeMetadata is the reverse of decodeMetadata. The content type is left
+// out: the payload of the events it is used for is JSON.
+func encodeMetadata(event *pipeline.Event) ([]byte, error) {
+	metadata := make(map[string]any, len(event.Metadata)+5)
+	for key, value := range event.Metadata {
+		metadata[key] = value
+	}
+	if event.Method != "" {
+		metadata[metadataMethod] = event.Method
+	}
+	if event.Path != "" {
+		metadata[metadataPath] = event.Path
+	}
+	metadata[metadataHeaders] = event.Headers
+	metadata[metadataQuery] = event.Query
+	if event.OutboundHeaders != nil {
+		metadata[metadataOutboundHeaders] = event.OutboundHeaders
+	}
+	return json.Marshal(metadata)
+}
+
+func decodeMetadata(event *pipeline.Event, raw []byte) error {
+	event.Headers = map[string]string{}
+	event.Query = map[string]string{}
+	event.Metadata = map[string]any{}
+
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var metadata map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return err
+	}
+
+	for key, value := range metadata {
+		var err error
+		switch key {
+		case metadataMethod:
+			err = json.Unmarshal(value, &event.Method)
+		case metadataPath:
+			err = json.Unmarshal(value, &event.Path)
+		case metadataHeaders:
+			err = json.Unmarshal(value, &event.Headers)
+		case metadataQuery:
+			err = json.Unmarshal(value, &event.Query)
+		case metadataOutboundHeaders:
+			err = json.Unmarshal(value, &event.OutboundHeaders)
+		case metadataContentType:
+			var contentType *string
+			if err = json.Unmarshal(value, &contentType); err == nil && contentType != nil {
+				event.ContentType = *contentType
+			}
+		default:
+			var v any
+			err = json.Unmarshal(value, &v)
+			event.Metadata[key] = v
+		}
+		if err != nil {
+			return fmt.Errorf("metadata.%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func marshalStepData(data any) ([]byte, error) {
+	switch v := data.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case json.RawMessage:
+		return v, nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
+func toNullUUID(id string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return pgtype.UUID{Bytes: uid, Valid: true}, nil
+}
+
+func toText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type pipelineRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewPipelineRepository(db *pgxpool.Pool, appLogger logger.Logger) pipeline.Repository {
+	return &pipelineRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r pipelineRepository) GetByID(ctx context.Context, id string) (*pipeline.Pipeline, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.repository.get_by_id")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+
+	result, err := r.queries.GetPipelineByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to get pipeline by ID", logger.Error(err))
+		return nil, fmt.Errorf("failed to get pipeline by ID: %w", err)
+	}
+
+	return &pipeline.Pipeline{
+		ID:             result.ID.String(),
+		UserID:         result.UserID.String(),
+		SourceID:       result.SourceID.String(),
+		DestinationID:  result.DestinationID.String(),
+		Name:           result.Name,
+		Description:    result.Description,
+		IsActive:       result.IsActive,
+		ExecutionOrder: result.ExecutionOrder,
+		OrderingKey:    result.OrderingKey,
+		CreatedAt:      result.CreatedAt.Time,
+		UpdatedAt:      result.UpdatedAt.Time,
+	}, nil
+}
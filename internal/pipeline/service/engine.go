@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	"github.com/theotruvelot/catchook/internal/filter/evaluator"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/internal/transformation/executor"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type engine struct {
+	eventRepo          pipeline.EventRepository
+	filterRepo         filter.Repository
+	transformationRepo transformation.Repository
+	evaluators         *evaluator.Cache
+	executors          *executor.Cache
+	dispatcher         delivery.Dispatcher
+	appLogger          logger.Logger
+}
+
+func NewEngine(
+	eventRepo pipeline.EventRepository,
+	filterRepo filter.Repository,
+	transformationRepo transformation.Repository,
+	evaluators *evaluator.Cache,
+	executors *executor.Cache,
+	dispatcher delivery.Dispatcher,
+	appLogger logger.Logger,
+) pipeline.Engine {
+	return &engine{
+		eventRepo:          eventRepo,
+		filterRepo:         filterRepo,
+		transformationRepo: transformationRepo,
+		evaluators:         evaluators,
+		executors:          executors,
+		dispatcher:         dispatcher,
+		appLogger:          appLogger,
+	}
+}
+
+// Process evaluates the active filters of the event's pipeline in execution
+// order, then applies its active transformations. Every filter must pass;
+// the first rejection marks the event as filtered and the first evaluation
+// or transformation error marks it as failed. An event that passes both
+// stages is delivered to the destination of its pipeline and marked as
+// delivered or failed, or left transformed while a retry is scheduled. When
+// the destination has a delay, the event is marked as delayed instead and
+// the delivery scheduler picks it up once due. Events are first keyed by the
+// ordering key of their pipeline or destination, if any, so that the
+// deliveries of a key go out in ingestion order. Each filter, transformation
+// and delivery attempt is recorded as a step, and the aggregated results are
+// stored in filter_results and transformation_results.
+func (e engine) Process(ctx context.Context, eventID string) (*pipeline.Event, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.engine.process")
+	defer span.End()
+
+	event, err := e.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting webhook event: %w", err)
+	}
+	if event == nil {
+		return nil, pipeline.ErrEventNotFound
+	}
+	if event.PipelineID == "" {
+		return nil, pipeline.ErrEventWithoutPipeline
+	}
+	if event.Status != pipeline.StatusPending {
+		return nil, pipeline.ErrEventAlreadyProcessed
+	}
+
+	if err := e.assignOrderingKey(ctx, event); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// A replayed event already has a trace, which its new steps follow.
+	order, err := e.eventRepo.NextStepOrder(ctx, event.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting next step order: %w", err)
+	}
+	order--
+
+	if event.Status == pipeline.StatusPending {
+		if err := e.runFilters(ctx, event, &order); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	if event.Status == pipeline.StatusPending {
+		if err := e.runTransformations(ctx, event, &order); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	if event.Status == pipeline.StatusPending || event.Status == pipeline.StatusTransformed {
+		e.deliver(ctx, event, order+1)
+	}
+
+	if event.Status == pipeline.StatusDelayed {
+		err = e.eventRepo.Schedule(ctx, event.ID, *event.ScheduledAt)
+	} else {
+		err = e.eventRepo.UpdateStatus(ctx, event.ID, event.Status, event.ErrorMessage)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("updating webhook event status: %w", err)
+	}
+
+	e.appLogger.Info(ctx, "Webhook event processed",
+		logger.String("webhook_event_id", event.ID),
+		logger.String("status", string(event.Status)),
+	)
+
+	return event, nil
+}
+
+// assignOrderingKey keys the event before any other stage, so that later
+// events of its key wait for it from now on. An ordering key that cannot be
+// evaluated fails the event. The returned error is reserved for storage
+// failures.
+func (e engine) assignOrderingKey(ctx context.Context, event *pipeline.Event) error {
+	key, err := e.dispatcher.OrderingKey(ctx, event)
+	if err != nil {
+		if delivery.IsPermanent(err) {
+			event.Status = pipeline.StatusFailed
+			event.ErrorMessage = err.Error()
+			return nil
+		}
+		return fmt.Errorf("resolving ordering key: %w", err)
+	}
+	if key == "" {
+		return nil
+	}
+	if err := e.eventRepo.SetOrderingKey(ctx, event.ID, key); err != nil {
+		return fmt.Errorf("saving ordering key: %w", err)
+	}
+	event.OrderingKey = key
+	return nil
+}
+
+// deliver sends the event to its destination, or schedules it when the
+// destination delays it, and sets the event status accordingly.
+func (e engine) deliver(ctx context.Context, event *pipeline.Event, order int32) {
+	at, err := e.dispatcher.DeliverAt(ctx, event)
+	if err != nil {
+		event.Status = pipeline.StatusFailed
+		event.ErrorMessage = err.Error()
+		return
+	}
+	if !at.IsZero() {
+		event.Status = pipeline.StatusDelayed
+		event.ScheduledAt = &at
+		return
+	}
+
+	record, err := e.dispatcher.Dispatch(ctx, event, order)
+	event.Status = pipeline.StatusFailed
+	if record != nil {
+		event.Status = record.EventStatus()
+	}
+	if err != nil {
+		event.ErrorMessage = err.Error()
+	}
+}
+
+// runFilters sets the event status to filtered or failed when a filter
+// stops it. The returned error is reserved for storage failures.
+func (e engine) runFilters(ctx context.Context, event *pipeline.Event, order *int32) error {
+	filters, err := e.filterRepo.ListActiveByPipeline(ctx, event.PipelineID)
+	if err != nil {
+		return fmt.Errorf("listing active filters: %w", err)
+	}
+
+	results := &filter.Results{Passed: true, Filters: make([]*filter.Result, 0, len(filters))}
+
+	for _, f := range filters {
+		*order++
+		result, err := e.evaluate(ctx, f, event, *order)
+		results.Filters = append(results.Filters, result)
+		if err != nil {
+			results.Passed = false
+			event.Status = pipeline.StatusFailed
+			event.ErrorMessage = err.Error()
+			break
+		}
+		if !result.Passed {
+			results.Passed = false
+			event.Status = pipeline.StatusFiltered
+			break
+		}
+		for name, value := range result.Captures {
+			if event.Captures == nil {
+				event.Captures = map[string]string{}
+			}
+			event.Captures[name] = value
+		}
+	}
+
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("marshal filter results: %w", err)
+	}
+	if err := e.eventRepo.UpdateFilterResults(ctx, event.ID, encoded); err != nil {
+		return fmt.Errorf("saving filter results: %w", err)
+	}
+
+	return nil
+}
+
+func (e engine) evaluate(ctx context.Context, f *filter.Filter, event *pipeline.Event, order int32) (*filter.Result, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.engine.filter")
+	defer span.End()
+
+	step := &pipeline.Step{
+		EventID:        event.ID,
+		PipelineID:     event.PipelineID,
+		Type:           pipeline.StepTypeFilter,
+		Name:           f.Name,
+		RefID:          f.ID,
+		ExecutionOrder: order,
+		StartedAt:      time.Now(),
+	}
+
+	var result *filter.Result
+	ev, err := e.evaluators.Get(f)
+	if err == nil {
+		result, err = ev.Evaluate(ctx, event)
+	}
+	if result == nil {
+		result = &filter.Result{}
+	}
+	result.FilterID = f.ID
+	result.Name = f.Name
+	result.FilterType = f.FilterType
+
+	step.CompletedAt = time.Now()
+	step.OutputData = result
+	step.Status = pipeline.StepStatusSuccess
+	if err != nil {
+		result.Error = err.Error()
+		step.Status = pipeline.StepStatusFailed
+		step.ErrorMessage = err.Error()
+	}
+	e.recordStep(ctx, step)
+
+	if err != nil {
+		span.RecordError(err)
+		return result, fmt.Errorf("filter %q: %w", f.Name, err)
+	}
+	return result, nil
+}
+
+// runTransformations applies the transformations in order and stores the
+// resulting payload and headers. When one fails, the event is marked as
+// failed and its payload and headers are left as they were before the
+// transformation stage.
+func (e engine) runTransformations(ctx context.Context, event *pipeline.Event, order *int32) error {
+	transformations, err := e.transformationRepo.ListActiveByPipeline(ctx, event.PipelineID)
+	if err != nil {
+		return fmt.Errorf("listing active transformations: %w", err)
+	}
+	if len(transformations) == 0 {
+		return nil
+	}
+
+	originalPayload, originalHeaders, originalContentType := event.Payload, event.Headers, event.ContentType
+	results := &transformation.Results{Transformations: make([]*transformation.Result, 0, len(transformations))}
+
+	for _, t := range transformations {
+		*order++
+		result, err := e.transform(ctx, t, event, *order)
+		results.Transformations = append(results.Transformations, result)
+		if err != nil {
+			event.Payload, event.Headers, event.ContentType = originalPayload, originalHeaders, originalContentType
+			event.Status = pipeline.StatusFailed
+			event.ErrorMessage = err.Error()
+			break
+		}
+	}
+
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("marshal transformation results: %w", err)
+	}
+	if event.Status == pipeline.StatusPending {
+		event.OutboundHeaders = event.Headers
+	}
+	if err := e.eventRepo.UpdateTransformationResults(ctx, event, encoded); err != nil {
+		return fmt.Errorf("saving transformation results: %w", err)
+	}
+
+	if event.Status == pipeline.StatusPending {
+		event.Status = pipeline.StatusTransformed
+	}
+	return nil
+}
+
+func (e engine) transform(ctx context.Context, t *transformation.Transformation, event *pipeline.Event, order int32) (*transformation.Result, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.engine.transformation")
+	defer span.End()
+
+	step := &pipeline.Step{
+		EventID:        event.ID,
+		PipelineID:     event.PipelineID,
+		Type:           pipeline.StepTypeTransformation,
+		Name:           t.Name,
+		RefID:          t.ID,
+		ExecutionOrder: order,
+		StartedAt:      time.Now(),
+	}
+
+	var result *transformation.Result
+	ex, err := e.executors.Get(t)
+	if err == nil {
+		result, err = ex.Apply(ctx, event)
+	}
+	if result == nil {
+		result = &transformation.Result{}
+	}
+	result.TransformationID = t.ID
+	result.Name = t.Name
+	result.TransformationType = t.TransformationType
+
+	step.CompletedAt = time.Now()
+	step.OutputData = result
+	step.Status = pipeline.StepStatusSuccess
+	if err != nil {
+		result.Error = err.Error()
+		step.Status = pipeline.StepStatusFailed
+		step.ErrorMessage = err.Error()
+	}
+	e.recordStep(ctx, step)
+
+	if err != nil {
+		span.RecordError(err)
+		return result, fmt.Errorf("transformation %q: %w", t.Name, err)
+	}
+	return result, nil
+}
+
+// recordStep stores a step of the execution trace. A failure to record is
+// logged but does not change the outcome of the event.
+func (e engine) recordStep(ctx context.Context, step *pipeline.Step) {
+	if err := e.eventRepo.CreateStep(ctx, step); err != nil {
+		e.appLogger.Error(ctx, "Failed to record pipeline step",
+			logger.String("webhook_event_id", step.EventID),
+			logger.String("step_type", string(step.Type)),
+			logger.String("step_id", step.RefID),
+			logger.Error(err),
+		)
+	}
+}
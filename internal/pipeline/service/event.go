@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	destination "github.com/theotruvelot/catchook/internal/destination/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type eventService struct {
+	eventRepo       pipeline.EventRepository
+	destinationRepo destination.Repository
+	engine          pipeline.Engine
+	dispatcher      delivery.Dispatcher
+	appLogger       logger.Logger
+}
+
+func NewEventService(
+	eventRepo pipeline.EventRepository,
+	destinationRepo destination.Repository,
+	engine pipeline.Engine,
+	dispatcher delivery.Dispatcher,
+	appLogger logger.Logger,
+) pipeline.EventService {
+	return &eventService{
+		eventRepo:       eventRepo,
+		destinationRepo: destinationRepo,
+		engine:          engine,
+		dispatcher:      dispatcher,
+		appLogger:       appLogger,
+	}
+}
+
+func (s eventService) GetByID(ctx context.Context, id string) (*pipeline.Event, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.service.event.get_by_id")
+	defer span.End()
+
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting webhook event by ID: %w", err)
+	}
+	if event == nil {
+		return nil, pipeline.ErrEventNotFound
+	}
+	return event, nil
+}
+
+// Cancel only succeeds while the event is delayed: once its delivery has
+// started, the event can no longer be cancelled.
+func (s eventService) Cancel(ctx context.Context, id string) (*pipeline.Event, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.service.event.cancel")
+	defer span.End()
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	s.appLogger.Info(ctx, "Cancelling delayed webhook event", logger.String("webhook_event_id", id))
+
+	cancelled, err := s.eventRepo.CancelDelayed(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("cancelling webhook event: %w", err)
+	}
+	if !cancelled {
+		return nil, pipeline.ErrEventNotDelayed
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Resend runs the derived event right away. In pipeline mode it goes
+// through the filters and transformations of the pipeline before being
+// delivered; in destination mode its payload and headers are delivered as
+// they are. A failed delivery does not fail the call: the derived event is
+// returned with its status.
+func (s eventService) Resend(ctx context.Context, userID, id string, req pipeline.ResendRequest) (*pipeline.Event, *pipeline.Edit, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.service.event.resend")
+	defer span.End()
+
+	original, err := s.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	mode := pipeline.EditMode(req.Mode)
+	switch mode {
+	case pipeline.EditModePipeline:
+		if original.PipelineID == "" {
+			return nil, nil, pipeline.ErrEventWithoutPipeline
+		}
+	case pipeline.EditModeDestination:
+		dest, err := s.destinationRepo.GetByID(ctx, req.DestinationID)
+		if err != nil {
+			span.RecordError(err)
+			return nil, nil, fmt.Errorf("getting destination: %w", err)
+		}
+		if dest == nil {
+			return nil, nil, delivery.ErrDestinationNotFound
+		}
+		if !dest.IsActive {
+			return nil, nil, delivery.ErrDestinationInactive
+		}
+	}
+
+	event, changes := original.Derive(req.Payload, req.Headers)
+	edit := &pipeline.Edit{
+		UserID:  userID,
+		Mode:    mode,
+		Changes: changes,
+		Note:    req.Note,
+	}
+	if mode == pipeline.EditModeDestination {
+		edit.DestinationID = req.DestinationID
+		event.Status = pipeline.StatusTransformed
+		event.OutboundHeaders = event.Headers
+	}
+
+	if err := s.eventRepo.CreateDerived(ctx, event, edit); err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("creating derived webhook event: %w", err)
+	}
+
+	s.appLogger.Info(ctx, "Webhook event edited",
+		logger.String("webhook_event_id", original.ID),
+		logger.String("derived_event_id", event.ID),
+		logger.String("user_id", userID),
+		logger.String("mode", string(mode)),
+	)
+
+	if mode == pipeline.EditModePipeline {
+		if _, err := s.engine.Process(ctx, event.ID); err != nil {
+			span.RecordError(err)
+			return nil, nil, fmt.Errorf("processing derived webhook event: %w", err)
+		}
+	} else if err := s.deliver(ctx, event, edit.DestinationID); err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	event, err = s.GetByID(ctx, event.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, edit, nil
+}
+
+// deliver sends the derived event to the destination and stores the
+// resulting status. The returned error is reserved for storage failures.
+func (s eventService) deliver(ctx context.Context, event *pipeline.Event, destinationID string) error {
+	order, err := s.eventRepo.NextStepOrder(ctx, event.ID)
+	if err != nil {
+		return fmt.Errorf("getting next step order: %w", err)
+	}
+
+	record, err := s.dispatcher.DispatchTo(ctx, event, destinationID, order)
+	status := pipeline.StatusFailed
+	if record != nil {
+		status = record.EventStatus()
+	}
+	errorMessage := ""
+	if err != nil {
+		errorMessage = err.Error()
+	}
+	if err := s.eventRepo.UpdateStatus(ctx, event.ID, status, errorMessage); err != nil {
+		return fmt.Errorf("updating webhook event status: %w", err)
+	}
+	return nil
+}
+
+func (s eventService) ListEdits(ctx context.Context, id string) ([]*pipeline.Edit, error) {
+	ctx, span := tracer.StartSpan(ctx, "pipeline.service.event.list_edits")
+	defer span.End()
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	edits, err := s.eventRepo.ListEdits(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("listing event edits: %w", err)
+	}
+	return edits, nil
+}
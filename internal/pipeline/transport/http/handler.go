@@ -0,0 +1,138 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/internal/platform/http/middleware"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+// Handler holds the webhook event dependencies
+type Handler struct {
+	eventService pipeline.EventService
+	validator    *validatorpkg.Validator
+}
+
+// NewHandler creates a new webhook event handler
+func NewHandler(eventService pipeline.EventService, validator *validatorpkg.Validator) *Handler {
+	return &Handler{
+		eventService: eventService,
+		validator:    validator,
+	}
+}
+
+func (h *Handler) GetEvent(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "pipeline.handler.event.get")
+	defer span.End()
+
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.BadRequest(c, "event_id is required", nil)
+	}
+
+	event, err := h.eventService.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, pipeline.ErrEventNotFound) {
+			return response.NotFound(c, "webhook event not found")
+		}
+		return response.InternalError(c, "failed to get webhook event")
+	}
+
+	return response.Success(c, event.ToResponse(), "webhook event")
+}
+
+func (h *Handler) CancelEvent(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "pipeline.handler.event.cancel")
+	defer span.End()
+
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.BadRequest(c, "event_id is required", nil)
+	}
+
+	event, err := h.eventService.Cancel(ctx, eventID)
+	if err != nil {
+		switch {
+		case errors.Is(err, pipeline.ErrEventNotFound):
+			return response.NotFound(c, "webhook event not found")
+		case errors.Is(err, pipeline.ErrEventNotDelayed):
+			return response.Conflict(c, "only delayed webhook events can be cancelled")
+		default:
+			return response.InternalError(c, "failed to cancel webhook event")
+		}
+	}
+
+	return response.Success(c, event.ToResponse(), "webhook event cancelled")
+}
+
+// ResendEvent derives a new event from an edited copy of the event and
+// sends it.
+func (h *Handler) ResendEvent(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "pipeline.handler.event.resend")
+	defer span.End()
+
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.BadRequest(c, "event_id is required", nil)
+	}
+
+	userID, err := middleware.GetAuthUserID(c)
+	if err != nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	var req pipeline.ResendRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	event, edit, err := h.eventService.Resend(ctx, userID, eventID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, pipeline.ErrEventNotFound):
+			return response.NotFound(c, "webhook event not found")
+		case errors.Is(err, pipeline.ErrEventWithoutPipeline):
+			return response.BadRequest(c, "webhook event is not attached to a pipeline", nil)
+		case errors.Is(err, delivery.ErrDestinationNotFound):
+			return response.NotFound(c, "destination not found")
+		case errors.Is(err, delivery.ErrDestinationInactive):
+			return response.BadRequest(c, "destination is inactive", nil)
+		default:
+			return response.InternalError(c, "failed to resend webhook event")
+		}
+	}
+
+	return response.Created(c, &pipeline.ResendResponse{
+		Event: event.ToResponse(),
+		Edit:  edit,
+	}, "webhook event resent")
+}
+
+func (h *Handler) ListEventEdits(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "pipeline.handler.event.list_edits")
+	defer span.End()
+
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.BadRequest(c, "event_id is required", nil)
+	}
+
+	edits, err := h.eventService.ListEdits(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, pipeline.ErrEventNotFound) {
+			return response.NotFound(c, "webhook event not found")
+		}
+		return response.InternalError(c, "failed to list webhook event edits")
+	}
+
+	return response.Success(c, edits, "webhook event edits")
+}
@@ -9,25 +9,51 @@ import (
 	auth "github.com/theotruvelot/catchook/internal/auth/domain"
 	authservice "github.com/theotruvelot/catchook/internal/auth/service"
 	"github.com/theotruvelot/catchook/internal/config"
+	"github.com/theotruvelot/catchook/internal/delivery/deliverer"
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	deliverypg "github.com/theotruvelot/catchook/internal/delivery/repository/postgres"
+	deliveryservice "github.com/theotruvelot/catchook/internal/delivery/service"
 	destination "github.com/theotruvelot/catchook/internal/destination/domain"
 	destinationpg "github.com/theotruvelot/catchook/internal/destination/repository/postgres"
 	destinationservice "github.com/theotruvelot/catchook/internal/destination/service"
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	"github.com/theotruvelot/catchook/internal/filter/evaluator"
+	filterpg "github.com/theotruvelot/catchook/internal/filter/repository/postgres"
+	filterservice "github.com/theotruvelot/catchook/internal/filter/service"
 	health "github.com/theotruvelot/catchook/internal/health/domain"
 	healthservice "github.com/theotruvelot/catchook/internal/health/service"
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+	lookuppg "github.com/theotruvelot/catchook/internal/lookup/repository/postgres"
+	lookupservice "github.com/theotruvelot/catchook/internal/lookup/service"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	pipelinepg "github.com/theotruvelot/catchook/internal/pipeline/repository/postgres"
+	pipelineservice "github.com/theotruvelot/catchook/internal/pipeline/service"
 	"github.com/theotruvelot/catchook/internal/platform/session"
 	pgstorage "github.com/theotruvelot/catchook/internal/platform/storage/postgres"
+	replay "github.com/theotruvelot/catchook/internal/replay/domain"
+	replaypg "github.com/theotruvelot/catchook/internal/replay/repository/postgres"
+	replayservice "github.com/theotruvelot/catchook/internal/replay/service"
 	setup "github.com/theotruvelot/catchook/internal/setup/domain"
 	setupservice "github.com/theotruvelot/catchook/internal/setup/service"
 	source "github.com/theotruvelot/catchook/internal/source/domain"
 	sourcepg "github.com/theotruvelot/catchook/internal/source/repository/postgres"
 	sourceservice "github.com/theotruvelot/catchook/internal/source/service"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/internal/transformation/executor"
+	transformationpg "github.com/theotruvelot/catchook/internal/transformation/repository/postgres"
+	transformationservice "github.com/theotruvelot/catchook/internal/transformation/service"
 	user "github.com/theotruvelot/catchook/internal/user/domain"
 	userpg "github.com/theotruvelot/catchook/internal/user/repository/postgres"
 	userservice "github.com/theotruvelot/catchook/internal/user/service"
+	wasm "github.com/theotruvelot/catchook/internal/wasm/domain"
+	wasmpg "github.com/theotruvelot/catchook/internal/wasm/repository/postgres"
+	wasmservice "github.com/theotruvelot/catchook/internal/wasm/service"
 	"github.com/theotruvelot/catchook/pkg/cache"
+	"github.com/theotruvelot/catchook/pkg/jsvm"
 	"github.com/theotruvelot/catchook/pkg/logger"
 	"github.com/theotruvelot/catchook/pkg/tracer"
 	"github.com/theotruvelot/catchook/pkg/validator"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
 )
 
 // Container handles dependency injection and initialization
@@ -39,14 +65,37 @@ type Container struct {
 	Cache     cache.Cache
 	Session   session.Manager
 	Validator *validator.Validator
+	Wasm      *wasmvm.Runtime
+	// LookupCache holds the lookup tables read by enrich transformations.
+	LookupCache *lookupservice.Cache
+	// HTTPDeliverer keeps a connection pool per HTTP destination.
+	HTTPDeliverer *deliverer.HTTPDeliverer
+	// FileDeliverer keeps the files of the file destinations open.
+	FileDeliverer *deliverer.FileDeliverer
+	// DatabaseDeliverer keeps a connection pool per database destination.
+	DatabaseDeliverer *deliverer.DatabaseDeliverer
+	// DeliveryScheduler delivers delayed events and retries failed
+	// deliveries in the background.
+	DeliveryScheduler *deliveryservice.Scheduler
+	// ReplayRunner carries out the replay jobs in the background.
+	ReplayRunner *replayservice.Runner
 
 	// Services
-	UserService        user.Service
-	AuthService        auth.Service
-	HealthService      health.Service
-	SetupService       setup.Service
-	SourceService      source.Service
-	DestinationService destination.Service
+	UserService           user.Service
+	AuthService           auth.Service
+	HealthService         health.Service
+	SetupService          setup.Service
+	SourceService         source.Service
+	DestinationService    destination.Service
+	FilterService         filter.Service
+	TransformationService transformation.Service
+	WasmService           wasm.Service
+	LookupService         lookup.Service
+	EventService          pipeline.EventService
+	DeliveryDispatcher    delivery.Dispatcher
+	DeadLetterService     delivery.DeadLetterService
+	PipelineEngine        pipeline.Engine
+	ReplayService         replay.Service
 }
 
 // NewContainer creates and initializes all dependencies
@@ -69,6 +118,11 @@ func NewContainer(cfg *config.Config, appLogger logger.Logger) (*Container, erro
 	}
 
 	container.initUtilities()
+
+	if err := container.initSandbox(); err != nil {
+		return nil, fmt.Errorf("failed to initialize sandbox: %w", err)
+	}
+
 	container.initServices()
 
 	appLogger.Info(context.Background(), "Application container initialized successfully")
@@ -100,26 +154,139 @@ func (c *Container) initUtilities() {
 	c.AppLogger.Info(context.Background(), "Utilities initialized")
 }
 
+func (c *Container) initSandbox() error {
+	runtime, err := wasmvm.NewRuntime(context.Background(), wasmvm.Limits{
+		Timeout:     c.Config.Sandbox.WasmTimeout,
+		MemoryBytes: c.Config.Sandbox.WasmMemoryLimit,
+		MaxLogLines: c.Config.Sandbox.MaxLogLines,
+	})
+	if err != nil {
+		return err
+	}
+	c.Wasm = runtime
+	return nil
+}
+
 func (c *Container) initServices() {
 	// Repositories
 	userRepo := userpg.NewUserRepository(c.DB, c.AppLogger)
 	sourceRepo := sourcepg.NewSourceRepository(c.DB, c.AppLogger)
 	destinationRepo := destinationpg.NewDestinationRepository(c.DB, c.AppLogger)
+	pipelineRepo := pipelinepg.NewPipelineRepository(c.DB, c.AppLogger)
+	eventRepo := pipelinepg.NewEventRepository(c.DB, c.AppLogger)
+	filterRepo := filterpg.NewFilterRepository(c.DB, c.AppLogger)
+	transformationRepo := transformationpg.NewTransformationRepository(c.DB, c.AppLogger)
+	wasmModuleRepo := wasmpg.NewModuleRepository(c.DB, c.AppLogger)
+	c.WasmService = wasmservice.NewModuleService(wasmModuleRepo, c.Wasm, c.Config.Sandbox.WasmMaxModuleSize, c.AppLogger)
+	lookupRepo := lookuppg.NewLookupRepository(c.DB, c.AppLogger)
+	c.LookupCache = lookupservice.NewCache(lookupRepo, c.Redis, lookupservice.DefaultCacheMaxAge, c.AppLogger)
+	if err := c.LookupCache.Listen(context.Background()); err != nil {
+		c.AppLogger.Warn(context.Background(), "Lookup tables will only be refreshed periodically", logger.Error(err))
+	}
+	// Compiled filters and transformations
+	jsvm.SetMaxConcurrent(c.Config.Sandbox.ScriptConcurrency)
+	scriptLimits := jsvm.Limits{
+		Timeout:        c.Config.Sandbox.ScriptTimeout,
+		MemoryBytes:    c.Config.Sandbox.ScriptMemoryLimit,
+		MaxInputBytes:  c.Config.Sandbox.ScriptMaxInput,
+		MaxOutputBytes: c.Config.Sandbox.ScriptMaxOutput,
+		MaxLogLines:    c.Config.Sandbox.MaxLogLines,
+	}
+	evaluators := evaluator.NewCache(evaluator.Options{Script: scriptLimits, Modules: c.WasmService})
+	executors := executor.NewCache(executor.Options{Script: scriptLimits, Modules: c.WasmService, Lookups: c.LookupCache})
+	// Deliverers by destination type
+	deliveryRepo := deliverypg.NewDeliveryRepository(c.DB, c.AppLogger)
+	deadLetterRepo := deliverypg.NewDeadLetterRepository(c.DB, c.AppLogger)
+	c.HTTPDeliverer = deliverer.NewHTTPDeliverer(deliverer.HTTPOptions{
+		MaxIdleConnsPerHost: c.Config.Delivery.MaxIdleConnsPerHost,
+		IdleConnTimeout:     c.Config.Delivery.IdleConnTimeout,
+		MaxResponseBodySize: c.Config.Delivery.MaxResponseBodySize,
+	})
+	c.FileDeliverer = deliverer.NewFileDeliverer(deliverer.FileOptions{
+		AllowedDirs: destination.DirAllowlist(c.Config.Delivery.FileAllowedDirs),
+		IdleTimeout: c.Config.Delivery.FileIdleTimeout,
+	}, c.AppLogger)
+	c.DatabaseDeliverer = deliverer.NewDatabaseDeliverer(deliverer.DatabaseOptions{
+		Timeout:         c.Config.Delivery.DatabaseTimeout,
+		IdleConnTimeout: c.Config.Delivery.IdleConnTimeout,
+		IdleTimeout:     c.Config.Delivery.DatabaseIdleTimeout,
+		AllowedHosts:    destination.HostAllowlist(c.Config.Delivery.DatabaseAllowedHosts),
+	}, c.AppLogger)
+	deliverers := map[destination.DestinationType]delivery.Deliverer{
+		destination.DestinationTypeHTTP:     c.HTTPDeliverer,
+		destination.DestinationTypeFile:     c.FileDeliverer,
+		destination.DestinationTypeDatabase: c.DatabaseDeliverer,
+	}
+	breaker := destinationservice.NewCircuitBreaker(c.Redis, c.AppLogger)
+	limiter := destinationservice.NewDeliveryLimiter(c.Redis, c.Config.Delivery.RetryLease)
 	// Services
 	c.UserService = userservice.NewUserService(userRepo, c.Cache, c.AppLogger)
 	c.AuthService = authservice.NewAuthService(userRepo, c.Session, c.AppLogger)
 	c.HealthService = healthservice.NewHealthService(c.DB, c.Redis, userRepo, c.AppLogger, c.Config.Server.Version)
 	c.SetupService = setupservice.NewSetupService(userRepo, c.AppLogger)
 	c.SourceService = sourceservice.NewSourceService(sourceRepo, c.AppLogger)
-	c.DestinationService = destinationservice.NewDestinationService(destinationRepo, c.AppLogger)
+	fileDirs := destination.DirAllowlist(c.Config.Delivery.FileAllowedDirs)
+	tables := destinationservice.NewTableInspector(destination.HostAllowlist(c.Config.Delivery.DatabaseAllowedHosts), c.Config.Delivery.DatabaseTimeout, c.AppLogger)
+	c.DestinationService = destinationservice.NewDestinationService(destinationRepo, breaker, fileDirs, tables, destination.ClientSet{c.HTTPDeliverer, c.DatabaseDeliverer}, c.AppLogger)
+	c.LookupService = lookupservice.NewLookupService(lookupRepo, c.LookupCache, c.AppLogger)
+	c.FilterService = filterservice.NewFilterService(filterRepo, pipelineRepo, evaluators, c.WasmService, c.AppLogger)
+	c.TransformationService = transformationservice.NewTransformationService(transformationRepo, pipelineRepo, eventRepo, executors, c.WasmService, c.LookupService, c.AppLogger)
+	c.DeliveryDispatcher = deliveryservice.NewDispatcher(deliveryRepo, deadLetterRepo, pipelineRepo, destinationRepo, eventRepo, deliverers, breaker, limiter, c.AppLogger)
+	c.DeadLetterService = deliveryservice.NewDeadLetterService(deadLetterRepo, deliveryRepo, destinationRepo, pipelineRepo, eventRepo, c.AppLogger)
+	c.DeliveryScheduler = deliveryservice.NewScheduler(deliveryRepo, eventRepo, c.DeliveryDispatcher, deliveryservice.SchedulerOptions{
+		PollInterval: c.Config.Delivery.PollInterval,
+		BatchSize:    c.Config.Delivery.BatchSize,
+		Concurrency:  c.Config.Delivery.Concurrency,
+		Lease:        c.Config.Delivery.RetryLease,
+	}, c.AppLogger)
+	c.PipelineEngine = pipelineservice.NewEngine(eventRepo, filterRepo, transformationRepo, evaluators, executors, c.DeliveryDispatcher, c.AppLogger)
+	c.EventService = pipelineservice.NewEventService(eventRepo, destinationRepo, c.PipelineEngine, c.DeliveryDispatcher, c.AppLogger)
+
+	replayRepo := replaypg.NewJobRepository(c.DB, c.AppLogger)
+	c.ReplayService = replayservice.NewReplayService(replayRepo, eventRepo, c.AppLogger)
+	c.ReplayRunner = replayservice.NewRunner(replayRepo, eventRepo, c.PipelineEngine, c.DeliveryDispatcher, replayservice.RunnerOptions{
+		PollInterval: c.Config.Replay.PollInterval,
+		Lease:        c.Config.Replay.Lease,
+		BatchSize:    c.Config.Replay.BatchSize,
+	}, c.AppLogger)
 	c.AppLogger.Info(context.Background(), "Services initialized")
 }
 
+// StartWorkers starts the background workers. They are stopped by Close.
+func (c *Container) StartWorkers(ctx context.Context) {
+	c.DeliveryScheduler.Start(ctx)
+	c.ReplayRunner.Start(ctx)
+	c.AppLogger.Info(ctx, "Background workers started")
+}
+
 // Close gracefully shuts down all connections
 func (c *Container) Close() error {
 	ctx := context.Background()
 	c.AppLogger.Info(ctx, "Closing application connections...")
 
+	// The workers stop first: until they do, they may still run wasm code,
+	// enrich events or deliver them.
+	if c.ReplayRunner != nil {
+		c.ReplayRunner.Stop()
+	}
+	if c.DeliveryScheduler != nil {
+		c.DeliveryScheduler.Stop()
+	}
+	if c.HTTPDeliverer != nil {
+		c.HTTPDeliverer.Close()
+	}
+	if c.FileDeliverer != nil {
+		c.FileDeliverer.Close()
+	}
+	if c.DatabaseDeliverer != nil {
+		c.DatabaseDeliverer.Close()
+	}
+	if c.Wasm != nil {
+		_ = c.Wasm.Close(ctx)
+	}
+	if c.LookupCache != nil {
+		_ = c.LookupCache.Close()
+	}
 	cache.CloseRedisClient(c.Redis, c.AppLogger)
 	pgstorage.ClosePool(c.DB, c.AppLogger)
 
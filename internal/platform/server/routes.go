@@ -33,6 +33,27 @@ func (s *Server) setupRoutes() {
 	// Destination routes
 	s.setupDestinationRoutes(api)
 
+	// Pipeline routes
+	s.setupPipelineRoutes(api)
+
+	// Transformation tooling routes
+	s.setupTransformationRoutes(api)
+
+	// WebAssembly module routes
+	s.setupWasmRoutes(api)
+
+	// Lookup table routes
+	s.setupLookupRoutes(api)
+
+	// Webhook event routes
+	s.setupEventRoutes(api)
+
+	// Dead letter routes
+	s.setupDeadLetterRoutes(api)
+
+	// Replay routes
+	s.setupReplayRoutes(api)
+
 	// 404 handler
 	s.app.Use(func(c *fiber.Ctx) error {
 		return response.NotFound(c, "Route not found")
@@ -99,4 +120,93 @@ func (s *Server) setupDestinationRoutes(api fiber.Router) {
 	destinations.Get("/", s.destinationHandler.ListDestinations)
 	destinations.Put("/:id", middleware.RequireOwnershipOrAdmin("id"), s.destinationHandler.UpdateDestination)
 	destinations.Delete("/:id", middleware.RequireOwnershipOrAdmin("id"), s.destinationHandler.DeleteDestination)
+	destinations.Post("/:id/signing/rotate", middleware.RequireOwnershipOrAdmin("id"), s.destinationHandler.RotateSigningSecret)
+	destinations.Get("/:id/circuit-breaker", s.destinationHandler.GetCircuitBreaker)
+	destinations.Post("/:id/circuit-breaker/reset", middleware.RequireOwnershipOrAdmin("id"), s.destinationHandler.ResetCircuitBreaker)
+	destinations.Get("/:id/dead-letters", s.deadLetterHandler.ListDestinationDeadLetters)
+}
+
+func (s *Server) setupPipelineRoutes(api fiber.Router) {
+	pipelines := api.Group("/pipelines")
+	pipelines.Use(middleware.SessionAuth(s.container.Session))
+
+	pipelines.Post("/:id/filters", middleware.RequirePermission(auth.PermissionWrite), s.filterHandler.CreateFilter)
+	pipelines.Get("/:id/filters", s.filterHandler.ListFilters)
+	pipelines.Get("/:id/filters/:filterId", s.filterHandler.GetFilter)
+	pipelines.Put("/:id/filters/:filterId", middleware.RequirePermission(auth.PermissionWrite), s.filterHandler.UpdateFilter)
+	pipelines.Delete("/:id/filters/:filterId", middleware.RequirePermission(auth.PermissionDelete), s.filterHandler.DeleteFilter)
+
+	pipelines.Post("/:id/transformations", middleware.RequirePermission(auth.PermissionWrite), s.transformationHandler.CreateTransformation)
+	pipelines.Get("/:id/transformations", s.transformationHandler.ListTransformations)
+	pipelines.Post("/:id/transformations/reorder", middleware.RequirePermission(auth.PermissionWrite), s.transformationHandler.ReorderTransformations)
+	pipelines.Post("/:id/transformations/preview", s.transformationHandler.PreviewTransformations)
+	pipelines.Get("/:id/transformations/:transformationId", s.transformationHandler.GetTransformation)
+	pipelines.Put("/:id/transformations/:transformationId", middleware.RequirePermission(auth.PermissionWrite), s.transformationHandler.UpdateTransformation)
+	pipelines.Delete("/:id/transformations/:transformationId", middleware.RequirePermission(auth.PermissionDelete), s.transformationHandler.DeleteTransformation)
+
+	pipelines.Get("/:id/dead-letters", s.deadLetterHandler.ListPipelineDeadLetters)
+}
+
+func (s *Server) setupTransformationRoutes(api fiber.Router) {
+	transformations := api.Group("/transformations")
+	transformations.Use(middleware.SessionAuth(s.container.Session))
+
+	transformations.Post("/patches/generate", s.transformationHandler.GeneratePatch)
+}
+
+func (s *Server) setupWasmRoutes(api fiber.Router) {
+	modules := api.Group("/wasm/modules")
+	modules.Use(middleware.SessionAuth(s.container.Session))
+
+	modules.Post("/", middleware.RequirePermission(auth.PermissionWrite), s.wasmHandler.UploadModule)
+	modules.Get("/", s.wasmHandler.ListModules)
+	modules.Get("/:hash", s.wasmHandler.GetModule)
+}
+
+func (s *Server) setupLookupRoutes(api fiber.Router) {
+	tables := api.Group("/lookup-tables")
+	tables.Use(middleware.SessionAuth(s.container.Session))
+
+	tables.Post("/", middleware.RequirePermission(auth.PermissionWrite), s.lookupHandler.CreateTable)
+	tables.Get("/", s.lookupHandler.ListTables)
+	tables.Get("/:id", s.lookupHandler.GetTable)
+	tables.Put("/:id", middleware.RequirePermission(auth.PermissionWrite), s.lookupHandler.UpdateTable)
+	tables.Delete("/:id", middleware.RequirePermission(auth.PermissionDelete), s.lookupHandler.DeleteTable)
+
+	tables.Get("/:id/entries", s.lookupHandler.ListEntries)
+	tables.Post("/:id/entries/upload", middleware.RequirePermission(auth.PermissionWrite), s.lookupHandler.UploadEntries)
+	tables.Get("/:id/entries/:key", s.lookupHandler.GetEntry)
+	tables.Put("/:id/entries/:key", middleware.RequirePermission(auth.PermissionWrite), s.lookupHandler.PutEntry)
+	tables.Delete("/:id/entries/:key", middleware.RequirePermission(auth.PermissionDelete), s.lookupHandler.DeleteEntry)
+}
+
+func (s *Server) setupEventRoutes(api fiber.Router) {
+	events := api.Group("/events")
+	events.Use(middleware.SessionAuth(s.container.Session))
+
+	events.Get("/:id", s.eventHandler.GetEvent)
+	events.Post("/:id/cancel", middleware.RequirePermission(auth.PermissionWrite), s.eventHandler.CancelEvent)
+	events.Post("/:id/replay", middleware.RequirePermission(auth.PermissionWrite), s.replayHandler.ReplayEvent)
+	events.Post("/:id/resend", middleware.RequirePermission(auth.PermissionWrite), s.eventHandler.ResendEvent)
+	events.Get("/:id/edits", s.eventHandler.ListEventEdits)
+}
+
+func (s *Server) setupDeadLetterRoutes(api fiber.Router) {
+	deadLetters := api.Group("/dead-letters")
+	deadLetters.Use(middleware.SessionAuth(s.container.Session))
+
+	deadLetters.Post("/redrive", middleware.RequirePermission(auth.PermissionWrite), s.deadLetterHandler.RedriveDeadLetters)
+	deadLetters.Post("/discard", middleware.RequirePermission(auth.PermissionDelete), s.deadLetterHandler.DiscardDeadLetters)
+	deadLetters.Post("/export", s.deadLetterHandler.ExportDeadLetters)
+	deadLetters.Get("/:id", s.deadLetterHandler.GetDeadLetter)
+}
+
+func (s *Server) setupReplayRoutes(api fiber.Router) {
+	replays := api.Group("/replays")
+	replays.Use(middleware.SessionAuth(s.container.Session))
+
+	replays.Post("/", middleware.RequirePermission(auth.PermissionWrite), s.replayHandler.CreateReplay)
+	replays.Get("/", s.replayHandler.ListReplays)
+	replays.Get("/:id", s.replayHandler.GetReplay)
+	replays.Post("/:id/cancel", middleware.RequirePermission(auth.PermissionWrite), s.replayHandler.CancelReplay)
 }
@@ -17,12 +17,19 @@ import (
 
 	authhttp "github.com/theotruvelot/catchook/internal/auth/transport/http"
 	"github.com/theotruvelot/catchook/internal/config"
+	deliveryhttp "github.com/theotruvelot/catchook/internal/delivery/transport/http"
 	destinationhttp "github.com/theotruvelot/catchook/internal/destination/transport/http"
+	filterhttp "github.com/theotruvelot/catchook/internal/filter/transport/http"
 	healthhttp "github.com/theotruvelot/catchook/internal/health/transport/http"
+	lookuphttp "github.com/theotruvelot/catchook/internal/lookup/transport/http"
+	pipelinehttp "github.com/theotruvelot/catchook/internal/pipeline/transport/http"
 	"github.com/theotruvelot/catchook/internal/platform/http/middleware"
+	replayhttp "github.com/theotruvelot/catchook/internal/replay/transport/http"
 	setuphttp "github.com/theotruvelot/catchook/internal/setup/transport/http"
 	sourcehttp "github.com/theotruvelot/catchook/internal/source/transport/http"
+	transformationhttp "github.com/theotruvelot/catchook/internal/transformation/transport/http"
 	userhttp "github.com/theotruvelot/catchook/internal/user/transport/http"
+	wasmhttp "github.com/theotruvelot/catchook/internal/wasm/transport/http"
 	"github.com/theotruvelot/catchook/pkg/logger"
 )
 
@@ -33,12 +40,19 @@ type Server struct {
 	appLogger logger.Logger
 
 	// Handlers
-	authHandler        *authhttp.Handler
-	healthHandler      *healthhttp.Handler
-	setupHandler       *setuphttp.Handler
-	userHandler        *userhttp.Handler
-	sourceHandler      *sourcehttp.Handler
-	destinationHandler *destinationhttp.Handler
+	authHandler           *authhttp.Handler
+	healthHandler         *healthhttp.Handler
+	setupHandler          *setuphttp.Handler
+	userHandler           *userhttp.Handler
+	sourceHandler         *sourcehttp.Handler
+	destinationHandler    *destinationhttp.Handler
+	filterHandler         *filterhttp.Handler
+	transformationHandler *transformationhttp.Handler
+	wasmHandler           *wasmhttp.Handler
+	lookupHandler         *lookuphttp.Handler
+	eventHandler          *pipelinehttp.Handler
+	deadLetterHandler     *deliveryhttp.Handler
+	replayHandler         *replayhttp.Handler
 }
 
 func NewServer(container *app.Container) *Server {
@@ -48,12 +62,19 @@ func NewServer(container *app.Container) *Server {
 		appLogger: container.AppLogger,
 
 		// Initialize handlers with their dependencies
-		authHandler:        authhttp.NewHandler(container.AuthService, container.Validator),
-		healthHandler:      healthhttp.NewHandler(container.HealthService),
-		setupHandler:       setuphttp.NewHandler(container.SetupService, container.Validator),
-		userHandler:        userhttp.NewHandler(container.UserService, container.Validator),
-		sourceHandler:      sourcehttp.NewHandler(container.SourceService, container.Validator),
-		destinationHandler: destinationhttp.NewHandler(container.DestinationService, container.Validator),
+		authHandler:           authhttp.NewHandler(container.AuthService, container.Validator),
+		healthHandler:         healthhttp.NewHandler(container.HealthService),
+		setupHandler:          setuphttp.NewHandler(container.SetupService, container.Validator),
+		userHandler:           userhttp.NewHandler(container.UserService, container.Validator),
+		sourceHandler:         sourcehttp.NewHandler(container.SourceService, container.Validator),
+		destinationHandler:    destinationhttp.NewHandler(container.DestinationService, container.Validator),
+		filterHandler:         filterhttp.NewHandler(container.FilterService, container.Validator),
+		transformationHandler: transformationhttp.NewHandler(container.TransformationService, container.Validator),
+		wasmHandler:           wasmhttp.NewHandler(container.WasmService, container.Validator),
+		lookupHandler:         lookuphttp.NewHandler(container.LookupService, container.Validator),
+		eventHandler:          pipelinehttp.NewHandler(container.EventService, container.Validator),
+		deadLetterHandler:     deliveryhttp.NewHandler(container.DeadLetterService, container.Validator),
+		replayHandler:         replayhttp.NewHandler(container.ReplayService, container.Validator),
 	}
 
 	server.app = server.createFiberApp()
@@ -0,0 +1,203 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: dead_letters.sql
+
+package generated
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countDeadLetters = `-- name: CountDeadLetters :one
+SELECT COUNT(*) FROM dead_letters
+WHERE ($1::uuid = '00000000-0000-0000-0000-000000000000' OR destination_id = $1)
+    AND ($2::uuid = '00000000-0000-0000-0000-000000000000' OR pipeline_id = $2)
+    AND ($3::text = '' OR status = $3::dead_letter_status)
+`
+
+func (q *Queries) CountDeadLetters(ctx context.Context, column1 uuid.UUID, column2 uuid.UUID, column3 string) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeadLetters, column1, column2, column3)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createDeadLetter = `-- name: CreateDeadLetter :one
+INSERT INTO dead_letters (
+    delivery_id, webhook_event_id, destination_id, pipeline_id, reason, attempts, last_response
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (delivery_id) DO UPDATE SET
+    reason = EXCLUDED.reason,
+    attempts = EXCLUDED.attempts,
+    last_response = EXCLUDED.last_response,
+    updated_at = NOW()
+RETURNING id, delivery_id, webhook_event_id, destination_id, pipeline_id, reason, attempts, last_response, status, redrive_delivery_id, resolved_at, created_at, updated_at
+`
+
+func (q *Queries) CreateDeadLetter(ctx context.Context, deliveryID uuid.UUID, webhookEventID uuid.UUID, destinationID uuid.UUID, pipelineID pgtype.UUID, reason string, attempts int32, lastResponse []byte) (DeadLetter, error) {
+	row := q.db.QueryRow(ctx, createDeadLetter,
+		deliveryID,
+		webhookEventID,
+		destinationID,
+		pipelineID,
+		reason,
+		attempts,
+		lastResponse,
+	)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.DeliveryID,
+		&i.WebhookEventID,
+		&i.DestinationID,
+		&i.PipelineID,
+		&i.Reason,
+		&i.Attempts,
+		&i.LastResponse,
+		&i.Status,
+		&i.RedriveDeliveryID,
+		&i.ResolvedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDeadLetterByID = `-- name: GetDeadLetterByID :one
+SELECT id, delivery_id, webhook_event_id, destination_id, pipeline_id, reason, attempts, last_response, status, redrive_delivery_id, resolved_at, created_at, updated_at FROM dead_letters WHERE id = $1
+`
+
+func (q *Queries) GetDeadLetterByID(ctx context.Context, id uuid.UUID) (DeadLetter, error) {
+	row := q.db.QueryRow(ctx, getDeadLetterByID, id)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.DeliveryID,
+		&i.WebhookEventID,
+		&i.DestinationID,
+		&i.PipelineID,
+		&i.Reason,
+		&i.Attempts,
+		&i.LastResponse,
+		&i.Status,
+		&i.RedriveDeliveryID,
+		&i.ResolvedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listDeadLetters = `-- name: ListDeadLetters :many
+SELECT id, delivery_id, webhook_event_id, destination_id, pipeline_id, reason, attempts, last_response, status, redrive_delivery_id, resolved_at, created_at, updated_at FROM dead_letters
+WHERE ($1::uuid = '00000000-0000-0000-0000-000000000000' OR destination_id = $1)
+    AND ($2::uuid = '00000000-0000-0000-0000-000000000000' OR pipeline_id = $2)
+    AND ($3::text = '' OR status = $3::dead_letter_status)
+ORDER BY created_at DESC, id DESC
+LIMIT $4 OFFSET $5
+`
+
+// Lists the dead letters of a destination, a pipeline or both, a nil UUID
+// leaving the scope open, and optionally of one status.
+func (q *Queries) ListDeadLetters(ctx context.Context, column1 uuid.UUID, column2 uuid.UUID, column3 string, limit int32, offset int32) ([]DeadLetter, error) {
+	rows, err := q.db.Query(ctx, listDeadLetters,
+		column1,
+		column2,
+		column3,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DeadLetter{}
+	for rows.Next() {
+		var i DeadLetter
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeliveryID,
+			&i.WebhookEventID,
+			&i.DestinationID,
+			&i.PipelineID,
+			&i.Reason,
+			&i.Attempts,
+			&i.LastResponse,
+			&i.Status,
+			&i.RedriveDeliveryID,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeadLettersByIDs = `-- name: ListDeadLettersByIDs :many
+SELECT id, delivery_id, webhook_event_id, destination_id, pipeline_id, reason, attempts, last_response, status, redrive_delivery_id, resolved_at, created_at, updated_at FROM dead_letters
+WHERE id = ANY($1::uuid[])
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) ListDeadLettersByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]DeadLetter, error) {
+	rows, err := q.db.Query(ctx, listDeadLettersByIDs, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DeadLetter{}
+	for rows.Next() {
+		var i DeadLetter
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeliveryID,
+			&i.WebhookEventID,
+			&i.DestinationID,
+			&i.PipelineID,
+			&i.Reason,
+			&i.Attempts,
+			&i.LastResponse,
+			&i.Status,
+			&i.RedriveDeliveryID,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveDeadLetter = `-- name: ResolveDeadLetter :execrows
+UPDATE dead_letters SET
+    status = $2,
+    redrive_delivery_id = $3,
+    resolved_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'dead'
+`
+
+// Settles a dead letter, only while it is dead so that concurrent bulk
+// actions settle it once.
+func (q *Queries) ResolveDeadLetter(ctx context.Context, iD uuid.UUID, status DeadLetterStatus, redriveDeliveryID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, resolveDeadLetter, iD, status, redriveDeliveryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -12,14 +12,63 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimDueDeliveries = `-- name: ClaimDueDeliveries :many
+UPDATE deliveries SET
+    scheduled_at = NOW() + make_interval(secs => $2::double precision),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT d.id FROM deliveries d
+    WHERE d.status = 'retrying' AND d.scheduled_at <= NOW()
+    ORDER BY d.scheduled_at ASC
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at, replay_job_id
+`
+
+// Claims the deliveries whose retry is due by pushing scheduled_at past a
+// lease, so that concurrent schedulers skip them. A delivery whose attempt
+// never completes is claimed again once the lease expires.
+func (q *Queries) ClaimDueDeliveries(ctx context.Context, limit int32, column2 float64) ([]Delivery, error) {
+	rows, err := q.db.Query(ctx, claimDueDeliveries, limit, column2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Delivery{}
+	for rows.Next() {
+		var i Delivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookEventID,
+			&i.DestinationID,
+			&i.Status,
+			&i.ResponseCode,
+			&i.Attempt,
+			&i.LastError,
+			&i.ScheduledAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReplayJobID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createDelivery = `-- name: CreateDelivery :one
 INSERT INTO deliveries (
-    webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at
-) VALUES ($1, $2, $3, $4, COALESCE($5, 0), $6, $7)
-RETURNING id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at
+    webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, replay_job_id
+) VALUES ($1, $2, $3, $4, COALESCE($5, 0), $6, $7, $8)
+RETURNING id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at, replay_job_id
 `
 
-func (q *Queries) CreateDelivery(ctx context.Context, webhookEventID uuid.UUID, destinationID uuid.UUID, status DeliveryStatus, responseCode pgtype.Int4, column5 interface{}, lastError pgtype.Text, scheduledAt pgtype.Timestamptz) (Delivery, error) {
+func (q *Queries) CreateDelivery(ctx context.Context, webhookEventID uuid.UUID, destinationID uuid.UUID, status DeliveryStatus, responseCode pgtype.Int4, column5 interface{}, lastError pgtype.Text, scheduledAt pgtype.Timestamptz, replayJobID pgtype.UUID) (Delivery, error) {
 	row := q.db.QueryRow(ctx, createDelivery,
 		webhookEventID,
 		destinationID,
@@ -28,6 +77,7 @@ func (q *Queries) CreateDelivery(ctx context.Context, webhookEventID uuid.UUID,
 		column5,
 		lastError,
 		scheduledAt,
+		replayJobID,
 	)
 	var i Delivery
 	err := row.Scan(
@@ -41,6 +91,7 @@ func (q *Queries) CreateDelivery(ctx context.Context, webhookEventID uuid.UUID,
 		&i.ScheduledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReplayJobID,
 	)
 	return i, err
 }
@@ -55,7 +106,7 @@ func (q *Queries) DeleteDelivery(ctx context.Context, id uuid.UUID) error {
 }
 
 const getDeliveryByID = `-- name: GetDeliveryByID :one
-SELECT id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at FROM deliveries WHERE id = $1
+SELECT id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at, replay_job_id FROM deliveries WHERE id = $1
 `
 
 func (q *Queries) GetDeliveryByID(ctx context.Context, id uuid.UUID) (Delivery, error) {
@@ -72,12 +123,13 @@ func (q *Queries) GetDeliveryByID(ctx context.Context, id uuid.UUID) (Delivery,
 		&i.ScheduledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReplayJobID,
 	)
 	return i, err
 }
 
 const listDeliveriesByWebhookEvent = `-- name: ListDeliveriesByWebhookEvent :many
-SELECT id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at FROM deliveries WHERE webhook_event_id = $1 ORDER BY created_at DESC
+SELECT id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at, replay_job_id FROM deliveries WHERE webhook_event_id = $1 ORDER BY created_at DESC
 `
 
 func (q *Queries) ListDeliveriesByWebhookEvent(ctx context.Context, webhookEventID uuid.UUID) ([]Delivery, error) {
@@ -100,6 +152,7 @@ func (q *Queries) ListDeliveriesByWebhookEvent(ctx context.Context, webhookEvent
 			&i.ScheduledAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ReplayJobID,
 		); err != nil {
 			return nil, err
 		}
@@ -111,6 +164,47 @@ func (q *Queries) ListDeliveriesByWebhookEvent(ctx context.Context, webhookEvent
 	return items, nil
 }
 
+const recordDeliveryAttempt = `-- name: RecordDeliveryAttempt :one
+UPDATE deliveries SET
+    status = $2,
+    response_code = $3,
+    attempt = $4,
+    last_error = $5,
+    scheduled_at = $6,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at, replay_job_id
+`
+
+// Stores the outcome of an attempt. Unlike UpdateDelivery, a missing
+// response code, error or retry time clears the one of the previous
+// attempt.
+func (q *Queries) RecordDeliveryAttempt(ctx context.Context, iD uuid.UUID, status DeliveryStatus, responseCode pgtype.Int4, attempt pgtype.Int4, lastError pgtype.Text, scheduledAt pgtype.Timestamptz) (Delivery, error) {
+	row := q.db.QueryRow(ctx, recordDeliveryAttempt,
+		iD,
+		status,
+		responseCode,
+		attempt,
+		lastError,
+		scheduledAt,
+	)
+	var i Delivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookEventID,
+		&i.DestinationID,
+		&i.Status,
+		&i.ResponseCode,
+		&i.Attempt,
+		&i.LastError,
+		&i.ScheduledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReplayJobID,
+	)
+	return i, err
+}
+
 const updateDelivery = `-- name: UpdateDelivery :one
 UPDATE deliveries SET
     status = COALESCE($2, status),
@@ -120,7 +214,7 @@ UPDATE deliveries SET
     scheduled_at = COALESCE($6, scheduled_at),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at
+RETURNING id, webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, created_at, updated_at, replay_job_id
 `
 
 func (q *Queries) UpdateDelivery(ctx context.Context, iD uuid.UUID, status DeliveryStatus, responseCode pgtype.Int4, attempt pgtype.Int4, lastError pgtype.Text, scheduledAt pgtype.Timestamptz) (Delivery, error) {
@@ -144,6 +238,7 @@ func (q *Queries) UpdateDelivery(ctx context.Context, iD uuid.UUID, status Deliv
 		&i.ScheduledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReplayJobID,
 	)
 	return i, err
 }
@@ -34,12 +34,12 @@ func (q *Queries) CountDestinations(ctx context.Context, column1 interface{}, co
 
 const createDestination = `-- name: CreateDestination :one
 INSERT INTO destinations (
-    user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts
-) VALUES ($1, $2, $3, $4, COALESCE($5, '{}'::jsonb), COALESCE($6, TRUE), COALESCE($7, 0), COALESCE($8, 0))
-RETURNING id, user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, created_at, updated_at
+    user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, retry_policy, delay_path, circuit_breaker, limits, ordering_key
+) VALUES ($1, $2, $3, $4, COALESCE($5, '{}'::jsonb), COALESCE($6, TRUE), COALESCE($7, 0), COALESCE($8, 0), COALESCE($9, '{}'::jsonb), COALESCE($10, ''), COALESCE($11, '{}'::jsonb), COALESCE($12, '{}'::jsonb), COALESCE($13, ''))
+RETURNING id, user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, created_at, updated_at, retry_policy, delay_path, circuit_breaker, limits, ordering_key
 `
 
-func (q *Queries) CreateDestination(ctx context.Context, userID uuid.UUID, name string, description string, destinationType DestinationType, column5 interface{}, column6 interface{}, column7 interface{}, column8 interface{}) (Destination, error) {
+func (q *Queries) CreateDestination(ctx context.Context, userID uuid.UUID, name string, description string, destinationType DestinationType, column5 interface{}, column6 interface{}, column7 interface{}, column8 interface{}, column9 interface{}, column10 interface{}, column11 interface{}, column12 interface{}, column13 interface{}) (Destination, error) {
 	row := q.db.QueryRow(ctx, createDestination,
 		userID,
 		name,
@@ -49,6 +49,11 @@ func (q *Queries) CreateDestination(ctx context.Context, userID uuid.UUID, name
 		column6,
 		column7,
 		column8,
+		column9,
+		column10,
+		column11,
+		column12,
+		column13,
 	)
 	var i Destination
 	err := row.Scan(
@@ -63,6 +68,11 @@ func (q *Queries) CreateDestination(ctx context.Context, userID uuid.UUID, name
 		&i.RetryAttempts,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RetryPolicy,
+		&i.DelayPath,
+		&i.CircuitBreaker,
+		&i.Limits,
+		&i.OrderingKey,
 	)
 	return i, err
 }
@@ -77,7 +87,7 @@ func (q *Queries) DeleteDestination(ctx context.Context, id uuid.UUID) error {
 }
 
 const getDestinationByID = `-- name: GetDestinationByID :one
-SELECT id, user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, created_at, updated_at FROM destinations WHERE id = $1
+SELECT id, user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, created_at, updated_at, retry_policy, delay_path, circuit_breaker, limits, ordering_key FROM destinations WHERE id = $1
 `
 
 func (q *Queries) GetDestinationByID(ctx context.Context, id uuid.UUID) (Destination, error) {
@@ -95,12 +105,17 @@ func (q *Queries) GetDestinationByID(ctx context.Context, id uuid.UUID) (Destina
 		&i.RetryAttempts,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RetryPolicy,
+		&i.DelayPath,
+		&i.CircuitBreaker,
+		&i.Limits,
+		&i.OrderingKey,
 	)
 	return i, err
 }
 
 const getDestinationByName = `-- name: GetDestinationByName :one
-SELECT id, user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, created_at, updated_at FROM destinations WHERE name = $1
+SELECT id, user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, created_at, updated_at, retry_policy, delay_path, circuit_breaker, limits, ordering_key FROM destinations WHERE name = $1
 `
 
 func (q *Queries) GetDestinationByName(ctx context.Context, name string) (Destination, error) {
@@ -118,6 +133,11 @@ func (q *Queries) GetDestinationByName(ctx context.Context, name string) (Destin
 		&i.RetryAttempts,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RetryPolicy,
+		&i.DelayPath,
+		&i.CircuitBreaker,
+		&i.Limits,
+		&i.OrderingKey,
 	)
 	return i, err
 }
@@ -210,12 +230,17 @@ UPDATE destinations SET
     is_active = COALESCE($6, is_active),
     delay_seconds = COALESCE($7, delay_seconds),
     retry_attempts = COALESCE($8, retry_attempts),
+    retry_policy = COALESCE($9, retry_policy),
+    delay_path = COALESCE($10, delay_path),
+    circuit_breaker = COALESCE($11, circuit_breaker),
+    limits = COALESCE($12, limits),
+    ordering_key = COALESCE($13, ordering_key),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, created_at, updated_at
+RETURNING id, user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, created_at, updated_at, retry_policy, delay_path, circuit_breaker, limits, ordering_key
 `
 
-func (q *Queries) UpdateDestination(ctx context.Context, iD uuid.UUID, name string, description string, destinationType DestinationType, config []byte, isActive bool, delaySeconds int32, retryAttempts int32) (Destination, error) {
+func (q *Queries) UpdateDestination(ctx context.Context, iD uuid.UUID, name string, description string, destinationType DestinationType, config []byte, isActive bool, delaySeconds int32, retryAttempts int32, retryPolicy []byte, delayPath string, circuitBreaker []byte, limits []byte, orderingKey string) (Destination, error) {
 	row := q.db.QueryRow(ctx, updateDestination,
 		iD,
 		name,
@@ -225,6 +250,11 @@ func (q *Queries) UpdateDestination(ctx context.Context, iD uuid.UUID, name stri
 		isActive,
 		delaySeconds,
 		retryAttempts,
+		retryPolicy,
+		delayPath,
+		circuitBreaker,
+		limits,
+		orderingKey,
 	)
 	var i Destination
 	err := row.Scan(
@@ -239,6 +269,11 @@ func (q *Queries) UpdateDestination(ctx context.Context, iD uuid.UUID, name stri
 		&i.RetryAttempts,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RetryPolicy,
+		&i.DelayPath,
+		&i.CircuitBreaker,
+		&i.Limits,
+		&i.OrderingKey,
 	)
 	return i, err
 }
@@ -0,0 +1,83 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: event_edits.sql
+
+package generated
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createEventEdit = `-- name: CreateEventEdit :one
+INSERT INTO event_edits (
+    original_event_id, derived_event_id, user_id, mode, destination_id, changes, note
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, original_event_id, derived_event_id, user_id, mode, destination_id, changes, note, created_at
+`
+
+func (q *Queries) CreateEventEdit(ctx context.Context, originalEventID uuid.UUID, derivedEventID uuid.UUID, userID pgtype.UUID, mode EventEditMode, destinationID pgtype.UUID, changes []byte, note string) (EventEdit, error) {
+	row := q.db.QueryRow(ctx, createEventEdit,
+		originalEventID,
+		derivedEventID,
+		userID,
+		mode,
+		destinationID,
+		changes,
+		note,
+	)
+	var i EventEdit
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalEventID,
+		&i.DerivedEventID,
+		&i.UserID,
+		&i.Mode,
+		&i.DestinationID,
+		&i.Changes,
+		&i.Note,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listEventEditsByEvent = `-- name: ListEventEditsByEvent :many
+SELECT id, original_event_id, derived_event_id, user_id, mode, destination_id, changes, note, created_at FROM event_edits
+WHERE original_event_id = $1 OR derived_event_id = $1
+ORDER BY created_at ASC
+`
+
+// Lists the edits an event was derived from or gave rise to, oldest
+// first.
+func (q *Queries) ListEventEditsByEvent(ctx context.Context, originalEventID uuid.UUID) ([]EventEdit, error) {
+	rows, err := q.db.Query(ctx, listEventEditsByEvent, originalEventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []EventEdit{}
+	for rows.Next() {
+		var i EventEdit
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalEventID,
+			&i.DerivedEventID,
+			&i.UserID,
+			&i.Mode,
+			&i.DestinationID,
+			&i.Changes,
+			&i.Note,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
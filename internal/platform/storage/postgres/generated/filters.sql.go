@@ -26,11 +26,11 @@ func (q *Queries) CountFiltersByPipeline(ctx context.Context, pipelineID uuid.UU
 const createFilter = `-- name: CreateFilter :one
 INSERT INTO filters (
     pipeline_id, name, description, filter_type, mode, config, code, execution_order, is_active
-) VALUES ($1, $2, COALESCE($3, ''), $4, COALESCE($5, 'nocode'), COALESCE($6, '{}'::jsonb), $7, COALESCE($8, 1), COALESCE($9, TRUE))
+) VALUES ($1, $2, COALESCE($3, ''), $4, COALESCE($5::filter_mode, 'nocode'), COALESCE($6, '{}'::jsonb), $7, COALESCE($8, 1), COALESCE($9, TRUE))
 RETURNING id, pipeline_id, name, description, filter_type, mode, config, code, is_active, execution_order, created_at, updated_at
 `
 
-func (q *Queries) CreateFilter(ctx context.Context, pipelineID uuid.UUID, name string, column3 interface{}, filterType FilterType, column5 interface{}, column6 interface{}, code pgtype.Text, column8 interface{}, column9 interface{}) (Filter, error) {
+func (q *Queries) CreateFilter(ctx context.Context, pipelineID uuid.UUID, name string, column3 interface{}, filterType FilterType, column5 FilterMode, column6 interface{}, code pgtype.Text, column8 interface{}, column9 interface{}) (Filter, error) {
 	row := q.db.QueryRow(ctx, createFilter,
 		pipelineID,
 		name,
@@ -0,0 +1,311 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: lookup_tables.sql
+
+package generated
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countLookupEntries = `-- name: CountLookupEntries :one
+SELECT COUNT(*) FROM lookup_entries
+WHERE table_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+`
+
+func (q *Queries) CountLookupEntries(ctx context.Context, tableID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countLookupEntries, tableID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createLookupTable = `-- name: CreateLookupTable :one
+INSERT INTO lookup_tables (
+    user_id, name, description, ttl_seconds
+) VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, name, description, ttl_seconds, created_at, updated_at
+`
+
+func (q *Queries) CreateLookupTable(ctx context.Context, userID uuid.UUID, name string, description pgtype.Text, ttlSeconds pgtype.Int4) (LookupTable, error) {
+	row := q.db.QueryRow(ctx, createLookupTable,
+		userID,
+		name,
+		description,
+		ttlSeconds,
+	)
+	var i LookupTable
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.TtlSeconds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteLookupEntriesByTable = `-- name: DeleteLookupEntriesByTable :exec
+DELETE FROM lookup_entries WHERE table_id = $1
+`
+
+func (q *Queries) DeleteLookupEntriesByTable(ctx context.Context, tableID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteLookupEntriesByTable, tableID)
+	return err
+}
+
+const deleteLookupEntry = `-- name: DeleteLookupEntry :execrows
+DELETE FROM lookup_entries
+WHERE table_id = $1 AND key = $2
+`
+
+func (q *Queries) DeleteLookupEntry(ctx context.Context, tableID uuid.UUID, key string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteLookupEntry, tableID, key)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteLookupTable = `-- name: DeleteLookupTable :exec
+DELETE FROM lookup_tables WHERE id = $1
+`
+
+func (q *Queries) DeleteLookupTable(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteLookupTable, id)
+	return err
+}
+
+const getLookupEntry = `-- name: GetLookupEntry :one
+SELECT table_id, key, value, expires_at, created_at, updated_at FROM lookup_entries
+WHERE table_id = $1 AND key = $2 AND (expires_at IS NULL OR expires_at > NOW())
+`
+
+func (q *Queries) GetLookupEntry(ctx context.Context, tableID uuid.UUID, key string) (LookupEntry, error) {
+	row := q.db.QueryRow(ctx, getLookupEntry, tableID, key)
+	var i LookupEntry
+	err := row.Scan(
+		&i.TableID,
+		&i.Key,
+		&i.Value,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLookupTableByID = `-- name: GetLookupTableByID :one
+SELECT id, user_id, name, description, ttl_seconds, created_at, updated_at FROM lookup_tables
+WHERE id = $1 AND user_id = $2
+`
+
+func (q *Queries) GetLookupTableByID(ctx context.Context, iD uuid.UUID, userID uuid.UUID) (LookupTable, error) {
+	row := q.db.QueryRow(ctx, getLookupTableByID, iD, userID)
+	var i LookupTable
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.TtlSeconds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLookupTableByName = `-- name: GetLookupTableByName :one
+SELECT id, user_id, name, description, ttl_seconds, created_at, updated_at FROM lookup_tables
+WHERE user_id = $1 AND name = $2
+`
+
+func (q *Queries) GetLookupTableByName(ctx context.Context, userID uuid.UUID, name string) (LookupTable, error) {
+	row := q.db.QueryRow(ctx, getLookupTableByName, userID, name)
+	var i LookupTable
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.TtlSeconds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listAllLookupEntries = `-- name: ListAllLookupEntries :many
+SELECT table_id, key, value, expires_at, created_at, updated_at FROM lookup_entries
+WHERE table_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+`
+
+func (q *Queries) ListAllLookupEntries(ctx context.Context, tableID uuid.UUID) ([]LookupEntry, error) {
+	rows, err := q.db.Query(ctx, listAllLookupEntries, tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LookupEntry{}
+	for rows.Next() {
+		var i LookupEntry
+		if err := rows.Scan(
+			&i.TableID,
+			&i.Key,
+			&i.Value,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLookupEntries = `-- name: ListLookupEntries :many
+SELECT table_id, key, value, expires_at, created_at, updated_at FROM lookup_entries
+WHERE table_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY key ASC
+LIMIT $2 OFFSET $3
+`
+
+func (q *Queries) ListLookupEntries(ctx context.Context, tableID uuid.UUID, limit int32, offset int32) ([]LookupEntry, error) {
+	rows, err := q.db.Query(ctx, listLookupEntries, tableID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LookupEntry{}
+	for rows.Next() {
+		var i LookupEntry
+		if err := rows.Scan(
+			&i.TableID,
+			&i.Key,
+			&i.Value,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLookupTablesByUser = `-- name: ListLookupTablesByUser :many
+SELECT id, user_id, name, description, ttl_seconds, created_at, updated_at FROM lookup_tables
+WHERE user_id = $1
+ORDER BY name ASC
+`
+
+func (q *Queries) ListLookupTablesByUser(ctx context.Context, userID uuid.UUID) ([]LookupTable, error) {
+	rows, err := q.db.Query(ctx, listLookupTablesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LookupTable{}
+	for rows.Next() {
+		var i LookupTable
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.TtlSeconds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const touchLookupTable = `-- name: TouchLookupTable :exec
+UPDATE lookup_tables SET updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) TouchLookupTable(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchLookupTable, id)
+	return err
+}
+
+const updateLookupTable = `-- name: UpdateLookupTable :one
+UPDATE lookup_tables SET
+    name = $2,
+    description = $3,
+    ttl_seconds = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, name, description, ttl_seconds, created_at, updated_at
+`
+
+func (q *Queries) UpdateLookupTable(ctx context.Context, iD uuid.UUID, name string, description pgtype.Text, ttlSeconds pgtype.Int4) (LookupTable, error) {
+	row := q.db.QueryRow(ctx, updateLookupTable,
+		iD,
+		name,
+		description,
+		ttlSeconds,
+	)
+	var i LookupTable
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.TtlSeconds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertLookupEntry = `-- name: UpsertLookupEntry :one
+INSERT INTO lookup_entries (
+    table_id, key, value, expires_at
+) VALUES ($1, $2, $3, $4)
+ON CONFLICT (table_id, key) DO UPDATE SET
+    value = EXCLUDED.value,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = NOW()
+RETURNING table_id, key, value, expires_at, created_at, updated_at
+`
+
+func (q *Queries) UpsertLookupEntry(ctx context.Context, tableID uuid.UUID, key string, value []byte, expiresAt pgtype.Timestamptz) (LookupEntry, error) {
+	row := q.db.QueryRow(ctx, upsertLookupEntry,
+		tableID,
+		key,
+		value,
+		expiresAt,
+	)
+	var i LookupEntry
+	err := row.Scan(
+		&i.TableID,
+		&i.Key,
+		&i.Value,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -57,6 +57,49 @@ func (ns NullAuthType) Value() (driver.Value, error) {
 	return string(ns.AuthType), nil
 }
 
+type DeadLetterStatus string
+
+const (
+	DeadLetterStatusDead      DeadLetterStatus = "dead"
+	DeadLetterStatusRedriven  DeadLetterStatus = "redriven"
+	DeadLetterStatusDiscarded DeadLetterStatus = "discarded"
+)
+
+func (e *DeadLetterStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DeadLetterStatus(s)
+	case string:
+		*e = DeadLetterStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DeadLetterStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDeadLetterStatus struct {
+	DeadLetterStatus DeadLetterStatus `json:"dead_letter_status"`
+	Valid            bool             `json:"valid"` // Valid is true if DeadLetterStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDeadLetterStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DeadLetterStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DeadLetterStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDeadLetterStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DeadLetterStatus), nil
+}
+
 type DeliveryStatus string
 
 const (
@@ -147,6 +190,48 @@ func (ns NullDestinationType) Value() (driver.Value, error) {
 	return string(ns.DestinationType), nil
 }
 
+type EventEditMode string
+
+const (
+	EventEditModePipeline    EventEditMode = "pipeline"
+	EventEditModeDestination EventEditMode = "destination"
+)
+
+func (e *EventEditMode) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EventEditMode(s)
+	case string:
+		*e = EventEditMode(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EventEditMode: %T", src)
+	}
+	return nil
+}
+
+type NullEventEditMode struct {
+	EventEditMode EventEditMode `json:"event_edit_mode"`
+	Valid         bool          `json:"valid"` // Valid is true if EventEditMode is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEventEditMode) Scan(value interface{}) error {
+	if value == nil {
+		ns.EventEditMode, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EventEditMode.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEventEditMode) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EventEditMode), nil
+}
+
 type FilterMode string
 
 const (
@@ -196,6 +281,7 @@ const (
 	FilterTypeJavascript FilterType = "javascript"
 	FilterTypeJsonpath   FilterType = "jsonpath"
 	FilterTypeRegex      FilterType = "regex"
+	FilterTypeWasm       FilterType = "wasm"
 )
 
 func (e *FilterType) Scan(src interface{}) error {
@@ -277,6 +363,93 @@ func (ns NullProtocolType) Value() (driver.Value, error) {
 	return string(ns.ProtocolType), nil
 }
 
+type ReplayMode string
+
+const (
+	ReplayModePipeline ReplayMode = "pipeline"
+	ReplayModeDelivery ReplayMode = "delivery"
+)
+
+func (e *ReplayMode) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReplayMode(s)
+	case string:
+		*e = ReplayMode(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReplayMode: %T", src)
+	}
+	return nil
+}
+
+type NullReplayMode struct {
+	ReplayMode ReplayMode `json:"replay_mode"`
+	Valid      bool       `json:"valid"` // Valid is true if ReplayMode is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReplayMode) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReplayMode, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReplayMode.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReplayMode) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReplayMode), nil
+}
+
+type ReplayStatus string
+
+const (
+	ReplayStatusPending   ReplayStatus = "pending"
+	ReplayStatusRunning   ReplayStatus = "running"
+	ReplayStatusCompleted ReplayStatus = "completed"
+	ReplayStatusCancelled ReplayStatus = "cancelled"
+	ReplayStatusFailed    ReplayStatus = "failed"
+)
+
+func (e *ReplayStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReplayStatus(s)
+	case string:
+		*e = ReplayStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReplayStatus: %T", src)
+	}
+	return nil
+}
+
+type NullReplayStatus struct {
+	ReplayStatus ReplayStatus `json:"replay_status"`
+	Valid        bool         `json:"valid"` // Valid is true if ReplayStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReplayStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReplayStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReplayStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReplayStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReplayStatus), nil
+}
+
 type StepStatus string
 
 const (
@@ -328,6 +501,7 @@ const (
 	StepTypeFilter         StepType = "filter"
 	StepTypeTransformation StepType = "transformation"
 	StepTypeDelivery       StepType = "delivery"
+	StepTypeReplay         StepType = "replay"
 )
 
 func (e *StepType) Scan(src interface{}) error {
@@ -420,6 +594,11 @@ const (
 	TransformationTypeFormatXml    TransformationType = "format_xml"
 	TransformationTypeJavascript   TransformationType = "javascript"
 	TransformationTypeJsonpath     TransformationType = "jsonpath"
+	TransformationTypeWasm         TransformationType = "wasm"
+	TransformationTypeTemplate     TransformationType = "template"
+	TransformationTypeJsonPatch    TransformationType = "json_patch"
+	TransformationTypeMergePatch   TransformationType = "merge_patch"
+	TransformationTypeEnrich       TransformationType = "enrich"
 )
 
 func (e *TransformationType) Scan(src interface{}) error {
@@ -509,6 +688,7 @@ const (
 	WebhookStatusDelayed     WebhookStatus = "delayed"
 	WebhookStatusDelivered   WebhookStatus = "delivered"
 	WebhookStatusFailed      WebhookStatus = "failed"
+	WebhookStatusCancelled   WebhookStatus = "cancelled"
 )
 
 func (e *WebhookStatus) Scan(src interface{}) error {
@@ -546,6 +726,22 @@ func (ns NullWebhookStatus) Value() (driver.Value, error) {
 	return string(ns.WebhookStatus), nil
 }
 
+type DeadLetter struct {
+	ID                uuid.UUID          `db:"id" json:"id"`
+	DeliveryID        uuid.UUID          `db:"delivery_id" json:"delivery_id"`
+	WebhookEventID    uuid.UUID          `db:"webhook_event_id" json:"webhook_event_id"`
+	DestinationID     uuid.UUID          `db:"destination_id" json:"destination_id"`
+	PipelineID        pgtype.UUID        `db:"pipeline_id" json:"pipeline_id"`
+	Reason            string             `db:"reason" json:"reason"`
+	Attempts          int32              `db:"attempts" json:"attempts"`
+	LastResponse      []byte             `db:"last_response" json:"last_response"`
+	Status            DeadLetterStatus   `db:"status" json:"status"`
+	RedriveDeliveryID pgtype.UUID        `db:"redrive_delivery_id" json:"redrive_delivery_id"`
+	ResolvedAt        pgtype.Timestamptz `db:"resolved_at" json:"resolved_at"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
 type Delivery struct {
 	ID             uuid.UUID          `db:"id" json:"id"`
 	WebhookEventID uuid.UUID          `db:"webhook_event_id" json:"webhook_event_id"`
@@ -557,6 +753,7 @@ type Delivery struct {
 	ScheduledAt    pgtype.Timestamptz `db:"scheduled_at" json:"scheduled_at"`
 	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ReplayJobID    pgtype.UUID        `db:"replay_job_id" json:"replay_job_id"`
 }
 
 type Destination struct {
@@ -571,6 +768,23 @@ type Destination struct {
 	RetryAttempts   int32              `db:"retry_attempts" json:"retry_attempts"`
 	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	RetryPolicy     []byte             `db:"retry_policy" json:"retry_policy"`
+	DelayPath       string             `db:"delay_path" json:"delay_path"`
+	CircuitBreaker  []byte             `db:"circuit_breaker" json:"circuit_breaker"`
+	Limits          []byte             `db:"limits" json:"limits"`
+	OrderingKey     string             `db:"ordering_key" json:"ordering_key"`
+}
+
+type EventEdit struct {
+	ID              uuid.UUID          `db:"id" json:"id"`
+	OriginalEventID uuid.UUID          `db:"original_event_id" json:"original_event_id"`
+	DerivedEventID  uuid.UUID          `db:"derived_event_id" json:"derived_event_id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Mode            EventEditMode      `db:"mode" json:"mode"`
+	DestinationID   pgtype.UUID        `db:"destination_id" json:"destination_id"`
+	Changes         []byte             `db:"changes" json:"changes"`
+	Note            string             `db:"note" json:"note"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
 type Filter struct {
@@ -588,6 +802,25 @@ type Filter struct {
 	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
+type LookupEntry struct {
+	TableID   uuid.UUID          `db:"table_id" json:"table_id"`
+	Key       string             `db:"key" json:"key"`
+	Value     []byte             `db:"value" json:"value"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type LookupTable struct {
+	ID          uuid.UUID          `db:"id" json:"id"`
+	UserID      uuid.UUID          `db:"user_id" json:"user_id"`
+	Name        string             `db:"name" json:"name"`
+	Description pgtype.Text        `db:"description" json:"description"`
+	TtlSeconds  pgtype.Int4        `db:"ttl_seconds" json:"ttl_seconds"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
 type Pipeline struct {
 	ID             uuid.UUID          `db:"id" json:"id"`
 	UserID         uuid.UUID          `db:"user_id" json:"user_id"`
@@ -599,6 +832,29 @@ type Pipeline struct {
 	ExecutionOrder int32              `db:"execution_order" json:"execution_order"`
 	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	OrderingKey    string             `db:"ordering_key" json:"ordering_key"`
+}
+
+type ReplayJob struct {
+	ID              uuid.UUID          `db:"id" json:"id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Mode            ReplayMode         `db:"mode" json:"mode"`
+	Query           []byte             `db:"query" json:"query"`
+	RatePerSecond   float64            `db:"rate_per_second" json:"rate_per_second"`
+	Status          ReplayStatus       `db:"status" json:"status"`
+	Total           int32              `db:"total" json:"total"`
+	Processed       int32              `db:"processed" json:"processed"`
+	Succeeded       int32              `db:"succeeded" json:"succeeded"`
+	Failed          int32              `db:"failed" json:"failed"`
+	Skipped         int32              `db:"skipped" json:"skipped"`
+	CursorCreatedAt pgtype.Timestamptz `db:"cursor_created_at" json:"cursor_created_at"`
+	CursorEventID   pgtype.UUID        `db:"cursor_event_id" json:"cursor_event_id"`
+	ErrorMessage    pgtype.Text        `db:"error_message" json:"error_message"`
+	LeaseExpiresAt  pgtype.Timestamptz `db:"lease_expires_at" json:"lease_expires_at"`
+	StartedAt       pgtype.Timestamptz `db:"started_at" json:"started_at"`
+	CompletedAt     pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
 type Source struct {
@@ -656,6 +912,9 @@ type WebhookEvent struct {
 	ProcessedAt           pgtype.Timestamptz `db:"processed_at" json:"processed_at"`
 	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt             pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	OrderingKey           pgtype.Text        `db:"ordering_key" json:"ordering_key"`
+	ReplayJobID           pgtype.UUID        `db:"replay_job_id" json:"replay_job_id"`
+	DerivedFromID         pgtype.UUID        `db:"derived_from_id" json:"derived_from_id"`
 }
 
 type WebhookStep struct {
@@ -27,7 +27,7 @@ const createPipeline = `-- name: CreatePipeline :one
 INSERT INTO pipelines (
     user_id, source_id, destination_id, name, description, is_active, execution_order
 ) VALUES ($1, $2, $3, $4, COALESCE($5, ''), COALESCE($6, TRUE), COALESCE($7, 1))
-RETURNING id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at
+RETURNING id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at, ordering_key
 `
 
 func (q *Queries) CreatePipeline(ctx context.Context, userID uuid.UUID, sourceID uuid.UUID, destinationID uuid.UUID, name string, column5 interface{}, column6 interface{}, column7 interface{}) (Pipeline, error) {
@@ -52,6 +52,7 @@ func (q *Queries) CreatePipeline(ctx context.Context, userID uuid.UUID, sourceID
 		&i.ExecutionOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
 	)
 	return i, err
 }
@@ -66,7 +67,7 @@ func (q *Queries) DeletePipeline(ctx context.Context, id uuid.UUID) error {
 }
 
 const getPipelineByID = `-- name: GetPipelineByID :one
-SELECT id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at FROM pipelines WHERE id = $1
+SELECT id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at, ordering_key FROM pipelines WHERE id = $1
 `
 
 func (q *Queries) GetPipelineByID(ctx context.Context, id uuid.UUID) (Pipeline, error) {
@@ -83,13 +84,14 @@ func (q *Queries) GetPipelineByID(ctx context.Context, id uuid.UUID) (Pipeline,
 		&i.ExecutionOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
 	)
 	return i, err
 }
 
 const getPipelineWithDetails = `-- name: GetPipelineWithDetails :one
 SELECT 
-    p.id, p.user_id, p.source_id, p.destination_id, p.name, p.description, p.is_active, p.execution_order, p.created_at, p.updated_at,
+    p.id, p.user_id, p.source_id, p.destination_id, p.name, p.description, p.is_active, p.execution_order, p.created_at, p.updated_at, p.ordering_key,
     s.name as source_name,
     d.name as destination_name,
     d.destination_type
@@ -110,6 +112,7 @@ type GetPipelineWithDetailsRow struct {
 	ExecutionOrder  int32              `db:"execution_order" json:"execution_order"`
 	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	OrderingKey     string             `db:"ordering_key" json:"ordering_key"`
 	SourceName      string             `db:"source_name" json:"source_name"`
 	DestinationName string             `db:"destination_name" json:"destination_name"`
 	DestinationType DestinationType    `db:"destination_type" json:"destination_type"`
@@ -129,6 +132,7 @@ func (q *Queries) GetPipelineWithDetails(ctx context.Context, id uuid.UUID) (Get
 		&i.ExecutionOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
 		&i.SourceName,
 		&i.DestinationName,
 		&i.DestinationType,
@@ -137,7 +141,7 @@ func (q *Queries) GetPipelineWithDetails(ctx context.Context, id uuid.UUID) (Get
 }
 
 const listActivePipelinesBySource = `-- name: ListActivePipelinesBySource :many
-SELECT id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at FROM pipelines 
+SELECT id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at, ordering_key FROM pipelines 
 WHERE source_id = $1 AND is_active = TRUE 
 ORDER BY execution_order ASC
 `
@@ -162,6 +166,7 @@ func (q *Queries) ListActivePipelinesBySource(ctx context.Context, sourceID uuid
 			&i.ExecutionOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrderingKey,
 		); err != nil {
 			return nil, err
 		}
@@ -174,7 +179,7 @@ func (q *Queries) ListActivePipelinesBySource(ctx context.Context, sourceID uuid
 }
 
 const listPipelinesBySourceAndDestination = `-- name: ListPipelinesBySourceAndDestination :many
-SELECT id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at FROM pipelines 
+SELECT id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at, ordering_key FROM pipelines 
 WHERE source_id = $1 AND destination_id = $2 
 ORDER BY execution_order ASC
 `
@@ -199,6 +204,7 @@ func (q *Queries) ListPipelinesBySourceAndDestination(ctx context.Context, sourc
 			&i.ExecutionOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrderingKey,
 		); err != nil {
 			return nil, err
 		}
@@ -211,7 +217,7 @@ func (q *Queries) ListPipelinesBySourceAndDestination(ctx context.Context, sourc
 }
 
 const listPipelinesByUser = `-- name: ListPipelinesByUser :many
-SELECT id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at FROM pipelines 
+SELECT id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at, ordering_key FROM pipelines 
 WHERE user_id = $1 
 ORDER BY execution_order ASC, created_at DESC
 `
@@ -236,6 +242,7 @@ func (q *Queries) ListPipelinesByUser(ctx context.Context, userID uuid.UUID) ([]
 			&i.ExecutionOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrderingKey,
 		); err != nil {
 			return nil, err
 		}
@@ -255,7 +262,7 @@ UPDATE pipelines SET
     execution_order = COALESCE($5, execution_order),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at
+RETURNING id, user_id, source_id, destination_id, name, description, is_active, execution_order, created_at, updated_at, ordering_key
 `
 
 func (q *Queries) UpdatePipeline(ctx context.Context, iD uuid.UUID, name string, description string, isActive bool, executionOrder int32) (Pipeline, error) {
@@ -278,6 +285,7 @@ func (q *Queries) UpdatePipeline(ctx context.Context, iD uuid.UUID, name string,
 		&i.ExecutionOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
 	)
 	return i, err
 }
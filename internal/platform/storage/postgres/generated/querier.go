@@ -12,34 +12,64 @@ import (
 )
 
 type Querier interface {
+	CancelDelayedWebhookEvent(ctx context.Context, id uuid.UUID) (int64, error)
+	CancelReplayJob(ctx context.Context, id uuid.UUID) (int64, error)
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
+	// Claims the deliveries whose retry is due by pushing scheduled_at past a
+	// lease, so that concurrent schedulers skip them. A delivery whose attempt
+	// never completes is claimed again once the lease expires.
+	ClaimDueDeliveries(ctx context.Context, limit int32, column2 float64) ([]Delivery, error)
+	// Claims the delayed events that are due by pushing scheduled_at past a
+	// lease, so that concurrent schedulers skip them. An event whose delivery
+	// never starts is claimed again once the lease expires.
+	ClaimDueWebhookEvents(ctx context.Context, limit int32, column2 float64) ([]WebhookEvent, error)
+	// Claims the oldest pending job, or a running job whose worker stopped
+	// renewing its lease, and leases it to the caller.
+	ClaimReplayJob(ctx context.Context, dollar_1 float64) (ReplayJob, error)
+	CountDeadLetters(ctx context.Context, column1 uuid.UUID, column2 uuid.UUID, column3 string) (int64, error)
 	CountDestinations(ctx context.Context, column1 interface{}, column2 interface{}, column3 interface{}, isActive bool) (int64, error)
 	CountFiltersByPipeline(ctx context.Context, pipelineID uuid.UUID) (int64, error)
+	CountLookupEntries(ctx context.Context, tableID uuid.UUID) (int64, error)
 	CountPipelinesByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountReplayJobs(ctx context.Context, dollar_1 string) (int64, error)
+	CountReplayWebhookEvents(ctx context.Context, column1 uuid.UUID, column2 uuid.UUID, column3 string, column4 pgtype.Timestamptz, column5 pgtype.Timestamptz) (int64, error)
 	CountSources(ctx context.Context) (int64, error)
 	CountTransformationsByPipeline(ctx context.Context, pipelineID uuid.UUID) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
 	CountWebhookEventsByStatus(ctx context.Context, status WebhookStatus) (int64, error)
-	CreateDelivery(ctx context.Context, webhookEventID uuid.UUID, destinationID uuid.UUID, status DeliveryStatus, responseCode pgtype.Int4, column5 interface{}, lastError pgtype.Text, scheduledAt pgtype.Timestamptz) (Delivery, error)
-	CreateDestination(ctx context.Context, userID uuid.UUID, name string, description string, destinationType DestinationType, column5 interface{}, column6 interface{}, column7 interface{}, column8 interface{}) (Destination, error)
-	CreateFilter(ctx context.Context, pipelineID uuid.UUID, name string, column3 interface{}, filterType FilterType, column5 interface{}, column6 interface{}, code pgtype.Text, column8 interface{}, column9 interface{}) (Filter, error)
+	CreateDeadLetter(ctx context.Context, deliveryID uuid.UUID, webhookEventID uuid.UUID, destinationID uuid.UUID, pipelineID pgtype.UUID, reason string, attempts int32, lastResponse []byte) (DeadLetter, error)
+	CreateDelivery(ctx context.Context, webhookEventID uuid.UUID, destinationID uuid.UUID, status DeliveryStatus, responseCode pgtype.Int4, column5 interface{}, lastError pgtype.Text, scheduledAt pgtype.Timestamptz, replayJobID pgtype.UUID) (Delivery, error)
+	// Creates an event derived from another one. Its original payload is the
+	// edited payload, which a replay starts again from.
+	CreateDerivedWebhookEvent(ctx context.Context, sourceID uuid.UUID, pipelineID pgtype.UUID, payload []byte, metadata []byte, status WebhookStatus, derivedFromID pgtype.UUID) (WebhookEvent, error)
+	CreateDestination(ctx context.Context, userID uuid.UUID, name string, description string, destinationType DestinationType, column5 interface{}, column6 interface{}, column7 interface{}, column8 interface{}, column9 interface{}, column10 interface{}, column11 interface{}, column12 interface{}, column13 interface{}) (Destination, error)
+	CreateEventEdit(ctx context.Context, originalEventID uuid.UUID, derivedEventID uuid.UUID, userID pgtype.UUID, mode EventEditMode, destinationID pgtype.UUID, changes []byte, note string) (EventEdit, error)
+	CreateFilter(ctx context.Context, pipelineID uuid.UUID, name string, column3 interface{}, filterType FilterType, column5 FilterMode, column6 interface{}, code pgtype.Text, column8 interface{}, column9 interface{}) (Filter, error)
+	CreateLookupTable(ctx context.Context, userID uuid.UUID, name string, description pgtype.Text, ttlSeconds pgtype.Int4) (LookupTable, error)
 	CreatePipeline(ctx context.Context, userID uuid.UUID, sourceID uuid.UUID, destinationID uuid.UUID, name string, column5 interface{}, column6 interface{}, column7 interface{}) (Pipeline, error)
+	CreateReplayJob(ctx context.Context, userID pgtype.UUID, mode ReplayMode, query []byte, ratePerSecond float64, total int32) (ReplayJob, error)
 	CreateSource(ctx context.Context, name string, userID uuid.UUID, description string, protocol ProtocolType, authType AuthType, authConfig []byte, column7 interface{}) (Source, error)
-	CreateTransformation(ctx context.Context, pipelineID uuid.UUID, name string, column3 interface{}, transformationType TransformationType, column5 interface{}, column6 interface{}, code pgtype.Text, column8 interface{}, column9 interface{}) (Transformation, error)
+	CreateTransformation(ctx context.Context, pipelineID uuid.UUID, name string, column3 interface{}, transformationType TransformationType, column5 TransformationMode, column6 interface{}, code pgtype.Text, column8 interface{}, column9 interface{}) (Transformation, error)
 	CreateUser(ctx context.Context, email string, role UserRole, passwordHash string, firstName string, lastName string, isActive bool) (User, error)
+	CreateWasmModule(ctx context.Context, userID uuid.UUID, name string, hash string, sizeBytes int32, content []byte) (CreateWasmModuleRow, error)
 	CreateWebhookEvent(ctx context.Context, sourceID uuid.UUID, pipelineID pgtype.UUID, payload []byte, originalPayload []byte, column5 interface{}, column6 interface{}, scheduledAt pgtype.Timestamptz) (WebhookEvent, error)
-	CreateWebhookStep(ctx context.Context, webhookEventID uuid.UUID, pipelineID pgtype.UUID, stepType StepType, stepName string, stepID pgtype.UUID, executionOrder int32, column7 interface{}, column8 interface{}, column9 interface{}, errorMessage pgtype.Text, durationMs pgtype.Int4, column12 interface{}, completedAt pgtype.Timestamptz) (WebhookStep, error)
+	CreateWebhookStep(ctx context.Context, webhookEventID uuid.UUID, pipelineID pgtype.UUID, stepType StepType, stepName string, stepID pgtype.UUID, executionOrder int32, column7 StepStatus, column8 interface{}, column9 interface{}, errorMessage pgtype.Text, durationMs pgtype.Int4, column12 interface{}, completedAt pgtype.Timestamptz) (WebhookStep, error)
 	DeactivateUser(ctx context.Context, id uuid.UUID) error
 	DeleteDelivery(ctx context.Context, id uuid.UUID) error
 	DeleteDestination(ctx context.Context, id uuid.UUID) error
 	DeleteFilter(ctx context.Context, id uuid.UUID) error
+	DeleteLookupEntriesByTable(ctx context.Context, tableID uuid.UUID) error
+	DeleteLookupEntry(ctx context.Context, tableID uuid.UUID, key string) (int64, error)
+	DeleteLookupTable(ctx context.Context, id uuid.UUID) error
 	DeletePipeline(ctx context.Context, id uuid.UUID) error
 	DeleteSource(ctx context.Context, id uuid.UUID) error
 	DeleteTransformation(ctx context.Context, id uuid.UUID) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 	DeleteWebhookEvent(ctx context.Context, id uuid.UUID) error
 	DeleteWebhookStep(ctx context.Context, id uuid.UUID) error
+	FinishReplayJob(ctx context.Context, iD uuid.UUID, status ReplayStatus, errorMessage pgtype.Text) (int64, error)
 	GetBodyTransformations(ctx context.Context, pipelineID uuid.UUID) ([]Transformation, error)
+	GetDeadLetterByID(ctx context.Context, id uuid.UUID) (DeadLetter, error)
 	GetDeliveryByID(ctx context.Context, id uuid.UUID) (Delivery, error)
 	GetDestinationByID(ctx context.Context, id uuid.UUID) (Destination, error)
 	GetDestinationByName(ctx context.Context, name string) (Destination, error)
@@ -47,8 +77,13 @@ type Querier interface {
 	GetFilterByID(ctx context.Context, id uuid.UUID) (Filter, error)
 	GetFiltersByType(ctx context.Context, pipelineID uuid.UUID, filterType FilterType) ([]Filter, error)
 	GetHeaderTransformations(ctx context.Context, pipelineID uuid.UUID) ([]Transformation, error)
+	GetLookupEntry(ctx context.Context, tableID uuid.UUID, key string) (LookupEntry, error)
+	GetLookupTableByID(ctx context.Context, iD uuid.UUID, userID uuid.UUID) (LookupTable, error)
+	GetLookupTableByName(ctx context.Context, userID uuid.UUID, name string) (LookupTable, error)
+	GetNextWebhookStepOrder(ctx context.Context, webhookEventID uuid.UUID) (int32, error)
 	GetPipelineByID(ctx context.Context, id uuid.UUID) (Pipeline, error)
 	GetPipelineWithDetails(ctx context.Context, id uuid.UUID) (GetPipelineWithDetailsRow, error)
+	GetReplayJobByID(ctx context.Context, id uuid.UUID) (ReplayJob, error)
 	GetSourceByID(ctx context.Context, id uuid.UUID) (Source, error)
 	GetSourceByName(ctx context.Context, name string) (Source, error)
 	GetTransformationByID(ctx context.Context, id uuid.UUID) (Transformation, error)
@@ -56,7 +91,13 @@ type Querier interface {
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByEmailWithPassword(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
+	GetWasmModuleByHash(ctx context.Context, userID uuid.UUID, hash string) (GetWasmModuleByHashRow, error)
+	GetWasmModuleContent(ctx context.Context, hash string) ([]byte, error)
 	GetWebhookEventByID(ctx context.Context, id uuid.UUID) (WebhookEvent, error)
+	// Returns the earliest event ingested before the given one that shares its
+	// ordering key and is not settled yet, along with when it is next due:
+	// the end of its delay or its next retry, if any.
+	GetWebhookEventOrderingPredecessor(ctx context.Context, id uuid.UUID) (GetWebhookEventOrderingPredecessorRow, error)
 	GetWebhookEventWithDetails(ctx context.Context, id uuid.UUID) (GetWebhookEventWithDetailsRow, error)
 	GetWebhookEventWithPipeline(ctx context.Context, id uuid.UUID) (GetWebhookEventWithPipelineRow, error)
 	GetWebhookStepByID(ctx context.Context, id uuid.UUID) (WebhookStep, error)
@@ -64,35 +105,80 @@ type Querier interface {
 	ListActiveFiltersByPipeline(ctx context.Context, pipelineID uuid.UUID) ([]Filter, error)
 	ListActivePipelinesBySource(ctx context.Context, sourceID uuid.UUID) ([]Pipeline, error)
 	ListActiveTransformationsByPipeline(ctx context.Context, pipelineID uuid.UUID) ([]Transformation, error)
+	ListAllLookupEntries(ctx context.Context, tableID uuid.UUID) ([]LookupEntry, error)
+	// Lists the dead letters of a destination, a pipeline or both, a nil UUID
+	// leaving the scope open, and optionally of one status.
+	ListDeadLetters(ctx context.Context, column1 uuid.UUID, column2 uuid.UUID, column3 string, limit int32, offset int32) ([]DeadLetter, error)
+	ListDeadLettersByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]DeadLetter, error)
 	ListDeliveriesByWebhookEvent(ctx context.Context, webhookEventID uuid.UUID) ([]Delivery, error)
 	ListDestinations(ctx context.Context, column1 interface{}, column2 interface{}, column3 interface{}, isActive bool, column5 interface{}, column6 interface{}, limit int32, offset int32) ([]ListDestinationsRow, error)
-	ListFailedWebhookEvents(ctx context.Context) ([]WebhookEvent, error)
+	// Lists the edits an event was derived from or gave rise to, oldest
+	// first.
+	ListEventEditsByEvent(ctx context.Context, originalEventID uuid.UUID) ([]EventEdit, error)
 	ListFiltersByPipeline(ctx context.Context, pipelineID uuid.UUID) ([]Filter, error)
+	ListLookupEntries(ctx context.Context, tableID uuid.UUID, limit int32, offset int32) ([]LookupEntry, error)
+	ListLookupTablesByUser(ctx context.Context, userID uuid.UUID) ([]LookupTable, error)
 	ListPendingWebhookEvents(ctx context.Context, limit int32) ([]WebhookEvent, error)
 	ListPipelinesBySourceAndDestination(ctx context.Context, sourceID uuid.UUID, destinationID uuid.UUID) ([]Pipeline, error)
 	ListPipelinesByUser(ctx context.Context, userID uuid.UUID) ([]Pipeline, error)
+	ListReplayJobs(ctx context.Context, column1 string, limit int32, offset int32) ([]ReplayJob, error)
+	// Lists the events matching a replay query after the cursor, in ingestion
+	// order. A nil UUID, an empty status or a NULL time leaves the matching
+	// criterion open.
+	ListReplayWebhookEvents(ctx context.Context, column1 uuid.UUID, column2 uuid.UUID, column3 string, column4 pgtype.Timestamptz, column5 pgtype.Timestamptz, column6 pgtype.Timestamptz, column7 uuid.UUID, limit int32) ([]ListReplayWebhookEventsRow, error)
 	ListSources(ctx context.Context, limit int32, offset int32) ([]Source, error)
 	ListTransformationsByPipeline(ctx context.Context, pipelineID uuid.UUID) ([]Transformation, error)
 	ListUsers(ctx context.Context, limit int32, offset int32) ([]User, error)
+	ListWasmModulesByUser(ctx context.Context, userID uuid.UUID) ([]ListWasmModulesByUserRow, error)
 	ListWebhookEventsByPipeline(ctx context.Context, pipelineID pgtype.UUID) ([]WebhookEvent, error)
 	ListWebhookEventsBySource(ctx context.Context, sourceID uuid.UUID) ([]WebhookEvent, error)
 	ListWebhookEventsBySourceAndStatus(ctx context.Context, sourceID uuid.UUID, status WebhookStatus) ([]WebhookEvent, error)
 	ListWebhookStepsByEvent(ctx context.Context, webhookEventID uuid.UUID) ([]WebhookStep, error)
 	ListWebhookStepsByEventAndType(ctx context.Context, webhookEventID uuid.UUID, stepType StepType) ([]WebhookStep, error)
+	// Locks the transformations of a pipeline while they are reordered, so
+	// that none is updated or deleted in between.
+	LockTransformationsByPipeline(ctx context.Context, pipelineID uuid.UUID) ([]uuid.UUID, error)
+	// Stores the outcome of an attempt. Unlike UpdateDelivery, a missing
+	// response code, error or retry time clears the one of the previous
+	// attempt.
+	RecordDeliveryAttempt(ctx context.Context, iD uuid.UUID, status DeliveryStatus, responseCode pgtype.Int4, attempt pgtype.Int4, lastError pgtype.Text, scheduledAt pgtype.Timestamptz) (Delivery, error)
 	ReorderFilters(ctx context.Context, iD uuid.UUID, executionOrder int32) error
 	ReorderTransformations(ctx context.Context, iD uuid.UUID, executionOrder int32) error
+	// Puts a failed event back in the transformed state awaiting a new
+	// delivery.
+	RequeueWebhookEvent(ctx context.Context, id uuid.UUID) (int64, error)
+	// Puts a delivered or failed event back in the transformed state, keeping
+	// its processed payload, ahead of a replay of its delivery.
+	ResetWebhookEventForRedelivery(ctx context.Context, iD uuid.UUID, replayJobID pgtype.UUID) (int64, error)
+	// Puts a settled event back in the pending state with its original
+	// payload, ahead of a replay through its pipeline.
+	ResetWebhookEventForReplay(ctx context.Context, iD uuid.UUID, replayJobID pgtype.UUID) (int64, error)
+	// Settles a dead letter, only while it is dead so that concurrent bulk
+	// actions settle it once.
+	ResolveDeadLetter(ctx context.Context, iD uuid.UUID, status DeadLetterStatus, redriveDeliveryID pgtype.UUID) (int64, error)
+	ScheduleWebhookEvent(ctx context.Context, iD uuid.UUID, scheduledAt pgtype.Timestamptz) error
+	SetWebhookEventOrderingKey(ctx context.Context, iD uuid.UUID, orderingKey pgtype.Text) error
+	StartDelayedWebhookEvent(ctx context.Context, id uuid.UUID) (int64, error)
+	TouchLookupTable(ctx context.Context, id uuid.UUID) error
 	UpdateDelivery(ctx context.Context, iD uuid.UUID, status DeliveryStatus, responseCode pgtype.Int4, attempt pgtype.Int4, lastError pgtype.Text, scheduledAt pgtype.Timestamptz) (Delivery, error)
-	UpdateDestination(ctx context.Context, iD uuid.UUID, name string, description string, destinationType DestinationType, config []byte, isActive bool, delaySeconds int32, retryAttempts int32) (Destination, error)
+	UpdateDestination(ctx context.Context, iD uuid.UUID, name string, description string, destinationType DestinationType, config []byte, isActive bool, delaySeconds int32, retryAttempts int32, retryPolicy []byte, delayPath string, circuitBreaker []byte, limits []byte, orderingKey string) (Destination, error)
 	UpdateFilter(ctx context.Context, iD uuid.UUID, name string, description pgtype.Text, filterType FilterType, mode FilterMode, config []byte, code pgtype.Text, executionOrder int32, isActive bool) (Filter, error)
+	UpdateLookupTable(ctx context.Context, iD uuid.UUID, name string, description pgtype.Text, ttlSeconds pgtype.Int4) (LookupTable, error)
 	UpdatePipeline(ctx context.Context, iD uuid.UUID, name string, description string, isActive bool, executionOrder int32) (Pipeline, error)
+	// Saves the progress of a running job and renews its lease. No row is
+	// updated once the job has been cancelled.
+	UpdateReplayJobProgress(ctx context.Context, iD uuid.UUID, processed int32, succeeded int32, failed int32, skipped int32, cursorCreatedAt pgtype.Timestamptz, cursorEventID pgtype.UUID, column8 float64) (int64, error)
 	UpdateSource(ctx context.Context, iD uuid.UUID, name string, description string, protocol ProtocolType, authType AuthType, authConfig []byte, isActive bool) (Source, error)
 	UpdateTransformation(ctx context.Context, iD uuid.UUID, name string, description pgtype.Text, transformationType TransformationType, mode TransformationMode, config []byte, code pgtype.Text, executionOrder int32, isActive bool) (Transformation, error)
 	UpdateUser(ctx context.Context, iD uuid.UUID, role UserRole, firstName string, lastName string) (User, error)
 	UpdateUserPassword(ctx context.Context, iD uuid.UUID, passwordHash string) (User, error)
 	UpdateWebhookEvent(ctx context.Context, iD uuid.UUID, status WebhookStatus, metadata []byte, pipelineID pgtype.UUID, filterResults []byte, transformationResults []byte, errorMessage pgtype.Text, scheduledAt pgtype.Timestamptz, processedAt pgtype.Timestamptz) (WebhookEvent, error)
+	UpdateWebhookEventFilterResults(ctx context.Context, iD uuid.UUID, filterResults []byte) error
 	UpdateWebhookEventStatus(ctx context.Context, iD uuid.UUID, status WebhookStatus, errorMessage pgtype.Text) (WebhookEvent, error)
+	UpdateWebhookEventTransformationResults(ctx context.Context, iD uuid.UUID, payload []byte, transformationResults []byte, column4 []byte, column5 string) error
 	UpdateWebhookStep(ctx context.Context, iD uuid.UUID, status StepStatus, outputData []byte, errorMessage pgtype.Text, durationMs pgtype.Int4, completedAt pgtype.Timestamptz) (WebhookStep, error)
 	UpdateWebhookStepStatus(ctx context.Context, iD uuid.UUID, status StepStatus, errorMessage pgtype.Text) (WebhookStep, error)
+	UpsertLookupEntry(ctx context.Context, tableID uuid.UUID, key string, value []byte, expiresAt pgtype.Timestamptz) (LookupEntry, error)
 }
 
 var _ Querier = (*Queries)(nil)
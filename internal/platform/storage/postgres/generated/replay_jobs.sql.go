@@ -0,0 +1,255 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: replay_jobs.sql
+
+package generated
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const cancelReplayJob = `-- name: CancelReplayJob :execrows
+UPDATE replay_jobs SET
+    status = 'cancelled',
+    lease_expires_at = NULL,
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'running')
+`
+
+func (q *Queries) CancelReplayJob(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelReplayJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimReplayJob = `-- name: ClaimReplayJob :one
+UPDATE replay_jobs SET
+    status = 'running',
+    lease_expires_at = NOW() + make_interval(secs => $1::double precision),
+    started_at = COALESCE(started_at, NOW()),
+    updated_at = NOW()
+WHERE id = (
+    SELECT j.id FROM replay_jobs j
+    WHERE j.status = 'pending' OR (j.status = 'running' AND j.lease_expires_at <= NOW())
+    ORDER BY j.created_at ASC
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, user_id, mode, query, rate_per_second, status, total, processed, succeeded, failed, skipped, cursor_created_at, cursor_event_id, error_message, lease_expires_at, started_at, completed_at, created_at, updated_at
+`
+
+// Claims the oldest pending job, or a running job whose worker stopped
+// renewing its lease, and leases it to the caller.
+func (q *Queries) ClaimReplayJob(ctx context.Context, dollar_1 float64) (ReplayJob, error) {
+	row := q.db.QueryRow(ctx, claimReplayJob, dollar_1)
+	var i ReplayJob
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Mode,
+		&i.Query,
+		&i.RatePerSecond,
+		&i.Status,
+		&i.Total,
+		&i.Processed,
+		&i.Succeeded,
+		&i.Failed,
+		&i.Skipped,
+		&i.CursorCreatedAt,
+		&i.CursorEventID,
+		&i.ErrorMessage,
+		&i.LeaseExpiresAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const countReplayJobs = `-- name: CountReplayJobs :one
+SELECT COUNT(*) FROM replay_jobs
+WHERE ($1::text = '' OR status = $1::replay_status)
+`
+
+func (q *Queries) CountReplayJobs(ctx context.Context, dollar_1 string) (int64, error) {
+	row := q.db.QueryRow(ctx, countReplayJobs, dollar_1)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createReplayJob = `-- name: CreateReplayJob :one
+INSERT INTO replay_jobs (
+    user_id, mode, query, rate_per_second, total
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, mode, query, rate_per_second, status, total, processed, succeeded, failed, skipped, cursor_created_at, cursor_event_id, error_message, lease_expires_at, started_at, completed_at, created_at, updated_at
+`
+
+func (q *Queries) CreateReplayJob(ctx context.Context, userID pgtype.UUID, mode ReplayMode, query []byte, ratePerSecond float64, total int32) (ReplayJob, error) {
+	row := q.db.QueryRow(ctx, createReplayJob,
+		userID,
+		mode,
+		query,
+		ratePerSecond,
+		total,
+	)
+	var i ReplayJob
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Mode,
+		&i.Query,
+		&i.RatePerSecond,
+		&i.Status,
+		&i.Total,
+		&i.Processed,
+		&i.Succeeded,
+		&i.Failed,
+		&i.Skipped,
+		&i.CursorCreatedAt,
+		&i.CursorEventID,
+		&i.ErrorMessage,
+		&i.LeaseExpiresAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const finishReplayJob = `-- name: FinishReplayJob :execrows
+UPDATE replay_jobs SET
+    status = $2,
+    error_message = $3,
+    lease_expires_at = NULL,
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'running'
+`
+
+func (q *Queries) FinishReplayJob(ctx context.Context, iD uuid.UUID, status ReplayStatus, errorMessage pgtype.Text) (int64, error) {
+	result, err := q.db.Exec(ctx, finishReplayJob, iD, status, errorMessage)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getReplayJobByID = `-- name: GetReplayJobByID :one
+SELECT id, user_id, mode, query, rate_per_second, status, total, processed, succeeded, failed, skipped, cursor_created_at, cursor_event_id, error_message, lease_expires_at, started_at, completed_at, created_at, updated_at FROM replay_jobs WHERE id = $1
+`
+
+func (q *Queries) GetReplayJobByID(ctx context.Context, id uuid.UUID) (ReplayJob, error) {
+	row := q.db.QueryRow(ctx, getReplayJobByID, id)
+	var i ReplayJob
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Mode,
+		&i.Query,
+		&i.RatePerSecond,
+		&i.Status,
+		&i.Total,
+		&i.Processed,
+		&i.Succeeded,
+		&i.Failed,
+		&i.Skipped,
+		&i.CursorCreatedAt,
+		&i.CursorEventID,
+		&i.ErrorMessage,
+		&i.LeaseExpiresAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listReplayJobs = `-- name: ListReplayJobs :many
+SELECT id, user_id, mode, query, rate_per_second, status, total, processed, succeeded, failed, skipped, cursor_created_at, cursor_event_id, error_message, lease_expires_at, started_at, completed_at, created_at, updated_at FROM replay_jobs
+WHERE ($1::text = '' OR status = $1::replay_status)
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3
+`
+
+func (q *Queries) ListReplayJobs(ctx context.Context, column1 string, limit int32, offset int32) ([]ReplayJob, error) {
+	rows, err := q.db.Query(ctx, listReplayJobs, column1, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReplayJob{}
+	for rows.Next() {
+		var i ReplayJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Mode,
+			&i.Query,
+			&i.RatePerSecond,
+			&i.Status,
+			&i.Total,
+			&i.Processed,
+			&i.Succeeded,
+			&i.Failed,
+			&i.Skipped,
+			&i.CursorCreatedAt,
+			&i.CursorEventID,
+			&i.ErrorMessage,
+			&i.LeaseExpiresAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateReplayJobProgress = `-- name: UpdateReplayJobProgress :execrows
+UPDATE replay_jobs SET
+    processed = $2,
+    succeeded = $3,
+    failed = $4,
+    skipped = $5,
+    cursor_created_at = $6,
+    cursor_event_id = $7,
+    lease_expires_at = NOW() + make_interval(secs => $8::double precision),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'running'
+`
+
+// Saves the progress of a running job and renews its lease. No row is
+// updated once the job has been cancelled.
+func (q *Queries) UpdateReplayJobProgress(ctx context.Context, iD uuid.UUID, processed int32, succeeded int32, failed int32, skipped int32, cursorCreatedAt pgtype.Timestamptz, cursorEventID pgtype.UUID, column8 float64) (int64, error) {
+	result, err := q.db.Exec(ctx, updateReplayJobProgress,
+		iD,
+		processed,
+		succeeded,
+		failed,
+		skipped,
+		cursorCreatedAt,
+		cursorEventID,
+		column8,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -26,11 +26,11 @@ func (q *Queries) CountTransformationsByPipeline(ctx context.Context, pipelineID
 const createTransformation = `-- name: CreateTransformation :one
 INSERT INTO transformations (
     pipeline_id, name, description, transformation_type, mode, config, code, execution_order, is_active
-) VALUES ($1, $2, COALESCE($3, ''), $4, COALESCE($5, 'nocode'), COALESCE($6, '{}'::jsonb), $7, COALESCE($8, 1), COALESCE($9, TRUE))
+) VALUES ($1, $2, COALESCE($3, ''), $4, COALESCE($5::transformation_mode, 'nocode'), COALESCE($6, '{}'::jsonb), $7, COALESCE($8, 1), COALESCE($9, TRUE))
 RETURNING id, pipeline_id, name, description, transformation_type, mode, config, code, is_active, execution_order, created_at, updated_at
 `
 
-func (q *Queries) CreateTransformation(ctx context.Context, pipelineID uuid.UUID, name string, column3 interface{}, transformationType TransformationType, column5 interface{}, column6 interface{}, code pgtype.Text, column8 interface{}, column9 interface{}) (Transformation, error) {
+func (q *Queries) CreateTransformation(ctx context.Context, pipelineID uuid.UUID, name string, column3 interface{}, transformationType TransformationType, column5 TransformationMode, column6 interface{}, code pgtype.Text, column8 interface{}, column9 interface{}) (Transformation, error) {
 	row := q.db.QueryRow(ctx, createTransformation,
 		pipelineID,
 		name,
@@ -288,6 +288,34 @@ func (q *Queries) ListTransformationsByPipeline(ctx context.Context, pipelineID
 	return items, nil
 }
 
+const lockTransformationsByPipeline = `-- name: LockTransformationsByPipeline :many
+SELECT id FROM transformations
+WHERE pipeline_id = $1
+FOR UPDATE
+`
+
+// Locks the transformations of a pipeline while they are reordered, so
+// that none is updated or deleted in between.
+func (q *Queries) LockTransformationsByPipeline(ctx context.Context, pipelineID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, lockTransformationsByPipeline, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const reorderTransformations = `-- name: ReorderTransformations :exec
 UPDATE transformations SET execution_order = $2, updated_at = NOW() WHERE id = $1
 `
@@ -0,0 +1,132 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: wasm_modules.sql
+
+package generated
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createWasmModule = `-- name: CreateWasmModule :one
+INSERT INTO wasm_modules (
+    user_id, name, hash, size_bytes, content
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, name, hash, size_bytes, created_at
+`
+
+type CreateWasmModuleRow struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	UserID    uuid.UUID          `db:"user_id" json:"user_id"`
+	Name      string             `db:"name" json:"name"`
+	Hash      string             `db:"hash" json:"hash"`
+	SizeBytes int32              `db:"size_bytes" json:"size_bytes"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+func (q *Queries) CreateWasmModule(ctx context.Context, userID uuid.UUID, name string, hash string, sizeBytes int32, content []byte) (CreateWasmModuleRow, error) {
+	row := q.db.QueryRow(ctx, createWasmModule,
+		userID,
+		name,
+		hash,
+		sizeBytes,
+		content,
+	)
+	var i CreateWasmModuleRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Hash,
+		&i.SizeBytes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWasmModuleByHash = `-- name: GetWasmModuleByHash :one
+SELECT id, user_id, name, hash, size_bytes, created_at FROM wasm_modules
+WHERE user_id = $1 AND hash = $2
+`
+
+type GetWasmModuleByHashRow struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	UserID    uuid.UUID          `db:"user_id" json:"user_id"`
+	Name      string             `db:"name" json:"name"`
+	Hash      string             `db:"hash" json:"hash"`
+	SizeBytes int32              `db:"size_bytes" json:"size_bytes"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+func (q *Queries) GetWasmModuleByHash(ctx context.Context, userID uuid.UUID, hash string) (GetWasmModuleByHashRow, error) {
+	row := q.db.QueryRow(ctx, getWasmModuleByHash, userID, hash)
+	var i GetWasmModuleByHashRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Hash,
+		&i.SizeBytes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWasmModuleContent = `-- name: GetWasmModuleContent :one
+SELECT content FROM wasm_modules
+WHERE hash = $1
+LIMIT 1
+`
+
+func (q *Queries) GetWasmModuleContent(ctx context.Context, hash string) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getWasmModuleContent, hash)
+	var content []byte
+	err := row.Scan(&content)
+	return content, err
+}
+
+const listWasmModulesByUser = `-- name: ListWasmModulesByUser :many
+SELECT id, user_id, name, hash, size_bytes, created_at FROM wasm_modules
+WHERE user_id = $1
+ORDER BY created_at DESC
+`
+
+type ListWasmModulesByUserRow struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	UserID    uuid.UUID          `db:"user_id" json:"user_id"`
+	Name      string             `db:"name" json:"name"`
+	Hash      string             `db:"hash" json:"hash"`
+	SizeBytes int32              `db:"size_bytes" json:"size_bytes"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+func (q *Queries) ListWasmModulesByUser(ctx context.Context, userID uuid.UUID) ([]ListWasmModulesByUserRow, error) {
+	rows, err := q.db.Query(ctx, listWasmModulesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWasmModulesByUserRow{}
+	for rows.Next() {
+		var i ListWasmModulesByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Hash,
+			&i.SizeBytes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
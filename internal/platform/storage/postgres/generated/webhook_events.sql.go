@@ -12,6 +12,99 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelDelayedWebhookEvent = `-- name: CancelDelayedWebhookEvent :execrows
+UPDATE webhook_events SET
+    status = 'cancelled',
+    processed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'delayed'
+`
+
+func (q *Queries) CancelDelayedWebhookEvent(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelDelayedWebhookEvent, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimDueWebhookEvents = `-- name: ClaimDueWebhookEvents :many
+UPDATE webhook_events SET
+    scheduled_at = NOW() + make_interval(secs => $2::double precision),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT we.id FROM webhook_events we
+    WHERE we.status = 'delayed' AND we.scheduled_at <= NOW()
+    ORDER BY we.scheduled_at ASC
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id
+`
+
+// Claims the delayed events that are due by pushing scheduled_at past a
+// lease, so that concurrent schedulers skip them. An event whose delivery
+// never starts is claimed again once the lease expires.
+func (q *Queries) ClaimDueWebhookEvents(ctx context.Context, limit int32, column2 float64) ([]WebhookEvent, error) {
+	rows, err := q.db.Query(ctx, claimDueWebhookEvents, limit, column2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookEvent{}
+	for rows.Next() {
+		var i WebhookEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.SourceID,
+			&i.PipelineID,
+			&i.Payload,
+			&i.OriginalPayload,
+			&i.Metadata,
+			&i.FilterResults,
+			&i.TransformationResults,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.ScheduledAt,
+			&i.ProcessedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrderingKey,
+			&i.ReplayJobID,
+			&i.DerivedFromID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countReplayWebhookEvents = `-- name: CountReplayWebhookEvents :one
+SELECT COUNT(*) FROM webhook_events
+WHERE ($1::uuid = '00000000-0000-0000-0000-000000000000' OR id = $1)
+    AND ($2::uuid = '00000000-0000-0000-0000-000000000000' OR source_id = $2)
+    AND ($3::text = '' OR status = $3::webhook_status)
+    AND ($4::timestamptz IS NULL OR created_at >= $4)
+    AND ($5::timestamptz IS NULL OR created_at < $5)
+`
+
+func (q *Queries) CountReplayWebhookEvents(ctx context.Context, column1 uuid.UUID, column2 uuid.UUID, column3 string, column4 pgtype.Timestamptz, column5 pgtype.Timestamptz) (int64, error) {
+	row := q.db.QueryRow(ctx, countReplayWebhookEvents,
+		column1,
+		column2,
+		column3,
+		column4,
+		column5,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countWebhookEventsByStatus = `-- name: CountWebhookEventsByStatus :one
 SELECT COUNT(*) FROM webhook_events WHERE status = $1
 `
@@ -23,11 +116,52 @@ func (q *Queries) CountWebhookEventsByStatus(ctx context.Context, status Webhook
 	return count, err
 }
 
+const createDerivedWebhookEvent = `-- name: CreateDerivedWebhookEvent :one
+INSERT INTO webhook_events (
+    source_id, pipeline_id, payload, original_payload, metadata, status, derived_from_id
+) VALUES ($1, $2, $3, $3, $4, $5, $6)
+RETURNING id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id
+`
+
+// Creates an event derived from another one. Its original payload is the
+// edited payload, which a replay starts again from.
+func (q *Queries) CreateDerivedWebhookEvent(ctx context.Context, sourceID uuid.UUID, pipelineID pgtype.UUID, payload []byte, metadata []byte, status WebhookStatus, derivedFromID pgtype.UUID) (WebhookEvent, error) {
+	row := q.db.QueryRow(ctx, createDerivedWebhookEvent,
+		sourceID,
+		pipelineID,
+		payload,
+		metadata,
+		status,
+		derivedFromID,
+	)
+	var i WebhookEvent
+	err := row.Scan(
+		&i.ID,
+		&i.SourceID,
+		&i.PipelineID,
+		&i.Payload,
+		&i.OriginalPayload,
+		&i.Metadata,
+		&i.FilterResults,
+		&i.TransformationResults,
+		&i.Status,
+		&i.ErrorMessage,
+		&i.ScheduledAt,
+		&i.ProcessedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrderingKey,
+		&i.ReplayJobID,
+		&i.DerivedFromID,
+	)
+	return i, err
+}
+
 const createWebhookEvent = `-- name: CreateWebhookEvent :one
 INSERT INTO webhook_events (
     source_id, pipeline_id, payload, original_payload, metadata, status, scheduled_at
 ) VALUES ($1, $2, $3, $4, COALESCE($5, '{}'::jsonb), COALESCE($6, 'pending'), $7)
-RETURNING id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at
+RETURNING id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id
 `
 
 func (q *Queries) CreateWebhookEvent(ctx context.Context, sourceID uuid.UUID, pipelineID pgtype.UUID, payload []byte, originalPayload []byte, column5 interface{}, column6 interface{}, scheduledAt pgtype.Timestamptz) (WebhookEvent, error) {
@@ -56,6 +190,9 @@ func (q *Queries) CreateWebhookEvent(ctx context.Context, sourceID uuid.UUID, pi
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
+		&i.ReplayJobID,
+		&i.DerivedFromID,
 	)
 	return i, err
 }
@@ -70,7 +207,7 @@ func (q *Queries) DeleteWebhookEvent(ctx context.Context, id uuid.UUID) error {
 }
 
 const getWebhookEventByID = `-- name: GetWebhookEventByID :one
-SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at FROM webhook_events WHERE id = $1
+SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id FROM webhook_events WHERE id = $1
 `
 
 func (q *Queries) GetWebhookEventByID(ctx context.Context, id uuid.UUID) (WebhookEvent, error) {
@@ -91,13 +228,48 @@ func (q *Queries) GetWebhookEventByID(ctx context.Context, id uuid.UUID) (Webhoo
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
+		&i.ReplayJobID,
+		&i.DerivedFromID,
 	)
 	return i, err
 }
 
+const getWebhookEventOrderingPredecessor = `-- name: GetWebhookEventOrderingPredecessor :one
+SELECT
+    e.id,
+    GREATEST(
+        CASE WHEN e.status = 'delayed' THEN e.scheduled_at END,
+        (SELECT MAX(d.scheduled_at) FROM deliveries d WHERE d.webhook_event_id = e.id AND d.status = 'retrying')
+    )::timestamptz AS due_at
+FROM webhook_events e
+JOIN webhook_events cur ON cur.ordering_key = e.ordering_key
+WHERE cur.id = $1
+    AND e.id <> cur.id
+    AND e.status IN ('pending', 'transformed', 'delayed')
+    AND (e.created_at < cur.created_at OR (e.created_at = cur.created_at AND e.id < cur.id))
+ORDER BY e.created_at ASC, e.id ASC
+LIMIT 1
+`
+
+type GetWebhookEventOrderingPredecessorRow struct {
+	ID    uuid.UUID          `db:"id" json:"id"`
+	DueAt pgtype.Timestamptz `db:"due_at" json:"due_at"`
+}
+
+// Returns the earliest event ingested before the given one that shares its
+// ordering key and is not settled yet, along with when it is next due:
+// the end of its delay or its next retry, if any.
+func (q *Queries) GetWebhookEventOrderingPredecessor(ctx context.Context, id uuid.UUID) (GetWebhookEventOrderingPredecessorRow, error) {
+	row := q.db.QueryRow(ctx, getWebhookEventOrderingPredecessor, id)
+	var i GetWebhookEventOrderingPredecessorRow
+	err := row.Scan(&i.ID, &i.DueAt)
+	return i, err
+}
+
 const getWebhookEventWithDetails = `-- name: GetWebhookEventWithDetails :one
 SELECT 
-    we.id, we.source_id, we.pipeline_id, we.payload, we.original_payload, we.metadata, we.filter_results, we.transformation_results, we.status, we.error_message, we.scheduled_at, we.processed_at, we.created_at, we.updated_at,
+    we.id, we.source_id, we.pipeline_id, we.payload, we.original_payload, we.metadata, we.filter_results, we.transformation_results, we.status, we.error_message, we.scheduled_at, we.processed_at, we.created_at, we.updated_at, we.ordering_key, we.replay_job_id, we.derived_from_id,
     p.name as pipeline_name,
     s.name as source_name,
     d.name as destination_name
@@ -123,6 +295,9 @@ type GetWebhookEventWithDetailsRow struct {
 	ProcessedAt           pgtype.Timestamptz `db:"processed_at" json:"processed_at"`
 	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt             pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	OrderingKey           pgtype.Text        `db:"ordering_key" json:"ordering_key"`
+	ReplayJobID           pgtype.UUID        `db:"replay_job_id" json:"replay_job_id"`
+	DerivedFromID         pgtype.UUID        `db:"derived_from_id" json:"derived_from_id"`
 	PipelineName          pgtype.Text        `db:"pipeline_name" json:"pipeline_name"`
 	SourceName            pgtype.Text        `db:"source_name" json:"source_name"`
 	DestinationName       pgtype.Text        `db:"destination_name" json:"destination_name"`
@@ -146,6 +321,9 @@ func (q *Queries) GetWebhookEventWithDetails(ctx context.Context, id uuid.UUID)
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
+		&i.ReplayJobID,
+		&i.DerivedFromID,
 		&i.PipelineName,
 		&i.SourceName,
 		&i.DestinationName,
@@ -155,7 +333,7 @@ func (q *Queries) GetWebhookEventWithDetails(ctx context.Context, id uuid.UUID)
 
 const getWebhookEventWithPipeline = `-- name: GetWebhookEventWithPipeline :one
 SELECT 
-    we.id, we.source_id, we.pipeline_id, we.payload, we.original_payload, we.metadata, we.filter_results, we.transformation_results, we.status, we.error_message, we.scheduled_at, we.processed_at, we.created_at, we.updated_at,
+    we.id, we.source_id, we.pipeline_id, we.payload, we.original_payload, we.metadata, we.filter_results, we.transformation_results, we.status, we.error_message, we.scheduled_at, we.processed_at, we.created_at, we.updated_at, we.ordering_key, we.replay_job_id, we.derived_from_id,
     p.name as pipeline_name,
     s.name as source_name,
     d.name as destination_name
@@ -181,6 +359,9 @@ type GetWebhookEventWithPipelineRow struct {
 	ProcessedAt           pgtype.Timestamptz `db:"processed_at" json:"processed_at"`
 	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt             pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	OrderingKey           pgtype.Text        `db:"ordering_key" json:"ordering_key"`
+	ReplayJobID           pgtype.UUID        `db:"replay_job_id" json:"replay_job_id"`
+	DerivedFromID         pgtype.UUID        `db:"derived_from_id" json:"derived_from_id"`
 	PipelineName          pgtype.Text        `db:"pipeline_name" json:"pipeline_name"`
 	SourceName            pgtype.Text        `db:"source_name" json:"source_name"`
 	DestinationName       pgtype.Text        `db:"destination_name" json:"destination_name"`
@@ -204,6 +385,9 @@ func (q *Queries) GetWebhookEventWithPipeline(ctx context.Context, id uuid.UUID)
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
+		&i.ReplayJobID,
+		&i.DerivedFromID,
 		&i.PipelineName,
 		&i.SourceName,
 		&i.DestinationName,
@@ -211,14 +395,15 @@ func (q *Queries) GetWebhookEventWithPipeline(ctx context.Context, id uuid.UUID)
 	return i, err
 }
 
-const listFailedWebhookEvents = `-- name: ListFailedWebhookEvents :many
-SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at FROM webhook_events
-WHERE status = 'failed'
-ORDER BY created_at DESC
+const listPendingWebhookEvents = `-- name: ListPendingWebhookEvents :many
+SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id FROM webhook_events
+WHERE status = 'pending' AND (scheduled_at IS NULL OR scheduled_at <= NOW())
+ORDER BY created_at ASC
+LIMIT $1
 `
 
-func (q *Queries) ListFailedWebhookEvents(ctx context.Context) ([]WebhookEvent, error) {
-	rows, err := q.db.Query(ctx, listFailedWebhookEvents)
+func (q *Queries) ListPendingWebhookEvents(ctx context.Context, limit int32) ([]WebhookEvent, error) {
+	rows, err := q.db.Query(ctx, listPendingWebhookEvents, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -241,6 +426,9 @@ func (q *Queries) ListFailedWebhookEvents(ctx context.Context) ([]WebhookEvent,
 			&i.ProcessedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrderingKey,
+			&i.ReplayJobID,
+			&i.DerivedFromID,
 		); err != nil {
 			return nil, err
 		}
@@ -252,38 +440,45 @@ func (q *Queries) ListFailedWebhookEvents(ctx context.Context) ([]WebhookEvent,
 	return items, nil
 }
 
-const listPendingWebhookEvents = `-- name: ListPendingWebhookEvents :many
-SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at FROM webhook_events
-WHERE status = 'pending' AND (scheduled_at IS NULL OR scheduled_at <= NOW())
-ORDER BY created_at ASC
-LIMIT $1
+const listReplayWebhookEvents = `-- name: ListReplayWebhookEvents :many
+SELECT id, created_at FROM webhook_events
+WHERE ($1::uuid = '00000000-0000-0000-0000-000000000000' OR id = $1)
+    AND ($2::uuid = '00000000-0000-0000-0000-000000000000' OR source_id = $2)
+    AND ($3::text = '' OR status = $3::webhook_status)
+    AND ($4::timestamptz IS NULL OR created_at >= $4)
+    AND ($5::timestamptz IS NULL OR created_at < $5)
+    AND (created_at, id) > ($6::timestamptz, $7::uuid)
+ORDER BY created_at ASC, id ASC
+LIMIT $8
 `
 
-func (q *Queries) ListPendingWebhookEvents(ctx context.Context, limit int32) ([]WebhookEvent, error) {
-	rows, err := q.db.Query(ctx, listPendingWebhookEvents, limit)
+type ListReplayWebhookEventsRow struct {
+	ID        uuid.UUID          `db:"id" json:"id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+// Lists the events matching a replay query after the cursor, in ingestion
+// order. A nil UUID, an empty status or a NULL time leaves the matching
+// criterion open.
+func (q *Queries) ListReplayWebhookEvents(ctx context.Context, column1 uuid.UUID, column2 uuid.UUID, column3 string, column4 pgtype.Timestamptz, column5 pgtype.Timestamptz, column6 pgtype.Timestamptz, column7 uuid.UUID, limit int32) ([]ListReplayWebhookEventsRow, error) {
+	rows, err := q.db.Query(ctx, listReplayWebhookEvents,
+		column1,
+		column2,
+		column3,
+		column4,
+		column5,
+		column6,
+		column7,
+		limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []WebhookEvent{}
+	items := []ListReplayWebhookEventsRow{}
 	for rows.Next() {
-		var i WebhookEvent
-		if err := rows.Scan(
-			&i.ID,
-			&i.SourceID,
-			&i.PipelineID,
-			&i.Payload,
-			&i.OriginalPayload,
-			&i.Metadata,
-			&i.FilterResults,
-			&i.TransformationResults,
-			&i.Status,
-			&i.ErrorMessage,
-			&i.ScheduledAt,
-			&i.ProcessedAt,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
+		var i ListReplayWebhookEventsRow
+		if err := rows.Scan(&i.ID, &i.CreatedAt); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -295,7 +490,7 @@ func (q *Queries) ListPendingWebhookEvents(ctx context.Context, limit int32) ([]
 }
 
 const listWebhookEventsByPipeline = `-- name: ListWebhookEventsByPipeline :many
-SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at FROM webhook_events
+SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id FROM webhook_events
 WHERE pipeline_id = $1
 ORDER BY created_at DESC
 `
@@ -324,6 +519,9 @@ func (q *Queries) ListWebhookEventsByPipeline(ctx context.Context, pipelineID pg
 			&i.ProcessedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrderingKey,
+			&i.ReplayJobID,
+			&i.DerivedFromID,
 		); err != nil {
 			return nil, err
 		}
@@ -336,7 +534,7 @@ func (q *Queries) ListWebhookEventsByPipeline(ctx context.Context, pipelineID pg
 }
 
 const listWebhookEventsBySource = `-- name: ListWebhookEventsBySource :many
-SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at FROM webhook_events
+SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id FROM webhook_events
 WHERE source_id = $1
 ORDER BY created_at DESC
 `
@@ -365,6 +563,9 @@ func (q *Queries) ListWebhookEventsBySource(ctx context.Context, sourceID uuid.U
 			&i.ProcessedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrderingKey,
+			&i.ReplayJobID,
+			&i.DerivedFromID,
 		); err != nil {
 			return nil, err
 		}
@@ -377,7 +578,7 @@ func (q *Queries) ListWebhookEventsBySource(ctx context.Context, sourceID uuid.U
 }
 
 const listWebhookEventsBySourceAndStatus = `-- name: ListWebhookEventsBySourceAndStatus :many
-SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at FROM webhook_events
+SELECT id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id FROM webhook_events
 WHERE source_id = $1 AND status = $2
 ORDER BY created_at DESC
 `
@@ -406,6 +607,9 @@ func (q *Queries) ListWebhookEventsBySourceAndStatus(ctx context.Context, source
 			&i.ProcessedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrderingKey,
+			&i.ReplayJobID,
+			&i.DerivedFromID,
 		); err != nil {
 			return nil, err
 		}
@@ -417,6 +621,110 @@ func (q *Queries) ListWebhookEventsBySourceAndStatus(ctx context.Context, source
 	return items, nil
 }
 
+const requeueWebhookEvent = `-- name: RequeueWebhookEvent :execrows
+UPDATE webhook_events SET
+    status = 'transformed',
+    processed_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'failed'
+`
+
+// Puts a failed event back in the transformed state awaiting a new
+// delivery.
+func (q *Queries) RequeueWebhookEvent(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, requeueWebhookEvent, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resetWebhookEventForRedelivery = `-- name: ResetWebhookEventForRedelivery :execrows
+UPDATE webhook_events SET
+    status = 'transformed',
+    error_message = NULL,
+    scheduled_at = NULL,
+    processed_at = NULL,
+    replay_job_id = $2,
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('delivered', 'failed')
+`
+
+// Puts a delivered or failed event back in the transformed state, keeping
+// its processed payload, ahead of a replay of its delivery.
+func (q *Queries) ResetWebhookEventForRedelivery(ctx context.Context, iD uuid.UUID, replayJobID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, resetWebhookEventForRedelivery, iD, replayJobID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resetWebhookEventForReplay = `-- name: ResetWebhookEventForReplay :execrows
+UPDATE webhook_events SET
+    status = 'pending',
+    payload = COALESCE(original_payload, payload),
+    metadata = COALESCE(metadata, '{}'::jsonb) - 'outbound_headers' - 'content_type',
+    filter_results = '{}'::jsonb,
+    transformation_results = '{}'::jsonb,
+    error_message = NULL,
+    scheduled_at = NULL,
+    processed_at = NULL,
+    replay_job_id = $2,
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('delivered', 'failed', 'filtered', 'cancelled')
+`
+
+// Puts a settled event back in the pending state with its original
+// payload, ahead of a replay through its pipeline.
+func (q *Queries) ResetWebhookEventForReplay(ctx context.Context, iD uuid.UUID, replayJobID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, resetWebhookEventForReplay, iD, replayJobID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const scheduleWebhookEvent = `-- name: ScheduleWebhookEvent :exec
+UPDATE webhook_events SET
+    status = 'delayed',
+    scheduled_at = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) ScheduleWebhookEvent(ctx context.Context, iD uuid.UUID, scheduledAt pgtype.Timestamptz) error {
+	_, err := q.db.Exec(ctx, scheduleWebhookEvent, iD, scheduledAt)
+	return err
+}
+
+const setWebhookEventOrderingKey = `-- name: SetWebhookEventOrderingKey :exec
+UPDATE webhook_events SET
+    ordering_key = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) SetWebhookEventOrderingKey(ctx context.Context, iD uuid.UUID, orderingKey pgtype.Text) error {
+	_, err := q.db.Exec(ctx, setWebhookEventOrderingKey, iD, orderingKey)
+	return err
+}
+
+const startDelayedWebhookEvent = `-- name: StartDelayedWebhookEvent :execrows
+UPDATE webhook_events SET
+    status = 'transformed',
+    updated_at = NOW()
+WHERE id = $1 AND status = 'delayed'
+`
+
+func (q *Queries) StartDelayedWebhookEvent(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, startDelayedWebhookEvent, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateWebhookEvent = `-- name: UpdateWebhookEvent :one
 UPDATE webhook_events SET
     status = COALESCE($2, status),
@@ -429,7 +737,7 @@ UPDATE webhook_events SET
     processed_at = COALESCE($9, processed_at),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at
+RETURNING id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id
 `
 
 func (q *Queries) UpdateWebhookEvent(ctx context.Context, iD uuid.UUID, status WebhookStatus, metadata []byte, pipelineID pgtype.UUID, filterResults []byte, transformationResults []byte, errorMessage pgtype.Text, scheduledAt pgtype.Timestamptz, processedAt pgtype.Timestamptz) (WebhookEvent, error) {
@@ -460,10 +768,25 @@ func (q *Queries) UpdateWebhookEvent(ctx context.Context, iD uuid.UUID, status W
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
+		&i.ReplayJobID,
+		&i.DerivedFromID,
 	)
 	return i, err
 }
 
+const updateWebhookEventFilterResults = `-- name: UpdateWebhookEventFilterResults :exec
+UPDATE webhook_events SET
+    filter_results = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) UpdateWebhookEventFilterResults(ctx context.Context, iD uuid.UUID, filterResults []byte) error {
+	_, err := q.db.Exec(ctx, updateWebhookEventFilterResults, iD, filterResults)
+	return err
+}
+
 const updateWebhookEventStatus = `-- name: UpdateWebhookEventStatus :one
 UPDATE webhook_events SET
     status = $2,
@@ -471,7 +794,7 @@ UPDATE webhook_events SET
     processed_at = CASE WHEN $2 IN ('delivered', 'failed', 'filtered') THEN NOW() ELSE processed_at END,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at
+RETURNING id, source_id, pipeline_id, payload, original_payload, metadata, filter_results, transformation_results, status, error_message, scheduled_at, processed_at, created_at, updated_at, ordering_key, replay_job_id, derived_from_id
 `
 
 func (q *Queries) UpdateWebhookEventStatus(ctx context.Context, iD uuid.UUID, status WebhookStatus, errorMessage pgtype.Text) (WebhookEvent, error) {
@@ -492,6 +815,35 @@ func (q *Queries) UpdateWebhookEventStatus(ctx context.Context, iD uuid.UUID, st
 		&i.ProcessedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrderingKey,
+		&i.ReplayJobID,
+		&i.DerivedFromID,
 	)
 	return i, err
 }
+
+const updateWebhookEventTransformationResults = `-- name: UpdateWebhookEventTransformationResults :exec
+UPDATE webhook_events SET
+    payload = $2,
+    transformation_results = $3,
+    metadata = CASE
+        WHEN $4::jsonb IS NULL THEN metadata
+        ELSE COALESCE(metadata, '{}'::jsonb) || jsonb_build_object(
+            'outbound_headers', $4::jsonb,
+            'content_type', NULLIF($5::text, '')
+        )
+    END,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) UpdateWebhookEventTransformationResults(ctx context.Context, iD uuid.UUID, payload []byte, transformationResults []byte, column4 []byte, column5 string) error {
+	_, err := q.db.Exec(ctx, updateWebhookEventTransformationResults,
+		iD,
+		payload,
+		transformationResults,
+		column4,
+		column5,
+	)
+	return err
+}
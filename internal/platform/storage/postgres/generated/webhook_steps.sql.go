@@ -16,12 +16,12 @@ const createWebhookStep = `-- name: CreateWebhookStep :one
 INSERT INTO webhook_steps (
     webhook_event_id, pipeline_id, step_type, step_name, step_id, execution_order, 
     status, input_data, output_data, error_message, duration_ms, started_at, completed_at
-) VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, 'pending'), COALESCE($8, '{}'::jsonb), 
+) VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7::step_status, 'pending'), COALESCE($8, '{}'::jsonb), 
           COALESCE($9, '{}'::jsonb), $10, $11, COALESCE($12, NOW()), $13)
 RETURNING id, webhook_event_id, pipeline_id, step_type, step_name, step_id, execution_order, status, input_data, output_data, error_message, duration_ms, started_at, completed_at, created_at
 `
 
-func (q *Queries) CreateWebhookStep(ctx context.Context, webhookEventID uuid.UUID, pipelineID pgtype.UUID, stepType StepType, stepName string, stepID pgtype.UUID, executionOrder int32, column7 interface{}, column8 interface{}, column9 interface{}, errorMessage pgtype.Text, durationMs pgtype.Int4, column12 interface{}, completedAt pgtype.Timestamptz) (WebhookStep, error) {
+func (q *Queries) CreateWebhookStep(ctx context.Context, webhookEventID uuid.UUID, pipelineID pgtype.UUID, stepType StepType, stepName string, stepID pgtype.UUID, executionOrder int32, column7 StepStatus, column8 interface{}, column9 interface{}, errorMessage pgtype.Text, durationMs pgtype.Int4, column12 interface{}, completedAt pgtype.Timestamptz) (WebhookStep, error) {
 	row := q.db.QueryRow(ctx, createWebhookStep,
 		webhookEventID,
 		pipelineID,
@@ -142,6 +142,17 @@ func (q *Queries) GetFailedWebhookSteps(ctx context.Context, limit int32) ([]Get
 	return items, nil
 }
 
+const getNextWebhookStepOrder = `-- name: GetNextWebhookStepOrder :one
+SELECT (COALESCE(MAX(execution_order), 0) + 1)::int FROM webhook_steps WHERE webhook_event_id = $1
+`
+
+func (q *Queries) GetNextWebhookStepOrder(ctx context.Context, webhookEventID uuid.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, getNextWebhookStepOrder, webhookEventID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const getWebhookStepByID = `-- name: GetWebhookStepByID :one
 SELECT id, webhook_event_id, pipeline_id, step_type, step_name, step_id, execution_order, status, input_data, output_data, error_message, duration_ms, started_at, completed_at, created_at FROM webhook_steps WHERE id = $1
 `
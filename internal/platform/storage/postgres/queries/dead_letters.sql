@@ -0,0 +1,44 @@
+-- name: CreateDeadLetter :one
+INSERT INTO dead_letters (
+    delivery_id, webhook_event_id, destination_id, pipeline_id, reason, attempts, last_response
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (delivery_id) DO UPDATE SET
+    reason = EXCLUDED.reason,
+    attempts = EXCLUDED.attempts,
+    last_response = EXCLUDED.last_response,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetDeadLetterByID :one
+SELECT * FROM dead_letters WHERE id = $1;
+
+-- name: ListDeadLetters :many
+-- Lists the dead letters of a destination, a pipeline or both, a nil UUID
+-- leaving the scope open, and optionally of one status.
+SELECT * FROM dead_letters
+WHERE ($1::uuid = '00000000-0000-0000-0000-000000000000' OR destination_id = $1)
+    AND ($2::uuid = '00000000-0000-0000-0000-000000000000' OR pipeline_id = $2)
+    AND ($3::text = '' OR status = $3::dead_letter_status)
+ORDER BY created_at DESC, id DESC
+LIMIT $4 OFFSET $5;
+
+-- name: CountDeadLetters :one
+SELECT COUNT(*) FROM dead_letters
+WHERE ($1::uuid = '00000000-0000-0000-0000-000000000000' OR destination_id = $1)
+    AND ($2::uuid = '00000000-0000-0000-0000-000000000000' OR pipeline_id = $2)
+    AND ($3::text = '' OR status = $3::dead_letter_status);
+
+-- name: ListDeadLettersByIDs :many
+SELECT * FROM dead_letters
+WHERE id = ANY($1::uuid[])
+ORDER BY created_at DESC, id DESC;
+
+-- name: ResolveDeadLetter :execrows
+-- Settles a dead letter, only while it is dead so that concurrent bulk
+-- actions settle it once.
+UPDATE dead_letters SET
+    status = $2,
+    redrive_delivery_id = $3,
+    resolved_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'dead';
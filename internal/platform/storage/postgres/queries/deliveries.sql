@@ -1,7 +1,7 @@
 -- name: CreateDelivery :one
 INSERT INTO deliveries (
-    webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at
-) VALUES ($1, $2, $3, $4, COALESCE($5, 0), $6, $7)
+    webhook_event_id, destination_id, status, response_code, attempt, last_error, scheduled_at, replay_job_id
+) VALUES ($1, $2, $3, $4, COALESCE($5, 0), $6, $7, $8)
 RETURNING *;
 
 -- name: GetDeliveryByID :one
@@ -21,5 +21,35 @@ UPDATE deliveries SET
 WHERE id = $1
 RETURNING *;
 
+-- name: RecordDeliveryAttempt :one
+-- Stores the outcome of an attempt. Unlike UpdateDelivery, a missing
+-- response code, error or retry time clears the one of the previous
+-- attempt.
+UPDATE deliveries SET
+    status = $2,
+    response_code = $3,
+    attempt = $4,
+    last_error = $5,
+    scheduled_at = $6,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
 -- name: DeleteDelivery :exec
 DELETE FROM deliveries WHERE id = $1;
+
+-- name: ClaimDueDeliveries :many
+-- Claims the deliveries whose retry is due by pushing scheduled_at past a
+-- lease, so that concurrent schedulers skip them. A delivery whose attempt
+-- never completes is claimed again once the lease expires.
+UPDATE deliveries SET
+    scheduled_at = NOW() + make_interval(secs => $2::double precision),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT d.id FROM deliveries d
+    WHERE d.status = 'retrying' AND d.scheduled_at <= NOW()
+    ORDER BY d.scheduled_at ASC
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
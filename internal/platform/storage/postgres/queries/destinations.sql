@@ -1,7 +1,7 @@
 -- name: CreateDestination :one
 INSERT INTO destinations (
-    user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts
-) VALUES ($1, $2, $3, $4, COALESCE($5, '{}'::jsonb), COALESCE($6, TRUE), COALESCE($7, 0), COALESCE($8, 0))
+    user_id, name, description, destination_type, config, is_active, delay_seconds, retry_attempts, retry_policy, delay_path, circuit_breaker, limits, ordering_key
+) VALUES ($1, $2, $3, $4, COALESCE($5, '{}'::jsonb), COALESCE($6, TRUE), COALESCE($7, 0), COALESCE($8, 0), COALESCE($9, '{}'::jsonb), COALESCE($10, ''), COALESCE($11, '{}'::jsonb), COALESCE($12, '{}'::jsonb), COALESCE($13, ''))
 RETURNING *;
 
 -- name: GetDestinationByID :one
@@ -59,6 +59,11 @@ UPDATE destinations SET
     is_active = COALESCE($6, is_active),
     delay_seconds = COALESCE($7, delay_seconds),
     retry_attempts = COALESCE($8, retry_attempts),
+    retry_policy = COALESCE($9, retry_policy),
+    delay_path = COALESCE($10, delay_path),
+    circuit_breaker = COALESCE($11, circuit_breaker),
+    limits = COALESCE($12, limits),
+    ordering_key = COALESCE($13, ordering_key),
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
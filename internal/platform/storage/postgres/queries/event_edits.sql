@@ -0,0 +1,12 @@
+-- name: CreateEventEdit :one
+INSERT INTO event_edits (
+    original_event_id, derived_event_id, user_id, mode, destination_id, changes, note
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListEventEditsByEvent :many
+-- Lists the edits an event was derived from or gave rise to, oldest
+-- first.
+SELECT * FROM event_edits
+WHERE original_event_id = $1 OR derived_event_id = $1
+ORDER BY created_at ASC;
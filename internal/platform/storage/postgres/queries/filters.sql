@@ -1,7 +1,7 @@
 -- name: CreateFilter :one
 INSERT INTO filters (
     pipeline_id, name, description, filter_type, mode, config, code, execution_order, is_active
-) VALUES ($1, $2, COALESCE($3, ''), $4, COALESCE($5, 'nocode'), COALESCE($6, '{}'::jsonb), $7, COALESCE($8, 1), COALESCE($9, TRUE))
+) VALUES ($1, $2, COALESCE($3, ''), $4, COALESCE($5::filter_mode, 'nocode'), COALESCE($6, '{}'::jsonb), $7, COALESCE($8, 1), COALESCE($9, TRUE))
 RETURNING *;
 
 -- name: GetFilterByID :one
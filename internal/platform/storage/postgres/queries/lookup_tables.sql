@@ -0,0 +1,68 @@
+-- name: CreateLookupTable :one
+INSERT INTO lookup_tables (
+    user_id, name, description, ttl_seconds
+) VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetLookupTableByID :one
+SELECT * FROM lookup_tables
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetLookupTableByName :one
+SELECT * FROM lookup_tables
+WHERE user_id = $1 AND name = $2;
+
+-- name: ListLookupTablesByUser :many
+SELECT * FROM lookup_tables
+WHERE user_id = $1
+ORDER BY name ASC;
+
+-- name: UpdateLookupTable :one
+UPDATE lookup_tables SET
+    name = $2,
+    description = $3,
+    ttl_seconds = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteLookupTable :exec
+DELETE FROM lookup_tables WHERE id = $1;
+
+-- name: UpsertLookupEntry :one
+INSERT INTO lookup_entries (
+    table_id, key, value, expires_at
+) VALUES ($1, $2, $3, $4)
+ON CONFLICT (table_id, key) DO UPDATE SET
+    value = EXCLUDED.value,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetLookupEntry :one
+SELECT * FROM lookup_entries
+WHERE table_id = $1 AND key = $2 AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: ListLookupEntries :many
+SELECT * FROM lookup_entries
+WHERE table_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY key ASC
+LIMIT $2 OFFSET $3;
+
+-- name: CountLookupEntries :one
+SELECT COUNT(*) FROM lookup_entries
+WHERE table_id = $1 AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: ListAllLookupEntries :many
+SELECT * FROM lookup_entries
+WHERE table_id = $1 AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: DeleteLookupEntry :execrows
+DELETE FROM lookup_entries
+WHERE table_id = $1 AND key = $2;
+
+-- name: DeleteLookupEntriesByTable :exec
+DELETE FROM lookup_entries WHERE table_id = $1;
+
+-- name: TouchLookupTable :exec
+UPDATE lookup_tables SET updated_at = NOW() WHERE id = $1;
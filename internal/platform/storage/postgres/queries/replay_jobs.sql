@@ -0,0 +1,66 @@
+-- name: CreateReplayJob :one
+INSERT INTO replay_jobs (
+    user_id, mode, query, rate_per_second, total
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetReplayJobByID :one
+SELECT * FROM replay_jobs WHERE id = $1;
+
+-- name: ListReplayJobs :many
+SELECT * FROM replay_jobs
+WHERE ($1::text = '' OR status = $1::replay_status)
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountReplayJobs :one
+SELECT COUNT(*) FROM replay_jobs
+WHERE ($1::text = '' OR status = $1::replay_status);
+
+-- name: ClaimReplayJob :one
+-- Claims the oldest pending job, or a running job whose worker stopped
+-- renewing its lease, and leases it to the caller.
+UPDATE replay_jobs SET
+    status = 'running',
+    lease_expires_at = NOW() + make_interval(secs => $1::double precision),
+    started_at = COALESCE(started_at, NOW()),
+    updated_at = NOW()
+WHERE id = (
+    SELECT j.id FROM replay_jobs j
+    WHERE j.status = 'pending' OR (j.status = 'running' AND j.lease_expires_at <= NOW())
+    ORDER BY j.created_at ASC
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateReplayJobProgress :execrows
+-- Saves the progress of a running job and renews its lease. No row is
+-- updated once the job has been cancelled.
+UPDATE replay_jobs SET
+    processed = $2,
+    succeeded = $3,
+    failed = $4,
+    skipped = $5,
+    cursor_created_at = $6,
+    cursor_event_id = $7,
+    lease_expires_at = NOW() + make_interval(secs => $8::double precision),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'running';
+
+-- name: FinishReplayJob :execrows
+UPDATE replay_jobs SET
+    status = $2,
+    error_message = $3,
+    lease_expires_at = NULL,
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'running';
+
+-- name: CancelReplayJob :execrows
+UPDATE replay_jobs SET
+    status = 'cancelled',
+    lease_expires_at = NULL,
+    completed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('pending', 'running');
@@ -1,7 +1,7 @@
 -- name: CreateTransformation :one
 INSERT INTO transformations (
     pipeline_id, name, description, transformation_type, mode, config, code, execution_order, is_active
-) VALUES ($1, $2, COALESCE($3, ''), $4, COALESCE($5, 'nocode'), COALESCE($6, '{}'::jsonb), $7, COALESCE($8, 1), COALESCE($9, TRUE))
+) VALUES ($1, $2, COALESCE($3, ''), $4, COALESCE($5::transformation_mode, 'nocode'), COALESCE($6, '{}'::jsonb), $7, COALESCE($8, 1), COALESCE($9, TRUE))
 RETURNING *;
 
 -- name: GetTransformationByID :one
@@ -42,6 +42,13 @@ SELECT * FROM transformations
 WHERE pipeline_id = $1 AND transformation_type = $2 AND is_active = TRUE
 ORDER BY execution_order ASC;
 
+-- name: LockTransformationsByPipeline :many
+-- Locks the transformations of a pipeline while they are reordered, so
+-- that none is updated or deleted in between.
+SELECT id FROM transformations
+WHERE pipeline_id = $1
+FOR UPDATE;
+
 -- name: ReorderTransformations :exec
 UPDATE transformations SET execution_order = $2, updated_at = NOW() WHERE id = $1;
 
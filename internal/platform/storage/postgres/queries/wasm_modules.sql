@@ -0,0 +1,19 @@
+-- name: CreateWasmModule :one
+INSERT INTO wasm_modules (
+    user_id, name, hash, size_bytes, content
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, name, hash, size_bytes, created_at;
+
+-- name: GetWasmModuleByHash :one
+SELECT id, user_id, name, hash, size_bytes, created_at FROM wasm_modules
+WHERE user_id = $1 AND hash = $2;
+
+-- name: ListWasmModulesByUser :many
+SELECT id, user_id, name, hash, size_bytes, created_at FROM wasm_modules
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetWasmModuleContent :one
+SELECT content FROM wasm_modules
+WHERE hash = $1
+LIMIT 1;
@@ -45,11 +45,6 @@ WHERE status = 'pending' AND (scheduled_at IS NULL OR scheduled_at <= NOW())
 ORDER BY created_at ASC
 LIMIT $1;
 
--- name: ListFailedWebhookEvents :many
-SELECT * FROM webhook_events
-WHERE status = 'failed'
-ORDER BY created_at DESC;
-
 -- name: GetWebhookEventWithPipeline :one
 SELECT 
     we.*,
@@ -84,4 +79,152 @@ FROM webhook_events we
 LEFT JOIN pipelines p ON we.pipeline_id = p.id
 LEFT JOIN sources s ON we.source_id = s.id
 LEFT JOIN destinations d ON p.destination_id = d.id
-WHERE we.id = $1;
\ No newline at end of file
+WHERE we.id = $1;
+
+-- name: UpdateWebhookEventFilterResults :exec
+UPDATE webhook_events SET
+    filter_results = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateWebhookEventTransformationResults :exec
+UPDATE webhook_events SET
+    payload = $2,
+    transformation_results = $3,
+    metadata = CASE
+        WHEN $4::jsonb IS NULL THEN metadata
+        ELSE COALESCE(metadata, '{}'::jsonb) || jsonb_build_object(
+            'outbound_headers', $4::jsonb,
+            'content_type', NULLIF($5::text, '')
+        )
+    END,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: ScheduleWebhookEvent :exec
+UPDATE webhook_events SET
+    status = 'delayed',
+    scheduled_at = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: ClaimDueWebhookEvents :many
+-- Claims the delayed events that are due by pushing scheduled_at past a
+-- lease, so that concurrent schedulers skip them. An event whose delivery
+-- never starts is claimed again once the lease expires.
+UPDATE webhook_events SET
+    scheduled_at = NOW() + make_interval(secs => $2::double precision),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT we.id FROM webhook_events we
+    WHERE we.status = 'delayed' AND we.scheduled_at <= NOW()
+    ORDER BY we.scheduled_at ASC
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: StartDelayedWebhookEvent :execrows
+UPDATE webhook_events SET
+    status = 'transformed',
+    updated_at = NOW()
+WHERE id = $1 AND status = 'delayed';
+
+-- name: CancelDelayedWebhookEvent :execrows
+UPDATE webhook_events SET
+    status = 'cancelled',
+    processed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'delayed';
+
+-- name: SetWebhookEventOrderingKey :exec
+UPDATE webhook_events SET
+    ordering_key = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetWebhookEventOrderingPredecessor :one
+-- Returns the earliest event ingested before the given one that shares its
+-- ordering key and is not settled yet, along with when it is next due:
+-- the end of its delay or its next retry, if any.
+SELECT
+    e.id,
+    GREATEST(
+        CASE WHEN e.status = 'delayed' THEN e.scheduled_at END,
+        (SELECT MAX(d.scheduled_at) FROM deliveries d WHERE d.webhook_event_id = e.id AND d.status = 'retrying')
+    )::timestamptz AS due_at
+FROM webhook_events e
+JOIN webhook_events cur ON cur.ordering_key = e.ordering_key
+WHERE cur.id = $1
+    AND e.id <> cur.id
+    AND e.status IN ('pending', 'transformed', 'delayed')
+    AND (e.created_at < cur.created_at OR (e.created_at = cur.created_at AND e.id < cur.id))
+ORDER BY e.created_at ASC, e.id ASC
+LIMIT 1;
+
+-- name: RequeueWebhookEvent :execrows
+-- Puts a failed event back in the transformed state awaiting a new
+-- delivery.
+UPDATE webhook_events SET
+    status = 'transformed',
+    processed_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'failed';
+
+-- name: ListReplayWebhookEvents :many
+-- Lists the events matching a replay query after the cursor, in ingestion
+-- order. A nil UUID, an empty status or a NULL time leaves the matching
+-- criterion open.
+SELECT id, created_at FROM webhook_events
+WHERE ($1::uuid = '00000000-0000-0000-0000-000000000000' OR id = $1)
+    AND ($2::uuid = '00000000-0000-0000-0000-000000000000' OR source_id = $2)
+    AND ($3::text = '' OR status = $3::webhook_status)
+    AND ($4::timestamptz IS NULL OR created_at >= $4)
+    AND ($5::timestamptz IS NULL OR created_at < $5)
+    AND (created_at, id) > ($6::timestamptz, $7::uuid)
+ORDER BY created_at ASC, id ASC
+LIMIT $8;
+
+-- name: CountReplayWebhookEvents :one
+SELECT COUNT(*) FROM webhook_events
+WHERE ($1::uuid = '00000000-0000-0000-0000-000000000000' OR id = $1)
+    AND ($2::uuid = '00000000-0000-0000-0000-000000000000' OR source_id = $2)
+    AND ($3::text = '' OR status = $3::webhook_status)
+    AND ($4::timestamptz IS NULL OR created_at >= $4)
+    AND ($5::timestamptz IS NULL OR created_at < $5);
+
+-- name: ResetWebhookEventForReplay :execrows
+-- Puts a settled event back in the pending state with its original
+-- payload, ahead of a replay through its pipeline.
+UPDATE webhook_events SET
+    status = 'pending',
+    payload = COALESCE(original_payload, payload),
+    metadata = COALESCE(metadata, '{}'::jsonb) - 'outbound_headers' - 'content_type',
+    filter_results = '{}'::jsonb,
+    transformation_results = '{}'::jsonb,
+    error_message = NULL,
+    scheduled_at = NULL,
+    processed_at = NULL,
+    replay_job_id = $2,
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('delivered', 'failed', 'filtered', 'cancelled');
+
+-- name: ResetWebhookEventForRedelivery :execrows
+-- Puts a delivered or failed event back in the transformed state, keeping
+-- its processed payload, ahead of a replay of its delivery.
+UPDATE webhook_events SET
+    status = 'transformed',
+    error_message = NULL,
+    scheduled_at = NULL,
+    processed_at = NULL,
+    replay_job_id = $2,
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('delivered', 'failed');
+
+-- name: CreateDerivedWebhookEvent :one
+-- Creates an event derived from another one. Its original payload is the
+-- edited payload, which a replay starts again from.
+INSERT INTO webhook_events (
+    source_id, pipeline_id, payload, original_payload, metadata, status, derived_from_id
+) VALUES ($1, $2, $3, $3, $4, $5, $6)
+RETURNING *;
@@ -2,10 +2,13 @@
 INSERT INTO webhook_steps (
     webhook_event_id, pipeline_id, step_type, step_name, step_id, execution_order, 
     status, input_data, output_data, error_message, duration_ms, started_at, completed_at
-) VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, 'pending'), COALESCE($8, '{}'::jsonb), 
+) VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7::step_status, 'pending'), COALESCE($8, '{}'::jsonb), 
           COALESCE($9, '{}'::jsonb), $10, $11, COALESCE($12, NOW()), $13)
 RETURNING *;
 
+-- name: GetNextWebhookStepOrder :one
+SELECT (COALESCE(MAX(execution_order), 0) + 1)::int FROM webhook_steps WHERE webhook_event_id = $1;
+
 -- name: GetWebhookStepByID :one
 SELECT * FROM webhook_steps WHERE id = $1;
 
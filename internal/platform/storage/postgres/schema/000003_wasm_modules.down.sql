@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_wasm_modules_hash;
+DROP INDEX IF EXISTS idx_wasm_modules_user_id;
+
+DROP TABLE IF EXISTS wasm_modules;
+
+-- PostgreSQL cannot drop enum values: the 'wasm' values of filter_type and
+-- transformation_type are kept.
@@ -0,0 +1,19 @@
+ALTER TYPE filter_type ADD VALUE IF NOT EXISTS 'wasm';
+ALTER TYPE transformation_type ADD VALUE IF NOT EXISTS 'wasm';
+
+-- Table wasm_modules
+-- Modules are immutable and addressed by the SHA-256 of their content, so a
+-- filter or transformation referencing a hash always runs the same code.
+CREATE TABLE IF NOT EXISTS wasm_modules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    content BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wasm_modules_user_id ON wasm_modules(user_id);
+CREATE INDEX IF NOT EXISTS idx_wasm_modules_hash ON wasm_modules(hash);
@@ -0,0 +1,2 @@
+-- PostgreSQL cannot drop enum values: the 'template' value of
+-- transformation_type is kept.
@@ -0,0 +1 @@
+ALTER TYPE transformation_type ADD VALUE IF NOT EXISTS 'template';
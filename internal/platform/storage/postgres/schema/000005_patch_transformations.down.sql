@@ -0,0 +1,2 @@
+-- PostgreSQL cannot drop enum values: the 'json_patch' and 'merge_patch'
+-- values of transformation_type are kept.
@@ -0,0 +1,2 @@
+ALTER TYPE transformation_type ADD VALUE IF NOT EXISTS 'json_patch';
+ALTER TYPE transformation_type ADD VALUE IF NOT EXISTS 'merge_patch';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_lookup_entries_expires_at;
+DROP INDEX IF EXISTS idx_lookup_tables_user_id;
+
+DROP TABLE IF EXISTS lookup_entries;
+DROP TABLE IF EXISTS lookup_tables;
+
+-- PostgreSQL cannot drop enum values: the 'enrich' value of
+-- transformation_type is kept.
@@ -0,0 +1,32 @@
+ALTER TYPE transformation_type ADD VALUE IF NOT EXISTS 'enrich';
+
+-- Table lookup_tables
+-- A lookup table maps keys to JSON values for the enrich transformation.
+-- ttl_seconds, when set, is the lifetime given to entries written without
+-- their own TTL.
+CREATE TABLE IF NOT EXISTS lookup_tables (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    ttl_seconds INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, name)
+);
+
+-- Table lookup_entries
+-- Expired entries are ignored when reading and overwritten on the next
+-- write of their key.
+CREATE TABLE IF NOT EXISTS lookup_entries (
+    table_id UUID NOT NULL REFERENCES lookup_tables(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    value JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (table_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lookup_tables_user_id ON lookup_tables(user_id);
+CREATE INDEX IF NOT EXISTS idx_lookup_entries_expires_at ON lookup_entries(expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_deliveries_retrying_scheduled_at;
+
+ALTER TABLE destinations DROP COLUMN IF EXISTS retry_policy;
@@ -0,0 +1,8 @@
+-- Backoff policy of the retries of a destination. The number of retries
+-- stays in retry_attempts; empty fields take the defaults.
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS retry_policy JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Deliveries waiting for their next attempt, polled by the retry scheduler.
+CREATE INDEX IF NOT EXISTS idx_deliveries_retrying_scheduled_at
+    ON deliveries(scheduled_at)
+    WHERE status = 'retrying';
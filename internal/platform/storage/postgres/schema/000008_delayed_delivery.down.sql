@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_webhook_events_delayed_scheduled_at;
+
+ALTER TABLE destinations DROP COLUMN IF EXISTS delay_path;
+
+-- PostgreSQL cannot drop enum values: the 'cancelled' value of
+-- webhook_status is kept.
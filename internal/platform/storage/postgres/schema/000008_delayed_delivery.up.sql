@@ -0,0 +1,11 @@
+-- JSON path of a payload field holding the time at which to deliver the
+-- event, used before delay_seconds.
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS delay_path TEXT NOT NULL DEFAULT '';
+
+-- Delayed events cancelled before their delivery.
+ALTER TYPE webhook_status ADD VALUE IF NOT EXISTS 'cancelled';
+
+-- Delayed events, polled by the delivery scheduler once due.
+CREATE INDEX IF NOT EXISTS idx_webhook_events_delayed_scheduled_at
+    ON webhook_events(scheduled_at)
+    WHERE status = 'delayed';
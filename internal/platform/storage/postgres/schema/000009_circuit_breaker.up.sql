@@ -0,0 +1,3 @@
+-- Circuit breaker policy of a destination; empty fields take the defaults.
+-- The state of the circuit itself is kept in Redis.
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS circuit_breaker JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1 @@
+ALTER TABLE destinations DROP COLUMN IF EXISTS limits;
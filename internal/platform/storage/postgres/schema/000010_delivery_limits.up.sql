@@ -0,0 +1,3 @@
+-- Concurrency and rate limits of the deliveries to a destination; empty
+-- fields set no limit. The limits are enforced in Redis.
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS limits JSONB NOT NULL DEFAULT '{}'::jsonb;
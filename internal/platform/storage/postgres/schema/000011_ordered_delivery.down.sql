@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_webhook_events_ordering_key_created_at;
+
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS ordering_key;
+ALTER TABLE destinations DROP COLUMN IF EXISTS ordering_key;
+ALTER TABLE pipelines DROP COLUMN IF EXISTS ordering_key;
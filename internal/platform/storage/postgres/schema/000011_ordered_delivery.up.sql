@@ -0,0 +1,14 @@
+-- Ordering key of a pipeline or a destination: a JSON path into the
+-- payload, or 'source' to order every event of the source. The key of the
+-- pipeline takes precedence.
+ALTER TABLE pipelines ADD COLUMN IF NOT EXISTS ordering_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS ordering_key TEXT NOT NULL DEFAULT '';
+
+-- Resolved ordering key of an event, scoped to its pipeline or destination.
+-- Events sharing a key are delivered in ingestion order.
+ALTER TABLE webhook_events ADD COLUMN IF NOT EXISTS ordering_key TEXT;
+
+-- Unsettled events of each key, looked up before every ordered delivery.
+CREATE INDEX IF NOT EXISTS idx_webhook_events_ordering_key_created_at
+    ON webhook_events(ordering_key, created_at, id)
+    WHERE ordering_key IS NOT NULL AND status IN ('pending', 'transformed', 'delayed');
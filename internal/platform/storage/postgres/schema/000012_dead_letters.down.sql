@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS dead_letters;
+DROP TYPE IF EXISTS dead_letter_status;
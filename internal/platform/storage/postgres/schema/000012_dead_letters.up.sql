@@ -0,0 +1,31 @@
+CREATE TYPE dead_letter_status AS ENUM ('dead', 'redriven', 'discarded');
+
+-- Table dead_letters
+-- A dead letter is a delivery that failed for good, once its retries were
+-- exhausted or retrying could not fix it. It keeps the failure reason and
+-- the last response; the attempts stay in webhook_steps. Redriving a dead
+-- letter creates a new delivery referenced by redrive_delivery_id.
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    delivery_id UUID NOT NULL UNIQUE REFERENCES deliveries(id) ON DELETE CASCADE,
+    webhook_event_id UUID NOT NULL REFERENCES webhook_events(id) ON DELETE CASCADE,
+    destination_id UUID NOT NULL REFERENCES destinations(id) ON DELETE CASCADE,
+    pipeline_id UUID REFERENCES pipelines(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_response JSONB,
+    status dead_letter_status NOT NULL DEFAULT 'dead',
+    redrive_delivery_id UUID REFERENCES deliveries(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_destination_id ON dead_letters(destination_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_pipeline_id ON dead_letters(pipeline_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_webhook_event_id ON dead_letters(webhook_event_id);
+
+CREATE TRIGGER update_dead_letters_updated_at
+    BEFORE UPDATE ON dead_letters
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_deliveries_replay_job_id;
+
+ALTER TABLE deliveries DROP COLUMN IF EXISTS replay_job_id;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS replay_job_id;
+
+DROP TABLE IF EXISTS replay_jobs;
+DROP TYPE IF EXISTS replay_status;
+DROP TYPE IF EXISTS replay_mode;
+
+-- PostgreSQL cannot drop enum values: the 'replay' value of step_type is
+-- kept.
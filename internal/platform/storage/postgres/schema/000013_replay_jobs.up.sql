@@ -0,0 +1,48 @@
+CREATE TYPE replay_mode AS ENUM ('pipeline', 'delivery');
+CREATE TYPE replay_status AS ENUM ('pending', 'running', 'completed', 'cancelled', 'failed');
+
+-- Replays are recorded in the trace of the events they re-run.
+ALTER TYPE step_type ADD VALUE IF NOT EXISTS 'replay';
+
+-- Table replay_jobs
+-- A replay job re-runs the events matching its query, in ingestion order,
+-- through the whole pipeline or only the delivery step. The cursor is the
+-- last event handled, so that a job taken over by another worker resumes
+-- where it stopped. lease_expires_at hides a running job from other
+-- workers while its worker is alive.
+CREATE TABLE IF NOT EXISTS replay_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    mode replay_mode NOT NULL,
+    query JSONB NOT NULL DEFAULT '{}'::jsonb,
+    rate_per_second DOUBLE PRECISION NOT NULL,
+    status replay_status NOT NULL DEFAULT 'pending',
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    cursor_created_at TIMESTAMPTZ,
+    cursor_event_id UUID,
+    error_message TEXT,
+    lease_expires_at TIMESTAMPTZ,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_replay_jobs_status ON replay_jobs(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_replay_jobs_created_at ON replay_jobs(created_at);
+
+CREATE TRIGGER update_replay_jobs_updated_at
+    BEFORE UPDATE ON replay_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- The replay that last re-ran an event, and the one that created a
+-- delivery.
+ALTER TABLE webhook_events ADD COLUMN IF NOT EXISTS replay_job_id UUID REFERENCES replay_jobs(id) ON DELETE SET NULL;
+ALTER TABLE deliveries ADD COLUMN IF NOT EXISTS replay_job_id UUID REFERENCES replay_jobs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_deliveries_replay_job_id ON deliveries(replay_job_id) WHERE replay_job_id IS NOT NULL;
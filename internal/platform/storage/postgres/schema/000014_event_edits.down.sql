@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS event_edits;
+
+DROP INDEX IF EXISTS idx_webhook_events_derived_from_id;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS derived_from_id;
+
+DROP TYPE IF EXISTS event_edit_mode;
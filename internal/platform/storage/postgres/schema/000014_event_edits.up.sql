@@ -0,0 +1,26 @@
+CREATE TYPE event_edit_mode AS ENUM ('pipeline', 'destination');
+
+-- A derived event is a copy of an event edited by hand and sent again. The
+-- original event is left untouched.
+ALTER TABLE webhook_events ADD COLUMN IF NOT EXISTS derived_from_id UUID REFERENCES webhook_events(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_derived_from_id ON webhook_events(derived_from_id) WHERE derived_from_id IS NOT NULL;
+
+-- Table event_edits
+-- The audit trail of the edits: who derived which event from which, what
+-- was changed and where the derived event was sent. Changes holds whether
+-- the payload changed and the headers added, changed and removed.
+CREATE TABLE IF NOT EXISTS event_edits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    original_event_id UUID NOT NULL REFERENCES webhook_events(id) ON DELETE CASCADE,
+    derived_event_id UUID NOT NULL UNIQUE REFERENCES webhook_events(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    mode event_edit_mode NOT NULL,
+    destination_id UUID REFERENCES destinations(id) ON DELETE SET NULL,
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_edits_original_event_id ON event_edits(original_event_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_event_edits_user_id ON event_edits(user_id);
@@ -0,0 +1,40 @@
+package replay
+
+import (
+	"time"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	"github.com/theotruvelot/catchook/pkg/response"
+)
+
+// DefaultRatePerSecond throttles the replays created without a rate.
+const DefaultRatePerSecond = 10
+
+type QueryRequest struct {
+	SourceID  string       `json:"source_id" validate:"omitempty,uuid"`
+	Status    string       `json:"status" validate:"omitempty,oneof=pending filtered transformed delayed delivered failed cancelled"`
+	From      *time.Time   `json:"from"`
+	To        *time.Time   `json:"to"`
+	Condition *filter.Rule `json:"condition"`
+}
+
+type CreateRequest struct {
+	Mode          string       `json:"mode" validate:"required,oneof=pipeline delivery"`
+	Query         QueryRequest `json:"query"`
+	RatePerSecond float64      `json:"rate_per_second" validate:"omitempty,gt=0,max=1000"`
+}
+
+type ReplayEventRequest struct {
+	Mode string `json:"mode" validate:"required,oneof=pipeline delivery"`
+}
+
+type ListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending running completed cancelled failed"`
+	Page   int
+	Limit  int
+}
+
+type ListResponse struct {
+	Jobs       []*Job               `json:"data"`
+	Pagination *response.Pagination `json:"pagination"`
+}
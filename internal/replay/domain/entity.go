@@ -0,0 +1,89 @@
+package replay
+
+import (
+	"encoding/json"
+	"time"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+)
+
+// Mode tells how far a replay re-runs its events.
+type Mode string
+
+const (
+	// ModePipeline runs the events through the current filters and
+	// transformations of their pipeline again, from their original payload,
+	// then delivers them.
+	ModePipeline Mode = "pipeline"
+	// ModeDelivery only delivers the processed payload of the events again,
+	// to the current destination of their pipeline.
+	ModeDelivery Mode = "delivery"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusCancelled Status = "cancelled"
+	StatusFailed    Status = "failed"
+)
+
+// Query selects the events of a replay. Empty fields match every event;
+// From is inclusive and To exclusive. Condition is a rule of the condition
+// filters, evaluated against each event as stored.
+type Query struct {
+	EventID   string       `json:"event_id,omitempty"`
+	SourceID  string       `json:"source_id,omitempty"`
+	Status    string       `json:"status,omitempty"`
+	From      *time.Time   `json:"from,omitempty"`
+	To        *time.Time   `json:"to,omitempty"`
+	Condition *filter.Rule `json:"condition,omitempty"`
+}
+
+// ConditionConfig returns the condition of the query as the config of a
+// condition filter.
+func (q *Query) ConditionConfig() (string, error) {
+	config, err := json.Marshal(&filter.ConditionConfig{Rule: q.Condition})
+	if err != nil {
+		return "", err
+	}
+	return string(config), nil
+}
+
+// EventRef locates an event in ingestion order. The cursor of a job is the
+// last event it handled.
+type EventRef struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Job is a replay running in the background. Total is the number of events
+// matching the query when the job was created, before the condition is
+// evaluated; the events that do not satisfy the condition or are not
+// settled when their turn comes are counted as skipped.
+type Job struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id,omitempty"`
+	Mode          Mode       `json:"mode"`
+	Query         Query      `json:"query"`
+	RatePerSecond float64    `json:"rate_per_second"`
+	Status        Status     `json:"status"`
+	Total         int32      `json:"total"`
+	Processed     int32      `json:"processed"`
+	Succeeded     int32      `json:"succeeded"`
+	Failed        int32      `json:"failed"`
+	Skipped       int32      `json:"skipped"`
+	Cursor        *EventRef  `json:"-"`
+	ErrorMessage  string     `json:"error_message,omitempty"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// IsFinished reports whether the job has stopped for good.
+func (j *Job) IsFinished() bool {
+	return j.Status == StatusCompleted || j.Status == StatusCancelled || j.Status == StatusFailed
+}
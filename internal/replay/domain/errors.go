@@ -0,0 +1,9 @@
+package replay
+
+import "errors"
+
+var (
+	ErrJobNotFound     = errors.New("replay job not found")
+	ErrJobFinished     = errors.New("replay job has already finished")
+	ErrNoMatchingEvent = errors.New("no webhook event matches the replay query")
+)
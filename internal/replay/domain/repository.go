@@ -0,0 +1,28 @@
+package replay
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, job *Job) error
+	GetByID(ctx context.Context, id string) (*Job, error)
+	List(ctx context.Context, status Status, limit, offset int) ([]*Job, int64, error)
+	// Claim leases the oldest pending job, or a running job whose lease
+	// expired, to the caller. It returns nil when there is none.
+	Claim(ctx context.Context, lease time.Duration) (*Job, error)
+	// SaveProgress stores the counters and cursor of a running job and
+	// renews its lease. It reports false once the job has been cancelled.
+	SaveProgress(ctx context.Context, job *Job, lease time.Duration) (bool, error)
+	// Finish moves a running job to its final status.
+	Finish(ctx context.Context, id string, status Status, errorMessage string) error
+	// Cancel reports false when the job has already finished.
+	Cancel(ctx context.Context, id string) (bool, error)
+	// CountEvents returns the number of events matching the query, leaving
+	// the condition aside.
+	CountEvents(ctx context.Context, query Query) (int64, error)
+	// ListEvents returns up to limit events matching the query after the
+	// cursor in ingestion order, leaving the condition aside.
+	ListEvents(ctx context.Context, query Query, after *EventRef, limit int) ([]EventRef, error)
+}
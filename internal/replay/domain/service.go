@@ -0,0 +1,20 @@
+package replay
+
+import (
+	"context"
+
+	"github.com/theotruvelot/catchook/pkg/response"
+)
+
+// Service creates and follows the replay jobs, which a Runner carries out
+// in the background.
+type Service interface {
+	Create(ctx context.Context, userID string, req CreateRequest) (*Job, error)
+	// ReplayEvent creates a job replaying a single event.
+	ReplayEvent(ctx context.Context, userID, eventID string, req ReplayEventRequest) (*Job, error)
+	GetByID(ctx context.Context, id string) (*Job, error)
+	List(ctx context.Context, req ListRequest) ([]*Job, *response.Pagination, error)
+	// Cancel stops a pending or running job. The events already replayed
+	// are left as they are.
+	Cancel(ctx context.Context, id string) (*Job, error)
+}
@@ -0,0 +1,336 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	replay "github.com/theotruvelot/catchook/internal/replay/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type jobRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewJobRepository(db *pgxpool.Pool, appLogger logger.Logger) replay.Repository {
+	return &jobRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r jobRepository) Create(ctx context.Context, job *replay.Job) error {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.create")
+	defer span.End()
+
+	userID, err := toNullUUID(job.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+	query, err := json.Marshal(job.Query)
+	if err != nil {
+		return fmt.Errorf("marshal replay query: %w", err)
+	}
+
+	result, err := r.queries.CreateReplayJob(ctx,
+		userID,
+		generated.ReplayMode(job.Mode),
+		query,
+		job.RatePerSecond,
+		job.Total,
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to create replay job", logger.Error(err))
+		return fmt.Errorf("failed to create replay job: %w", err)
+	}
+
+	created, err := toJob(result)
+	if err != nil {
+		return err
+	}
+	*job = *created
+	return nil
+}
+
+func (r jobRepository) GetByID(ctx context.Context, id string) (*replay.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.get_by_id")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replay job ID format: %w", err)
+	}
+
+	result, err := r.queries.GetReplayJobByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get replay job by ID: %w", err)
+	}
+	return toJob(result)
+}
+
+func (r jobRepository) List(ctx context.Context, status replay.Status, limit, offset int) ([]*replay.Job, int64, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.list")
+	defer span.End()
+
+	results, err := r.queries.ListReplayJobs(ctx, string(status), int32(limit), int32(offset))
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, fmt.Errorf("failed to list replay jobs: %w", err)
+	}
+
+	total, err := r.queries.CountReplayJobs(ctx, string(status))
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, fmt.Errorf("failed to count replay jobs: %w", err)
+	}
+
+	jobs := make([]*replay.Job, 0, len(results))
+	for _, result := range results {
+		job, err := toJob(result)
+		if err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, total, nil
+}
+
+func (r jobRepository) Claim(ctx context.Context, lease time.Duration) (*replay.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.claim")
+	defer span.End()
+
+	result, err := r.queries.ClaimReplayJob(ctx, lease.Seconds())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to claim replay job: %w", err)
+	}
+	return toJob(result)
+}
+
+func (r jobRepository) SaveProgress(ctx context.Context, job *replay.Job, lease time.Duration) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.save_progress")
+	defer span.End()
+
+	uid, err := uuid.Parse(job.ID)
+	if err != nil {
+		return false, fmt.Errorf("invalid replay job ID format: %w", err)
+	}
+
+	var cursorAt pgtype.Timestamptz
+	var cursorID pgtype.UUID
+	if job.Cursor != nil {
+		cursorAt = pgtype.Timestamptz{Time: job.Cursor.CreatedAt, Valid: true}
+		if cursorID, err = toNullUUID(job.Cursor.ID); err != nil {
+			return false, fmt.Errorf("invalid webhook event ID format: %w", err)
+		}
+	}
+
+	rows, err := r.queries.UpdateReplayJobProgress(ctx,
+		uid,
+		job.Processed,
+		job.Succeeded,
+		job.Failed,
+		job.Skipped,
+		cursorAt,
+		cursorID,
+		lease.Seconds(),
+	)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to save replay job progress: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r jobRepository) Finish(ctx context.Context, id string, status replay.Status, errorMessage string) error {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.finish")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid replay job ID format: %w", err)
+	}
+
+	if _, err := r.queries.FinishReplayJob(ctx, uid, generated.ReplayStatus(status), toText(errorMessage)); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to finish replay job: %w", err)
+	}
+	return nil
+}
+
+func (r jobRepository) Cancel(ctx context.Context, id string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.cancel")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid replay job ID format: %w", err)
+	}
+
+	rows, err := r.queries.CancelReplayJob(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to cancel replay job: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r jobRepository) CountEvents(ctx context.Context, query replay.Query) (int64, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.count_events")
+	defer span.End()
+
+	eventID, sourceID, err := queryIDs(query)
+	if err != nil {
+		return 0, err
+	}
+
+	total, err := r.queries.CountReplayWebhookEvents(ctx,
+		eventID,
+		sourceID,
+		query.Status,
+		toTimestamptz(query.From),
+		toTimestamptz(query.To),
+	)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to count webhook events: %w", err)
+	}
+	return total, nil
+}
+
+func (r jobRepository) ListEvents(ctx context.Context, query replay.Query, after *replay.EventRef, limit int) ([]replay.EventRef, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.repository.list_events")
+	defer span.End()
+
+	eventID, sourceID, err := queryIDs(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// The zero time and the nil UUID sort before every event.
+	afterAt := pgtype.Timestamptz{Valid: true}
+	afterID := uuid.Nil
+	if after != nil {
+		afterAt.Time = after.CreatedAt
+		if afterID, err = uuid.Parse(after.ID); err != nil {
+			return nil, fmt.Errorf("invalid webhook event ID format: %w", err)
+		}
+	}
+
+	results, err := r.queries.ListReplayWebhookEvents(ctx,
+		eventID,
+		sourceID,
+		query.Status,
+		toTimestamptz(query.From),
+		toTimestamptz(query.To),
+		afterAt,
+		afterID,
+		int32(limit),
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list webhook events: %w", err)
+	}
+
+	refs := make([]replay.EventRef, 0, len(results))
+	for _, result := range results {
+		refs = append(refs, replay.EventRef{ID: result.ID.String(), CreatedAt: result.CreatedAt.Time})
+	}
+	return refs, nil
+}
+
+func toJob(result generated.ReplayJob) (*replay.Job, error) {
+	job := &replay.Job{
+		ID:            result.ID.String(),
+		Mode:          replay.Mode(result.Mode),
+		RatePerSecond: result.RatePerSecond,
+		Status:        replay.Status(result.Status),
+		Total:         result.Total,
+		Processed:     result.Processed,
+		Succeeded:     result.Succeeded,
+		Failed:        result.Failed,
+		Skipped:       result.Skipped,
+		ErrorMessage:  result.ErrorMessage.String,
+		CreatedAt:     result.CreatedAt.Time,
+		UpdatedAt:     result.UpdatedAt.Time,
+	}
+	if result.UserID.Valid {
+		job.UserID = uuid.UUID(result.UserID.Bytes).String()
+	}
+	if result.CursorCreatedAt.Valid && result.CursorEventID.Valid {
+		job.Cursor = &replay.EventRef{
+			ID:        uuid.UUID(result.CursorEventID.Bytes).String(),
+			CreatedAt: result.CursorCreatedAt.Time,
+		}
+	}
+	if result.StartedAt.Valid {
+		job.StartedAt = &result.StartedAt.Time
+	}
+	if result.CompletedAt.Valid {
+		job.CompletedAt = &result.CompletedAt.Time
+	}
+	if err := json.Unmarshal(result.Query, &job.Query); err != nil {
+		return nil, fmt.Errorf("decode query of replay job %s: %w", job.ID, err)
+	}
+	return job, nil
+}
+
+// queryIDs maps the empty IDs of a query to the nil UUID, which the event
+// queries read as no filter.
+func queryIDs(query replay.Query) (uuid.UUID, uuid.UUID, error) {
+	eventID, sourceID := uuid.Nil, uuid.Nil
+	var err error
+	if query.EventID != "" {
+		if eventID, err = uuid.Parse(query.EventID); err != nil {
+			return uuid.Nil, uuid.Nil, fmt.Errorf("invalid webhook event ID format: %w", err)
+		}
+	}
+	if query.SourceID != "" {
+		if sourceID, err = uuid.Parse(query.SourceID); err != nil {
+			return uuid.Nil, uuid.Nil, fmt.Errorf("invalid source ID format: %w", err)
+		}
+	}
+	return eventID, sourceID, nil
+}
+
+func toNullUUID(id string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return pgtype.UUID{Bytes: uid, Valid: true}, nil
+}
+
+func toText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func toTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	"github.com/theotruvelot/catchook/internal/filter/evaluator"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	replay "github.com/theotruvelot/catchook/internal/replay/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+const defaultJobsLimit = 50
+
+type replayService struct {
+	jobRepo   replay.Repository
+	eventRepo pipeline.EventRepository
+	appLogger logger.Logger
+}
+
+func NewReplayService(
+	jobRepo replay.Repository,
+	eventRepo pipeline.EventRepository,
+	appLogger logger.Logger,
+) replay.Service {
+	return &replayService{
+		jobRepo:   jobRepo,
+		eventRepo: eventRepo,
+		appLogger: appLogger,
+	}
+}
+
+func (s replayService) Create(ctx context.Context, userID string, req replay.CreateRequest) (*replay.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.service.create")
+	defer span.End()
+
+	query := replay.Query{
+		SourceID:  req.Query.SourceID,
+		Status:    req.Query.Status,
+		From:      req.Query.From,
+		To:        req.Query.To,
+		Condition: req.Query.Condition,
+	}
+
+	validationErrors := map[string]string{}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		validationErrors["query.to"] = "must be after from"
+	}
+	if query.Condition != nil {
+		if _, err := compileCondition(query); err != nil {
+			validationErrors["query.condition"] = err.Error()
+		}
+	}
+	if len(validationErrors) > 0 {
+		return nil, &validatorpkg.ValidationErrors{Errors: validationErrors}
+	}
+
+	return s.create(ctx, userID, replay.Mode(req.Mode), query, req.RatePerSecond)
+}
+
+func (s replayService) ReplayEvent(ctx context.Context, userID, eventID string, req replay.ReplayEventRequest) (*replay.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.service.replay_event")
+	defer span.End()
+
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting webhook event: %w", err)
+	}
+	if event == nil {
+		return nil, pipeline.ErrEventNotFound
+	}
+
+	return s.create(ctx, userID, replay.Mode(req.Mode), replay.Query{EventID: event.ID}, 0)
+}
+
+func (s replayService) create(ctx context.Context, userID string, mode replay.Mode, query replay.Query, rate float64) (*replay.Job, error) {
+	total, err := s.jobRepo.CountEvents(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("counting webhook events: %w", err)
+	}
+	if total == 0 {
+		return nil, replay.ErrNoMatchingEvent
+	}
+
+	if rate <= 0 {
+		rate = replay.DefaultRatePerSecond
+	}
+
+	job := &replay.Job{
+		UserID:        userID,
+		Mode:          mode,
+		Query:         query,
+		RatePerSecond: rate,
+		Total:         int32(total),
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("creating replay job: %w", err)
+	}
+
+	s.appLogger.Info(ctx, "Replay job created",
+		logger.String("replay_job_id", job.ID),
+		logger.String("mode", string(job.Mode)),
+		logger.Int("total", int(job.Total)),
+	)
+
+	return job, nil
+}
+
+func (s replayService) GetByID(ctx context.Context, id string) (*replay.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.service.get_by_id")
+	defer span.End()
+
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting replay job: %w", err)
+	}
+	if job == nil {
+		return nil, replay.ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (s replayService) List(ctx context.Context, req replay.ListRequest) ([]*replay.Job, *response.Pagination, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.service.list")
+	defer span.End()
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = defaultJobsLimit
+	}
+
+	jobs, total, err := s.jobRepo.List(ctx, replay.Status(req.Status), req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("listing replay jobs: %w", err)
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	pagination := &response.Pagination{
+		CurrentPage: req.Page,
+		TotalPages:  totalPages,
+		Total:       int(total),
+		Limit:       req.Limit,
+		HasNext:     req.Page < totalPages,
+		HasPrev:     req.Page > 1,
+	}
+
+	return jobs, pagination, nil
+}
+
+func (s replayService) Cancel(ctx context.Context, id string) (*replay.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "replay.service.cancel")
+	defer span.End()
+
+	job, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled, err := s.jobRepo.Cancel(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("cancelling replay job: %w", err)
+	}
+	if !cancelled {
+		return nil, replay.ErrJobFinished
+	}
+
+	s.appLogger.Info(ctx, "Replay job cancelled", logger.String("replay_job_id", job.ID))
+
+	return s.GetByID(ctx, id)
+}
+
+// compileCondition builds the evaluator of the condition of the query, or
+// returns nil when the query has none.
+func compileCondition(query replay.Query) (filter.Evaluator, error) {
+	if query.Condition == nil {
+		return nil, nil
+	}
+	config, err := query.ConditionConfig()
+	if err != nil {
+		return nil, err
+	}
+	return evaluator.New(&filter.Filter{
+		FilterType: filter.FilterTypeCondition,
+		Mode:       filter.ModeNocode,
+		Config:     config,
+	}, evaluator.Options{})
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	delivery "github.com/theotruvelot/catchook/internal/delivery/domain"
+	filter "github.com/theotruvelot/catchook/internal/filter/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	replay "github.com/theotruvelot/catchook/internal/replay/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+// RunnerOptions tunes the runner.
+type RunnerOptions struct {
+	// PollInterval is the time between two polls when no job is pending.
+	PollInterval time.Duration
+	// Lease hides a claimed job from other runners. It is renewed after
+	// every event, so it must exceed the time taken to replay one.
+	Lease time.Duration
+	// BatchSize is the number of events listed at once.
+	BatchSize int
+}
+
+type outcome int
+
+const (
+	outcomeSkipped outcome = iota
+	outcomeSucceeded
+	outcomeFailed
+)
+
+// Runner carries out the replay jobs, one at a time, at the rate of each
+// job. Several instances can run side by side: each job is claimed by a
+// single one, and a job left behind by a runner that stopped is resumed
+// after the last event it handled once its lease expires.
+type Runner struct {
+	jobRepo    replay.Repository
+	eventRepo  pipeline.EventRepository
+	engine     pipeline.Engine
+	dispatcher delivery.Dispatcher
+	opts       RunnerOptions
+	appLogger  logger.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRunner(
+	jobRepo replay.Repository,
+	eventRepo pipeline.EventRepository,
+	engine pipeline.Engine,
+	dispatcher delivery.Dispatcher,
+	opts RunnerOptions,
+	appLogger logger.Logger,
+) *Runner {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 6 * time.Minute
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	return &Runner{
+		jobRepo:    jobRepo,
+		eventRepo:  eventRepo,
+		engine:     engine,
+		dispatcher: dispatcher,
+		opts:       opts,
+		appLogger:  appLogger,
+	}
+}
+
+// Start polls for pending jobs in the background until Stop is called.
+func (r *Runner) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.opts.PollInterval)
+		defer ticker.Stop()
+		for {
+			if r.poll(ctx) && ctx.Err() == nil {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the runner and waits for the event being replayed. The job in
+// progress is left running, to be resumed later.
+func (r *Runner) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
+
+// poll claims a job and runs it. It reports whether a job was found.
+func (r *Runner) poll(ctx context.Context) bool {
+	job, err := r.jobRepo.Claim(ctx, r.opts.Lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.appLogger.Error(ctx, "Failed to claim replay job", logger.Error(err))
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+	r.run(ctx, job)
+	return true
+}
+
+func (r *Runner) run(ctx context.Context, job *replay.Job) {
+	ctx, span := tracer.StartSpan(ctx, "replay.runner.run")
+	defer span.End()
+
+	r.appLogger.Info(ctx, "Replay job started",
+		logger.String("replay_job_id", job.ID),
+		logger.Int("processed", int(job.Processed)),
+	)
+
+	condition, err := compileCondition(job.Query)
+	if err != nil {
+		span.RecordError(err)
+		r.finish(ctx, job, replay.StatusFailed, err.Error())
+		return
+	}
+
+	interval := time.Duration(float64(time.Second) / job.RatePerSecond)
+	next := time.Now()
+	// An event is replayed to the end even when the runner stops, so that
+	// its outcome is counted.
+	workCtx := context.WithoutCancel(ctx)
+
+	for {
+		refs, err := r.jobRepo.ListEvents(ctx, job.Query, job.Cursor, r.opts.BatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			span.RecordError(err)
+			r.finish(ctx, job, replay.StatusFailed, err.Error())
+			return
+		}
+		if len(refs) == 0 {
+			r.finish(ctx, job, replay.StatusCompleted, "")
+			return
+		}
+
+		for _, ref := range refs {
+			if wait := time.Until(next); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			} else if ctx.Err() != nil {
+				return
+			}
+			if now := time.Now(); now.After(next) {
+				next = now
+			}
+			next = next.Add(interval)
+
+			switch r.replay(workCtx, job, condition, ref.ID) {
+			case outcomeSucceeded:
+				job.Succeeded++
+			case outcomeFailed:
+				job.Failed++
+			default:
+				job.Skipped++
+			}
+			job.Processed++
+			job.Cursor = &ref
+
+			running, err := r.jobRepo.SaveProgress(workCtx, job, r.opts.Lease)
+			if err != nil {
+				span.RecordError(err)
+				r.appLogger.Error(ctx, "Failed to save replay job progress",
+					logger.String("replay_job_id", job.ID),
+					logger.Error(err),
+				)
+				return
+			}
+			if !running {
+				r.appLogger.Info(ctx, "Replay job stopped", logger.String("replay_job_id", job.ID))
+				return
+			}
+		}
+	}
+}
+
+// replay runs one event of the job again. The events that no longer exist,
+// do not satisfy the condition of the job or are not settled are skipped.
+func (r *Runner) replay(ctx context.Context, job *replay.Job, condition filter.Evaluator, eventID string) outcome {
+	ctx, span := tracer.StartSpan(ctx, "replay.runner.replay")
+	defer span.End()
+
+	event, err := r.eventRepo.GetByID(ctx, eventID)
+	if err != nil || event == nil {
+		if err != nil {
+			span.RecordError(err)
+			r.logError(ctx, job, eventID, "Failed to get webhook event", err)
+		}
+		return outcomeSkipped
+	}
+
+	if condition != nil {
+		result, err := condition.Evaluate(ctx, event)
+		if err != nil {
+			r.logError(ctx, job, eventID, "Failed to evaluate replay condition", err)
+			return outcomeSkipped
+		}
+		if !result.Passed {
+			return outcomeSkipped
+		}
+	}
+
+	var reset bool
+	if job.Mode == replay.ModeDelivery {
+		reset, err = r.eventRepo.ResetForRedelivery(ctx, eventID, job.ID)
+	} else {
+		reset, err = r.eventRepo.ResetForReplay(ctx, eventID, job.ID)
+	}
+	if err != nil {
+		span.RecordError(err)
+		r.logError(ctx, job, eventID, "Failed to reset webhook event", err)
+		return outcomeFailed
+	}
+	if !reset {
+		return outcomeSkipped
+	}
+	r.recordStep(ctx, job, event)
+
+	if job.Mode == replay.ModePipeline {
+		processed, err := r.engine.Process(ctx, eventID)
+		if err != nil {
+			span.RecordError(err)
+			r.logError(ctx, job, eventID, "Failed to process replayed webhook event", err)
+			return outcomeFailed
+		}
+		if processed.Status == pipeline.StatusFailed {
+			return outcomeFailed
+		}
+		return outcomeSucceeded
+	}
+
+	return r.redeliver(ctx, job, eventID)
+}
+
+// redeliver sends the processed payload of a reset event to the destination
+// of its pipeline and stores the resulting event status.
+func (r *Runner) redeliver(ctx context.Context, job *replay.Job, eventID string) outcome {
+	event, err := r.eventRepo.GetByID(ctx, eventID)
+	if err != nil || event == nil {
+		if err == nil {
+			err = pipeline.ErrEventNotFound
+		}
+		r.logError(ctx, job, eventID, "Failed to get webhook event", err)
+		return outcomeFailed
+	}
+
+	order, err := r.eventRepo.NextStepOrder(ctx, event.ID)
+	if err != nil {
+		r.logError(ctx, job, eventID, "Failed to get next step order", err)
+	}
+
+	record, err := r.dispatcher.Dispatch(ctx, event, order)
+	status := pipeline.StatusFailed
+	if record != nil {
+		status = record.EventStatus()
+	}
+	errorMessage := ""
+	if err != nil {
+		errorMessage = err.Error()
+	}
+	if err := r.eventRepo.UpdateStatus(ctx, event.ID, status, errorMessage); err != nil {
+		r.logError(ctx, job, eventID, "Failed to update webhook event status", err)
+	}
+
+	if status == pipeline.StatusFailed {
+		return outcomeFailed
+	}
+	return outcomeSucceeded
+}
+
+// recordStep adds the replay to the execution trace of the event, ahead of
+// the steps it leads to. A failure to record is logged but does not stop
+// the replay.
+func (r *Runner) recordStep(ctx context.Context, job *replay.Job, event *pipeline.Event) {
+	order, err := r.eventRepo.NextStepOrder(ctx, event.ID)
+	if err == nil {
+		now := time.Now()
+		err = r.eventRepo.CreateStep(ctx, &pipeline.Step{
+			EventID:        event.ID,
+			PipelineID:     event.PipelineID,
+			Type:           pipeline.StepTypeReplay,
+			Name:           "replay",
+			RefID:          job.ID,
+			Status:         pipeline.StepStatusSuccess,
+			ExecutionOrder: order,
+			OutputData: map[string]any{
+				"replay_job_id":   job.ID,
+				"mode":            job.Mode,
+				"previous_status": event.Status,
+			},
+			StartedAt:   now,
+			CompletedAt: now,
+		})
+	}
+	if err != nil {
+		r.logError(ctx, job, event.ID, "Failed to record replay step", err)
+	}
+}
+
+func (r *Runner) finish(ctx context.Context, job *replay.Job, status replay.Status, errorMessage string) {
+	if err := r.jobRepo.Finish(ctx, job.ID, status, errorMessage); err != nil {
+		r.appLogger.Error(ctx, "Failed to finish replay job",
+			logger.String("replay_job_id", job.ID),
+			logger.Error(err),
+		)
+		return
+	}
+	r.appLogger.Info(ctx, "Replay job finished",
+		logger.String("replay_job_id", job.ID),
+		logger.String("status", string(status)),
+		logger.Int("processed", int(job.Processed)),
+		logger.Int("succeeded", int(job.Succeeded)),
+		logger.Int("failed", int(job.Failed)),
+		logger.Int("skipped", int(job.Skipped)),
+	)
+}
+
+func (r *Runner) logError(ctx context.Context, job *replay.Job, eventID, message string, err error) {
+	r.appLogger.Error(ctx, message,
+		logger.String("replay_job_id", job.ID),
+		logger.String("webhook_event_id", eventID),
+		logger.Error(err),
+	)
+}
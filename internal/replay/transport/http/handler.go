@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/internal/platform/http/middleware"
+	replay "github.com/theotruvelot/catchook/internal/replay/domain"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+// Handler holds the replay dependencies
+type Handler struct {
+	replayService replay.Service
+	validator     *validatorpkg.Validator
+}
+
+// NewHandler creates a new replay handler
+func NewHandler(replayService replay.Service, validator *validatorpkg.Validator) *Handler {
+	return &Handler{
+		replayService: replayService,
+		validator:     validator,
+	}
+}
+
+func (h *Handler) CreateReplay(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "replay.handler.create")
+	defer span.End()
+
+	userID, err := middleware.GetAuthUserID(c)
+	if err != nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	var req replay.CreateRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	job, err := h.replayService.Create(ctx, userID, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, replay.ErrNoMatchingEvent):
+			return response.UnprocessableEntity(c, err.Error(), "query")
+		default:
+			return response.InternalError(c, "failed to create replay")
+		}
+	}
+
+	return response.Created(c, job, "replay created")
+}
+
+// ReplayEvent replays a single webhook event.
+func (h *Handler) ReplayEvent(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "replay.handler.replay_event")
+	defer span.End()
+
+	eventID := c.Params("id")
+	if eventID == "" {
+		return response.BadRequest(c, "event_id is required", nil)
+	}
+
+	userID, err := middleware.GetAuthUserID(c)
+	if err != nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	var req replay.ReplayEventRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	job, err := h.replayService.ReplayEvent(ctx, userID, eventID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, pipeline.ErrEventNotFound), errors.Is(err, replay.ErrNoMatchingEvent):
+			return response.NotFound(c, "event not found")
+		default:
+			return response.InternalError(c, "failed to replay event")
+		}
+	}
+
+	return response.Created(c, job, "replay created")
+}
+
+func (h *Handler) ListReplays(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "replay.handler.list")
+	defer span.End()
+
+	req := replay.ListRequest{Status: c.Query("status")}
+
+	req.Page = c.QueryInt("page", 1)
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	req.Limit = c.QueryInt("limit", 50)
+	if req.Limit < 1 {
+		req.Limit = 50
+	}
+	if req.Limit > 1000 {
+		req.Limit = 1000
+	}
+
+	if errs := h.validator.Validate(req); len(errs) > 0 {
+		return response.ValidationFailed(c, errs)
+	}
+
+	jobs, pagination, err := h.replayService.List(ctx, req)
+	if err != nil {
+		return response.InternalError(c, "failed to list replays")
+	}
+
+	return response.Success(c, &replay.ListResponse{
+		Jobs:       jobs,
+		Pagination: pagination,
+	}, "replays listed")
+}
+
+func (h *Handler) GetReplay(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "replay.handler.get")
+	defer span.End()
+
+	id := c.Params("id")
+	if id == "" {
+		return response.BadRequest(c, "replay_id is required", nil)
+	}
+
+	job, err := h.replayService.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, replay.ErrJobNotFound) {
+			return response.NotFound(c, "replay not found")
+		}
+		return response.InternalError(c, "failed to get replay")
+	}
+
+	return response.Success(c, job, "replay retrieved")
+}
+
+func (h *Handler) CancelReplay(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "replay.handler.cancel")
+	defer span.End()
+
+	id := c.Params("id")
+	if id == "" {
+		return response.BadRequest(c, "replay_id is required", nil)
+	}
+
+	job, err := h.replayService.Cancel(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, replay.ErrJobNotFound):
+			return response.NotFound(c, "replay not found")
+		case errors.Is(err, replay.ErrJobFinished):
+			return response.Conflict(c, "replay has already finished")
+		default:
+			return response.InternalError(c, "failed to cancel replay")
+		}
+	}
+
+	return response.Success(c, job, "replay cancelled")
+}
@@ -0,0 +1,687 @@
+package transformation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/theotruvelot/catchook/pkg/jsonpatch"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+	"github.com/theotruvelot/catchook/pkg/xmljson"
+)
+
+type JSONPathAction string
+
+const (
+	// JSONPathActionReplace makes the extracted value the new payload.
+	JSONPathActionReplace JSONPathAction = "replace"
+	// JSONPathActionWrap makes the new payload an object holding the
+	// extracted value under WrapKey.
+	JSONPathActionWrap JSONPathAction = "wrap"
+)
+
+const DefaultWrapKey = "data"
+
+const (
+	MaxHeaderEntries   = 64
+	MaxBodyEntries     = 64
+	MaxTemplateLength  = 64 * 1024
+	DefaultRootElement = xmljson.DefaultRootElement
+	DefaultArrayItem   = xmljson.DefaultArrayItem
+	DefaultAttrPrefix  = xmljson.DefaultAttrPrefix
+	DefaultTextKey     = xmljson.DefaultTextKey
+)
+
+// HeaderValue is a header set by a header_add transformation.
+type HeaderValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HeaderAddConfig configures a header_add transformation. Values are
+// value templates, so they can be static or read from the event.
+//
+//	{"headers": [{"name": "X-Tenant", "value": "acme"}, {"name": "X-Customer", "value": "{{body.data.customer}}"}]}
+type HeaderAddConfig struct {
+	Headers []HeaderValue `json:"headers"`
+}
+
+// HeaderRemoveConfig configures a header_remove transformation. Names are
+// exact header names or glob patterns such as "X-Stripe-*".
+type HeaderRemoveConfig struct {
+	Names []string `json:"names"`
+}
+
+type HeaderOp string
+
+const (
+	HeaderOpRename  HeaderOp = "rename"
+	HeaderOpRewrite HeaderOp = "rewrite"
+)
+
+// HeaderOperation is a step of a header_modify transformation. Rename moves
+// Name to To; rewrite replaces the value of Name with the Value template, in
+// which {{value}} is the current value.
+type HeaderOperation struct {
+	Op    HeaderOp `json:"op"`
+	Name  string   `json:"name"`
+	To    string   `json:"to,omitempty"`
+	Value string   `json:"value,omitempty"`
+}
+
+// HeaderModifyConfig configures a header_modify transformation.
+//
+//	{"operations": [{"op": "rename", "name": "X-Signature", "to": "X-Upstream-Signature"}]}
+type HeaderModifyConfig struct {
+	Operations []HeaderOperation `json:"operations"`
+}
+
+// BodyField is a value set by a body_add transformation. The value is either
+// the static Value or a copy of the value found at From.
+type BodyField struct {
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+	From  string `json:"from,omitempty"`
+}
+
+// BodyAddConfig configures a body_add transformation.
+//
+//	{"fields": [{"path": "$.meta.source", "value": "stripe"}, {"path": "$.customer_id", "from": "$.data.object.customer"}]}
+type BodyAddConfig struct {
+	Fields []BodyField `json:"fields"`
+}
+
+// BodyRemoveConfig configures a body_remove transformation. Paths may use
+// wildcards, such as "$.items[*].internal_id".
+type BodyRemoveConfig struct {
+	Paths []string `json:"paths"`
+}
+
+type BodyOp string
+
+const (
+	BodyOpRename     BodyOp = "rename"
+	BodyOpCast       BodyOp = "cast"
+	BodyOpCase       BodyOp = "case"
+	BodyOpArithmetic BodyOp = "arithmetic"
+	BodyOpDate       BodyOp = "date"
+)
+
+// BodyOperation is a step of a body_modify transformation applied to every
+// value matched by Path:
+//
+//   - rename moves the matched member to the key To;
+//   - cast converts it to Type (string, number, integer or boolean);
+//   - case changes the case of a string (upper, lower, title, snake, camel);
+//   - arithmetic applies Operator (add, subtract, multiply, divide) with Operand;
+//   - date parses it with From and formats it with To, each being rfc3339,
+//     rfc1123, date, unix, unix_ms or a Go layout.
+type BodyOperation struct {
+	Op       BodyOp   `json:"op"`
+	Path     string   `json:"path"`
+	To       string   `json:"to,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Case     string   `json:"case,omitempty"`
+	Operator string   `json:"operator,omitempty"`
+	Operand  *float64 `json:"operand,omitempty"`
+	From     string   `json:"from,omitempty"`
+}
+
+// BodyModifyConfig configures a body_modify transformation.
+type BodyModifyConfig struct {
+	Operations []BodyOperation `json:"operations"`
+}
+
+// FormatXMLConfig configures a format_xml transformation, which serializes
+// the JSON payload as XML. Object members whose key starts with
+// AttributePrefix become attributes, and array elements are written as
+// ArrayItem elements.
+//
+//	{"root_element": "invoice", "attribute_prefix": "@", "array_item": "line"}
+type FormatXMLConfig struct {
+	RootElement     string `json:"root_element"`
+	AttributePrefix string `json:"attribute_prefix"`
+	ArrayItem       string `json:"array_item"`
+}
+
+// FormatJSONConfig configures a format_json transformation, the reverse of
+// format_xml: an XML payload is parsed back into JSON. Attributes become
+// members prefixed with AttributePrefix, text next to attributes or child
+// elements is stored under TextKey, and elements holding only ArrayItem
+// children become arrays.
+type FormatJSONConfig struct {
+	AttributePrefix string `json:"attribute_prefix"`
+	ArrayItem       string `json:"array_item"`
+	TextKey         string `json:"text_key"`
+}
+
+// JSONPatchConfig configures a json_patch transformation, which applies an
+// RFC 6902 patch to the payload. A failing test operation fails the
+// transformation.
+//
+//	{"patch": [{"op": "test", "path": "/type", "value": "invoice.paid"}, {"op": "remove", "path": "/livemode"}]}
+type JSONPatchConfig struct {
+	Patch jsonpatch.Patch `json:"patch"`
+}
+
+// MergePatchConfig configures a merge_patch transformation, which applies
+// an RFC 7396 merge patch to the payload.
+//
+//	{"patch": {"livemode": null, "meta": {"source": "stripe"}}}
+type MergePatchConfig struct {
+	Patch json.RawMessage `json:"patch"`
+}
+
+// TemplateConfig configures a template transformation, which renders the
+// new payload with a Go text/template. ContentType is the media type of the
+// rendered payload, application/json by default, in which case the output
+// must be valid JSON.
+//
+//	{"template": "{\"text\": {{json (printf \"Invoice %v paid\" .Body.id)}}}"}
+type TemplateConfig struct {
+	Template    string `json:"template"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+type EnrichMiss string
+
+const (
+	// EnrichMissDefault writes Default to the target.
+	EnrichMissDefault EnrichMiss = "default"
+	// EnrichMissSkip leaves the payload unchanged.
+	EnrichMissSkip EnrichMiss = "skip"
+	// EnrichMissFail fails the transformation.
+	EnrichMissFail EnrichMiss = "fail"
+)
+
+// EnrichConfig configures an enrich transformation, which looks the value
+// at Source up in a lookup table and writes the value found to Target.
+// OnMiss decides what happens when the key is not in the table or Source
+// is absent; it defaults to default when Default is set, skip otherwise.
+//
+//	{"table_id": "5b0c…", "source": "$.data.customer_id", "target": "$.data.owner_email", "default": "support@acme.io"}
+type EnrichConfig struct {
+	TableID string     `json:"table_id"`
+	Source  string     `json:"source"`
+	Target  string     `json:"target"`
+	Default any        `json:"default,omitempty"`
+	OnMiss  EnrichMiss `json:"on_miss,omitempty"`
+}
+
+// JSONPathConfig configures a jsonpath transformation.
+//
+//	{"expression": "$.data.object", "action": "wrap", "wrap_key": "invoice"}
+type JSONPathConfig struct {
+	Expression string         `json:"expression"`
+	Action     JSONPathAction `json:"action"`
+	WrapKey    string         `json:"wrap_key"`
+}
+
+func JSONPathConfigFromMap(data map[string]interface{}) (*JSONPathConfig, error) {
+	var config JSONPathConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func decodeConfig(data map[string]interface{}, v any) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonData, v)
+}
+
+func (c *JSONPathConfig) Validate() error {
+	if strings.TrimSpace(c.Expression) == "" {
+		return fmt.Errorf("expression is required")
+	}
+	if _, err := jsonpath.Compile(c.Expression); err != nil {
+		return err
+	}
+
+	switch c.Action {
+	case "", JSONPathActionReplace, JSONPathActionWrap:
+	default:
+		return fmt.Errorf("action must be one of: replace wrap")
+	}
+	return nil
+}
+
+// WasmConfig configures a wasm transformation. Module is the content hash of
+// an uploaded module exporting transform(ptr, len).
+type WasmConfig struct {
+	Module string `json:"module"`
+}
+
+func WasmConfigFromMap(data map[string]interface{}) (*WasmConfig, error) {
+	var config WasmConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *WasmConfig) Validate() error {
+	if c.Module == "" {
+		return fmt.Errorf("module is required")
+	}
+	if !wasmvm.IsHash(c.Module) {
+		return fmt.Errorf("module must be the sha256 hash of an uploaded module")
+	}
+	return nil
+}
+
+func HeaderAddConfigFromMap(data map[string]interface{}) (*HeaderAddConfig, error) {
+	var config HeaderAddConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *HeaderAddConfig) Validate() error {
+	if len(c.Headers) == 0 {
+		return fmt.Errorf("headers cannot be empty")
+	}
+	if len(c.Headers) > MaxHeaderEntries {
+		return fmt.Errorf("headers accepts at most %d entries", MaxHeaderEntries)
+	}
+	for i, h := range c.Headers {
+		if !validHeaderName(h.Name) {
+			return fmt.Errorf("headers[%d]: invalid header name %q", i, h.Name)
+		}
+		if _, err := ParseValueTemplate(h.Value); err != nil {
+			return fmt.Errorf("headers[%d].value: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func HeaderRemoveConfigFromMap(data map[string]interface{}) (*HeaderRemoveConfig, error) {
+	var config HeaderRemoveConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *HeaderRemoveConfig) Validate() error {
+	if len(c.Names) == 0 {
+		return fmt.Errorf("names cannot be empty")
+	}
+	if len(c.Names) > MaxHeaderEntries {
+		return fmt.Errorf("names accepts at most %d entries", MaxHeaderEntries)
+	}
+	for i, name := range c.Names {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("names[%d]: name cannot be empty", i)
+		}
+		if _, err := path.Match(name, ""); err != nil {
+			return fmt.Errorf("names[%d]: invalid pattern %q", i, name)
+		}
+	}
+	return nil
+}
+
+func HeaderModifyConfigFromMap(data map[string]interface{}) (*HeaderModifyConfig, error) {
+	var config HeaderModifyConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *HeaderModifyConfig) Validate() error {
+	if len(c.Operations) == 0 {
+		return fmt.Errorf("operations cannot be empty")
+	}
+	if len(c.Operations) > MaxHeaderEntries {
+		return fmt.Errorf("operations accepts at most %d entries", MaxHeaderEntries)
+	}
+	for i, op := range c.Operations {
+		if !validHeaderName(op.Name) {
+			return fmt.Errorf("operations[%d]: invalid header name %q", i, op.Name)
+		}
+		switch op.Op {
+		case HeaderOpRename:
+			if !validHeaderName(op.To) {
+				return fmt.Errorf("operations[%d]: rename requires a valid header name in to", i)
+			}
+		case HeaderOpRewrite:
+			if _, err := ParseValueTemplate(op.Value); err != nil {
+				return fmt.Errorf("operations[%d].value: %w", i, err)
+			}
+		case "":
+			return fmt.Errorf("operations[%d]: op is required", i)
+		default:
+			return fmt.Errorf("operations[%d]: op must be one of: rename rewrite", i)
+		}
+	}
+	return nil
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r >= 0x7f || r <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func BodyAddConfigFromMap(data map[string]interface{}) (*BodyAddConfig, error) {
+	var config BodyAddConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *BodyAddConfig) Validate() error {
+	if len(c.Fields) == 0 {
+		return fmt.Errorf("fields cannot be empty")
+	}
+	if len(c.Fields) > MaxBodyEntries {
+		return fmt.Errorf("fields accepts at most %d entries", MaxBodyEntries)
+	}
+	for i, field := range c.Fields {
+		if err := validateTargetPath(field.Path); err != nil {
+			return fmt.Errorf("fields[%d].path: %w", i, err)
+		}
+		if field.From != "" {
+			if field.Value != nil {
+				return fmt.Errorf("fields[%d]: value and from are mutually exclusive", i)
+			}
+			if _, err := jsonpath.Compile(field.From); err != nil {
+				return fmt.Errorf("fields[%d].from: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func BodyRemoveConfigFromMap(data map[string]interface{}) (*BodyRemoveConfig, error) {
+	var config BodyRemoveConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *BodyRemoveConfig) Validate() error {
+	if len(c.Paths) == 0 {
+		return fmt.Errorf("paths cannot be empty")
+	}
+	if len(c.Paths) > MaxBodyEntries {
+		return fmt.Errorf("paths accepts at most %d entries", MaxBodyEntries)
+	}
+	for i, p := range c.Paths {
+		compiled, err := jsonpath.Compile(p)
+		if err != nil {
+			return fmt.Errorf("paths[%d]: %w", i, err)
+		}
+		if compiled.String() == "$" {
+			return fmt.Errorf("paths[%d]: the root cannot be removed", i)
+		}
+	}
+	return nil
+}
+
+func BodyModifyConfigFromMap(data map[string]interface{}) (*BodyModifyConfig, error) {
+	var config BodyModifyConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *BodyModifyConfig) Validate() error {
+	if len(c.Operations) == 0 {
+		return fmt.Errorf("operations cannot be empty")
+	}
+	if len(c.Operations) > MaxBodyEntries {
+		return fmt.Errorf("operations accepts at most %d entries", MaxBodyEntries)
+	}
+	for i, op := range c.Operations {
+		if err := op.validate(); err != nil {
+			return fmt.Errorf("operations[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (op *BodyOperation) validate() error {
+	if _, err := jsonpath.Compile(op.Path); err != nil {
+		return fmt.Errorf("path: %w", err)
+	}
+
+	switch op.Op {
+	case BodyOpRename:
+		if op.To == "" {
+			return fmt.Errorf("rename requires the new key in to")
+		}
+	case BodyOpCast:
+		switch op.Type {
+		case "string", "number", "integer", "boolean":
+		default:
+			return fmt.Errorf("cast type must be one of: string number integer boolean")
+		}
+	case BodyOpCase:
+		switch op.Case {
+		case "upper", "lower", "title", "snake", "camel":
+		default:
+			return fmt.Errorf("case must be one of: upper lower title snake camel")
+		}
+	case BodyOpArithmetic:
+		switch op.Operator {
+		case "add", "subtract", "multiply", "divide":
+		default:
+			return fmt.Errorf("operator must be one of: add subtract multiply divide")
+		}
+		if op.Operand == nil {
+			return fmt.Errorf("arithmetic requires an operand")
+		}
+		if op.Operator == "divide" && *op.Operand == 0 {
+			return fmt.Errorf("operand cannot be zero for divide")
+		}
+	case BodyOpDate:
+		if op.From == "" || op.To == "" {
+			return fmt.Errorf("date requires the from and to formats")
+		}
+	case "":
+		return fmt.Errorf("op is required")
+	default:
+		return fmt.Errorf("op must be one of: rename cast case arithmetic date")
+	}
+	return nil
+}
+
+// validateTargetPath checks that p designates a single location below the
+// root, which is what a value can be written to.
+func validateTargetPath(p string) error {
+	compiled, err := jsonpath.Compile(p)
+	if err != nil {
+		return err
+	}
+	if !compiled.IsDefinite() {
+		return fmt.Errorf("%q must designate a single value, without wildcards, slices, filters or descendants", p)
+	}
+	if compiled.String() == "$" {
+		return fmt.Errorf("the root cannot be a target")
+	}
+	return nil
+}
+
+func FormatXMLConfigFromMap(data map[string]interface{}) (*FormatXMLConfig, error) {
+	var config FormatXMLConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *FormatXMLConfig) Validate() error {
+	if c.RootElement != "" && !validXMLName(c.RootElement) {
+		return fmt.Errorf("root_element %q is not a valid XML name", c.RootElement)
+	}
+	if c.ArrayItem != "" && !validXMLName(c.ArrayItem) {
+		return fmt.Errorf("array_item %q is not a valid XML name", c.ArrayItem)
+	}
+	if strings.ContainsAny(c.AttributePrefix, " <>&\"'") {
+		return fmt.Errorf("attribute_prefix contains invalid characters")
+	}
+	return nil
+}
+
+func FormatJSONConfigFromMap(data map[string]interface{}) (*FormatJSONConfig, error) {
+	var config FormatJSONConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *FormatJSONConfig) Validate() error {
+	if c.ArrayItem != "" && !validXMLName(c.ArrayItem) {
+		return fmt.Errorf("array_item %q is not a valid XML name", c.ArrayItem)
+	}
+	if c.TextKey != "" && c.AttributePrefix != "" && strings.HasPrefix(c.TextKey, c.AttributePrefix) {
+		return fmt.Errorf("text_key cannot start with attribute_prefix")
+	}
+	return nil
+}
+
+func TemplateConfigFromMap(data map[string]interface{}) (*TemplateConfig, error) {
+	var config TemplateConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *TemplateConfig) Validate() error {
+	if strings.TrimSpace(c.Template) == "" {
+		return fmt.Errorf("template is required")
+	}
+	if len(c.Template) > MaxTemplateLength {
+		return fmt.Errorf("template exceeds %d bytes", MaxTemplateLength)
+	}
+	switch c.ContentType {
+	case "", "application/json", "application/xml", "application/x-www-form-urlencoded", "text/plain":
+	default:
+		return fmt.Errorf("content_type must be one of: application/json application/xml application/x-www-form-urlencoded text/plain")
+	}
+	return nil
+}
+
+func JSONPatchConfigFromMap(data map[string]interface{}) (*JSONPatchConfig, error) {
+	var config JSONPatchConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *JSONPatchConfig) Validate() error {
+	if len(c.Patch) == 0 {
+		return fmt.Errorf("patch cannot be empty")
+	}
+	return c.Patch.Validate()
+}
+
+func MergePatchConfigFromMap(data map[string]interface{}) (*MergePatchConfig, error) {
+	var config MergePatchConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *MergePatchConfig) Validate() error {
+	if len(c.Patch) == 0 {
+		return fmt.Errorf("patch is required")
+	}
+	if !json.Valid(c.Patch) {
+		return fmt.Errorf("patch is not valid JSON")
+	}
+	if string(bytes.TrimSpace(c.Patch)) == "null" {
+		return fmt.Errorf("patch cannot be null")
+	}
+	return nil
+}
+
+func EnrichConfigFromMap(data map[string]interface{}) (*EnrichConfig, error) {
+	var config EnrichConfig
+	if err := decodeConfig(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *EnrichConfig) Validate() error {
+	if c.TableID == "" {
+		return fmt.Errorf("table_id is required")
+	}
+	if _, err := uuid.Parse(c.TableID); err != nil {
+		return fmt.Errorf("table_id must be a UUID")
+	}
+	for _, field := range []struct{ name, path string }{{"source", c.Source}, {"target", c.Target}} {
+		name := field.name
+		if strings.TrimSpace(field.path) == "" {
+			return fmt.Errorf("%s is required", name)
+		}
+		compiled, err := jsonpath.Compile(field.path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if !compiled.IsDefinite() {
+			return fmt.Errorf("%s must designate a single value", name)
+		}
+	}
+
+	switch c.OnMiss {
+	case "", EnrichMissDefault, EnrichMissSkip, EnrichMissFail:
+	default:
+		return fmt.Errorf("on_miss must be one of: default skip fail")
+	}
+	return nil
+}
+
+// Miss returns the behavior applied on a missing key.
+func (c *EnrichConfig) Miss() EnrichMiss {
+	switch {
+	case c.OnMiss != "":
+		return c.OnMiss
+	case c.Default != nil:
+		return EnrichMissDefault
+	default:
+		return EnrichMissSkip
+	}
+}
+
+// validXMLName reports whether name can be used as an element name. It
+// accepts the ASCII subset of the XML name production, without namespaces.
+func validXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
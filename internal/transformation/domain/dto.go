@@ -0,0 +1,135 @@
+package transformation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/theotruvelot/catchook/pkg/jsonpatch"
+)
+
+type CreateRequest struct {
+	Name               string         `json:"name" validate:"required,min=2,max=100"`
+	Description        string         `json:"description" validate:"omitempty,max=255"`
+	TransformationType Type           `json:"transformation_type" validate:"required,oneof=header_add header_remove header_modify body_add body_remove body_modify format_json format_xml javascript jsonpath wasm template json_patch merge_patch enrich"`
+	Mode               Mode           `json:"mode" validate:"omitempty,oneof=nocode code"`
+	Config             map[string]any `json:"config" validate:"omitempty"`
+	Code               string         `json:"code" validate:"omitempty"`
+	ExecutionOrder     int32          `json:"execution_order" validate:"omitempty,min=1"`
+}
+
+type UpdateRequest struct {
+	Name               string         `json:"name" validate:"omitempty,min=2,max=100"`
+	Description        string         `json:"description" validate:"omitempty,max=255"`
+	TransformationType Type           `json:"transformation_type" validate:"omitempty,oneof=header_add header_remove header_modify body_add body_remove body_modify format_json format_xml javascript jsonpath wasm template json_patch merge_patch enrich"`
+	Mode               Mode           `json:"mode" validate:"omitempty,oneof=nocode code"`
+	Config             map[string]any `json:"config" validate:"omitempty"`
+	Code               string         `json:"code" validate:"omitempty"`
+	ExecutionOrder     int32          `json:"execution_order" validate:"omitempty,min=1"`
+	IsActive           *bool          `json:"is_active" validate:"omitempty"`
+}
+
+// ReorderRequest lists every transformation of the pipeline in the new
+// execution order.
+type ReorderRequest struct {
+	TransformationIDs []string `json:"transformation_ids" validate:"required,min=1,dive,uuid"`
+}
+
+// GeneratePatchRequest holds two sample payloads to derive a patch from.
+type GeneratePatchRequest struct {
+	Before json.RawMessage `json:"before" validate:"required"`
+	After  json.RawMessage `json:"after" validate:"required"`
+	Format Type            `json:"format" validate:"omitempty,oneof=json_patch merge_patch"`
+}
+
+// GeneratePatchResponse is ready to be used as the type and config of a new
+// transformation.
+type GeneratePatchResponse struct {
+	TransformationType Type           `json:"transformation_type"`
+	Config             map[string]any `json:"config"`
+}
+
+// PreviewRequest designates the input of a preview: a stored event of the
+// pipeline, or an ad-hoc payload with optional headers and metadata.
+type PreviewRequest struct {
+	EventID  string            `json:"event_id" validate:"omitempty,uuid"`
+	Payload  json.RawMessage   `json:"payload"`
+	Headers  map[string]string `json:"headers"`
+	Metadata map[string]any    `json:"metadata"`
+}
+
+// PreviewResponse is the outcome of running the active transformations of
+// a pipeline without storing anything. Diff turns the original payload into
+// Output. When a transformation fails, Error is set and Output is the state
+// left by the previous one.
+type PreviewResponse struct {
+	Output      json.RawMessage   `json:"output"`
+	ContentType string            `json:"content_type"`
+	Headers     map[string]string `json:"headers"`
+	Diff        jsonpatch.Patch   `json:"diff"`
+	Steps       []*PreviewStep    `json:"steps"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// PreviewStep is the state after one transformation. Payloads that are not
+// JSON, such as XML, are given as a JSON string.
+type PreviewStep struct {
+	*Result
+	Status      string            `json:"status"`
+	Output      json.RawMessage   `json:"output,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+type TransformationResponse struct {
+	ID                 string         `json:"id"`
+	PipelineID         string         `json:"pipeline_id"`
+	Name               string         `json:"name"`
+	Description        string         `json:"description"`
+	TransformationType string         `json:"transformation_type"`
+	Mode               string         `json:"mode"`
+	Config             map[string]any `json:"config,omitempty"`
+	Code               string         `json:"code,omitempty"`
+	IsActive           bool           `json:"is_active"`
+	ExecutionOrder     int32          `json:"execution_order"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+func (t *Transformation) ToResponse() (*TransformationResponse, error) {
+	resp := &TransformationResponse{
+		ID:                 t.ID,
+		PipelineID:         t.PipelineID,
+		Name:               t.Name,
+		Description:        t.Description,
+		TransformationType: string(t.TransformationType),
+		Mode:               string(t.Mode),
+		Code:               t.Code,
+		IsActive:           t.IsActive,
+		ExecutionOrder:     t.ExecutionOrder,
+		CreatedAt:          t.CreatedAt,
+		UpdatedAt:          t.UpdatedAt,
+	}
+
+	if t.Config != "" {
+		var cfg map[string]any
+		if err := json.Unmarshal([]byte(t.Config), &cfg); err != nil {
+			return nil, fmt.Errorf("unmarshal config: %w", err)
+		}
+		resp.Config = cfg
+	}
+
+	return resp, nil
+}
+
+func ToResponses(list []*Transformation) ([]*TransformationResponse, error) {
+	resp := make([]*TransformationResponse, 0, len(list))
+	for _, item := range list {
+		r, err := item.ToResponse()
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, r)
+	}
+	return resp, nil
+}
@@ -0,0 +1,47 @@
+package transformation
+
+import (
+	"time"
+)
+
+type Type string
+
+const (
+	TypeHeaderAdd    Type = "header_add"
+	TypeHeaderRemove Type = "header_remove"
+	TypeHeaderModify Type = "header_modify"
+	TypeBodyAdd      Type = "body_add"
+	TypeBodyRemove   Type = "body_remove"
+	TypeBodyModify   Type = "body_modify"
+	TypeFormatJSON   Type = "format_json"
+	TypeFormatXML    Type = "format_xml"
+	TypeJavascript   Type = "javascript"
+	TypeJSONPath     Type = "jsonpath"
+	TypeWasm         Type = "wasm"
+	TypeTemplate     Type = "template"
+	TypeJSONPatch    Type = "json_patch"
+	TypeMergePatch   Type = "merge_patch"
+	TypeEnrich       Type = "enrich"
+)
+
+type Mode string
+
+const (
+	ModeNocode Mode = "nocode"
+	ModeCode   Mode = "code"
+)
+
+type Transformation struct {
+	ID                 string    `json:"id"`
+	PipelineID         string    `json:"pipeline_id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	TransformationType Type      `json:"transformation_type"`
+	Mode               Mode      `json:"mode"`
+	Config             string    `json:"config"`
+	Code               string    `json:"code"`
+	IsActive           bool      `json:"is_active"`
+	ExecutionOrder     int32     `json:"execution_order"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
@@ -0,0 +1,9 @@
+package transformation
+
+import "errors"
+
+var (
+	ErrTransformationNotFound        = errors.New("transformation not found")
+	ErrUnsupportedTransformationType = errors.New("unsupported transformation type")
+	ErrInvalidOrder                  = errors.New("transformation_ids must list every transformation of the pipeline exactly once")
+)
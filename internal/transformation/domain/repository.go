@@ -0,0 +1,19 @@
+package transformation
+
+import (
+	"context"
+)
+
+type Repository interface {
+	Create(ctx context.Context, transformation *Transformation) error
+	GetByID(ctx context.Context, id string) (*Transformation, error)
+	ListByPipeline(ctx context.Context, pipelineID string) ([]*Transformation, error)
+	ListActiveByPipeline(ctx context.Context, pipelineID string) ([]*Transformation, error)
+	Update(ctx context.Context, transformation *Transformation) error
+	Delete(ctx context.Context, id string) error
+	// Reorder sets the execution order of the transformations of the
+	// pipeline to their position in ids, starting at 1, in a single
+	// transaction. It reports false, changing nothing, when ids are not
+	// exactly the transformations of the pipeline.
+	Reorder(ctx context.Context, pipelineID string, ids []string) (bool, error)
+}
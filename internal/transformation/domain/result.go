@@ -0,0 +1,28 @@
+package transformation
+
+import "encoding/json"
+
+// Result is the outcome of one transformation for one event. Details
+// carries the type-specific explanation of what was changed.
+type Result struct {
+	TransformationID   string `json:"transformation_id"`
+	Name               string `json:"name"`
+	TransformationType Type   `json:"transformation_type"`
+	Error              string `json:"error,omitempty"`
+	Details            any    `json:"details,omitempty"`
+	// Logs holds the console output of code transformations.
+	Logs []string `json:"logs,omitempty"`
+	// Snapshot holds the payload around body transformations.
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+}
+
+// Snapshot is the payload before and after a transformation.
+type Snapshot struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// Results is the document stored in webhook_events.transformation_results.
+type Results struct {
+	Transformations []*Result `json:"transformations"`
+}
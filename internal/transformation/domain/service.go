@@ -0,0 +1,24 @@
+package transformation
+
+import (
+	"context"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+)
+
+type Service interface {
+	Create(ctx context.Context, pipelineID string, req CreateRequest) (*Transformation, error)
+	GetByID(ctx context.Context, pipelineID, id string) (*Transformation, error)
+	List(ctx context.Context, pipelineID string) ([]*Transformation, error)
+	Update(ctx context.Context, pipelineID, id string, req UpdateRequest) (*Transformation, error)
+	Delete(ctx context.Context, pipelineID, id string) error
+	Reorder(ctx context.Context, pipelineID string, req ReorderRequest) ([]*Transformation, error)
+	GeneratePatch(ctx context.Context, req GeneratePatchRequest) (*GeneratePatchResponse, error)
+	Preview(ctx context.Context, pipelineID string, req PreviewRequest) (*PreviewResponse, error)
+}
+
+// Executor applies a single transformation to an event in place. It must
+// leave the event untouched when it returns an error.
+type Executor interface {
+	Apply(ctx context.Context, event *pipeline.Event) (*Result, error)
+}
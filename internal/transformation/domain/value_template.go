@@ -0,0 +1,154 @@
+package transformation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// ValueTemplate is a string with {{placeholders}} resolved against an
+// event. A placeholder is one of:
+//
+//	{{body.customer.id}}      JSONPath into the body, also {{body.items[0].sku}}
+//	{{metadata.tenant}}       JSONPath into the metadata
+//	{{headers.X-Event-Type}}  request header, case-insensitive
+//	{{query.page}}            query parameter
+//	{{captures.action}}       named group captured by a regex filter
+//	{{value}}                 current value, where the template rewrites one
+//
+// Missing values render as an empty string. Strings are inserted as is and
+// other values as JSON.
+type ValueTemplate struct {
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal string
+	scope   string
+	name    string
+	path    *jsonpath.Path
+}
+
+const (
+	placeholderOpen  = "{{"
+	placeholderClose = "}}"
+)
+
+func ParseValueTemplate(s string) (*ValueTemplate, error) {
+	t := &ValueTemplate{}
+	for {
+		start := strings.Index(s, placeholderOpen)
+		if start < 0 {
+			if s != "" {
+				t.parts = append(t.parts, templatePart{literal: s})
+			}
+			return t, nil
+		}
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{literal: s[:start]})
+		}
+		s = s[start+len(placeholderOpen):]
+
+		end := strings.Index(s, placeholderClose)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed placeholder")
+		}
+		part, err := parsePlaceholder(strings.TrimSpace(s[:end]))
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, part)
+		s = s[end+len(placeholderClose):]
+	}
+}
+
+func parsePlaceholder(expr string) (templatePart, error) {
+	if expr == "value" {
+		return templatePart{scope: expr}, nil
+	}
+
+	scope, rest := expr, ""
+	if i := strings.IndexAny(expr, ".["); i >= 0 {
+		scope, rest = expr[:i], expr[i:]
+	}
+
+	switch scope {
+	case "body", "metadata":
+		p := "$" + rest
+		path, err := jsonpath.Compile(p)
+		if err != nil {
+			return templatePart{}, fmt.Errorf("placeholder {{%s}}: %w", expr, err)
+		}
+		if !path.IsDefinite() {
+			return templatePart{}, fmt.Errorf("placeholder {{%s}} must designate a single value", expr)
+		}
+		return templatePart{scope: scope, path: path}, nil
+	case "headers", "query", "captures":
+		name := strings.TrimPrefix(rest, ".")
+		if name == "" || !strings.HasPrefix(rest, ".") {
+			return templatePart{}, fmt.Errorf("placeholder {{%s}} must name a %s entry", expr, scope)
+		}
+		return templatePart{scope: scope, name: name}, nil
+	default:
+		return templatePart{}, fmt.Errorf("placeholder {{%s}} must start with one of: body metadata headers query captures value", expr)
+	}
+}
+
+// Render resolves the template. body is the decoded payload, passed in so
+// that it is decoded once per transformation; current is the value of
+// {{value}}.
+func (t *ValueTemplate) Render(event *pipeline.Event, body any, current string) string {
+	var sb strings.Builder
+	for _, part := range t.parts {
+		switch part.scope {
+		case "":
+			sb.WriteString(part.literal)
+		case "value":
+			sb.WriteString(current)
+		case "body":
+			if v, ok := part.path.Get(body); ok {
+				sb.WriteString(stringify(v))
+			}
+		case "metadata":
+			if v, ok := part.path.Get(map[string]any(event.Metadata)); ok {
+				sb.WriteString(stringify(v))
+			}
+		case "headers":
+			v, _ := event.Header(part.name)
+			sb.WriteString(v)
+		case "query":
+			sb.WriteString(event.Query[part.name])
+		case "captures":
+			sb.WriteString(event.Captures[part.name])
+		}
+	}
+	return sb.String()
+}
+
+// UsesBody reports whether rendering needs the decoded body.
+func (t *ValueTemplate) UsesBody() bool {
+	for _, part := range t.parts {
+		if part.scope == "body" {
+			return true
+		}
+	}
+	return false
+}
+
+func stringify(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,288 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// BodyAddDetails lists the paths written by a body_add transformation and
+// the source paths that matched nothing, whose fields were skipped.
+type BodyAddDetails struct {
+	Set     []string `json:"set,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// BodyRemoveDetails lists the locations removed by a body_remove
+// transformation.
+type BodyRemoveDetails struct {
+	Removed []string `json:"removed,omitempty"`
+}
+
+// BodyModifyDetails reports how many values each operation of a
+// body_modify transformation changed.
+type BodyModifyDetails struct {
+	Operations []BodyOperationDetails `json:"operations"`
+}
+
+type BodyOperationDetails struct {
+	Op      transformation.BodyOp `json:"op"`
+	Path    string                `json:"path"`
+	Matches int                   `json:"matches"`
+}
+
+type compiledBodyField struct {
+	path  *jsonpath.Path
+	value json.RawMessage
+	from  *jsonpath.Path
+}
+
+type bodyAddExecutor struct {
+	fields []compiledBodyField
+}
+
+func newBodyAddExecutor(config string) (*bodyAddExecutor, error) {
+	var cfg transformation.BodyAddConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid body_add config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid body_add config: %w", err)
+	}
+
+	e := &bodyAddExecutor{}
+	for _, f := range cfg.Fields {
+		field := compiledBodyField{path: jsonpath.MustCompile(f.Path)}
+		if f.From != "" {
+			field.from = jsonpath.MustCompile(f.From)
+		} else {
+			value, err := json.Marshal(f.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid body_add config: %w", err)
+			}
+			field.value = value
+		}
+		e.fields = append(e.fields, field)
+	}
+	return e, nil
+}
+
+// Apply writes every field, creating the missing objects along its path.
+// Sources are read from the body as it was before the transformation.
+func (e *bodyAddExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	source := cloneJSON(body)
+
+	details := &BodyAddDetails{}
+	for _, f := range e.fields {
+		var value any
+		if f.from != nil {
+			v, ok := f.from.Get(source)
+			if !ok {
+				details.Missing = append(details.Missing, f.from.String())
+				continue
+			}
+			value = cloneJSON(v)
+		} else if value, err = pipeline.DecodeJSON(f.value); err != nil {
+			return nil, err
+		}
+
+		if body, err = f.path.Set(body, value); err != nil {
+			return nil, fmt.Errorf("set %s: %w", f.path, err)
+		}
+		details.Set = append(details.Set, f.path.String())
+	}
+
+	return applyBody(event, body, details)
+}
+
+type bodyRemoveExecutor struct {
+	paths []*jsonpath.Path
+}
+
+func newBodyRemoveExecutor(config string) (*bodyRemoveExecutor, error) {
+	var cfg transformation.BodyRemoveConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid body_remove config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid body_remove config: %w", err)
+	}
+
+	e := &bodyRemoveExecutor{}
+	for _, p := range cfg.Paths {
+		e.paths = append(e.paths, jsonpath.MustCompile(p))
+	}
+	return e, nil
+}
+
+// Apply removes every value matched by the paths. Paths matching nothing
+// are not an error.
+func (e *bodyRemoveExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	var locations []jsonpath.Location
+	for _, p := range e.paths {
+		for _, node := range p.Locate(body) {
+			locations = append(locations, node.Location)
+		}
+	}
+	body = jsonpath.Remove(body, locations)
+
+	details := &BodyRemoveDetails{}
+	for _, loc := range locations {
+		details.Removed = append(details.Removed, loc.String())
+	}
+	return applyBody(event, body, details)
+}
+
+type compiledBodyOperation struct {
+	transformation.BodyOperation
+	path *jsonpath.Path
+}
+
+type bodyModifyExecutor struct {
+	operations []compiledBodyOperation
+}
+
+func newBodyModifyExecutor(config string) (*bodyModifyExecutor, error) {
+	var cfg transformation.BodyModifyConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid body_modify config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid body_modify config: %w", err)
+	}
+
+	e := &bodyModifyExecutor{}
+	for _, op := range cfg.Operations {
+		e.operations = append(e.operations, compiledBodyOperation{
+			BodyOperation: op,
+			path:          jsonpath.MustCompile(op.Path),
+		})
+	}
+	return e, nil
+}
+
+// Apply runs the operations in order, each on the values matched by its
+// path in the body left by the previous one. Null values are left as they
+// are; a value of the wrong type fails the transformation.
+func (e *bodyModifyExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	details := &BodyModifyDetails{}
+	for _, op := range e.operations {
+		nodes := op.path.Locate(body)
+		for _, node := range nodes {
+			if body, err = modifyValue(body, node, op.BodyOperation); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", op.Op, node.Location, err)
+			}
+		}
+		details.Operations = append(details.Operations, BodyOperationDetails{
+			Op:      op.Op,
+			Path:    op.path.String(),
+			Matches: len(nodes),
+		})
+	}
+
+	return applyBody(event, body, details)
+}
+
+func modifyValue(body any, node jsonpath.Node, op transformation.BodyOperation) (any, error) {
+	if op.Op == transformation.BodyOpRename {
+		return renameMember(body, node, op.To)
+	}
+	if node.Value == nil {
+		return body, nil
+	}
+
+	var value any
+	var err error
+	switch op.Op {
+	case transformation.BodyOpCast:
+		value, err = castValue(node.Value, op.Type)
+	case transformation.BodyOpCase:
+		value, err = changeCase(node.Value, op.Case)
+	case transformation.BodyOpArithmetic:
+		value, err = compute(node.Value, op.Operator, *op.Operand)
+	case transformation.BodyOpDate:
+		value, err = reformatDate(node.Value, op.From, op.To)
+	default:
+		err = fmt.Errorf("unsupported operation")
+	}
+	if err != nil {
+		return body, err
+	}
+	return jsonpath.Replace(body, node.Location, value)
+}
+
+func renameMember(body any, node jsonpath.Node, to string) (any, error) {
+	if len(node.Location) == 0 {
+		return body, fmt.Errorf("the root cannot be renamed")
+	}
+	key, ok := node.Location[len(node.Location)-1].(string)
+	if !ok {
+		return body, fmt.Errorf("only object members can be renamed")
+	}
+	parent, _ := jsonpath.Lookup(body, node.Location[:len(node.Location)-1])
+	if m, ok := parent.(map[string]any); ok && key != to {
+		delete(m, key)
+		m[to] = node.Value
+	}
+	return body, nil
+}
+
+// applyBody encodes the new body into the event payload and snapshots the
+// payload before and after the change.
+func applyBody(event *pipeline.Event, body any, details any) (*transformation.Result, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+
+	before := json.RawMessage(event.Payload)
+	if len(bytes.TrimSpace(before)) == 0 {
+		before = json.RawMessage("null")
+	}
+	event.Payload = payload
+
+	return &transformation.Result{
+		Details:  details,
+		Snapshot: &transformation.Snapshot{Before: before, After: payload},
+	}, nil
+}
+
+// cloneJSON deep copies a decoded JSON value, so that a value copied to
+// another path is not shared between the two.
+func cloneJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		clone := make(map[string]any, len(v))
+		for k, child := range v {
+			clone[k] = cloneJSON(child)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(v))
+		for i, child := range v {
+			clone[i] = cloneJSON(child)
+		}
+		return clone
+	default:
+		return v
+	}
+}
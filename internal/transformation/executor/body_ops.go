@@ -0,0 +1,257 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+func castValue(v any, typ string) (any, error) {
+	switch typ {
+	case "string":
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		default:
+			b, err := json.Marshal(v)
+			return string(b), err
+		}
+	case "number", "integer":
+		var f float64
+		switch v := v.(type) {
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast %q to a %s", v, typ)
+			}
+			f = parsed
+		case bool:
+			if v {
+				f = 1
+			}
+		default:
+			n, ok := jsonpath.ToNumber(v)
+			if !ok {
+				return nil, fmt.Errorf("cannot cast a %T to a %s", v, typ)
+			}
+			f = n
+		}
+		if typ == "integer" {
+			f = math.Trunc(f)
+		}
+		return toJSONNumber(f)
+	case "boolean":
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast %q to a boolean", v)
+			}
+			return b, nil
+		default:
+			n, ok := jsonpath.ToNumber(v)
+			if !ok {
+				return nil, fmt.Errorf("cannot cast a %T to a boolean", v)
+			}
+			return n != 0, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %q", typ)
+	}
+}
+
+func changeCase(v any, c string) (any, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("value is not a string")
+	}
+
+	switch c {
+	case "upper":
+		return strings.ToUpper(s), nil
+	case "lower":
+		return strings.ToLower(s), nil
+	case "title":
+		runes := []rune(s)
+		start := true
+		for i, r := range runes {
+			if unicode.IsSpace(r) {
+				start = true
+				continue
+			}
+			if start {
+				runes[i] = unicode.ToUpper(r)
+			} else {
+				runes[i] = unicode.ToLower(r)
+			}
+			start = false
+		}
+		return string(runes), nil
+	case "snake":
+		words := splitWords(s)
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+		}
+		return strings.Join(words, "_"), nil
+	case "camel":
+		words := splitWords(s)
+		for i, w := range words {
+			w = strings.ToLower(w)
+			if i > 0 {
+				r := []rune(w)
+				r[0] = unicode.ToUpper(r[0])
+				w = string(r)
+			}
+			words[i] = w
+		}
+		return strings.Join(words, ""), nil
+	default:
+		return nil, fmt.Errorf("unsupported case %q", c)
+	}
+}
+
+// splitWords splits an identifier or a phrase into words, on separators and
+// on case changes: "invoiceID", "invoice_id" and "Invoice Id" all give
+// "invoice" and "id".
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := current[len(current)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+func compute(v any, operator string, operand float64) (any, error) {
+	n, ok := jsonpath.ToNumber(v)
+	if !ok {
+		return nil, fmt.Errorf("value is not a number")
+	}
+
+	switch operator {
+	case "add":
+		n += operand
+	case "subtract":
+		n -= operand
+	case "multiply":
+		n *= operand
+	case "divide":
+		n /= operand
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", operator)
+	}
+	return toJSONNumber(n)
+}
+
+func toJSONNumber(f float64) (json.Number, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("result is not a finite number")
+	}
+	return json.Number(strconv.FormatFloat(f, 'f', -1, 64)), nil
+}
+
+// Named date formats of the date operation. Any other format is a Go
+// layout such as "02/01/2006 15:04".
+var dateLayouts = map[string]string{
+	"rfc3339": time.RFC3339,
+	"rfc1123": time.RFC1123,
+	"date":    time.DateOnly,
+}
+
+func reformatDate(v any, from, to string) (any, error) {
+	t, err := parseDate(v, from)
+	if err != nil {
+		return nil, err
+	}
+	return formatDate(t, to), nil
+}
+
+func formatDate(t time.Time, to string) any {
+	switch to {
+	case "unix":
+		return json.Number(strconv.FormatInt(t.Unix(), 10))
+	case "unix_ms":
+		return json.Number(strconv.FormatInt(t.UnixMilli(), 10))
+	}
+	if layout, ok := dateLayouts[to]; ok {
+		return t.Format(layout)
+	}
+	return t.Format(to)
+}
+
+func parseDate(v any, format string) (time.Time, error) {
+	if format == "unix" || format == "unix_ms" {
+		var n float64
+		switch v := v.(type) {
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%q is not a %s timestamp", v, format)
+			}
+			n = parsed
+		default:
+			parsed, ok := jsonpath.ToNumber(v)
+			if !ok {
+				return time.Time{}, fmt.Errorf("value is not a %s timestamp", format)
+			}
+			n = parsed
+		}
+		if format == "unix_ms" {
+			return time.UnixMilli(int64(n)).UTC(), nil
+		}
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("value is not a date string")
+	}
+	layout, ok := dateLayouts[format]
+	if !ok {
+		layout = format
+	}
+	t, err := time.Parse(layout, strings.TrimSpace(s))
+	if err != nil && format == "rfc1123" {
+		t, err = time.Parse(time.RFC1123Z, strings.TrimSpace(s))
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q does not match the %s format", s, format)
+	}
+	return t, nil
+}
@@ -0,0 +1,29 @@
+package executor
+
+import (
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/compiled"
+)
+
+// Cache keeps compiled executors per transformation ID. An entry is rebuilt
+// when the transformation has been updated since it was compiled.
+type Cache struct {
+	opts      Options
+	executors *compiled.Cache[transformation.Executor]
+}
+
+func NewCache(opts Options) *Cache {
+	return &Cache{opts: opts, executors: compiled.NewCache[transformation.Executor]()}
+}
+
+// Get returns the executor of the transformation, compiling it on first use.
+func (c *Cache) Get(t *transformation.Transformation) (transformation.Executor, error) {
+	return c.executors.Get(t.ID, t.UpdatedAt, func() (transformation.Executor, error) {
+		return New(t, c.opts)
+	})
+}
+
+// Invalidate drops the compiled executor of a transformation.
+func (c *Cache) Invalidate(id string) {
+	c.executors.Invalidate(id)
+}
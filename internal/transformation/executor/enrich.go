@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// EnrichDetails reports the key looked up by an enrich transformation and
+// whether the table held it. Missing is set when the source was absent.
+type EnrichDetails struct {
+	Key     string                    `json:"key,omitempty"`
+	Found   bool                      `json:"found"`
+	Missing bool                      `json:"missing,omitempty"`
+	OnMiss  transformation.EnrichMiss `json:"on_miss,omitempty"`
+}
+
+type enrichExecutor struct {
+	tableID      string
+	source       *jsonpath.Path
+	target       *jsonpath.Path
+	defaultValue json.RawMessage
+	onMiss       transformation.EnrichMiss
+	lookups      lookup.Resolver
+}
+
+func newEnrichExecutor(config string, lookups lookup.Resolver) (*enrichExecutor, error) {
+	var cfg transformation.EnrichConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid enrich config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid enrich config: %w", err)
+	}
+
+	defaultValue, err := json.Marshal(cfg.Default)
+	if err != nil {
+		return nil, fmt.Errorf("invalid enrich config: %w", err)
+	}
+
+	return &enrichExecutor{
+		tableID:      cfg.TableID,
+		source:       jsonpath.MustCompile(cfg.Source),
+		target:       jsonpath.MustCompile(cfg.Target),
+		defaultValue: defaultValue,
+		onMiss:       cfg.Miss(),
+		lookups:      lookups,
+	}, nil
+}
+
+// Apply looks the source value up and writes the result to the target.
+// Strings are used as keys as is and numbers and booleans in their JSON
+// form; objects and arrays cannot be keys.
+func (e *enrichExecutor) Apply(ctx context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	if e.lookups == nil {
+		return nil, fmt.Errorf("lookup tables are not configured")
+	}
+
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	details := &EnrichDetails{}
+	var value json.RawMessage
+
+	source, ok := e.source.Get(body)
+	if !ok || source == nil {
+		details.Missing = true
+	} else {
+		key, err := lookupKey(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.source, err)
+		}
+		details.Key = key
+		value, details.Found, err = e.lookups.Lookup(ctx, e.tableID, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !details.Found {
+		details.OnMiss = e.onMiss
+		switch e.onMiss {
+		case transformation.EnrichMissFail:
+			if details.Missing {
+				return nil, fmt.Errorf("%s matched nothing", e.source)
+			}
+			return nil, fmt.Errorf("key %q is not in the lookup table", details.Key)
+		case transformation.EnrichMissSkip:
+			return &transformation.Result{Details: details}, nil
+		default:
+			value = e.defaultValue
+		}
+	}
+
+	decoded, err := pipeline.DecodeJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("decode lookup value: %w", err)
+	}
+	if body, err = e.target.Set(body, decoded); err != nil {
+		return nil, fmt.Errorf("set %s: %w", e.target, err)
+	}
+	return applyBody(event, body, details)
+}
+
+func lookupKey(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool, float64:
+		b, _ := json.Marshal(v)
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("an object or array cannot be used as a lookup key")
+	}
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"fmt"
+
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/jsvm"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+// Options holds the runtime settings shared by the executors. Modules
+// resolves the modules of wasm transformations and Lookups the tables of
+// enrich transformations.
+type Options struct {
+	Script  jsvm.Limits
+	Modules wasmvm.Loader
+	Lookups lookup.Resolver
+}
+
+// New builds the executor matching the type of the given transformation.
+func New(t *transformation.Transformation, opts Options) (transformation.Executor, error) {
+	switch t.TransformationType {
+	case transformation.TypeHeaderAdd:
+		return newHeaderAddExecutor(t.Config)
+	case transformation.TypeHeaderRemove:
+		return newHeaderRemoveExecutor(t.Config)
+	case transformation.TypeHeaderModify:
+		return newHeaderModifyExecutor(t.Config)
+	case transformation.TypeBodyAdd:
+		return newBodyAddExecutor(t.Config)
+	case transformation.TypeBodyRemove:
+		return newBodyRemoveExecutor(t.Config)
+	case transformation.TypeBodyModify:
+		return newBodyModifyExecutor(t.Config)
+	case transformation.TypeFormatXML:
+		return newFormatXMLExecutor(t.Config)
+	case transformation.TypeFormatJSON:
+		return newFormatJSONExecutor(t.Config)
+	case transformation.TypeTemplate:
+		return newTemplateExecutor(t)
+	case transformation.TypeJSONPatch:
+		return newJSONPatchExecutor(t.Config)
+	case transformation.TypeMergePatch:
+		return newMergePatchExecutor(t.Config)
+	case transformation.TypeEnrich:
+		return newEnrichExecutor(t.Config, opts.Lookups)
+	case transformation.TypeJSONPath:
+		return newJSONPathExecutor(t.Config)
+	case transformation.TypeJavascript:
+		if t.Mode != transformation.ModeCode {
+			return nil, fmt.Errorf("%w: javascript transformations only support code mode", transformation.ErrUnsupportedTransformationType)
+		}
+		return newJavascriptExecutor(t, opts.Script)
+	case transformation.TypeWasm:
+		if t.Mode != transformation.ModeCode {
+			return nil, fmt.Errorf("%w: wasm transformations only support code mode", transformation.ErrUnsupportedTransformationType)
+		}
+		return newWasmExecutor(t.Config, opts.Modules)
+	default:
+		return nil, fmt.Errorf("%w: %s", transformation.ErrUnsupportedTransformationType, t.TransformationType)
+	}
+}
@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/xmljson"
+)
+
+// FormatDetails is stored as the details of a format transformation.
+type FormatDetails struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Size int    `json:"size"`
+}
+
+type formatXMLExecutor struct {
+	opts xmljson.Options
+}
+
+func newFormatXMLExecutor(config string) (*formatXMLExecutor, error) {
+	var cfg transformation.FormatXMLConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid format_xml config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid format_xml config: %w", err)
+	}
+
+	return &formatXMLExecutor{opts: xmljson.Options{
+		RootElement:     cfg.RootElement,
+		AttributePrefix: cfg.AttributePrefix,
+		ArrayItem:       cfg.ArrayItem,
+		TextKey:         transformation.DefaultTextKey,
+	}}, nil
+}
+
+// Apply serializes the JSON payload as XML and sets the Content-Type header
+// accordingly. Transformations that read the body as JSON cannot follow it.
+func (e *formatXMLExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	payload, err := xmljson.Encode(body, e.opts)
+	if err != nil {
+		return nil, fmt.Errorf("convert to XML: %w", err)
+	}
+
+	setContentType(event, payload, pipeline.ContentTypeXML)
+	return &transformation.Result{Details: &FormatDetails{
+		From: pipeline.ContentTypeJSON,
+		To:   pipeline.ContentTypeXML,
+		Size: len(payload),
+	}}, nil
+}
+
+type formatJSONExecutor struct {
+	opts xmljson.Options
+}
+
+func newFormatJSONExecutor(config string) (*formatJSONExecutor, error) {
+	var cfg transformation.FormatJSONConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid format_json config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid format_json config: %w", err)
+	}
+
+	return &formatJSONExecutor{opts: xmljson.Options{
+		AttributePrefix: cfg.AttributePrefix,
+		ArrayItem:       cfg.ArrayItem,
+		TextKey:         cfg.TextKey,
+	}}, nil
+}
+
+// Apply parses an XML payload into JSON. The XML is either the payload
+// itself, after a format_xml transformation, or a JSON string holding it.
+func (e *formatJSONExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	from := event.PayloadContentType()
+
+	source := event.Payload
+	if event.IsJSON() {
+		body, err := event.Body()
+		if err != nil {
+			return nil, fmt.Errorf("decode payload: %w", err)
+		}
+		s, ok := body.(string)
+		if !ok {
+			return nil, fmt.Errorf("the payload is not XML")
+		}
+		source = []byte(s)
+	}
+
+	doc, err := xmljson.Decode(source, e.opts)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+
+	setContentType(event, payload, "")
+	return &transformation.Result{Details: &FormatDetails{
+		From: from,
+		To:   pipeline.ContentTypeJSON,
+		Size: len(payload),
+	}}, nil
+}
+
+// setContentType replaces the payload and records its media type, in the
+// event and in its Content-Type header. An empty contentType means JSON.
+func setContentType(event *pipeline.Event, payload []byte, contentType string) {
+	event.Payload = payload
+	event.ContentType = contentType
+
+	headers := cloneHeaders(event.Headers)
+	deleteHeader(headers, "Content-Type")
+	headers["Content-Type"] = event.PayloadContentType()
+	event.Headers = headers
+}
@@ -0,0 +1,242 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+)
+
+// HeaderDetails lists the header names changed by a header transformation.
+type HeaderDetails struct {
+	Set     []string          `json:"set,omitempty"`
+	Removed []string          `json:"removed,omitempty"`
+	Renamed map[string]string `json:"renamed,omitempty"`
+}
+
+type compiledHeader struct {
+	name  string
+	value *transformation.ValueTemplate
+}
+
+type headerAddExecutor struct {
+	headers []compiledHeader
+}
+
+func newHeaderAddExecutor(config string) (*headerAddExecutor, error) {
+	var cfg transformation.HeaderAddConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid header_add config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid header_add config: %w", err)
+	}
+
+	e := &headerAddExecutor{}
+	for _, h := range cfg.Headers {
+		tmpl, err := transformation.ParseValueTemplate(h.Value)
+		if err != nil {
+			return nil, err
+		}
+		e.headers = append(e.headers, compiledHeader{name: h.Name, value: tmpl})
+	}
+	return e, nil
+}
+
+// Apply sets every configured header, replacing any header with the same
+// name regardless of its case.
+func (e *headerAddExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := bodyFor(event, e.usesBody())
+	if err != nil {
+		return nil, err
+	}
+
+	headers := cloneHeaders(event.Headers)
+	details := &HeaderDetails{}
+	for _, h := range e.headers {
+		current, _ := event.Header(h.name)
+		deleteHeader(headers, h.name)
+		headers[h.name] = h.value.Render(event, body, current)
+		details.Set = append(details.Set, h.name)
+	}
+
+	event.Headers = headers
+	return &transformation.Result{Details: details}, nil
+}
+
+func (e *headerAddExecutor) usesBody() bool {
+	for _, h := range e.headers {
+		if h.value.UsesBody() {
+			return true
+		}
+	}
+	return false
+}
+
+type headerRemoveExecutor struct {
+	patterns []string
+}
+
+func newHeaderRemoveExecutor(config string) (*headerRemoveExecutor, error) {
+	var cfg transformation.HeaderRemoveConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid header_remove config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid header_remove config: %w", err)
+	}
+
+	e := &headerRemoveExecutor{}
+	for _, name := range cfg.Names {
+		e.patterns = append(e.patterns, strings.ToLower(name))
+	}
+	return e, nil
+}
+
+// Apply removes the headers matching any of the names or glob patterns,
+// ignoring case.
+func (e *headerRemoveExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	headers := cloneHeaders(event.Headers)
+	details := &HeaderDetails{}
+	for name := range headers {
+		lower := strings.ToLower(name)
+		for _, pattern := range e.patterns {
+			if matched, _ := path.Match(pattern, lower); matched {
+				delete(headers, name)
+				details.Removed = append(details.Removed, name)
+				break
+			}
+		}
+	}
+	sort.Strings(details.Removed)
+
+	event.Headers = headers
+	return &transformation.Result{Details: details}, nil
+}
+
+type compiledHeaderOperation struct {
+	op    transformation.HeaderOp
+	name  string
+	to    string
+	value *transformation.ValueTemplate
+}
+
+type headerModifyExecutor struct {
+	operations []compiledHeaderOperation
+}
+
+func newHeaderModifyExecutor(config string) (*headerModifyExecutor, error) {
+	var cfg transformation.HeaderModifyConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid header_modify config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid header_modify config: %w", err)
+	}
+
+	e := &headerModifyExecutor{}
+	for _, op := range cfg.Operations {
+		compiled := compiledHeaderOperation{op: op.Op, name: op.Name, to: op.To}
+		if op.Op == transformation.HeaderOpRewrite {
+			tmpl, err := transformation.ParseValueTemplate(op.Value)
+			if err != nil {
+				return nil, err
+			}
+			compiled.value = tmpl
+		}
+		e.operations = append(e.operations, compiled)
+	}
+	return e, nil
+}
+
+// Apply runs the operations in order. Operations on a header the event does
+// not have are skipped.
+func (e *headerModifyExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := bodyFor(event, e.usesBody())
+	if err != nil {
+		return nil, err
+	}
+
+	working := *event
+	working.Headers = cloneHeaders(event.Headers)
+	details := &HeaderDetails{}
+
+	for _, op := range e.operations {
+		current, ok := working.Header(op.name)
+		if !ok {
+			continue
+		}
+		switch op.op {
+		case transformation.HeaderOpRename:
+			deleteHeader(working.Headers, op.name)
+			deleteHeader(working.Headers, op.to)
+			working.Headers[op.to] = current
+			if details.Renamed == nil {
+				details.Renamed = map[string]string{}
+			}
+			details.Renamed[op.name] = op.to
+		case transformation.HeaderOpRewrite:
+			key := headerKey(working.Headers, op.name)
+			working.Headers[key] = op.value.Render(&working, body, current)
+			details.Set = append(details.Set, key)
+		}
+	}
+
+	event.Headers = working.Headers
+	return &transformation.Result{Details: details}, nil
+}
+
+func (e *headerModifyExecutor) usesBody() bool {
+	for _, op := range e.operations {
+		if op.value != nil && op.value.UsesBody() {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyFor decodes the payload only when a template reads it.
+func bodyFor(event *pipeline.Event, needed bool) (any, error) {
+	if !needed {
+		return nil, nil
+	}
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode body: %w", err)
+	}
+	return body, nil
+}
+
+func cloneHeaders(headers map[string]string) map[string]string {
+	clone := make(map[string]string, len(headers))
+	for k, v := range headers {
+		clone[k] = v
+	}
+	return clone
+}
+
+// headerKey returns the key under which the headers hold name.
+func headerKey(headers map[string]string, name string) string {
+	if _, ok := headers[name]; ok {
+		return name
+	}
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+func deleteHeader(headers map[string]string, name string) {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			delete(headers, k)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/jsvm"
+)
+
+// JavascriptEntry is the function a javascript transformation must define.
+const JavascriptEntry = "transform"
+
+type javascriptExecutor struct {
+	program *jsvm.Program
+	limits  jsvm.Limits
+}
+
+func newJavascriptExecutor(t *transformation.Transformation, limits jsvm.Limits) (*javascriptExecutor, error) {
+	program, err := jsvm.Compile(t.Name+".js", t.Code, JavascriptEntry)
+	if err != nil {
+		return nil, err
+	}
+	return &javascriptExecutor{program: program, limits: limits}, nil
+}
+
+// Apply calls transform(event) and takes the body and headers of the
+// returned event. Console output is kept in the result even when the script
+// fails.
+func (e *javascriptExecutor) Apply(ctx context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	res, err := e.program.Call(ctx, e.limits, event.ScriptEvent())
+	if res == nil {
+		return nil, err
+	}
+
+	result := &transformation.Result{Logs: res.Logs}
+	if err != nil {
+		return result, err
+	}
+
+	if err := event.ApplyScriptOutput(res.Output); err != nil {
+		return result, fmt.Errorf("%s(event): %w", JavascriptEntry, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// JSONPathResult is stored as the details of a jsonpath transformation.
+type JSONPathResult struct {
+	Expression string                        `json:"expression"`
+	Action     transformation.JSONPathAction `json:"action"`
+	Matches    int                           `json:"matches"`
+}
+
+type jsonPathExecutor struct {
+	path    *jsonpath.Path
+	action  transformation.JSONPathAction
+	wrapKey string
+}
+
+func newJSONPathExecutor(config string) (*jsonPathExecutor, error) {
+	var cfg transformation.JSONPathConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath config: %w", err)
+	}
+
+	e := &jsonPathExecutor{
+		path:    jsonpath.MustCompile(cfg.Expression),
+		action:  cfg.Action,
+		wrapKey: cfg.WrapKey,
+	}
+	if e.action == "" {
+		e.action = transformation.JSONPathActionReplace
+	}
+	if e.wrapKey == "" {
+		e.wrapKey = transformation.DefaultWrapKey
+	}
+	return e, nil
+}
+
+// Apply replaces the payload with the extracted value, or with an object
+// wrapping it. A definite path that matches nothing is an error, whereas
+// other paths produce an empty list.
+func (e *jsonPathExecutor) Apply(ctx context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	value, ok := e.path.Value(body)
+	if !ok {
+		return nil, fmt.Errorf("expression %s matched nothing", e.path)
+	}
+
+	details := &JSONPathResult{Expression: e.path.String(), Action: e.action, Matches: 1}
+	if nodes, isList := value.([]any); isList && !e.path.IsDefinite() {
+		details.Matches = len(nodes)
+	}
+
+	if e.action == transformation.JSONPathActionWrap {
+		value = map[string]any{e.wrapKey: value}
+	}
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+	event.Payload = payload
+
+	return &transformation.Result{Details: details}, nil
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpatch"
+)
+
+// PatchDetails is stored as the details of a patch transformation.
+type PatchDetails struct {
+	Operations int `json:"operations"`
+}
+
+type jsonPatchExecutor struct {
+	patch jsonpatch.Patch
+}
+
+func newJSONPatchExecutor(config string) (*jsonPatchExecutor, error) {
+	var cfg transformation.JSONPatchConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid json_patch config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid json_patch config: %w", err)
+	}
+	return &jsonPatchExecutor{patch: cfg.Patch}, nil
+}
+
+// Apply applies the patch atomically: when an operation fails, a test
+// included, the payload is left unchanged and the error names the
+// operation.
+func (e *jsonPatchExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	patched, err := e.patch.Apply(body)
+	if err != nil {
+		return nil, err
+	}
+	return applyBody(event, patched, &PatchDetails{Operations: len(e.patch)})
+}
+
+type mergePatchExecutor struct {
+	patch any
+}
+
+func newMergePatchExecutor(config string) (*mergePatchExecutor, error) {
+	var cfg transformation.MergePatchConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid merge_patch config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid merge_patch config: %w", err)
+	}
+
+	patch, err := pipeline.DecodeJSON(cfg.Patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge_patch config: %w", err)
+	}
+	return &mergePatchExecutor{patch: patch}, nil
+}
+
+func (e *mergePatchExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	return applyBody(event, jsonpatch.MergePatch(body, e.patch), nil)
+}
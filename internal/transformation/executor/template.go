@@ -0,0 +1,221 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// MaxTemplateOutput bounds the size of a rendered payload.
+const MaxTemplateOutput = 1 << 20
+
+var errTemplateOutputTooLarge = fmt.Errorf("rendered payload exceeds %d bytes", MaxTemplateOutput)
+
+// TemplateDetails is stored as the details of a template transformation.
+type TemplateDetails struct {
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// TemplateData is the data a template is executed with:
+//
+//	{{.Body.data.id}}  {{.Header "X-Event"}}  {{.Metadata.tenant}}  {{.Source.ID}}
+type TemplateData struct {
+	Body     any
+	Headers  map[string]string
+	Query    map[string]string
+	Metadata map[string]any
+	Captures map[string]string
+	Source   TemplateSource
+	Event    TemplateEvent
+}
+
+type TemplateSource struct {
+	ID string
+}
+
+type TemplateEvent struct {
+	ID         string
+	PipelineID string
+	Method     string
+	Path       string
+	CreatedAt  time.Time
+}
+
+// Header returns the value of the named header, ignoring case.
+func (d *TemplateData) Header(name string) string {
+	for k, v := range d.Headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// templateFuncs are the helpers available to templates:
+//
+//	json VALUE            JSON encoding, to embed values in a JSON payload
+//	date FORMAT VALUE     reformats a time, a unix timestamp or an RFC 3339 string
+//	now                   the current time
+//	default FALLBACK VAL  VAL, or FALLBACK when VAL is missing or empty
+//	lookup PATH DOC       the value at a JSONPath expression in DOC
+var templateFuncs = template.FuncMap{
+	"json":    templateJSON,
+	"date":    templateDate,
+	"now":     time.Now,
+	"default": templateDefault,
+	"lookup":  templateLookup,
+}
+
+type templateExecutor struct {
+	tmpl        *template.Template
+	contentType string
+}
+
+func newTemplateExecutor(t *transformation.Transformation) (*templateExecutor, error) {
+	var cfg transformation.TemplateConfig
+	if err := json.Unmarshal([]byte(t.Config), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid template config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid template config: %w", err)
+	}
+
+	tmpl, err := template.New(t.Name).Option("missingkey=zero").Funcs(templateFuncs).Parse(cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	e := &templateExecutor{tmpl: tmpl, contentType: cfg.ContentType}
+	if e.contentType == pipeline.ContentTypeJSON {
+		e.contentType = ""
+	}
+	return e, nil
+}
+
+// Apply renders the template into the new payload. A JSON template must
+// render valid JSON.
+func (e *templateExecutor) Apply(_ context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	body, err := event.Body()
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	data := &TemplateData{
+		Body:     body,
+		Headers:  event.Headers,
+		Query:    event.Query,
+		Metadata: event.Metadata,
+		Captures: event.Captures,
+		Source:   TemplateSource{ID: event.SourceID},
+		Event: TemplateEvent{
+			ID:         event.ID,
+			PipelineID: event.PipelineID,
+			Method:     event.Method,
+			Path:       event.Path,
+			CreatedAt:  event.CreatedAt,
+		},
+	}
+
+	out := &limitedBuffer{max: MaxTemplateOutput}
+	if err := e.tmpl.Execute(out, data); err != nil {
+		if errors.Is(err, errTemplateOutputTooLarge) {
+			return nil, errTemplateOutputTooLarge
+		}
+		return nil, fmt.Errorf("render template: %w", err)
+	}
+
+	payload := out.Bytes()
+	if e.contentType == "" {
+		payload = bytes.TrimSpace(payload)
+		if !json.Valid(payload) {
+			return nil, fmt.Errorf("the template did not render valid JSON")
+		}
+	}
+
+	setContentType(event, payload, e.contentType)
+	return &transformation.Result{Details: &TemplateDetails{
+		ContentType: event.PayloadContentType(),
+		Size:        len(payload),
+	}}, nil
+}
+
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, errTemplateOutputTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+func templateJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func templateDefault(fallback, v any) any {
+	switch v := v.(type) {
+	case nil:
+		return fallback
+	case string:
+		if v == "" {
+			return fallback
+		}
+	}
+	return v
+}
+
+func templateLookup(path string, doc any) (any, error) {
+	compiled, err := jsonpath.Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := compiled.Value(doc)
+	return v, nil
+}
+
+// templateDate formats v with format, a named format of the date operation
+// of body_modify or a Go layout. v is a time, a unix timestamp in seconds or
+// a string in RFC 3339 or unix seconds.
+func templateDate(format string, v any) (any, error) {
+	var t time.Time
+	switch v := v.(type) {
+	case time.Time:
+		t = v
+	case string:
+		parsed, err := parseDate(v, "rfc3339")
+		if err != nil {
+			if _, numErr := strconv.ParseFloat(strings.TrimSpace(v), 64); numErr != nil {
+				return nil, err
+			}
+			if parsed, err = parseDate(v, "unix"); err != nil {
+				return nil, err
+			}
+		}
+		t = parsed
+	default:
+		parsed, err := parseDate(v, "unix")
+		if err != nil {
+			return nil, err
+		}
+		t = parsed
+	}
+	return formatDate(t, format), nil
+}
@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+// WasmEntry is the function a wasm transformation module must export.
+const WasmEntry = "transform"
+
+type wasmExecutor struct {
+	hash    string
+	modules wasmvm.Loader
+}
+
+func newWasmExecutor(raw string, modules wasmvm.Loader) (*wasmExecutor, error) {
+	var config transformation.WasmConfig
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		return nil, fmt.Errorf("invalid wasm config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &wasmExecutor{hash: config.Module, modules: modules}, nil
+}
+
+// Apply passes the event to the module and takes the body and headers of
+// the event it returns, like a javascript transformation.
+func (e *wasmExecutor) Apply(ctx context.Context, event *pipeline.Event) (*transformation.Result, error) {
+	if e.modules == nil {
+		return nil, fmt.Errorf("wasm runtime is not configured")
+	}
+	module, err := e.modules.Load(ctx, e.hash)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := json.Marshal(event.ScriptEvent())
+	if err != nil {
+		return nil, fmt.Errorf("encode event: %w", err)
+	}
+
+	res, err := module.Call(ctx, WasmEntry, input)
+	if res == nil {
+		return nil, err
+	}
+
+	result := &transformation.Result{Logs: res.Logs}
+	if err != nil {
+		return result, err
+	}
+
+	if err := event.ApplyScriptOutput(res.Output); err != nil {
+		return result, fmt.Errorf("%s: %w", WasmEntry, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type transformationRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewTransformationRepository(db *pgxpool.Pool, appLogger logger.Logger) transformation.Repository {
+	return &transformationRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r transformationRepository) Create(ctx context.Context, t *transformation.Transformation) error {
+	ctx, span := tracer.StartSpan(ctx, "transformation.repository.create")
+	defer span.End()
+
+	pipelineID, err := uuid.Parse(t.PipelineID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("invalid pipeline id: %w", err)
+	}
+
+	result, err := r.queries.CreateTransformation(ctx,
+		pipelineID,
+		t.Name,
+		t.Description,
+		generated.TransformationType(t.TransformationType),
+		generated.TransformationMode(t.Mode),
+		[]byte(configOrEmpty(t.Config)),
+		toText(t.Code),
+		t.ExecutionOrder,
+		t.IsActive,
+	)
+	if err != nil {
+		r.appLogger.Error(ctx, "Failed to create transformation",
+			logger.String("name", t.Name),
+			logger.String("pipeline_id", t.PipelineID),
+			logger.Error(err),
+		)
+		span.RecordError(err)
+		return fmt.Errorf("failed to create transformation: %w", err)
+	}
+
+	*t = *toTransformation(result)
+	return nil
+}
+
+func (r transformationRepository) GetByID(ctx context.Context, id string) (*transformation.Transformation, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.repository.get_by_id")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transformation ID format: %w", err)
+	}
+
+	result, err := r.queries.GetTransformationByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to get transformation by ID", logger.Error(err))
+		return nil, fmt.Errorf("failed to get transformation by ID: %w", err)
+	}
+
+	return toTransformation(result), nil
+}
+
+func (r transformationRepository) ListByPipeline(ctx context.Context, pipelineID string) ([]*transformation.Transformation, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.repository.list_by_pipeline")
+	defer span.End()
+
+	uid, err := uuid.Parse(pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+
+	results, err := r.queries.ListTransformationsByPipeline(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list transformations: %w", err)
+	}
+
+	return toTransformations(results), nil
+}
+
+func (r transformationRepository) ListActiveByPipeline(ctx context.Context, pipelineID string) ([]*transformation.Transformation, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.repository.list_active_by_pipeline")
+	defer span.End()
+
+	uid, err := uuid.Parse(pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline ID format: %w", err)
+	}
+
+	results, err := r.queries.ListActiveTransformationsByPipeline(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list active transformations: %w", err)
+	}
+
+	return toTransformations(results), nil
+}
+
+func (r transformationRepository) Update(ctx context.Context, t *transformation.Transformation) error {
+	ctx, span := tracer.StartSpan(ctx, "transformation.repository.update")
+	defer span.End()
+
+	uid, err := uuid.Parse(t.ID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("invalid transformation id: %w", err)
+	}
+
+	result, err := r.queries.UpdateTransformation(ctx,
+		uid,
+		t.Name,
+		pgtype.Text{String: t.Description, Valid: true},
+		generated.TransformationType(t.TransformationType),
+		generated.TransformationMode(t.Mode),
+		[]byte(configOrEmpty(t.Config)),
+		toText(t.Code),
+		t.ExecutionOrder,
+		t.IsActive,
+	)
+	if err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to update transformation", logger.Error(err))
+		return fmt.Errorf("failed to update transformation: %w", err)
+	}
+
+	*t = *toTransformation(result)
+	return nil
+}
+
+func (r transformationRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.StartSpan(ctx, "transformation.repository.delete")
+	defer span.End()
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid transformation id: %w", err)
+	}
+
+	if err := r.queries.DeleteTransformation(ctx, uid); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete transformation: %w", err)
+	}
+	return nil
+}
+
+func (r transformationRepository) Reorder(ctx context.Context, pipelineID string, ids []string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.repository.reorder")
+	defer span.End()
+
+	pipelineUID, err := uuid.Parse(pipelineID)
+	if err != nil {
+		return false, fmt.Errorf("invalid pipeline id: %w", err)
+	}
+	uids := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			return false, fmt.Errorf("invalid transformation id: %w", err)
+		}
+		uids[i] = uid
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+	current, err := qtx.LockTransformationsByPipeline(ctx, pipelineUID)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to lock transformations: %w", err)
+	}
+	if len(current) != len(uids) {
+		return false, nil
+	}
+	known := make(map[uuid.UUID]bool, len(current))
+	for _, uid := range current {
+		known[uid] = true
+	}
+	for _, uid := range uids {
+		if !known[uid] {
+			return false, nil
+		}
+		delete(known, uid)
+	}
+
+	for i, uid := range uids {
+		if err := qtx.ReorderTransformations(ctx, uid, int32(i+1)); err != nil {
+			span.RecordError(err)
+			return false, fmt.Errorf("failed to reorder transformations: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to commit transformation order", logger.Error(err))
+		return false, fmt.Errorf("failed to commit transformation order: %w", err)
+	}
+	return true, nil
+}
+
+func toTransformation(result generated.Transformation) *transformation.Transformation {
+	return &transformation.Transformation{
+		ID:                 result.ID.String(),
+		PipelineID:         result.PipelineID.String(),
+		Name:               result.Name,
+		Description:        result.Description.String,
+		TransformationType: transformation.Type(result.TransformationType),
+		Mode:               transformation.Mode(result.Mode),
+		Config:             string(result.Config),
+		Code:               result.Code.String,
+		IsActive:           result.IsActive,
+		ExecutionOrder:     result.ExecutionOrder,
+		CreatedAt:          result.CreatedAt.Time,
+		UpdatedAt:          result.UpdatedAt.Time,
+	}
+}
+
+func toTransformations(results []generated.Transformation) []*transformation.Transformation {
+	transformations := make([]*transformation.Transformation, len(results))
+	for i, result := range results {
+		transformations[i] = toTransformation(result)
+	}
+	return transformations
+}
+
+func configOrEmpty(config string) string {
+	if config == "" {
+		return "{}"
+	}
+	return config
+}
+
+func toText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
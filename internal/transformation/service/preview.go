@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpatch"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+const (
+	previewStatusSuccess = "success"
+	previewStatusFailed  = "failed"
+)
+
+// Preview runs the active transformations of a pipeline against a stored
+// event or an ad-hoc payload. Nothing is persisted: no step is recorded and
+// the stored event is left untouched. A stored event is replayed from its
+// original payload and inbound headers, as the engine saw it.
+func (s transformationService) Preview(ctx context.Context, pipelineID string, req transformation.PreviewRequest) (*transformation.PreviewResponse, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.service.preview")
+	defer span.End()
+
+	if err := s.ensurePipeline(ctx, pipelineID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	event, err := s.previewEvent(ctx, pipelineID, req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	transformations, err := s.transformationRepo.ListActiveByPipeline(ctx, pipelineID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("listing active transformations: %w", err)
+	}
+
+	original := event.Payload
+	response := &transformation.PreviewResponse{
+		Steps: make([]*transformation.PreviewStep, 0, len(transformations)),
+	}
+
+	for _, t := range transformations {
+		step := &transformation.PreviewStep{Status: previewStatusSuccess}
+
+		var result *transformation.Result
+		ex, err := s.executors.Get(t)
+		if err == nil {
+			result, err = ex.Apply(ctx, event)
+		}
+		if result == nil {
+			result = &transformation.Result{}
+		}
+		result.TransformationID = t.ID
+		result.Name = t.Name
+		result.TransformationType = t.TransformationType
+		step.Result = result
+
+		if err != nil {
+			result.Error = err.Error()
+			step.Status = previewStatusFailed
+			response.Error = fmt.Sprintf("transformation %q: %v", t.Name, err)
+			response.Steps = append(response.Steps, step)
+			break
+		}
+
+		step.Output, err = previewPayload(event)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		step.ContentType = event.PayloadContentType()
+		step.Headers = maps.Clone(event.Headers)
+		response.Steps = append(response.Steps, step)
+	}
+
+	response.Output, err = previewPayload(event)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	response.ContentType = event.PayloadContentType()
+	response.Headers = event.Headers
+
+	response.Diff, err = previewDiff(original, response.Output)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// previewEvent builds the event to run through the transformations from
+// exactly one of a stored event of the pipeline or an ad-hoc payload.
+func (s transformationService) previewEvent(ctx context.Context, pipelineID string, req transformation.PreviewRequest) (*pipeline.Event, error) {
+	hasPayload := len(req.Payload) > 0 && string(req.Payload) != "null"
+
+	switch {
+	case req.EventID != "" && hasPayload:
+		return nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"event_id": "provide either event_id or payload, not both",
+		}}
+	case req.EventID == "" && !hasPayload:
+		return nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"event_id": "event_id or payload is required",
+		}}
+	case hasPayload:
+		if !json.Valid(req.Payload) {
+			return nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+				"payload": "payload must be valid JSON",
+			}}
+		}
+		headers := req.Headers
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		metadata := req.Metadata
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		return &pipeline.Event{
+			PipelineID: pipelineID,
+			Headers:    headers,
+			Query:      map[string]string{},
+			Payload:    req.Payload,
+			Metadata:   metadata,
+			Status:     pipeline.StatusPending,
+		}, nil
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, req.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook event: %w", err)
+	}
+	if event == nil || event.PipelineID != pipelineID {
+		return nil, pipeline.ErrEventNotFound
+	}
+
+	if len(event.OriginalPayload) > 0 {
+		event.Payload = event.OriginalPayload
+	}
+	event.ContentType = ""
+	event.OutboundHeaders = nil
+	event.Captures = nil
+	return event, nil
+}
+
+// previewPayload returns the payload as JSON, wrapping payloads in another
+// format in a JSON string.
+func previewPayload(event *pipeline.Event) (json.RawMessage, error) {
+	if event.IsJSON() {
+		return json.RawMessage(event.Payload), nil
+	}
+	encoded, err := json.Marshal(string(event.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("encoding payload: %w", err)
+	}
+	return encoded, nil
+}
+
+func previewDiff(original, output json.RawMessage) (jsonpatch.Patch, error) {
+	before, err := pipeline.DecodeJSON(original)
+	if err != nil {
+		return nil, fmt.Errorf("decoding original payload: %w", err)
+	}
+	after, err := pipeline.DecodeJSON(output)
+	if err != nil {
+		return nil, fmt.Errorf("decoding output: %w", err)
+	}
+	patch, err := jsonpatch.Diff(before, after)
+	if err != nil {
+		return nil, fmt.Errorf("generating diff: %w", err)
+	}
+	return patch, nil
+}
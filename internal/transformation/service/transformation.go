@@ -0,0 +1,524 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	lookup "github.com/theotruvelot/catchook/internal/lookup/domain"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/internal/transformation/executor"
+	wasm "github.com/theotruvelot/catchook/internal/wasm/domain"
+	"github.com/theotruvelot/catchook/pkg/jsonpatch"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+type transformationService struct {
+	transformationRepo transformation.Repository
+	pipelineRepo       pipeline.Repository
+	eventRepo          pipeline.EventRepository
+	executors          *executor.Cache
+	modules            wasmvm.Loader
+	lookupTables       lookup.Service
+	appLogger          logger.Logger
+}
+
+func NewTransformationService(
+	transformationRepo transformation.Repository,
+	pipelineRepo pipeline.Repository,
+	eventRepo pipeline.EventRepository,
+	executors *executor.Cache,
+	modules wasmvm.Loader,
+	lookupTables lookup.Service,
+	appLogger logger.Logger,
+) transformation.Service {
+	return &transformationService{
+		transformationRepo: transformationRepo,
+		pipelineRepo:       pipelineRepo,
+		eventRepo:          eventRepo,
+		executors:          executors,
+		modules:            modules,
+		lookupTables:       lookupTables,
+		appLogger:          appLogger,
+	}
+}
+
+func (s transformationService) Create(ctx context.Context, pipelineID string, req transformation.CreateRequest) (*transformation.Transformation, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.service.create")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Creating new transformation",
+		logger.String("pipeline_id", pipelineID),
+		logger.String("name", req.Name),
+	)
+
+	if err := s.ensurePipeline(ctx, pipelineID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = transformation.ModeNocode
+		if isCodeType(req.TransformationType) {
+			mode = transformation.ModeCode
+		}
+	}
+	executionOrder := req.ExecutionOrder
+	if executionOrder == 0 {
+		executionOrder = 1
+	}
+
+	newTransformation := &transformation.Transformation{
+		PipelineID:         pipelineID,
+		Name:               req.Name,
+		Description:        req.Description,
+		TransformationType: req.TransformationType,
+		Mode:               mode,
+		Code:               req.Code,
+		IsActive:           true,
+		ExecutionOrder:     executionOrder,
+	}
+
+	config, err := validateAndMarshalConfig(newTransformation, req.Config)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("building config: %w", err)
+	}
+	newTransformation.Config = config
+
+	if err := s.ensureModule(ctx, newTransformation); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if err := s.ensureLookupTable(ctx, newTransformation); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.transformationRepo.Create(ctx, newTransformation); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to create transformation", logger.Error(err))
+		return nil, fmt.Errorf("creating transformation: %w", err)
+	}
+
+	return newTransformation, nil
+}
+
+func isCodeType(t transformation.Type) bool {
+	return t == transformation.TypeJavascript || t == transformation.TypeWasm
+}
+
+// validateAndMarshalConfig decodes and validates the config of each
+// transformation type, then builds the executor once: a JavaScript
+// transformation that does not compile is reported on the code field, any
+// other failure on the config.
+func validateAndMarshalConfig(t *transformation.Transformation, cfg map[string]any) (string, error) {
+	errors := map[string]string{}
+
+	if cfg == nil {
+		cfg = map[string]any{}
+	}
+
+	if isCodeType(t.TransformationType) {
+		if t.Mode != transformation.ModeCode {
+			errors["mode"] = fmt.Sprintf("%s transformations only support code mode", t.TransformationType)
+		}
+	} else if t.Mode != transformation.ModeNocode {
+		errors["mode"] = fmt.Sprintf("%s transformations only support nocode mode", t.TransformationType)
+	}
+	if len(errors) > 0 {
+		return "", &validatorpkg.ValidationErrors{Errors: errors}
+	}
+
+	var validate func() error
+	switch t.TransformationType {
+	case transformation.TypeHeaderAdd:
+		config, err := transformation.HeaderAddConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeHeaderRemove:
+		config, err := transformation.HeaderRemoveConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeHeaderModify:
+		config, err := transformation.HeaderModifyConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeBodyAdd:
+		config, err := transformation.BodyAddConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeBodyRemove:
+		config, err := transformation.BodyRemoveConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeBodyModify:
+		config, err := transformation.BodyModifyConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeFormatJSON:
+		config, err := transformation.FormatJSONConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeFormatXML:
+		config, err := transformation.FormatXMLConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeJSONPath:
+		config, err := transformation.JSONPathConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeTemplate:
+		config, err := transformation.TemplateConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeJSONPatch:
+		config, err := transformation.JSONPatchConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeMergePatch:
+		config, err := transformation.MergePatchConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeEnrich:
+		config, err := transformation.EnrichConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeWasm:
+		config, err := transformation.WasmConfigFromMap(cfg)
+		if err == nil {
+			validate = config.Validate
+		}
+		setDecodeError(errors, err)
+	case transformation.TypeJavascript:
+		if strings.TrimSpace(t.Code) == "" {
+			errors["code"] = "code is required for javascript transformations"
+		}
+	default:
+		return "", &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"transformation_type": "unsupported transformation_type",
+		}}
+	}
+
+	if validate != nil {
+		if err := validate(); err != nil {
+			errors["config"] = fmt.Sprintf("%s config validation failed: %v", t.TransformationType, err)
+		}
+	}
+	if len(errors) > 0 {
+		return "", &validatorpkg.ValidationErrors{Errors: errors}
+	}
+
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("marshal config: %w", err)
+	}
+
+	t.Config = string(b)
+	if _, err := executor.New(t, executor.Options{}); err != nil {
+		field := "config"
+		if t.Mode == transformation.ModeCode && t.TransformationType == transformation.TypeJavascript {
+			field = "code"
+		}
+		return "", &validatorpkg.ValidationErrors{Errors: map[string]string{
+			field: err.Error(),
+		}}
+	}
+
+	return string(b), nil
+}
+
+func setDecodeError(errors map[string]string, err error) {
+	if err != nil {
+		errors["config"] = fmt.Sprintf("invalid config format: %v", err)
+	}
+}
+
+func (s transformationService) GetByID(ctx context.Context, pipelineID, id string) (*transformation.Transformation, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.service.get_by_id")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Fetching transformation by ID", logger.String("transformation_id", id))
+
+	found, err := s.transformationRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting transformation by ID: %w", err)
+	}
+	if found == nil || found.PipelineID != pipelineID {
+		return nil, transformation.ErrTransformationNotFound
+	}
+
+	return found, nil
+}
+
+// List returns the transformations of the pipeline in execution order.
+func (s transformationService) List(ctx context.Context, pipelineID string) ([]*transformation.Transformation, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.service.list")
+	defer span.End()
+
+	if err := s.ensurePipeline(ctx, pipelineID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	transformations, err := s.transformationRepo.ListByPipeline(ctx, pipelineID)
+	if err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to list transformations", logger.Error(err))
+		return nil, fmt.Errorf("failed to list transformations: %w", err)
+	}
+
+	return transformations, nil
+}
+
+func (s transformationService) Update(ctx context.Context, pipelineID, id string, req transformation.UpdateRequest) (*transformation.Transformation, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.service.update")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Updating transformation", logger.String("transformation_id", id))
+
+	existing, err := s.GetByID(ctx, pipelineID, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.Name) != "" {
+		existing.Name = req.Name
+	}
+	if req.Description != "" {
+		existing.Description = req.Description
+	}
+	if req.ExecutionOrder != 0 {
+		existing.ExecutionOrder = req.ExecutionOrder
+	}
+	if req.IsActive != nil {
+		existing.IsActive = *req.IsActive
+	}
+
+	definitionChanged := req.TransformationType != "" || req.Mode != "" || req.Config != nil || req.Code != ""
+	if req.TransformationType != "" {
+		existing.TransformationType = req.TransformationType
+	}
+	if req.Mode != "" {
+		existing.Mode = req.Mode
+	}
+	if req.Code != "" {
+		existing.Code = req.Code
+	}
+
+	if definitionChanged {
+		cfg := req.Config
+		if cfg == nil {
+			if err := json.Unmarshal([]byte(existing.Config), &cfg); err != nil {
+				return nil, fmt.Errorf("unmarshal existing config: %w", err)
+			}
+		}
+		config, err := validateAndMarshalConfig(existing, cfg)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("building config: %w", err)
+		}
+		existing.Config = config
+
+		if err := s.ensureModule(ctx, existing); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if err := s.ensureLookupTable(ctx, existing); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	if err := s.transformationRepo.Update(ctx, existing); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to update transformation", logger.Error(err))
+		return nil, fmt.Errorf("updating transformation: %w", err)
+	}
+	s.executors.Invalidate(id)
+
+	return existing, nil
+}
+
+func (s transformationService) Delete(ctx context.Context, pipelineID, id string) error {
+	ctx, span := tracer.StartSpan(ctx, "transformation.service.delete")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Deleting transformation", logger.String("transformation_id", id))
+
+	if _, err := s.GetByID(ctx, pipelineID, id); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err := s.transformationRepo.Delete(ctx, id); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to delete transformation", logger.Error(err))
+		return fmt.Errorf("deleting transformation: %w", err)
+	}
+	s.executors.Invalidate(id)
+
+	return nil
+}
+
+// Reorder applies a complete ordering of the pipeline's transformations. A
+// partial list is rejected so that no two transformations end up sharing a
+// position.
+func (s transformationService) Reorder(ctx context.Context, pipelineID string, req transformation.ReorderRequest) ([]*transformation.Transformation, error) {
+	ctx, span := tracer.StartSpan(ctx, "transformation.service.reorder")
+	defer span.End()
+
+	s.appLogger.Info(ctx, "Reordering transformations", logger.String("pipeline_id", pipelineID))
+
+	if err := s.ensurePipeline(ctx, pipelineID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	reordered, err := s.transformationRepo.Reorder(ctx, pipelineID, req.TransformationIDs)
+	if err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to reorder transformations", logger.Error(err))
+		return nil, fmt.Errorf("reordering transformations: %w", err)
+	}
+	if !reordered {
+		return nil, transformation.ErrInvalidOrder
+	}
+
+	return s.List(ctx, pipelineID)
+}
+
+// GeneratePatch derives a patch turning the before sample into the after
+// sample, as a JSON Patch unless a merge patch is requested.
+func (s transformationService) GeneratePatch(ctx context.Context, req transformation.GeneratePatchRequest) (*transformation.GeneratePatchResponse, error) {
+	_, span := tracer.StartSpan(ctx, "transformation.service.generate_patch")
+	defer span.End()
+
+	errors := map[string]string{}
+	before, err := pipeline.DecodeJSON(req.Before)
+	if err != nil {
+		errors["before"] = fmt.Sprintf("invalid JSON: %v", err)
+	}
+	after, err := pipeline.DecodeJSON(req.After)
+	if err != nil {
+		errors["after"] = fmt.Sprintf("invalid JSON: %v", err)
+	}
+	if len(errors) > 0 {
+		return nil, &validatorpkg.ValidationErrors{Errors: errors}
+	}
+
+	if req.Format == transformation.TypeMergePatch {
+		return &transformation.GeneratePatchResponse{
+			TransformationType: transformation.TypeMergePatch,
+			Config:             map[string]any{"patch": jsonpatch.MergeDiff(before, after)},
+		}, nil
+	}
+
+	patch, err := jsonpatch.Diff(before, after)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("generating patch: %w", err)
+	}
+	return &transformation.GeneratePatchResponse{
+		TransformationType: transformation.TypeJSONPatch,
+		Config:             map[string]any{"patch": patch},
+	}, nil
+}
+
+// ensureModule checks that the module of a wasm transformation was uploaded
+// and exports the transform entry point.
+func (s transformationService) ensureModule(ctx context.Context, t *transformation.Transformation) error {
+	if t.TransformationType != transformation.TypeWasm {
+		return nil
+	}
+
+	var config transformation.WasmConfig
+	if err := json.Unmarshal([]byte(t.Config), &config); err != nil {
+		return fmt.Errorf("unmarshal wasm config: %w", err)
+	}
+
+	module, err := s.modules.Load(ctx, config.Module)
+	switch {
+	case errors.Is(err, wasm.ErrModuleNotFound):
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"config.module": "module not found",
+		}}
+	case err != nil:
+		return fmt.Errorf("loading wasm module: %w", err)
+	case !module.HasEntry(executor.WasmEntry):
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"config.module": fmt.Sprintf("module must export %s(ptr i32, len i32) i64", executor.WasmEntry),
+		}}
+	}
+	return nil
+}
+
+// ensureLookupTable checks that the table of an enrich transformation
+// belongs to the current user.
+func (s transformationService) ensureLookupTable(ctx context.Context, t *transformation.Transformation) error {
+	if t.TransformationType != transformation.TypeEnrich {
+		return nil
+	}
+
+	var config transformation.EnrichConfig
+	if err := json.Unmarshal([]byte(t.Config), &config); err != nil {
+		return fmt.Errorf("unmarshal enrich config: %w", err)
+	}
+
+	_, err := s.lookupTables.GetByID(ctx, config.TableID)
+	switch {
+	case errors.Is(err, lookup.ErrTableNotFound):
+		return &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"config.table_id": "lookup table not found",
+		}}
+	case err != nil:
+		return fmt.Errorf("getting lookup table: %w", err)
+	}
+	return nil
+}
+
+func (s transformationService) ensurePipeline(ctx context.Context, pipelineID string) error {
+	existing, err := s.pipelineRepo.GetByID(ctx, pipelineID)
+	if err != nil {
+		return fmt.Errorf("getting pipeline by ID: %w", err)
+	}
+	if existing == nil {
+		return pipeline.ErrPipelineNotFound
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	pipeline "github.com/theotruvelot/catchook/internal/pipeline/domain"
+	"github.com/theotruvelot/catchook/internal/platform/http/middleware"
+	transformation "github.com/theotruvelot/catchook/internal/transformation/domain"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+// Handler holds the transformation-specific dependencies
+type Handler struct {
+	transformationService transformation.Service
+	validator             *validatorpkg.Validator
+}
+
+// NewHandler creates a new transformation handler
+func NewHandler(transformationService transformation.Service, validator *validatorpkg.Validator) *Handler {
+	return &Handler{
+		transformationService: transformationService,
+		validator:             validator,
+	}
+}
+
+func (h *Handler) CreateTransformation(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "transformation.handler.create")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	if pipelineID == "" {
+		return response.BadRequest(c, "pipeline_id is required", nil)
+	}
+
+	var req transformation.CreateRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	created, err := h.transformationService.Create(ctx, pipelineID, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, pipeline.ErrPipelineNotFound):
+			return response.NotFound(c, "pipeline not found")
+		default:
+			return response.InternalError(c, "failed to create transformation")
+		}
+	}
+
+	resp, err := created.ToResponse()
+	if err != nil {
+		return response.InternalError(c, "failed to serialize transformation")
+	}
+
+	return response.Success(c, resp, "transformation created")
+}
+
+func (h *Handler) GetTransformation(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "transformation.handler.get")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	transformationID := c.Params("transformationId")
+	if pipelineID == "" || transformationID == "" {
+		return response.BadRequest(c, "pipeline_id and transformation_id are required", nil)
+	}
+
+	found, err := h.transformationService.GetByID(ctx, pipelineID, transformationID)
+	if err != nil {
+		if errors.Is(err, transformation.ErrTransformationNotFound) {
+			return response.NotFound(c, "transformation not found")
+		}
+		return response.InternalError(c, "failed to get transformation")
+	}
+
+	resp, err := found.ToResponse()
+	if err != nil {
+		return response.InternalError(c, "failed to serialize transformation")
+	}
+	return response.Success(c, resp, "transformation")
+}
+
+func (h *Handler) ListTransformations(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "transformation.handler.list")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	if pipelineID == "" {
+		return response.BadRequest(c, "pipeline_id is required", nil)
+	}
+
+	transformations, err := h.transformationService.List(ctx, pipelineID)
+	if err != nil {
+		if errors.Is(err, pipeline.ErrPipelineNotFound) {
+			return response.NotFound(c, "pipeline not found")
+		}
+		return response.InternalError(c, "failed to list transformations")
+	}
+
+	resp, err := transformation.ToResponses(transformations)
+	if err != nil {
+		return response.InternalError(c, "failed to serialize transformations")
+	}
+
+	return response.Success(c, resp, "transformations listed")
+}
+
+func (h *Handler) UpdateTransformation(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "transformation.handler.update")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	transformationID := c.Params("transformationId")
+	if pipelineID == "" || transformationID == "" {
+		return response.BadRequest(c, "pipeline_id and transformation_id are required", nil)
+	}
+
+	var req transformation.UpdateRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	updated, err := h.transformationService.Update(ctx, pipelineID, transformationID, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, transformation.ErrTransformationNotFound):
+			return response.NotFound(c, "transformation not found")
+		default:
+			return response.InternalError(c, "failed to update transformation")
+		}
+	}
+
+	resp, err := updated.ToResponse()
+	if err != nil {
+		return response.InternalError(c, "failed to serialize transformation")
+	}
+
+	return response.Success(c, resp, "transformation updated")
+}
+
+func (h *Handler) DeleteTransformation(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "transformation.handler.delete")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	transformationID := c.Params("transformationId")
+	if pipelineID == "" || transformationID == "" {
+		return response.BadRequest(c, "pipeline_id and transformation_id are required", nil)
+	}
+
+	if err := h.transformationService.Delete(ctx, pipelineID, transformationID); err != nil {
+		switch {
+		case errors.Is(err, transformation.ErrTransformationNotFound):
+			return response.NotFound(c, "transformation not found")
+		default:
+			return response.InternalError(c, "failed to delete transformation")
+		}
+	}
+
+	return response.Success(c, nil, "transformation deleted")
+}
+
+func (h *Handler) ReorderTransformations(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "transformation.handler.reorder")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	if pipelineID == "" {
+		return response.BadRequest(c, "pipeline_id is required", nil)
+	}
+
+	var req transformation.ReorderRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	transformations, err := h.transformationService.Reorder(ctx, pipelineID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, transformation.ErrInvalidOrder):
+			return response.ValidationFailed(c, map[string]string{"transformation_ids": err.Error()})
+		case errors.Is(err, pipeline.ErrPipelineNotFound):
+			return response.NotFound(c, "pipeline not found")
+		default:
+			return response.InternalError(c, "failed to reorder transformations")
+		}
+	}
+
+	resp, err := transformation.ToResponses(transformations)
+	if err != nil {
+		return response.InternalError(c, "failed to serialize transformations")
+	}
+
+	return response.Success(c, resp, "transformations reordered")
+}
+
+func (h *Handler) GeneratePatch(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "transformation.handler.generate_patch")
+	defer span.End()
+
+	var req transformation.GeneratePatchRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	generated, err := h.transformationService.GeneratePatch(ctx, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.InternalError(c, "failed to generate patch")
+	}
+
+	return response.Success(c, generated, "patch generated")
+}
+
+func (h *Handler) PreviewTransformations(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "transformation.handler.preview")
+	defer span.End()
+
+	pipelineID := c.Params("id")
+	if pipelineID == "" {
+		return response.BadRequest(c, "pipeline_id is required", nil)
+	}
+
+	var req transformation.PreviewRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	preview, err := h.transformationService.Preview(ctx, pipelineID, req)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, pipeline.ErrPipelineNotFound):
+			return response.NotFound(c, "pipeline not found")
+		case errors.Is(err, pipeline.ErrEventNotFound):
+			return response.NotFound(c, "webhook event not found")
+		default:
+			return response.InternalError(c, "failed to preview transformations")
+		}
+	}
+
+	return response.Success(c, preview, "transformations previewed")
+}
@@ -0,0 +1,33 @@
+package wasm
+
+import "time"
+
+type UploadRequest struct {
+	Name string `form:"name" validate:"required,min=2,max=100"`
+}
+
+type ModuleResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	SizeBytes int32     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (m *Module) ToResponse() *ModuleResponse {
+	return &ModuleResponse{
+		ID:        m.ID,
+		Name:      m.Name,
+		Hash:      m.Hash,
+		SizeBytes: m.SizeBytes,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func ToResponses(list []*Module) []*ModuleResponse {
+	resp := make([]*ModuleResponse, 0, len(list))
+	for _, item := range list {
+		resp = append(resp, item.ToResponse())
+	}
+	return resp
+}
@@ -0,0 +1,15 @@
+package wasm
+
+import "time"
+
+// Module is an uploaded WebAssembly module. Hash is the SHA-256 of its
+// content and is how filters and transformations reference it.
+type Module struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	SizeBytes int32     `json:"size_bytes"`
+	Content   []byte    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
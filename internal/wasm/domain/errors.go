@@ -0,0 +1,8 @@
+package wasm
+
+import "errors"
+
+var (
+	ErrModuleNotFound = errors.New("wasm module not found")
+	ErrModuleTooLarge = errors.New("wasm module is too large")
+)
@@ -0,0 +1,12 @@
+package wasm
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, module *Module) error
+	GetByHash(ctx context.Context, userID, hash string) (*Module, error)
+	ListByUser(ctx context.Context, userID string) ([]*Module, error)
+	// GetContent returns the bytes of the module with the given hash,
+	// whoever uploaded it, or nil when no module has this hash.
+	GetContent(ctx context.Context, hash string) ([]byte, error)
+}
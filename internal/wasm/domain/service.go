@@ -0,0 +1,16 @@
+package wasm
+
+import (
+	"context"
+
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+type Service interface {
+	Upload(ctx context.Context, name string, content []byte) (*Module, error)
+	GetByHash(ctx context.Context, hash string) (*Module, error)
+	List(ctx context.Context) ([]*Module, error)
+	// Load returns the compiled module used by wasm filters and
+	// transformations.
+	Load(ctx context.Context, hash string) (*wasmvm.Module, error)
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/theotruvelot/catchook/internal/platform/storage/postgres/generated"
+	wasm "github.com/theotruvelot/catchook/internal/wasm/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+)
+
+type moduleRepository struct {
+	db        *pgxpool.Pool
+	queries   *generated.Queries
+	appLogger logger.Logger
+}
+
+func NewModuleRepository(db *pgxpool.Pool, appLogger logger.Logger) wasm.Repository {
+	return &moduleRepository{
+		db:        db,
+		queries:   generated.New(db),
+		appLogger: appLogger,
+	}
+}
+
+func (r moduleRepository) Create(ctx context.Context, m *wasm.Module) error {
+	ctx, span := tracer.StartSpan(ctx, "wasm.repository.create")
+	defer span.End()
+
+	userID, err := uuid.Parse(m.UserID)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	result, err := r.queries.CreateWasmModule(ctx, userID, m.Name, m.Hash, m.SizeBytes, m.Content)
+	if err != nil {
+		r.appLogger.Error(ctx, "Failed to create wasm module",
+			logger.String("name", m.Name),
+			logger.String("hash", m.Hash),
+			logger.Error(err),
+		)
+		span.RecordError(err)
+		return fmt.Errorf("failed to create wasm module: %w", err)
+	}
+
+	m.ID = result.ID.String()
+	m.CreatedAt = result.CreatedAt.Time
+	return nil
+}
+
+func (r moduleRepository) GetByHash(ctx context.Context, userID, hash string) (*wasm.Module, error) {
+	ctx, span := tracer.StartSpan(ctx, "wasm.repository.get_by_hash")
+	defer span.End()
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	result, err := r.queries.GetWasmModuleByHash(ctx, uid, hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		r.appLogger.Error(ctx, "Failed to get wasm module by hash", logger.Error(err))
+		return nil, fmt.Errorf("failed to get wasm module by hash: %w", err)
+	}
+
+	return toModule(result), nil
+}
+
+func (r moduleRepository) ListByUser(ctx context.Context, userID string) ([]*wasm.Module, error) {
+	ctx, span := tracer.StartSpan(ctx, "wasm.repository.list_by_user")
+	defer span.End()
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	results, err := r.queries.ListWasmModulesByUser(ctx, uid)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list wasm modules: %w", err)
+	}
+
+	modules := make([]*wasm.Module, len(results))
+	for i, result := range results {
+		modules[i] = toModule(generated.GetWasmModuleByHashRow(result))
+	}
+	return modules, nil
+}
+
+func (r moduleRepository) GetContent(ctx context.Context, hash string) ([]byte, error) {
+	ctx, span := tracer.StartSpan(ctx, "wasm.repository.get_content")
+	defer span.End()
+
+	content, err := r.queries.GetWasmModuleContent(ctx, hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get wasm module content: %w", err)
+	}
+	return content, nil
+}
+
+func toModule(result generated.GetWasmModuleByHashRow) *wasm.Module {
+	return &wasm.Module{
+		ID:        result.ID.String(),
+		UserID:    result.UserID.String(),
+		Name:      result.Name,
+		Hash:      result.Hash,
+		SizeBytes: result.SizeBytes,
+		CreatedAt: result.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/theotruvelot/catchook/internal/platform/auth"
+	wasm "github.com/theotruvelot/catchook/internal/wasm/domain"
+	"github.com/theotruvelot/catchook/pkg/logger"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+	"github.com/theotruvelot/catchook/pkg/wasmvm"
+)
+
+var wasmMagic = []byte("\x00asm")
+
+type moduleService struct {
+	moduleRepo    wasm.Repository
+	runtime       *wasmvm.Runtime
+	maxModuleSize int
+	appLogger     logger.Logger
+}
+
+func NewModuleService(moduleRepo wasm.Repository, runtime *wasmvm.Runtime, maxModuleSize int, appLogger logger.Logger) wasm.Service {
+	return &moduleService{
+		moduleRepo:    moduleRepo,
+		runtime:       runtime,
+		maxModuleSize: maxModuleSize,
+		appLogger:     appLogger,
+	}
+}
+
+// Upload stores a module after checking that it compiles and follows the
+// ABI. Uploading the same content twice returns the existing module.
+func (s moduleService) Upload(ctx context.Context, name string, content []byte) (*wasm.Module, error) {
+	ctx, span := tracer.StartSpan(ctx, "wasm.service.upload")
+	defer span.End()
+
+	currentUserID, err := auth.GetUserID(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting current user ID: %w", err)
+	}
+
+	if len(content) > s.maxModuleSize {
+		return nil, fmt.Errorf("%w: %d bytes, the limit is %d", wasm.ErrModuleTooLarge, len(content), s.maxModuleSize)
+	}
+	if !bytes.HasPrefix(content, wasmMagic) {
+		return nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"module": "file is not a WebAssembly binary",
+		}}
+	}
+
+	hash := wasmvm.Hash(content)
+	s.appLogger.Info(ctx, "Uploading wasm module",
+		logger.String("name", name),
+		logger.String("hash", hash),
+		logger.Int("size_bytes", len(content)),
+	)
+
+	existing, err := s.moduleRepo.GetByHash(ctx, currentUserID, hash)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting wasm module by hash: %w", err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	if _, err := s.runtime.Compile(ctx, content); err != nil {
+		return nil, &validatorpkg.ValidationErrors{Errors: map[string]string{
+			"module": err.Error(),
+		}}
+	}
+
+	module := &wasm.Module{
+		UserID:    currentUserID,
+		Name:      name,
+		Hash:      hash,
+		SizeBytes: int32(len(content)),
+		Content:   content,
+	}
+	if err := s.moduleRepo.Create(ctx, module); err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to create wasm module", logger.Error(err))
+		return nil, fmt.Errorf("creating wasm module: %w", err)
+	}
+
+	return module, nil
+}
+
+func (s moduleService) GetByHash(ctx context.Context, hash string) (*wasm.Module, error) {
+	ctx, span := tracer.StartSpan(ctx, "wasm.service.get_by_hash")
+	defer span.End()
+
+	currentUserID, err := auth.GetUserID(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting current user ID: %w", err)
+	}
+
+	module, err := s.moduleRepo.GetByHash(ctx, currentUserID, hash)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting wasm module by hash: %w", err)
+	}
+	if module == nil {
+		return nil, wasm.ErrModuleNotFound
+	}
+	return module, nil
+}
+
+func (s moduleService) List(ctx context.Context) ([]*wasm.Module, error) {
+	ctx, span := tracer.StartSpan(ctx, "wasm.service.list")
+	defer span.End()
+
+	currentUserID, err := auth.GetUserID(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting current user ID: %w", err)
+	}
+
+	modules, err := s.moduleRepo.ListByUser(ctx, currentUserID)
+	if err != nil {
+		span.RecordError(err)
+		s.appLogger.Error(ctx, "Failed to list wasm modules", logger.Error(err))
+		return nil, fmt.Errorf("listing wasm modules: %w", err)
+	}
+	return modules, nil
+}
+
+// Load compiles the module on first use. The engine calls it without a user
+// in the context, so modules are resolved by hash only.
+func (s moduleService) Load(ctx context.Context, hash string) (*wasmvm.Module, error) {
+	if module, ok := s.runtime.Cached(hash); ok {
+		return module, nil
+	}
+
+	ctx, span := tracer.StartSpan(ctx, "wasm.service.load")
+	defer span.End()
+
+	content, err := s.moduleRepo.GetContent(ctx, hash)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("getting wasm module content: %w", err)
+	}
+	if content == nil {
+		return nil, fmt.Errorf("%w: %s", wasm.ErrModuleNotFound, hash)
+	}
+
+	module, err := s.runtime.Compile(ctx, content)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("compiling wasm module %s: %w", hash, err)
+	}
+	return module, nil
+}
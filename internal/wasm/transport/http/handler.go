@@ -0,0 +1,105 @@
+package http
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/theotruvelot/catchook/internal/platform/http/middleware"
+	wasm "github.com/theotruvelot/catchook/internal/wasm/domain"
+	"github.com/theotruvelot/catchook/pkg/response"
+	"github.com/theotruvelot/catchook/pkg/tracer"
+	validatorpkg "github.com/theotruvelot/catchook/pkg/validator"
+)
+
+// Handler holds the wasm module dependencies
+type Handler struct {
+	wasmService wasm.Service
+	validator   *validatorpkg.Validator
+}
+
+// NewHandler creates a new wasm module handler
+func NewHandler(wasmService wasm.Service, validator *validatorpkg.Validator) *Handler {
+	return &Handler{
+		wasmService: wasmService,
+		validator:   validator,
+	}
+}
+
+// UploadModule expects a multipart form with a name field and the binary in
+// a module file field.
+func (h *Handler) UploadModule(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "wasm.handler.upload")
+	defer span.End()
+
+	var req wasm.UploadRequest
+	if err := h.validator.ParseAndValidate(c, &req); err != nil {
+		var verr *validatorpkg.ValidationErrors
+		if errors.As(err, &verr) {
+			return response.ValidationFailed(c, verr.Errors)
+		}
+		return response.BadRequest(c, err.Error(), nil)
+	}
+
+	fileHeader, err := c.FormFile("module")
+	if err != nil {
+		return response.ValidationFailed(c, map[string]string{"module": "module file is required"})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return response.BadRequest(c, "failed to read module file", nil)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return response.BadRequest(c, "failed to read module file", nil)
+	}
+
+	module, err := h.wasmService.Upload(ctx, req.Name, content)
+	if err != nil {
+		var verr *validatorpkg.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			return response.ValidationFailed(c, verr.Errors)
+		case errors.Is(err, wasm.ErrModuleTooLarge):
+			return response.BadRequest(c, err.Error(), nil)
+		default:
+			return response.InternalError(c, "failed to upload wasm module")
+		}
+	}
+
+	return response.Success(c, module.ToResponse(), "wasm module uploaded")
+}
+
+func (h *Handler) GetModule(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "wasm.handler.get")
+	defer span.End()
+
+	hash := c.Params("hash")
+	if hash == "" {
+		return response.BadRequest(c, "hash is required", nil)
+	}
+
+	module, err := h.wasmService.GetByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, wasm.ErrModuleNotFound) {
+			return response.NotFound(c, "wasm module not found")
+		}
+		return response.InternalError(c, "failed to get wasm module")
+	}
+
+	return response.Success(c, module.ToResponse(), "wasm module")
+}
+
+func (h *Handler) ListModules(c *fiber.Ctx) error {
+	ctx, span := tracer.StartSpan(middleware.GetContextWithRequestID(c), "wasm.handler.list")
+	defer span.End()
+
+	modules, err := h.wasmService.List(ctx)
+	if err != nil {
+		return response.InternalError(c, "failed to list wasm modules")
+	}
+
+	return response.Success(c, wasm.ToResponses(modules), "wasm modules listed")
+}
@@ -0,0 +1,55 @@
+// Package compiled caches values built from versioned records, such as the
+// compiled programs of filters and transformations.
+package compiled
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache keeps a value per record ID. An entry is rebuilt when the record
+// has been updated since the value was built; the owner of the records
+// invalidates the entries of the records it deletes or deactivates.
+type Cache[T any] struct {
+	mu      sync.RWMutex
+	entries map[string]entry[T]
+}
+
+type entry[T any] struct {
+	updatedAt time.Time
+	value     T
+}
+
+func NewCache[T any]() *Cache[T] {
+	return &Cache[T]{entries: map[string]entry[T]{}}
+}
+
+// Get returns the value of the record, building it on first use or when
+// the record was updated at another time than the cached value.
+func (c *Cache[T]) Get(id string, updatedAt time.Time, build func() (T, error)) (T, error) {
+	c.mu.RLock()
+	e, ok := c.entries[id]
+	c.mu.RUnlock()
+	if ok && e.updatedAt.Equal(updatedAt) {
+		return e.value, nil
+	}
+
+	value, err := build()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	c.mu.Lock()
+	c.entries[id] = entry[T]{updatedAt: updatedAt, value: value}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+// Invalidate drops the value of a record.
+func (c *Cache[T]) Invalidate(id string) {
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+}
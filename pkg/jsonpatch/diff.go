@@ -0,0 +1,137 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// Diff returns a JSON Patch turning a into b. Objects are compared member by
+// member and arrays element by element, elements beyond the length of the
+// shorter array being added or removed at the end.
+func Diff(a, b any) (Patch, error) {
+	d := &differ{patch: Patch{}}
+	if err := d.diff(Pointer{}, a, b); err != nil {
+		return nil, err
+	}
+	return d.patch, nil
+}
+
+type differ struct {
+	patch Patch
+}
+
+func (d *differ) diff(path Pointer, a, b any) error {
+	if jsonpath.Equal(a, b) {
+		return nil
+	}
+
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok {
+			return d.emit(OpReplace, path, b)
+		}
+		for _, k := range sortedKeys(x) {
+			if _, ok := y[k]; !ok {
+				if err := d.emit(OpRemove, path.child(k), nil); err != nil {
+					return err
+				}
+			}
+		}
+		for _, k := range sortedKeys(y) {
+			v, ok := x[k]
+			var err error
+			if !ok {
+				err = d.emit(OpAdd, path.child(k), y[k])
+			} else {
+				err = d.diff(path.child(k), v, y[k])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		y, ok := b.([]any)
+		if !ok {
+			return d.emit(OpReplace, path, b)
+		}
+		common := min(len(x), len(y))
+		for i := 0; i < common; i++ {
+			if err := d.diff(path.child(strconv.Itoa(i)), x[i], y[i]); err != nil {
+				return err
+			}
+		}
+		for i := len(x) - 1; i >= common; i-- {
+			if err := d.emit(OpRemove, path.child(strconv.Itoa(i)), nil); err != nil {
+				return err
+			}
+		}
+		for i := common; i < len(y); i++ {
+			if err := d.emit(OpAdd, path.child(strconv.Itoa(i)), y[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return d.emit(OpReplace, path, b)
+	}
+}
+
+func (d *differ) emit(op string, path Pointer, value any) error {
+	operation := Operation{Op: op, Path: path.String()}
+	if op != OpRemove {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		operation.Value = raw
+	}
+	d.patch = append(d.patch, operation)
+	return nil
+}
+
+// MergeDiff returns a JSON Merge Patch turning a into b. Merge patches
+// cannot set a member to null nor patch arrays element by element, so a
+// null member of b is dropped and a changed array is replaced.
+func MergeDiff(a, b any) any {
+	x, okA := a.(map[string]any)
+	y, okB := b.(map[string]any)
+	if !okA || !okB {
+		return Clone(b)
+	}
+
+	patch := map[string]any{}
+	for k := range x {
+		if v, ok := y[k]; !ok || v == nil {
+			patch[k] = nil
+		}
+	}
+	for k, v := range y {
+		if v == nil {
+			continue
+		}
+		old, ok := x[k]
+		if !ok {
+			patch[k] = Clone(v)
+			continue
+		}
+		if jsonpath.Equal(old, v) {
+			continue
+		}
+		patch[k] = MergeDiff(old, v)
+	}
+	return patch
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
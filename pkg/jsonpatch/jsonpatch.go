@@ -0,0 +1,331 @@
+// Package jsonpatch implements JSON Patch (RFC 6902), JSON Merge Patch
+// (RFC 7396) and JSON Pointer (RFC 6901) over documents decoded with
+// encoding/json, and generates patches from the difference between two
+// documents.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/theotruvelot/catchook/pkg/jsonpath"
+)
+
+// MaxOperations bounds the size of a patch.
+const MaxOperations = 1000
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation is an operation of a JSON Patch. Value is required by add,
+// replace and test, where null is a valid value, hence its raw form.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Patch is a JSON Patch document.
+type Patch []Operation
+
+// Validate checks the operations without applying them.
+func (p Patch) Validate() error {
+	if len(p) > MaxOperations {
+		return fmt.Errorf("patch exceeds %d operations", MaxOperations)
+	}
+	for i, op := range p {
+		if err := op.validate(); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (op Operation) validate() error {
+	if _, err := ParsePointer(op.Path); err != nil {
+		return fmt.Errorf("path: %w", err)
+	}
+
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		if len(op.Value) == 0 {
+			return fmt.Errorf("%s requires a value", op.Op)
+		}
+		if !json.Valid(op.Value) {
+			return fmt.Errorf("value is not valid JSON")
+		}
+	case OpRemove:
+	case OpMove, OpCopy:
+		if _, err := ParsePointer(op.From); err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		if op.Op == OpMove && strings.HasPrefix(op.Path, op.From+"/") {
+			return fmt.Errorf("cannot move %s into one of its children", op.From)
+		}
+	case "":
+		return fmt.Errorf("op is required")
+	default:
+		return fmt.Errorf("op must be one of: add remove replace move copy test")
+	}
+	return nil
+}
+
+// Apply applies the patch to a copy of doc and returns it. Operations are
+// applied in order and the patch fails as a whole at the first failing
+// operation, a test that does not hold included, in which case doc is left
+// as it was.
+func (p Patch) Apply(doc any) (any, error) {
+	doc = Clone(doc)
+	for i, op := range p {
+		var err error
+		if doc, err = op.apply(doc); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func (op Operation) apply(doc any) (any, error) {
+	path, err := ParsePointer(op.Path)
+	if err != nil {
+		return doc, err
+	}
+
+	switch op.Op {
+	case OpAdd:
+		value, err := decode(op.Value)
+		if err != nil {
+			return doc, err
+		}
+		return add(doc, path, value)
+	case OpRemove:
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case OpReplace:
+		value, err := decode(op.Value)
+		if err != nil {
+			return doc, err
+		}
+		if _, err := path.get(doc); err != nil {
+			return doc, err
+		}
+		if doc, _, err = remove(doc, path); err != nil {
+			return doc, err
+		}
+		return add(doc, path, value)
+	case OpMove:
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return doc, err
+		}
+		if op.From == op.Path {
+			_, err := from.get(doc)
+			return doc, err
+		}
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return doc, err
+		}
+		return add(doc, path, value)
+	case OpCopy:
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return doc, err
+		}
+		value, err := from.get(doc)
+		if err != nil {
+			return doc, err
+		}
+		return add(doc, path, Clone(value))
+	case OpTest:
+		expected, err := decode(op.Value)
+		if err != nil {
+			return doc, err
+		}
+		actual, err := path.get(doc)
+		if err != nil {
+			return doc, fmt.Errorf("test failed: %w", err)
+		}
+		if !jsonpath.Equal(actual, expected) {
+			return doc, fmt.Errorf("test failed: the value is %s, expected %s", encode(actual), encode(expected))
+		}
+		return doc, nil
+	default:
+		return doc, fmt.Errorf("unsupported op %q", op.Op)
+	}
+}
+
+func add(doc any, path Pointer, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := path.parent().get(doc)
+	if err != nil {
+		return doc, err
+	}
+	last := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		p[last] = value
+		return doc, nil
+	case []any:
+		i := len(p)
+		if last != "-" {
+			if i, err = arrayIndex(last, len(p)+1); err != nil {
+				return doc, err
+			}
+		}
+		grown := make([]any, 0, len(p)+1)
+		grown = append(grown, p[:i]...)
+		grown = append(grown, value)
+		grown = append(grown, p[i:]...)
+		return set(doc, path.parent(), grown)
+	default:
+		return doc, fmt.Errorf("%s is not inside an object or an array", path)
+	}
+}
+
+func remove(doc any, path Pointer) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := path.parent().get(doc)
+	if err != nil {
+		return doc, nil, err
+	}
+	last := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case map[string]any:
+		value, ok := p[last]
+		if !ok {
+			return doc, nil, fmt.Errorf("%s does not exist", path)
+		}
+		delete(p, last)
+		return doc, value, nil
+	case []any:
+		i, err := arrayIndex(last, len(p))
+		if err != nil {
+			return doc, nil, err
+		}
+		value := p[i]
+		shrunk := make([]any, 0, len(p)-1)
+		shrunk = append(shrunk, p[:i]...)
+		shrunk = append(shrunk, p[i+1:]...)
+		doc, err = set(doc, path.parent(), shrunk)
+		return doc, value, err
+	default:
+		return doc, nil, fmt.Errorf("%s does not exist", path)
+	}
+}
+
+// set replaces the existing value at path.
+func set(doc any, path Pointer, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := path.parent().get(doc)
+	if err != nil {
+		return doc, err
+	}
+	last := path[len(path)-1]
+	switch p := parent.(type) {
+	case map[string]any:
+		p[last] = value
+	case []any:
+		i, err := arrayIndex(last, len(p))
+		if err != nil {
+			return doc, err
+		}
+		p[i] = value
+	}
+	return doc, nil
+}
+
+func arrayIndex(token string, length int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i >= length {
+		return 0, fmt.Errorf("array index %d is out of range", i)
+	}
+	return i, nil
+}
+
+// MergePatch applies a JSON Merge Patch to a copy of doc: members of an
+// object patch replace those of the document, null members remove them,
+// and any other patch replaces the document.
+func MergePatch(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return Clone(patch)
+	}
+	target, ok := Clone(doc).(map[string]any)
+	if !ok {
+		target = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(target, k)
+			continue
+		}
+		target[k] = MergePatch(target[k], v)
+	}
+	return target
+}
+
+// Clone deep copies a decoded JSON document.
+func Clone(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		clone := make(map[string]any, len(v))
+		for k, child := range v {
+			clone[k] = Clone(child)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(v))
+		for i, child := range v {
+			clone[i] = Clone(child)
+		}
+		return clone
+	default:
+		return v
+	}
+}
+
+func decode(raw json.RawMessage) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	return v, nil
+}
+
+func encode(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(b) > 100 {
+		return string(b[:100]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,73 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pointer is a parsed JSON Pointer: the unescaped reference tokens.
+type Pointer []string
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// ParsePointer parses a JSON Pointer such as "/items/0/sku". The empty
+// string designates the whole document.
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("pointer %q must start with '/'", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(token, "~0", ""), "~1", ""), "~") {
+			return nil, fmt.Errorf("pointer %q has an invalid escape", s)
+		}
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return tokens, nil
+}
+
+func (p Pointer) String() string {
+	var sb strings.Builder
+	for _, token := range p {
+		sb.WriteByte('/')
+		sb.WriteString(pointerEscaper.Replace(token))
+	}
+	return sb.String()
+}
+
+func (p Pointer) parent() Pointer {
+	return p[:len(p)-1]
+}
+
+func (p Pointer) child(token string) Pointer {
+	return append(p[:len(p):len(p)], token)
+}
+
+func (p Pointer) get(doc any) (any, error) {
+	node := doc
+	for i, token := range p {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%s does not exist", p[:i+1])
+			}
+			node = v
+		case []any:
+			idx, err := arrayIndex(token, len(n))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p[:i+1], err)
+			}
+			node = n[idx]
+		default:
+			return nil, fmt.Errorf("%s does not exist", p[:i+1])
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,233 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// operand is the result of a filter sub-expression: either the nodes
+// selected by a path or a plain value.
+type operand struct {
+	nodes  []any
+	value  any
+	isPath bool
+}
+
+func (o operand) truthy() bool {
+	if o.isPath {
+		return len(o.nodes) > 0
+	}
+	b, ok := o.value.(bool)
+	return ok && b
+}
+
+// single returns the value a comparison works on. Paths only compare when
+// they select exactly one node.
+func (o operand) single() (any, bool) {
+	if !o.isPath {
+		return o.value, true
+	}
+	if len(o.nodes) == 1 {
+		return o.nodes[0], true
+	}
+	return nil, false
+}
+
+type expr interface {
+	eval(root, current any) operand
+}
+
+type literalExpr struct {
+	value any
+}
+
+func (e literalExpr) eval(_, _ any) operand {
+	return operand{value: e.value}
+}
+
+type pathExpr struct {
+	relative bool
+	path     *Path
+}
+
+func (e pathExpr) eval(root, current any) operand {
+	start := root
+	if e.relative {
+		start = current
+	}
+	return operand{nodes: e.path.query(root, start), isPath: true}
+}
+
+type notExpr struct {
+	inner expr
+}
+
+func (e notExpr) eval(root, current any) operand {
+	return operand{value: !e.inner.eval(root, current).truthy()}
+}
+
+type logicalExpr struct {
+	and         bool
+	left, right expr
+}
+
+func (e logicalExpr) eval(root, current any) operand {
+	left := e.left.eval(root, current).truthy()
+	if e.and && !left {
+		return operand{value: false}
+	}
+	if !e.and && left {
+		return operand{value: true}
+	}
+	return operand{value: e.right.eval(root, current).truthy()}
+}
+
+type comparisonExpr struct {
+	op          string
+	left, right expr
+	pattern     *regexp.Regexp
+}
+
+func (e comparisonExpr) eval(root, current any) operand {
+	lv, lok := e.left.eval(root, current).single()
+
+	if e.op == "=~" {
+		s, ok := lv.(string)
+		return operand{value: lok && ok && e.pattern.MatchString(s)}
+	}
+
+	rv, rok := e.right.eval(root, current).single()
+
+	var result bool
+	switch e.op {
+	case "==":
+		result = operandsEqual(lv, lok, rv, rok)
+	case "!=":
+		result = !operandsEqual(lv, lok, rv, rok)
+	case "<":
+		result = lok && rok && less(lv, rv)
+	case "<=":
+		result = lok && rok && (less(lv, rv) || Equal(lv, rv))
+	case ">":
+		result = lok && rok && less(rv, lv)
+	case ">=":
+		result = lok && rok && (less(rv, lv) || Equal(lv, rv))
+	}
+	return operand{value: result}
+}
+
+// operandsEqual treats two missing values as equal, as RFC 9535 does.
+func operandsEqual(lv any, lok bool, rv any, rok bool) bool {
+	if !lok || !rok {
+		return !lok && !rok
+	}
+	return Equal(lv, rv)
+}
+
+// Equal compares two JSON values. Numbers compare by value regardless of
+// their Go representation.
+func Equal(a, b any) bool {
+	if x, ok := ToNumber(a); ok {
+		y, ok := ToNumber(b)
+		return ok && x == y
+	}
+
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !Equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !Equal(v, w) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func less(a, b any) bool {
+	if x, ok := ToNumber(a); ok {
+		y, ok := ToNumber(b)
+		return ok && x < y
+	}
+	x, ok := a.(string)
+	if !ok {
+		return false
+	}
+	y, ok := b.(string)
+	return ok && x < y
+}
+
+// ToNumber returns the value of a JSON number. Strings are not converted.
+func ToNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Truthy reports whether a value counts as true: false, null, zero, the
+// empty string and empty arrays or objects do not.
+func Truthy(v any) bool {
+	if n, ok := ToNumber(v); ok {
+		return n != 0
+	}
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case []any:
+		return len(t) > 0
+	case map[string]any:
+		return len(t) > 0
+	default:
+		return true
+	}
+}
+
+func compilePattern(pattern, flags string) (*regexp.Regexp, error) {
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
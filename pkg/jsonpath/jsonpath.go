@@ -0,0 +1,319 @@
+// Package jsonpath evaluates JSONPath expressions against documents decoded
+// with encoding/json: maps, slices, strings, numbers (float64 or
+// json.Number), booleans and nil.
+//
+// Supported syntax:
+//
+//	$                     root
+//	.name  ['name']       member
+//	[0]  [-1]             array index, negative counts from the end
+//	[start:end:step]      array slice
+//	.*  [*]               every child
+//	..name  ..*  ..[0]    recursive descent
+//	['a','b']  [0,2]      union
+//	[?(@.price > 10)]     filter, also written [?@.price > 10]
+//
+// Filter expressions support @ (current node) and $ (root) paths, string,
+// number, true, false and null literals, the ==, !=, <, <=, >, >= and =~
+// operators (the latter against /regex/flags or a quoted pattern), the
+// && and || connectives, ! and parentheses. A path used on its own tests
+// for existence.
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxExpressionLength bounds the size of an expression so that user
+// supplied paths stay cheap to compile and evaluate.
+const MaxExpressionLength = 1024
+
+// maxNesting bounds the nesting of filter expressions and parentheses.
+const maxNesting = 32
+
+// SyntaxError reports an invalid expression and where parsing stopped.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jsonpath: %s at position %d", e.Msg, e.Pos)
+}
+
+// Path is a compiled JSONPath expression. It is immutable and safe for
+// concurrent use.
+type Path struct {
+	expr     string
+	segments []*segment
+}
+
+// Compile parses a JSONPath expression.
+func Compile(expr string) (*Path, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, &SyntaxError{Msg: "empty expression"}
+	}
+	if len(expr) > MaxExpressionLength {
+		return nil, fmt.Errorf("jsonpath: expression is longer than %d characters", MaxExpressionLength)
+	}
+
+	p := &parser{input: expr}
+	return p.parse()
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+func MustCompile(expr string) *Path {
+	path, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+func (p *Path) String() string {
+	return p.expr
+}
+
+// IsDefinite reports whether the path selects at most one value, i.e. it
+// only uses member names and indexes.
+func (p *Path) IsDefinite() bool {
+	for _, seg := range p.segments {
+		if seg.descendant || len(seg.selectors) != 1 || !seg.selectors[0].singular() {
+			return false
+		}
+	}
+	return true
+}
+
+// Query returns every value selected by the path, in document order.
+// Members of an object are visited in key order.
+func (p *Path) Query(doc any) []any {
+	return p.query(doc, doc)
+}
+
+// Get returns the first value selected by the path.
+func (p *Path) Get(doc any) (any, bool) {
+	nodes := p.Query(doc)
+	if len(nodes) == 0 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+// Value returns the result of the path the way callers usually expect it:
+// the selected value for a definite path, and the list of matches (possibly
+// empty) otherwise. The boolean is false only when a definite path did not
+// match.
+func (p *Path) Value(doc any) (any, bool) {
+	nodes := p.Query(doc)
+	if p.IsDefinite() {
+		if len(nodes) == 0 {
+			return nil, false
+		}
+		return nodes[0], true
+	}
+	return nodes, true
+}
+
+func (p *Path) query(root, current any) []any {
+	nodes := []any{current}
+	for _, seg := range p.segments {
+		next := make([]any, 0, len(nodes))
+		for _, node := range nodes {
+			next = seg.apply(root, node, next)
+		}
+		nodes = next
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+func (s *segment) apply(root, node any, out []any) []any {
+	if !s.descendant {
+		return s.selectAll(root, node, out)
+	}
+	descend(node, func(n any) {
+		out = s.selectAll(root, n, out)
+	})
+	return out
+}
+
+func (s *segment) selectAll(root, node any, out []any) []any {
+	for _, sel := range s.selectors {
+		out = sel.apply(root, node, out)
+	}
+	return out
+}
+
+// descend visits node and all of its descendants, parents first.
+func descend(node any, visit func(any)) {
+	visit(node)
+	for _, child := range children(node) {
+		descend(child, visit)
+	}
+}
+
+func children(node any) []any {
+	switch n := node.(type) {
+	case map[string]any:
+		keys := sortedKeys(n)
+		out := make([]any, len(keys))
+		for i, k := range keys {
+			out[i] = n[k]
+		}
+		return out
+	case []any:
+		return n
+	default:
+		return nil
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type selector interface {
+	apply(root, node any, out []any) []any
+	locate(root any, node Node, out []Node) []Node
+	singular() bool
+}
+
+type nameSelector string
+
+func (s nameSelector) apply(_, node any, out []any) []any {
+	if m, ok := node.(map[string]any); ok {
+		if v, ok := m[string(s)]; ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (s nameSelector) singular() bool { return true }
+
+type indexSelector int
+
+func (s indexSelector) apply(_, node any, out []any) []any {
+	arr, ok := node.([]any)
+	if !ok {
+		return out
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(arr)
+	}
+	if i >= 0 && i < len(arr) {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+func (s indexSelector) singular() bool { return true }
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(_, node any, out []any) []any {
+	return append(out, children(node)...)
+}
+
+func (wildcardSelector) singular() bool { return false }
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSelector) apply(_, node any, out []any) []any {
+	arr, ok := node.([]any)
+	if !ok {
+		return out
+	}
+	for _, i := range s.indices(len(arr)) {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+// indices returns the indexes selected in an array of length n.
+func (s sliceSelector) indices(n int) []int {
+	if s.step == 0 {
+		return nil
+	}
+
+	normalize := func(i int) int {
+		if i < 0 {
+			return n + i
+		}
+		return i
+	}
+
+	var out []int
+	if s.step > 0 {
+		start, end := 0, n
+		if s.start != nil {
+			start = clamp(normalize(*s.start), 0, n)
+		}
+		if s.end != nil {
+			end = clamp(normalize(*s.end), 0, n)
+		}
+		for i := start; i < end; i += s.step {
+			out = append(out, i)
+		}
+		return out
+	}
+
+	start, end := n-1, -1
+	if s.start != nil {
+		start = clamp(normalize(*s.start), -1, n-1)
+	}
+	if s.end != nil {
+		end = clamp(normalize(*s.end), -1, n-1)
+	}
+	for i := start; i > end; i += s.step {
+		out = append(out, i)
+	}
+	return out
+}
+
+func (sliceSelector) singular() bool { return false }
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+type filterSelector struct {
+	expr expr
+}
+
+func (s filterSelector) apply(root, node any, out []any) []any {
+	for _, child := range children(node) {
+		if s.expr.eval(root, child).truthy() {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+func (filterSelector) singular() bool { return false }
@@ -0,0 +1,338 @@
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Location is the normalized path of a value inside a document: a list of
+// member names (string) and array indexes (non-negative int) from the root.
+type Location []any
+
+// Node is a value selected by a path together with its location.
+type Node struct {
+	Location Location
+	Value    any
+}
+
+func (l Location) String() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, step := range l {
+		switch s := step.(type) {
+		case string:
+			sb.WriteString("['")
+			sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `'`, `\'`))
+			sb.WriteString("']")
+		case int:
+			sb.WriteString("[" + strconv.Itoa(s) + "]")
+		}
+	}
+	return sb.String()
+}
+
+func (l Location) child(step any) Location {
+	return append(l[:len(l):len(l)], step)
+}
+
+// Locate returns every value selected by the path with its location, in the
+// same order as Query.
+func (p *Path) Locate(doc any) []Node {
+	nodes := []Node{{Value: doc}}
+	for _, seg := range p.segments {
+		next := make([]Node, 0, len(nodes))
+		for _, node := range nodes {
+			next = seg.locate(doc, node, next)
+		}
+		nodes = next
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+func (s *segment) locate(root any, node Node, out []Node) []Node {
+	if !s.descendant {
+		return s.locateAll(root, node, out)
+	}
+	descendNodes(node, func(n Node) {
+		out = s.locateAll(root, n, out)
+	})
+	return out
+}
+
+func (s *segment) locateAll(root any, node Node, out []Node) []Node {
+	for _, sel := range s.selectors {
+		out = sel.locate(root, node, out)
+	}
+	return out
+}
+
+func descendNodes(node Node, visit func(Node)) {
+	visit(node)
+	for _, child := range childNodes(node) {
+		descendNodes(child, visit)
+	}
+}
+
+func childNodes(node Node) []Node {
+	switch n := node.Value.(type) {
+	case map[string]any:
+		keys := sortedKeys(n)
+		out := make([]Node, len(keys))
+		for i, k := range keys {
+			out[i] = Node{Location: node.Location.child(k), Value: n[k]}
+		}
+		return out
+	case []any:
+		out := make([]Node, len(n))
+		for i, v := range n {
+			out[i] = Node{Location: node.Location.child(i), Value: v}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func (s nameSelector) locate(_ any, node Node, out []Node) []Node {
+	if m, ok := node.Value.(map[string]any); ok {
+		if v, ok := m[string(s)]; ok {
+			out = append(out, Node{Location: node.Location.child(string(s)), Value: v})
+		}
+	}
+	return out
+}
+
+func (s indexSelector) locate(_ any, node Node, out []Node) []Node {
+	arr, ok := node.Value.([]any)
+	if !ok {
+		return out
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(arr)
+	}
+	if i >= 0 && i < len(arr) {
+		out = append(out, Node{Location: node.Location.child(i), Value: arr[i]})
+	}
+	return out
+}
+
+func (wildcardSelector) locate(_ any, node Node, out []Node) []Node {
+	return append(out, childNodes(node)...)
+}
+
+func (s sliceSelector) locate(_ any, node Node, out []Node) []Node {
+	arr, ok := node.Value.([]any)
+	if !ok {
+		return out
+	}
+	for _, i := range s.indices(len(arr)) {
+		out = append(out, Node{Location: node.Location.child(i), Value: arr[i]})
+	}
+	return out
+}
+
+func (s filterSelector) locate(root any, node Node, out []Node) []Node {
+	for _, child := range childNodes(node) {
+		if s.expr.eval(root, child.Value).truthy() {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// Lookup returns the value at a location.
+func Lookup(doc any, loc Location) (any, bool) {
+	node := doc
+	for _, step := range loc {
+		switch s := step.(type) {
+		case string:
+			m, ok := node.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if node, ok = m[s]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := node.([]any)
+			if !ok || s < 0 || s >= len(arr) {
+				return nil, false
+			}
+			node = arr[s]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// Replace sets the value at an existing location and returns the document,
+// which is v itself when loc is the root. Maps and slices of doc are
+// modified in place.
+func Replace(doc any, loc Location, v any) (any, error) {
+	if len(loc) == 0 {
+		return v, nil
+	}
+	parent, ok := Lookup(doc, loc[:len(loc)-1])
+	if !ok {
+		return doc, fmt.Errorf("jsonpath: %s does not exist", loc)
+	}
+	switch s := loc[len(loc)-1].(type) {
+	case string:
+		m, ok := parent.(map[string]any)
+		if !ok {
+			return doc, fmt.Errorf("jsonpath: %s is not an object member", loc)
+		}
+		m[s] = v
+	case int:
+		arr, ok := parent.([]any)
+		if !ok || s < 0 || s >= len(arr) {
+			return doc, fmt.Errorf("jsonpath: %s is not an array element", loc)
+		}
+		arr[s] = v
+	}
+	return doc, nil
+}
+
+// Remove deletes the values at the given locations and returns the
+// document. Array elements are removed from the highest index down, so the
+// locations stay valid while removing; locations that no longer exist,
+// such as those below an already removed value, are ignored. The root
+// cannot be removed.
+func Remove(doc any, locs []Location) any {
+	ordered := append([]Location(nil), locs...)
+	sortDescending(ordered)
+
+	for _, loc := range ordered {
+		if len(loc) == 0 {
+			continue
+		}
+		parentLoc := loc[:len(loc)-1]
+		parent, ok := Lookup(doc, parentLoc)
+		if !ok {
+			continue
+		}
+		switch s := loc[len(loc)-1].(type) {
+		case string:
+			if m, ok := parent.(map[string]any); ok {
+				delete(m, s)
+			}
+		case int:
+			arr, ok := parent.([]any)
+			if !ok || s < 0 || s >= len(arr) {
+				continue
+			}
+			trimmed := make([]any, 0, len(arr)-1)
+			trimmed = append(trimmed, arr[:s]...)
+			trimmed = append(trimmed, arr[s+1:]...)
+			doc, _ = Replace(doc, parentLoc, trimmed)
+		}
+	}
+	return doc
+}
+
+func sortDescending(locs []Location) {
+	less := func(a, b Location) bool {
+		for i := 0; i < len(a) && i < len(b); i++ {
+			switch x := a[i].(type) {
+			case int:
+				if y, ok := b[i].(int); ok && x != y {
+					return x < y
+				}
+			case string:
+				if y, ok := b[i].(string); ok && x != y {
+					return x < y
+				}
+			}
+		}
+		return len(a) < len(b)
+	}
+	sort.SliceStable(locs, func(i, j int) bool { return less(locs[j], locs[i]) })
+}
+
+// Set writes v at the location designated by a definite path and returns
+// the document. Missing objects along the way are created, and an index
+// equal to the length of an array appends to it.
+func (p *Path) Set(doc, v any) (any, error) {
+	if !p.IsDefinite() {
+		return doc, fmt.Errorf("jsonpath: %s does not designate a single value", p.expr)
+	}
+	return setIn(doc, p.segments, v)
+}
+
+func setIn(node any, segments []*segment, v any) (any, error) {
+	if len(segments) == 0 {
+		return v, nil
+	}
+
+	switch sel := segments[0].selectors[0].(type) {
+	case nameSelector:
+		m, ok := node.(map[string]any)
+		if node == nil {
+			m, ok = map[string]any{}, true
+		}
+		if !ok {
+			return node, fmt.Errorf("jsonpath: cannot set member %q of a %s", string(sel), kind(node))
+		}
+		child, err := setIn(m[string(sel)], segments[1:], v)
+		if err != nil {
+			return node, err
+		}
+		m[string(sel)] = child
+		return m, nil
+	case indexSelector:
+		arr, ok := node.([]any)
+		if node == nil {
+			ok = true
+		}
+		if !ok {
+			return node, fmt.Errorf("jsonpath: cannot set index %d of a %s", int(sel), kind(node))
+		}
+		i := int(sel)
+		if i < 0 {
+			i += len(arr)
+		}
+		switch {
+		case i >= 0 && i < len(arr):
+			child, err := setIn(arr[i], segments[1:], v)
+			if err != nil {
+				return node, err
+			}
+			arr[i] = child
+		case i == len(arr):
+			child, err := setIn(nil, segments[1:], v)
+			if err != nil {
+				return node, err
+			}
+			arr = append(arr, child)
+		default:
+			return node, fmt.Errorf("jsonpath: index %d is out of range", int(sel))
+		}
+		return arr, nil
+	default:
+		return node, fmt.Errorf("jsonpath: cannot set through a non-singular selector")
+	}
+}
+
+func kind(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,532 @@
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type parser struct {
+	input string
+	pos   int
+	depth int
+}
+
+func (p *parser) parse() (*Path, error) {
+	if !p.consume('$') {
+		return nil, p.errorf("expression must start with '$'")
+	}
+
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	return &Path{expr: p.input, segments: segments}, nil
+}
+
+// parseSegments reads segments until a character that cannot continue a
+// path, which lets filter expressions embed paths.
+func (p *parser) parseSegments() ([]*segment, error) {
+	var segments []*segment
+	for !p.eof() {
+		var seg *segment
+		var err error
+
+		switch {
+		case strings.HasPrefix(p.input[p.pos:], ".."):
+			p.pos += 2
+			seg, err = p.parseDescendant()
+		case p.peek() == '.':
+			p.pos++
+			seg, err = p.parseDotMember()
+		case p.peek() == '[':
+			seg, err = p.parseBracket()
+		default:
+			return segments, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+func (p *parser) parseDotMember() (*segment, error) {
+	if p.consume('*') {
+		return &segment{selectors: []selector{wildcardSelector{}}}, nil
+	}
+	name := p.parseName()
+	if name == "" {
+		return nil, p.errorf("expected member name")
+	}
+	return &segment{selectors: []selector{nameSelector(name)}}, nil
+}
+
+func (p *parser) parseDescendant() (*segment, error) {
+	var seg *segment
+	var err error
+
+	switch {
+	case p.peek() == '[':
+		seg, err = p.parseBracket()
+	case p.consume('*'):
+		seg = &segment{selectors: []selector{wildcardSelector{}}}
+	default:
+		name := p.parseName()
+		if name == "" {
+			return nil, p.errorf("expected member name after '..'")
+		}
+		seg = &segment{selectors: []selector{nameSelector(name)}}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seg.descendant = true
+	return seg, nil
+}
+
+func (p *parser) parseName() string {
+	start := p.pos
+	for !p.eof() && isNameChar(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') ||
+		c >= utf8.RuneSelf
+}
+
+func (p *parser) parseBracket() (*segment, error) {
+	p.pos++ // '['
+
+	seg := &segment{}
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		seg.selectors = append(seg.selectors, sel)
+
+		p.skipSpace()
+		if p.consume(',') {
+			continue
+		}
+		if p.consume(']') {
+			return seg, nil
+		}
+		return nil, p.errorf("expected ',' or ']'")
+	}
+}
+
+func (p *parser) parseSelector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector(s), nil
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr: e}, nil
+	case c == '-' || c == ':' || isDigit(c):
+		return p.parseIndexOrSlice()
+	default:
+		return nil, p.errorf("invalid selector")
+	}
+}
+
+func (p *parser) parseIndexOrSlice() (selector, error) {
+	start, err := p.parseOptionalInt()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.consume(':') {
+		if start == nil {
+			return nil, p.errorf("expected index")
+		}
+		return indexSelector(*start), nil
+	}
+
+	p.skipSpace()
+	end, err := p.parseOptionalInt()
+	if err != nil {
+		return nil, err
+	}
+
+	step := 1
+	p.skipSpace()
+	if p.consume(':') {
+		p.skipSpace()
+		s, err := p.parseOptionalInt()
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			step = *s
+		}
+	}
+
+	return sliceSelector{start: start, end: end, step: step}, nil
+}
+
+func (p *parser) parseOptionalInt() (*int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.eof() && isDigit(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid integer")
+	}
+	return &n, nil
+}
+
+func (p *parser) parseString() (string, error) {
+	quote := p.input[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for !p.eof() {
+		c := p.input[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("unterminated escape sequence")
+			}
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) parseEscape(b *strings.Builder) error {
+	c := p.input[p.pos]
+	p.pos++
+
+	switch c {
+	case '\\', '/', '\'', '"':
+		b.WriteByte(c)
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'u':
+		if p.pos+4 > len(p.input) {
+			return p.errorf("invalid unicode escape")
+		}
+		r, err := strconv.ParseUint(p.input[p.pos:p.pos+4], 16, 32)
+		if err != nil {
+			return p.errorf("invalid unicode escape")
+		}
+		p.pos += 4
+		b.WriteRune(rune(r))
+	default:
+		p.pos--
+		return p.errorf("invalid escape sequence")
+	}
+	return nil
+}
+
+// Filter expressions, lowest precedence first.
+
+func (p *parser) parseOr() (expr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxNesting {
+		return nil, p.errorf("expression is nested too deeply")
+	}
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !strings.HasPrefix(p.input[p.pos:], "||") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !strings.HasPrefix(p.input[p.pos:], "&&") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: true, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	p.skipSpace()
+	if p.peek() == '!' && p.peekAt(1) != '=' {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner: inner}, nil
+	}
+	return p.parseComparison()
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(p.input[p.pos:], candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return left, nil
+	}
+	p.pos += len(op)
+	p.skipSpace()
+
+	if op == "=~" {
+		re, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+		return comparisonExpr{op: op, left: left, pattern: re}, nil
+	}
+
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return comparisonExpr{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(')') {
+			return nil, p.errorf("expected ')'")
+		}
+		return e, nil
+	case c == '@' || c == '$':
+		start := p.pos
+		p.pos++
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return pathExpr{
+			relative: c == '@',
+			path:     &Path{expr: p.input[start:p.pos], segments: segments},
+		}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalExpr{value: s}, nil
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	default:
+		for keyword, value := range map[string]any{"true": true, "false": false, "null": nil} {
+			if strings.HasPrefix(p.input[p.pos:], keyword) &&
+				(p.pos+len(keyword) == len(p.input) || !isNameChar(p.input[p.pos+len(keyword)])) {
+				p.pos += len(keyword)
+				return literalExpr{value: value}, nil
+			}
+		}
+		return nil, p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *parser) parseNumber() (expr, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.eof() {
+		c := p.input[p.pos]
+		if !isDigit(c) && c != '.' && c != 'e' && c != 'E' &&
+			!((c == '+' || c == '-') && (p.input[p.pos-1] == 'e' || p.input[p.pos-1] == 'E')) {
+			break
+		}
+		p.pos++
+	}
+
+	f, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	return literalExpr{value: f}, nil
+}
+
+// parsePattern reads the right-hand side of =~, either /pattern/flags or a
+// quoted string, and compiles it.
+func (p *parser) parsePattern() (*regexp.Regexp, error) {
+	start := p.pos
+
+	var pattern, flags string
+	switch p.peek() {
+	case '/':
+		p.pos++
+		var b strings.Builder
+		for {
+			if p.eof() {
+				return nil, p.errorf("unterminated regular expression")
+			}
+			c := p.input[p.pos]
+			p.pos++
+			if c == '/' {
+				break
+			}
+			if c == '\\' && p.peek() == '/' {
+				c = '/'
+				p.pos++
+			} else if c == '\\' && !p.eof() {
+				b.WriteByte(c)
+				c = p.input[p.pos]
+				p.pos++
+			}
+			b.WriteByte(c)
+		}
+		pattern = b.String()
+		for !p.eof() && strings.IndexByte("ims", p.peek()) >= 0 {
+			flags += string(p.peek())
+			p.pos++
+		}
+	case '\'', '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		pattern = s
+	default:
+		return nil, p.errorf("expected regular expression after '=~'")
+	}
+
+	re, err := compilePattern(pattern, flags)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid regular expression: %v", err)
+	}
+	return re, nil
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) byte {
+	if p.pos+offset >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos+offset]
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.peek() == c && !p.eof() {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n' || p.input[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
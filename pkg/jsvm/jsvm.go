@@ -0,0 +1,353 @@
+// Package jsvm runs user supplied JavaScript in an embedded, pure-Go
+// runtime. Scripts only see the ECMAScript built-ins and a console object:
+// there is no module loader, timer, network or filesystem API. Every call
+// runs in a fresh runtime so that no state leaks from one event to the
+// next, while the compiled program is reused.
+//
+// goja cannot account for the memory of a single runtime. Scripts are
+// instead bounded together: at most MaxConcurrent of them run at once, the
+// size of their input and output is capped, and a script is interrupted
+// when the heap of the process grows past its memory limit while it runs.
+// Allocations of the scripts running alongside count towards that growth,
+// so the limit may stop a script early, but the heap used by scripts stays
+// within MaxConcurrent times the memory limit.
+package jsvm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/metrics"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// MaxCodeLength bounds the size of a script.
+const MaxCodeLength = 64 * 1024
+
+const (
+	maxCallStackSize = 512
+	maxLogLineLength = 1024
+	memoryPollPeriod = time.Millisecond
+	heapObjectsBytes = "/memory/classes/heap/objects:bytes"
+)
+
+var (
+	ErrTimeout        = errors.New("script exceeded its time limit")
+	ErrMemoryLimit    = errors.New("script exceeded its memory limit")
+	ErrInputTooLarge  = errors.New("script input exceeds its size limit")
+	ErrOutputTooLarge = errors.New("script output exceeds its size limit")
+)
+
+// Limits bound a single execution. Zero values leave a limit unset.
+type Limits struct {
+	Timeout time.Duration
+	// MemoryBytes is the growth of the heap after which the script is
+	// interrupted.
+	MemoryBytes    uint64
+	MaxInputBytes  int
+	MaxOutputBytes int
+	MaxLogLines    int
+}
+
+var DefaultLimits = Limits{
+	Timeout:        250 * time.Millisecond,
+	MemoryBytes:    32 << 20,
+	MaxInputBytes:  1 << 20,
+	MaxOutputBytes: 1 << 20,
+	MaxLogLines:    100,
+}
+
+// DefaultMaxConcurrent is the number of scripts running at once until
+// SetMaxConcurrent is called.
+const DefaultMaxConcurrent = 4
+
+var slots atomic.Pointer[chan struct{}]
+
+func init() {
+	SetMaxConcurrent(DefaultMaxConcurrent)
+}
+
+// SetMaxConcurrent sets the number of scripts that may run at once in the
+// process. It is meant to be called once, before any script runs.
+func SetMaxConcurrent(n int) {
+	if n <= 0 {
+		n = DefaultMaxConcurrent
+	}
+	ch := make(chan struct{}, n)
+	slots.Store(&ch)
+}
+
+// acquire waits for a free slot, until the context is done. The returned
+// function releases the slot.
+func acquire(ctx context.Context) (func(), error) {
+	ch := *slots.Load()
+	select {
+	case ch <- struct{}{}:
+		return func() { <-ch }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Program is a compiled script exposing an entry function. It is safe for
+// concurrent use.
+type Program struct {
+	entry   string
+	program *goja.Program
+}
+
+// Result is the outcome of a call. Output is the JSON encoding of the value
+// returned by the entry function, nil when it returned undefined.
+type Result struct {
+	Output json.RawMessage
+	Logs   []string
+}
+
+// Compile parses the script and checks that it defines the entry function.
+func Compile(name, code, entry string) (*Program, error) {
+	if strings.TrimSpace(code) == "" {
+		return nil, fmt.Errorf("code is required")
+	}
+	if len(code) > MaxCodeLength {
+		return nil, fmt.Errorf("code exceeds %d bytes", MaxCodeLength)
+	}
+
+	compiled, err := goja.Compile(name, code, true)
+	if err != nil {
+		return nil, fmt.Errorf("syntax error: %w", err)
+	}
+
+	p := &Program{entry: entry, program: compiled}
+
+	// The top-level code of the script runs here, under the same limits
+	// as a call.
+	release, err := acquire(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	vm, _ := newRuntime(DefaultLimits)
+	stop := watch(context.Background(), vm, DefaultLimits)
+	defer stop()
+
+	if _, err := p.load(vm); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Call runs the entry function with arg, converted to a plain JavaScript
+// value through JSON.
+func (p *Program) Call(ctx context.Context, limits Limits, arg any) (*Result, error) {
+	input, err := json.Marshal(arg)
+	if err != nil {
+		return nil, fmt.Errorf("encode input: %w", err)
+	}
+	if limits.MaxInputBytes > 0 && len(input) > limits.MaxInputBytes {
+		return nil, ErrInputTooLarge
+	}
+
+	release, err := acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	vm, logs := newRuntime(limits)
+	stop := watch(ctx, vm, limits)
+	defer stop()
+
+	result := &Result{}
+
+	fn, err := p.load(vm)
+	if err != nil {
+		result.Logs = logs.lines()
+		return result, err
+	}
+
+	value, err := vm.RunString("(" + string(input) + ")")
+	if err == nil {
+		value, err = fn(goja.Undefined(), value)
+	}
+	if err == nil {
+		result.Output, err = stringify(vm, value)
+	}
+	if err == nil && limits.MaxOutputBytes > 0 && len(result.Output) > limits.MaxOutputBytes {
+		result.Output = nil
+		err = ErrOutputTooLarge
+	}
+
+	result.Logs = logs.lines()
+	if err != nil {
+		return result, scriptError(err)
+	}
+	return result, nil
+}
+
+func (p *Program) load(vm *goja.Runtime) (goja.Callable, error) {
+	if _, err := vm.RunProgram(p.program); err != nil {
+		return nil, scriptError(err)
+	}
+	fn, ok := goja.AssertFunction(vm.Get(p.entry))
+	if !ok {
+		return nil, fmt.Errorf("code must define a function %s(event)", p.entry)
+	}
+	return fn, nil
+}
+
+func newRuntime(limits Limits) (*goja.Runtime, *logBuffer) {
+	vm := goja.New()
+	vm.SetMaxCallStackSize(maxCallStackSize)
+
+	logs := &logBuffer{max: limits.MaxLogLines}
+	console := vm.NewObject()
+	for _, level := range []string{"log", "info", "warn", "error", "debug"} {
+		_ = console.Set(level, logs.writer(vm, level))
+	}
+	_ = vm.Set("console", console)
+
+	return vm, logs
+}
+
+// watch interrupts the runtime when the context is done, the time limit is
+// reached or the heap grows past the memory limit. The returned function
+// stops watching.
+func watch(ctx context.Context, vm *goja.Runtime, limits Limits) func() {
+	done := make(chan struct{})
+
+	go func() {
+		var timeout <-chan time.Time
+		if limits.Timeout > 0 {
+			timer := time.NewTimer(limits.Timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		var poll <-chan time.Time
+		var baseline uint64
+		if limits.MemoryBytes > 0 {
+			ticker := time.NewTicker(memoryPollPeriod)
+			defer ticker.Stop()
+			poll = ticker.C
+			baseline = heapObjects()
+		}
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				vm.Interrupt(ctx.Err())
+				return
+			case <-timeout:
+				vm.Interrupt(ErrTimeout)
+				return
+			case <-poll:
+				if current := heapObjects(); current > baseline && current-baseline > limits.MemoryBytes {
+					vm.Interrupt(ErrMemoryLimit)
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func heapObjects() uint64 {
+	sample := []metrics.Sample{{Name: heapObjectsBytes}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+func stringify(vm *goja.Runtime, value goja.Value) (json.RawMessage, error) {
+	if value == nil || goja.IsUndefined(value) {
+		return nil, nil
+	}
+	stringifyFn, _ := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("stringify"))
+	encoded, err := stringifyFn(goja.Undefined(), value)
+	if err != nil {
+		return nil, err
+	}
+	if goja.IsUndefined(encoded) {
+		return nil, nil
+	}
+	return json.RawMessage(encoded.String()), nil
+}
+
+// scriptError turns runtime errors into messages suitable for the step
+// trace, keeping the limit errors comparable with errors.Is.
+func scriptError(err error) error {
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		if cause, ok := interrupted.Value().(error); ok {
+			return cause
+		}
+	}
+	var overflow *goja.StackOverflowError
+	if errors.As(err, &overflow) {
+		return fmt.Errorf("script error: maximum call stack size exceeded")
+	}
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return fmt.Errorf("script error: %s", exception.Error())
+	}
+	return fmt.Errorf("script error: %w", err)
+}
+
+type logBuffer struct {
+	max     int
+	entries []string
+	dropped int
+}
+
+func (b *logBuffer) writer(vm *goja.Runtime, level string) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		if len(b.entries) >= b.max {
+			b.dropped++
+			return goja.Undefined()
+		}
+
+		parts := make([]string, len(call.Arguments))
+		for i, arg := range call.Arguments {
+			parts[i] = formatLogValue(vm, arg)
+		}
+		line := strings.Join(parts, " ")
+		if level != "log" && level != "info" {
+			line = "[" + level + "] " + line
+		}
+		if len(line) > maxLogLineLength {
+			line = line[:maxLogLineLength] + "..."
+		}
+
+		b.entries = append(b.entries, line)
+		return goja.Undefined()
+	}
+}
+
+func (b *logBuffer) lines() []string {
+	if b.dropped > 0 {
+		return append(b.entries, fmt.Sprintf("... %d more lines dropped", b.dropped))
+	}
+	return b.entries
+}
+
+func formatLogValue(vm *goja.Runtime, v goja.Value) string {
+	if _, ok := v.(*goja.Object); ok {
+		if _, isFunc := goja.AssertFunction(v); !isFunc {
+			if encoded, err := stringify(vm, v); err == nil && encoded != nil {
+				return string(encoded)
+			}
+		}
+	}
+	return v.String()
+}
@@ -0,0 +1,302 @@
+// Package wasmvm runs user supplied WebAssembly modules in an embedded,
+// pure-Go runtime. Modules get no host access besides a logging function:
+// no WASI, filesystem, clock or network.
+//
+// A module exchanges JSON with the host through its linear memory:
+//
+//	(export "memory" (memory 1))
+//	(export "alloc" (func (param $size i32) (result i32)))
+//	(export "<entry>" (func (param $ptr i32) (param $len i32) (result i64)))
+//	(import "env" "log" (func (param $ptr i32) (param $len i32)))  ;; optional
+//
+// The host calls alloc to reserve room for the input document, writes it
+// there and calls the entry function with its location. The entry function
+// returns the location of its JSON output packed as ptr<<32 | len. Every
+// call runs in a fresh instance that is discarded afterwards, so modules
+// need not free anything.
+package wasmvm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const (
+	AllocFunction = "alloc"
+	MemoryExport  = "memory"
+	HostModule    = "env"
+	LogFunction   = "log"
+	pageSize      = 64 * 1024
+	maxLogLength  = 1024
+)
+
+var ErrTimeout = errors.New("module exceeded its time limit")
+
+// Limits bound the execution of a module. Timeout applies to a single call
+// and MemoryBytes to the linear memory of an instance, rounded down to whole
+// 64KiB pages. wazero has no fuel metering, so the timeout is what stops a
+// module that never returns.
+type Limits struct {
+	Timeout     time.Duration
+	MemoryBytes uint64
+	MaxLogLines int
+}
+
+var DefaultLimits = Limits{
+	Timeout:     500 * time.Millisecond,
+	MemoryBytes: 64 << 20,
+	MaxLogLines: 100,
+}
+
+// Loader resolves a module from its content hash.
+type Loader interface {
+	Load(ctx context.Context, hash string) (*Module, error)
+}
+
+// Runtime compiles and runs modules. Compiled modules are cached by content
+// hash, which never needs invalidation since the hash identifies the code.
+type Runtime struct {
+	runtime wazero.Runtime
+	limits  Limits
+
+	mu      sync.RWMutex
+	modules map[string]*Module
+}
+
+// Module is a compiled module. It is safe for concurrent use.
+type Module struct {
+	hash     string
+	compiled wazero.CompiledModule
+	runtime  *Runtime
+}
+
+// Result is the outcome of a call.
+type Result struct {
+	Output []byte
+	Logs   []string
+}
+
+type logsKey struct{}
+
+func NewRuntime(ctx context.Context, limits Limits) (*Runtime, error) {
+	config := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if pages := limits.MemoryBytes / pageSize; pages > 0 && pages < 65536 {
+		config = config.WithMemoryLimitPages(uint32(pages))
+	}
+
+	r := wazero.NewRuntimeWithConfig(ctx, config)
+
+	_, err := r.NewHostModuleBuilder(HostModule).
+		NewFunctionBuilder().
+		WithFunc(hostLog).
+		Export(LogFunction).
+		Instantiate(ctx)
+	if err != nil {
+		_ = r.Close(ctx)
+		return nil, fmt.Errorf("instantiate host module: %w", err)
+	}
+
+	return &Runtime{runtime: r, limits: limits, modules: map[string]*Module{}}, nil
+}
+
+// Hash returns the content hash identifying a module.
+func Hash(code []byte) string {
+	sum := sha256.Sum256(code)
+	return hex.EncodeToString(sum[:])
+}
+
+// IsHash reports whether s has the format of a module hash.
+func IsHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil && strings.ToLower(s) == s
+}
+
+// Cached returns the compiled module with the given hash if it was already
+// compiled by this runtime.
+func (r *Runtime) Cached(hash string) (*Module, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.modules[hash]
+	return m, ok
+}
+
+// Compile validates the module against the ABI and compiles it.
+func (r *Runtime) Compile(ctx context.Context, code []byte) (*Module, error) {
+	hash := Hash(code)
+	if m, ok := r.Cached(hash); ok {
+		return m, nil
+	}
+
+	compiled, err := r.runtime.CompileModule(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module: %w", err)
+	}
+	if err := checkABI(compiled); err != nil {
+		_ = compiled.Close(ctx)
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.modules[hash]; ok {
+		_ = compiled.Close(ctx)
+		return m, nil
+	}
+	m := &Module{hash: hash, compiled: compiled, runtime: r}
+	r.modules[hash] = m
+	return m, nil
+}
+
+func (r *Runtime) Close(ctx context.Context) error {
+	return r.runtime.Close(ctx)
+}
+
+func checkABI(compiled wazero.CompiledModule) error {
+	for _, fn := range compiled.ImportedFunctions() {
+		module, name, _ := fn.Import()
+		if module != HostModule || name != LogFunction {
+			return fmt.Errorf("invalid module: import %s.%s is not allowed, only %s.%s is available", module, name, HostModule, LogFunction)
+		}
+		if !sameSignature(fn, []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, nil) {
+			return fmt.Errorf("invalid module: %s.%s must have the signature (i32, i32)", HostModule, LogFunction)
+		}
+	}
+	if len(compiled.ImportedMemories()) > 0 {
+		return fmt.Errorf("invalid module: memory imports are not allowed")
+	}
+	if _, ok := compiled.ExportedMemories()[MemoryExport]; !ok {
+		return fmt.Errorf("invalid module: it must export its memory as %q", MemoryExport)
+	}
+	alloc, ok := compiled.ExportedFunctions()[AllocFunction]
+	if !ok || !sameSignature(alloc, []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}) {
+		return fmt.Errorf("invalid module: it must export %s(size i32) i32", AllocFunction)
+	}
+	return nil
+}
+
+func sameSignature(fn api.FunctionDefinition, params, results []api.ValueType) bool {
+	return string(fn.ParamTypes()) == string(params) && string(fn.ResultTypes()) == string(results)
+}
+
+func (m *Module) Hash() string {
+	return m.hash
+}
+
+// HasEntry reports whether the module exports name with the entry signature.
+func (m *Module) HasEntry(name string) bool {
+	fn, ok := m.compiled.ExportedFunctions()[name]
+	return ok && sameSignature(fn,
+		[]api.ValueType{api.ValueTypeI32, api.ValueTypeI32},
+		[]api.ValueType{api.ValueTypeI64},
+	)
+}
+
+// Call instantiates the module, passes input to the entry function and
+// returns a copy of its output.
+func (m *Module) Call(ctx context.Context, entry string, input []byte) (*Result, error) {
+	if !m.HasEntry(entry) {
+		return nil, fmt.Errorf("module must export %s(ptr i32, len i32) i64", entry)
+	}
+
+	logs := &logBuffer{max: m.runtime.limits.MaxLogLines}
+	callCtx := context.WithValue(ctx, logsKey{}, logs)
+	if m.runtime.limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(callCtx, m.runtime.limits.Timeout)
+		defer cancel()
+	}
+
+	result := &Result{}
+	output, err := m.call(callCtx, entry, input)
+	result.Logs = logs.lines()
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return result, ErrTimeout
+		}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		return result, fmt.Errorf("module error: %w", err)
+	}
+	result.Output = output
+	return result, nil
+}
+
+func (m *Module) call(ctx context.Context, entry string, input []byte) ([]byte, error) {
+	instance, err := m.runtime.runtime.InstantiateModule(ctx, m.compiled,
+		wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, err
+	}
+	defer instance.Close(context.Background())
+
+	allocated, err := instance.ExportedFunction(AllocFunction).Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", AllocFunction, err)
+	}
+	ptr := uint32(allocated[0])
+	memory := instance.Memory()
+	if !memory.Write(ptr, input) {
+		return nil, fmt.Errorf("%s returned an out of range pointer", AllocFunction)
+	}
+
+	packed, err := instance.ExportedFunction(entry).Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", entry, err)
+	}
+	outPtr, outLen := uint32(packed[0]>>32), uint32(packed[0])
+	output, ok := memory.Read(outPtr, outLen)
+	if !ok {
+		return nil, fmt.Errorf("%s returned an out of range output", entry)
+	}
+	return append([]byte(nil), output...), nil
+}
+
+func hostLog(ctx context.Context, mod api.Module, ptr, length uint32) {
+	logs, ok := ctx.Value(logsKey{}).(*logBuffer)
+	if !ok {
+		return
+	}
+	if length > maxLogLength {
+		length = maxLogLength
+	}
+	line, ok := mod.Memory().Read(ptr, length)
+	if !ok {
+		return
+	}
+	logs.add(string(line))
+}
+
+// logBuffer is only written by the goroutine running the call.
+type logBuffer struct {
+	max     int
+	entries []string
+	dropped int
+}
+
+func (b *logBuffer) add(line string) {
+	if len(b.entries) >= b.max {
+		b.dropped++
+		return
+	}
+	b.entries = append(b.entries, line)
+}
+
+func (b *logBuffer) lines() []string {
+	if b.dropped > 0 {
+		return append(b.entries, fmt.Sprintf("... %d more lines dropped", b.dropped))
+	}
+	return b.entries
+}
@@ -0,0 +1,315 @@
+// Package xmljson converts documents decoded with encoding/json to XML and
+// back. The mapping follows the usual convention:
+//
+//   - the document is wrapped in a root element;
+//   - object members become child elements, except those whose key starts
+//     with the attribute prefix, which become attributes, and the text key,
+//     which becomes the text of the element;
+//   - array elements become item elements.
+//
+// XML has no types, so values decoded from XML are strings, and an empty
+// element decodes as an empty string.
+package xmljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	DefaultRootElement = "root"
+	DefaultArrayItem   = "item"
+	DefaultAttrPrefix  = "@"
+	DefaultTextKey     = "#text"
+
+	// MaxDepth bounds the nesting of the documents converted in either
+	// direction.
+	MaxDepth = 64
+)
+
+// Options configures the mapping. Empty fields take their default value.
+type Options struct {
+	RootElement     string
+	AttributePrefix string
+	ArrayItem       string
+	TextKey         string
+}
+
+func (o Options) withDefaults() Options {
+	if o.RootElement == "" {
+		o.RootElement = DefaultRootElement
+	}
+	if o.AttributePrefix == "" {
+		o.AttributePrefix = DefaultAttrPrefix
+	}
+	if o.ArrayItem == "" {
+		o.ArrayItem = DefaultArrayItem
+	}
+	if o.TextKey == "" {
+		o.TextKey = DefaultTextKey
+	}
+	return o
+}
+
+// Encode serializes a JSON document as an XML document. Keys that are not
+// valid XML names are sanitized, invalid characters being replaced by '_'.
+func Encode(doc any, opts Options) ([]byte, error) {
+	opts = opts.withDefaults()
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	e := &encoder{buf: &buf, opts: opts}
+	if err := e.element(ElementName(opts.RootElement), doc, 1); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type encoder struct {
+	buf  *bytes.Buffer
+	opts Options
+}
+
+func (e *encoder) element(name string, v any, depth int) error {
+	if depth > MaxDepth {
+		return fmt.Errorf("document exceeds the maximum depth of %d", MaxDepth)
+	}
+
+	e.buf.WriteString("<" + name)
+
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var children []string
+		text, hasText := "", false
+		for _, k := range keys {
+			switch {
+			case k == e.opts.TextKey:
+				s, ok := scalar(v[k])
+				if !ok {
+					return fmt.Errorf("%s: text must be a scalar", k)
+				}
+				text, hasText = s, true
+			case strings.HasPrefix(k, e.opts.AttributePrefix) && len(k) > len(e.opts.AttributePrefix):
+				s, ok := scalar(v[k])
+				if !ok {
+					return fmt.Errorf("%s: attributes must be scalars", k)
+				}
+				e.buf.WriteString(" " + ElementName(k[len(e.opts.AttributePrefix):]) + `="`)
+				_ = xml.EscapeText(e.buf, []byte(s))
+				e.buf.WriteString(`"`)
+			default:
+				children = append(children, k)
+			}
+		}
+
+		e.buf.WriteString(">")
+		if hasText {
+			_ = xml.EscapeText(e.buf, []byte(text))
+		}
+		for _, k := range children {
+			if err := e.element(ElementName(k), v[k], depth+1); err != nil {
+				return err
+			}
+		}
+	case []any:
+		e.buf.WriteString(">")
+		for _, item := range v {
+			if err := e.element(ElementName(e.opts.ArrayItem), item, depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		e.buf.WriteString(">")
+		s, _ := scalar(v)
+		_ = xml.EscapeText(e.buf, []byte(s))
+	}
+
+	e.buf.WriteString("</" + name + ">")
+	return nil
+}
+
+func scalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// ElementName turns s into a valid XML name.
+func ElementName(s string) string {
+	if s == "" {
+		return "_"
+	}
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case isNameChar(r, i == 0):
+		case i == 0 && isNameChar(r, false):
+			sb.WriteByte('_')
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func isNameChar(r rune, first bool) bool {
+	if unicode.IsLetter(r) || r == '_' {
+		return true
+	}
+	if first {
+		return false
+	}
+	return unicode.IsDigit(r) || r == '-' || r == '.'
+}
+
+// Decode parses an XML document into a JSON document: the content of the
+// root element, which is the reverse of Encode.
+func Decode(data []byte, opts Options) (any, error) {
+	opts = opts.withDefaults()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("invalid XML: no root element")
+			}
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			root, err := parseElement(dec, start, 1)
+			if err != nil {
+				return nil, err
+			}
+			return root.value(opts), nil
+		}
+	}
+}
+
+type element struct {
+	attrs    []xml.Attr
+	children []child
+	text     strings.Builder
+}
+
+type child struct {
+	name string
+	node *element
+}
+
+func parseElement(dec *xml.Decoder, start xml.StartElement, depth int) (*element, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("document exceeds the maximum depth of %d", MaxDepth)
+	}
+
+	el := &element{attrs: start.Attr}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node, err := parseElement(dec, t, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			el.children = append(el.children, child{name: t.Name.Local, node: node})
+		case xml.CharData:
+			el.text.Write(t)
+		case xml.EndElement:
+			return el, nil
+		}
+	}
+}
+
+func (el *element) value(opts Options) any {
+	text := el.text.String()
+	if strings.TrimSpace(text) == "" {
+		text = ""
+	}
+
+	if len(el.attrs) == 0 && len(el.children) == 0 {
+		return text
+	}
+
+	if len(el.attrs) == 0 && text == "" && el.onlyItems(opts.ArrayItem) {
+		items := make([]any, len(el.children))
+		for i, c := range el.children {
+			items[i] = c.node.value(opts)
+		}
+		return items
+	}
+
+	obj := map[string]any{}
+	for _, attr := range el.attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		obj[opts.AttributePrefix+attr.Name.Local] = attr.Value
+	}
+	if text != "" {
+		obj[opts.TextKey] = text
+	}
+	for _, c := range el.children {
+		v := c.node.value(opts)
+		existing, ok := obj[c.name]
+		if !ok {
+			obj[c.name] = v
+			continue
+		}
+		if list, isList := existing.(repeated); isList {
+			obj[c.name] = append(list, v)
+		} else {
+			obj[c.name] = repeated{existing, v}
+		}
+	}
+	for k, v := range obj {
+		if list, ok := v.(repeated); ok {
+			obj[k] = []any(list)
+		}
+	}
+	return obj
+}
+
+// repeated marks the values of an element name seen several times, so that
+// they are told apart from a single value that is already an array.
+type repeated []any
+
+func (el *element) onlyItems(name string) bool {
+	if len(el.children) == 0 {
+		return false
+	}
+	for _, c := range el.children {
+		if c.name != name {
+			return false
+		}
+	}
+	return true
+}